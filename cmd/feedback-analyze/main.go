@@ -0,0 +1,37 @@
+// Command feedback-analyze scores the sentiment and topics of feedback saved
+// before it was scored on creation:
+//
+//	go run ./cmd/feedback-analyze
+//
+// After changing the lexicon, rescore all feedback with -all.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	database "wow-bato-backend/internal"
+	"wow-bato-backend/internal/services"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	all := flag.Bool("all", false, "rescore feedback that was already analyzed")
+	flag.Parse()
+
+	if err := godotenv.Load(".env"); err != nil {
+		log.Printf("No .env file loaded: %v", err)
+	}
+
+	db, err := database.ConnectDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	scored, err := services.NewFeedbackService(db).Reanalyze(*all)
+	fmt.Printf("Analyzed %d feedback\n", scored)
+	if err != nil {
+		log.Fatalf("Analysis incomplete: %v", err)
+	}
+}
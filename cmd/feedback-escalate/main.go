@@ -0,0 +1,37 @@
+// Command feedback-escalate escalates citizen feedback that officials left
+// unanswered past the escalation target of their barangay.
+//
+// Run it from cron, hourly for instance:
+//
+//	go run ./cmd/feedback-escalate
+//
+// Feedback is escalated once, so repeated runs only mail about feedback that
+// became overdue since the last one.
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+	database "wow-bato-backend/internal"
+	"wow-bato-backend/internal/services"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	if err := godotenv.Load(".env"); err != nil {
+		log.Printf("No .env file loaded: %v", err)
+	}
+
+	db, err := database.ConnectDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	escalated, err := services.NewResponseService(db).EscalateOverdue(time.Now())
+	fmt.Printf("Escalated %d feedback\n", escalated)
+	if err != nil {
+		log.Fatalf("Escalation incomplete: %v", err)
+	}
+}
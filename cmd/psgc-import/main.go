@@ -0,0 +1,52 @@
+// Command psgc-import loads the PSA PSGC publication into the database.
+//
+// Save the PSGC sheet of the publication workbook as CSV, then run
+//
+//	go run ./cmd/psgc-import -file PSGC-Publication.csv
+//
+// Regions, provinces and cities/municipalities are upserted by code, so the
+// import can be repeated with each quarterly publication. Existing barangays
+// are linked by name and city, the ones it could not match are listed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	database "wow-bato-backend/internal"
+	"wow-bato-backend/internal/services"
+)
+
+func main() {
+	path := flag.String("file", "", "PSGC sheet saved as CSV")
+	flag.Parse()
+
+	if *path == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("Failed to open PSGC file: %v", err)
+	}
+	defer file.Close()
+
+	db, err := database.ConnectDB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	summary, err := services.NewPSGCService(db).Import(file)
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	fmt.Printf("Imported %d regions, %d provinces and %d cities/municipalities\n",
+		summary.Regions, summary.Provinces, summary.CityMunicipalities)
+	fmt.Printf("Linked %d barangays\n", summary.BarangaysLinked)
+	for _, name := range summary.BarangaysUnmatched {
+		fmt.Printf("  no PSGC match: %s\n", name)
+	}
+}
@@ -18,17 +18,40 @@ import (
 )
 
 type App struct {
-	DB                     *gorm.DB
-	BarangayHandlers       *handlers.BarangayHandlers
-	UserHandlers           *handlers.UserHandlers
-	BudgetItemHandlers     *handlers.BudgetItemHandlers
-	FeedbackHandlers       *handlers.FeedbackHandlers
-	FeedbackReplyHandlers  *handlers.FeedbackReplyHandlers
-	BudgetCategoryHandlers *handlers.BudgetCategoryHandlers
-	ProjectHandlers        *handlers.ProjectHandlers
+	DB                      *gorm.DB
+	BarangayHandlers        *handlers.BarangayHandlers
+	UserHandlers            *handlers.UserHandlers
+	BudgetItemHandlers      *handlers.BudgetItemHandlers
+	FeedbackHandlers        *handlers.FeedbackHandlers
+	FeedbackReplyHandlers   *handlers.FeedbackReplyHandlers
+	BudgetCategoryHandlers  *handlers.BudgetCategoryHandlers
+	ProjectHandlers         *handlers.ProjectHandlers
+	ResidencyHandlers       *handlers.ResidencyHandlers
+	OIDCHandlers            *handlers.OIDCHandlers
+	SearchHandlers          *handlers.SearchHandlers
+	GeographyHandlers       *handlers.GeographyHandlers
+	DashboardHandlers       *handlers.PublicDashboardHandlers
+	OfficialHandlers        *handlers.OfficialHandlers
+	ResolutionHandlers      *handlers.ResolutionHandlers
+	ComplianceHandlers      *handlers.ComplianceHandlers
+	RevenueHandlers         *handlers.RevenueHandlers
+	AmendmentHandlers       *handlers.AmendmentHandlers
+	ExpenseClassHandlers    *handlers.ExpenseClassHandlers
+	ProjectProgressHandlers *handlers.ProjectProgressHandlers
+	ReportHandlers          *handlers.ReportHandlers
+	ModerationHandlers      *handlers.ModerationHandlers
+	ResponseHandlers        *handlers.ResponseHandlers
+	IdentityHandlers        *handlers.IdentityHandlers
+	PollHandlers            *handlers.PollHandlers
+	ProposalHandlers        *handlers.ProposalHandlers
 }
 
 func NewApp() (*App, error) {
+	// pseudonyms of anonymous feedback are derived from the secret
+	if _, err := services.AliasKeyFromEnv(); err != nil {
+		return nil, err
+	}
+
 	db, err := database.ConnectDB()
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
@@ -41,16 +64,52 @@ func NewApp() (*App, error) {
 	feedbackReplyService := services.NewFeedbackReplyService(db)
 	budgetCategoryService := services.NewBudgetCategoryService(db)
 	projectService := services.NewProjectService(db)
+	residencyService := services.NewResidencyService(db)
+	oidcService := services.NewOIDCService(db, services.OIDCConfigFromEnv())
+	searchService := services.NewSearchService(db)
+	psgcService := services.NewPSGCService(db)
+	publicDashboardService := services.NewPublicDashboardService(db)
+	officialService := services.NewOfficialService(db)
+	resolutionService := services.NewResolutionService(db)
+	complianceService := services.NewComplianceService(db)
+	revenueService := services.NewRevenueService(db)
+	amendmentService := services.NewAmendmentService(db)
+	expenseClassService := services.NewExpenseClassService(db)
+	projectProgressService := services.NewProjectProgressService(db)
+	reportService := services.NewReportService(db)
+	moderationService := services.NewModerationService(db)
+	responseService := services.NewResponseService(db)
+	identityService := services.NewIdentityService(db)
+	pollService := services.NewPollService(db)
+	proposalService := services.NewProposalService(db)
 
 	return &App{
-		DB:                     db,
-		BarangayHandlers:       handlers.NewBarangayHandlers(barangayService),
-		UserHandlers:           handlers.NewUserHandlers(userService),
-		BudgetItemHandlers:     handlers.NewBudgetItemHandlers(budgetItemService),
-		FeedbackHandlers:       handlers.NewFeedbackHandlers(feedbackService),
-		FeedbackReplyHandlers:  handlers.NewFeedbackReplyHandlers(feedbackReplyService),
-		BudgetCategoryHandlers: handlers.NewBudgetCategoryHandlers(budgetCategoryService),
-		ProjectHandlers:        handlers.NewProjectHandlers(projectService, budgetCategoryService),
+		DB:                      db,
+		BarangayHandlers:        handlers.NewBarangayHandlers(barangayService),
+		UserHandlers:            handlers.NewUserHandlers(userService),
+		BudgetItemHandlers:      handlers.NewBudgetItemHandlers(budgetItemService),
+		FeedbackHandlers:        handlers.NewFeedbackHandlers(feedbackService),
+		FeedbackReplyHandlers:   handlers.NewFeedbackReplyHandlers(feedbackReplyService),
+		BudgetCategoryHandlers:  handlers.NewBudgetCategoryHandlers(budgetCategoryService),
+		ProjectHandlers:         handlers.NewProjectHandlers(projectService, budgetCategoryService),
+		ResidencyHandlers:       handlers.NewResidencyHandlers(residencyService),
+		OIDCHandlers:            handlers.NewOIDCHandlers(oidcService),
+		SearchHandlers:          handlers.NewSearchHandlers(searchService),
+		GeographyHandlers:       handlers.NewGeographyHandlers(psgcService),
+		DashboardHandlers:       handlers.NewPublicDashboardHandlers(publicDashboardService),
+		OfficialHandlers:        handlers.NewOfficialHandlers(officialService),
+		ResolutionHandlers:      handlers.NewResolutionHandlers(resolutionService),
+		ComplianceHandlers:      handlers.NewComplianceHandlers(complianceService),
+		RevenueHandlers:         handlers.NewRevenueHandlers(revenueService),
+		AmendmentHandlers:       handlers.NewAmendmentHandlers(amendmentService),
+		ExpenseClassHandlers:    handlers.NewExpenseClassHandlers(expenseClassService),
+		ProjectProgressHandlers: handlers.NewProjectProgressHandlers(projectProgressService),
+		ReportHandlers:          handlers.NewReportHandlers(reportService),
+		ModerationHandlers:      handlers.NewModerationHandlers(moderationService),
+		ResponseHandlers:        handlers.NewResponseHandlers(responseService),
+		IdentityHandlers:        handlers.NewIdentityHandlers(identityService),
+		PollHandlers:            handlers.NewPollHandlers(pollService),
+		ProposalHandlers:        handlers.NewProposalHandlers(proposalService),
 	}, nil
 }
 
@@ -66,6 +125,7 @@ func main() {
 	}
 
 	router := gin.Default()
+	router.Use(services.RequestID())
 
 	store := cookie.NewStore([]byte(os.Getenv("SESSION_SECRET")))
 	store.Options(sessions.Options{
@@ -76,12 +136,16 @@ func main() {
 	})
 
 	router.Use(sessions.Sessions("mysession", store))
+	router.Use(app.UserHandlers.ValidateSession)
+
+	router.MaxMultipartMemory = services.MAX_IMAGE_BYTES
+	router.Static(services.UPLOAD_URL_PREFIX, services.UploadDirectory())
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:3000"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", services.REQUEST_ID_HEADER},
+		ExposeHeaders:    []string{"Content-Length", services.REQUEST_ID_HEADER},
 		AllowCredentials: true,
 	}))
 
@@ -94,6 +158,24 @@ func main() {
 		routes.RegisterProjectRoutes(v1, app.ProjectHandlers)
 		routes.RegisterFeedbackRoutes(v1, app.FeedbackHandlers)
 		routes.RegisterFeedbackReplyRoutes(v1, app.FeedbackReplyHandlers)
+		routes.RegisterResidencyRoutes(v1, app.ResidencyHandlers)
+		routes.RegisterOIDCRoutes(v1, app.OIDCHandlers)
+		routes.RegisterSearchRoutes(v1, app.SearchHandlers)
+		routes.RegisterGeographyRoutes(v1, app.GeographyHandlers)
+		routes.RegisterPublicDashboardRoutes(v1, app.DashboardHandlers)
+		routes.RegisterOfficialRoutes(v1, app.OfficialHandlers)
+		routes.RegisterResolutionRoutes(v1, app.ResolutionHandlers)
+		routes.RegisterComplianceRoutes(v1, app.ComplianceHandlers)
+		routes.RegisterRevenueRoutes(v1, app.RevenueHandlers)
+		routes.RegisterAmendmentRoutes(v1, app.AmendmentHandlers)
+		routes.RegisterExpenseClassRoutes(v1, app.ExpenseClassHandlers)
+		routes.RegisterProjectProgressRoutes(v1, app.ProjectProgressHandlers)
+		routes.RegisterReportRoutes(v1, app.ReportHandlers)
+		routes.RegisterModerationRoutes(v1, app.ModerationHandlers)
+		routes.RegisterResponseRoutes(v1, app.ResponseHandlers)
+		routes.RegisterIdentityRoutes(v1, app.IdentityHandlers)
+		routes.RegisterPollRoutes(v1, app.PollHandlers)
+		routes.RegisterProposalRoutes(v1, app.ProposalHandlers)
 	}
 
 	router.Run(":8080")
@@ -14,10 +14,18 @@ func ConnectDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Barangay{}, &models.Budget_Category{}, &models.Budget_Item{}, &models.Project{}, &models.Feedback{}, &models.FeedbackReply{})
+	err = db.AutoMigrate(&models.Region{}, &models.Province{}, &models.CityMunicipality{}, &models.User{}, &models.Barangay{}, &models.ExpenseClass{}, &models.Budget_Category{}, &models.Revenue{}, &models.Budget_Item{}, &models.Project{}, &models.ProjectMilestone{}, &models.ProjectPhoto{}, &models.Feedback{}, &models.FeedbackTag{}, &models.FeedbackTopic{}, &models.FeedbackReaction{}, &models.FeedbackReply{}, &models.ResponseTarget{}, &models.ContentFlag{}, &models.ModerationAction{}, &models.ModerationAppeal{}, &models.IdentityModerator{}, &models.IdentityDisclosure{}, &models.ResidencyClaim{}, &models.Official{}, &models.OfficialCommittee{}, &models.Resolution{}, &models.ResolutionVote{}, &models.Proposal{}, &models.ProposalPhoto{}, &models.ProposalEndorsement{}, &models.Poll{}, &models.PollCandidate{}, &models.PollVoter{}, &models.PollBallot{}, &models.PollChoice{}, &models.Amendment{}, &models.AmendmentLine{})
 	if err != nil {
 		return nil, err
 	}
 
+	if err := migrateSearch(db); err != nil {
+		return nil, err
+	}
+
+	if err := seedExpenseClasses(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"fmt"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func expenseClass(code, parent, name string) models.ExpenseClass {
+	class := models.ExpenseClass{Code: code, Name: name}
+	if parent != "" {
+		class.ParentCode = &parent
+	}
+	return class
+}
+
+// defaultExpenseClasses follows the allotment classes and the object codes
+// of the government chart of accounts barangays use most. Parents come
+// before their children.
+var defaultExpenseClasses = []models.ExpenseClass{
+	expenseClass("PS", "", "Personnel Services"),
+	expenseClass("MOOE", "", "Maintenance and Other Operating Expenses"),
+	expenseClass("CO", "", "Capital Outlay"),
+
+	expenseClass("5-01-01-010", "PS", "Salaries and Wages - Regular"),
+	expenseClass("5-01-02-010", "PS", "Personnel Economic Relief Allowance (PERA)"),
+	expenseClass("5-01-02-990", "PS", "Other Bonuses and Allowances"),
+	expenseClass("5-01-03-010", "PS", "Retirement and Life Insurance Premiums"),
+
+	expenseClass("5-02-01-010", "MOOE", "Traveling Expenses - Local"),
+	expenseClass("5-02-02-010", "MOOE", "Training Expenses"),
+	expenseClass("5-02-03-010", "MOOE", "Office Supplies Expenses"),
+	expenseClass("5-02-03-090", "MOOE", "Fuel, Oil and Lubricants Expenses"),
+	expenseClass("5-02-04-010", "MOOE", "Water Expenses"),
+	expenseClass("5-02-04-020", "MOOE", "Electricity Expenses"),
+	expenseClass("5-02-13-040", "MOOE", "Repairs and Maintenance - Buildings and Other Structures"),
+	expenseClass("5-02-99-990", "MOOE", "Other Maintenance and Operating Expenses"),
+
+	expenseClass("1-07-03-010", "CO", "Road Networks"),
+	expenseClass("1-07-03-040", "CO", "Water Supply Systems"),
+	expenseClass("1-07-04-010", "CO", "Buildings"),
+	expenseClass("1-07-05-020", "CO", "Office Equipment"),
+	expenseClass("1-07-05-030", "CO", "Information and Communication Technology Equipment"),
+}
+
+// seedExpenseClasses adds the default classification on first start. Codes
+// that already exist are left alone so changes made by an admin stay.
+func seedExpenseClasses(db *gorm.DB) error {
+	classes := make([]models.ExpenseClass, len(defaultExpenseClasses))
+	copy(classes, defaultExpenseClasses)
+
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&classes).Error; err != nil {
+		return fmt.Errorf("failed to seed expense classes: %w", err)
+	}
+	return nil
+}
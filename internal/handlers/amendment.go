@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AmendmentHandlers struct {
+	svc *services.AmendmentService
+}
+
+func NewAmendmentHandlers(svc *services.AmendmentService) *AmendmentHandlers {
+	return &AmendmentHandlers{svc: svc}
+}
+
+func (h *AmendmentHandlers) AddAmendment(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var amendment models.NewAmendment
+	if !services.BindJSON(c, &amendment) {
+		return
+	}
+
+	amendmentID, err := h.svc.AddAmendment(barangay_ID, amendment)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Amendment recorded", "id": amendmentID})
+}
+
+func (h *AmendmentHandlers) GetAmendments(c *gin.Context) {
+
+	amendments, meta, err := h.svc.ListAmendments(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Amendments retrieved", "data": amendments, "meta": meta})
+}
+
+func (h *AmendmentHandlers) GetAmendment(c *gin.Context) {
+
+	amendment, err := h.svc.GetAmendment(c.Param("amendmentID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Amendment retrieved", "data": amendment})
+}
+
+func (h *AmendmentHandlers) GetAmendedBudget(c *gin.Context) {
+
+	budget, err := h.svc.AmendedBudget(c.Param("barangay_ID"), c.Param("fiscal_year"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Amended budget retrieved", "data": budget})
+}
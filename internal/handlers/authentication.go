@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"wow-bato-backend/internal/models"
 	"wow-bato-backend/internal/services"
@@ -20,11 +21,14 @@ func NewUserHandlers(svc *services.UserService) *UserHandlers {
 func (h *UserHandlers) RegisterUser(c *gin.Context) {
 	var registerUser models.RegisterUser
 
-	services.BindJSON(c, &registerUser)
+	if !services.BindJSON(c, &registerUser) {
+		return
+	}
 
-	err := h.svc.RegisterUser(registerUser)
-
-	services.CheckServiceError(c, err)
+	if err := h.svc.RegisterUser(registerUser); err != nil {
+		services.WriteError(c, err)
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "User registered successfully"})
 }
@@ -32,16 +36,23 @@ func (h *UserHandlers) RegisterUser(c *gin.Context) {
 func (h *UserHandlers) LoginUser(c *gin.Context) {
 	var loginUser models.LoginUser
 
-	services.BindJSON(c, &loginUser)
+	if !services.BindJSON(c, &loginUser) {
+		return
+	}
 
 	user, err := h.svc.LoginUser(loginUser)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	session := sessions.Default(c)
+	session.Clear()
 	services.SetSession(session, user)
 
 	err = session.Save()
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "User logged in successfully", "sessionStatus": session.Get("authenticated"), "role": session.Get("user_role")})
 
@@ -59,7 +70,9 @@ func (h *UserHandlers) LogoutUser(c *gin.Context) {
 	})
 
 	err := session.Save()
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "User logged out successfully"})
 }
@@ -67,6 +80,9 @@ func (h *UserHandlers) LogoutUser(c *gin.Context) {
 func (h *UserHandlers) CheckAuth(c *gin.Context) {
 
 	session := services.CheckAuthentication(c)
+	if session == nil {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"sessionStatus": session.Get("authenticated"), "role": session.Get("user_role"),
 		"user_id": session.Get("user_id"), "barangay_id": session.Get("barangay_id"), "barangay_name": session.Get("barangay_name")})
@@ -74,13 +90,170 @@ func (h *UserHandlers) CheckAuth(c *gin.Context) {
 
 func (h *UserHandlers) GetUserProfile(c *gin.Context) {
 
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	userProfile, err := h.svc.GetUserProfile(userID)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "User profile fetched successfully", "data": userProfile})
+}
+
+// sessionUser returns the authenticated session and its user ID, writing
+// the error response itself when either is missing.
+func sessionUser(c *gin.Context) (sessions.Session, uint, bool) {
+
 	session := services.CheckAuthentication(c)
+	if session == nil {
+		return nil, 0, false
+	}
+
+	userID, ok := session.Get("user_id").(uint)
+	if !ok {
+		services.WriteError(c, services.ErrInvalidSession)
+		return nil, 0, false
+	}
 
-	userID := session.Get("user_id")
+	return session, userID, true
+}
 
-	userProfile, err := h.svc.GetUserProfile(userID.(uint))
+// ValidateSession logs out sessions issued before the user's last password
+// change or belonging to a deleted user. Registered as middleware after the
+// session store.
+func (h *UserHandlers) ValidateSession(c *gin.Context) {
 
-	services.CheckServiceError(c, err)
+	session := sessions.Default(c)
+	if session.Get("authenticated") != true {
+		c.Next()
+		return
+	}
 
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "User profile fetched successfully", "data": userProfile})
+	userID, _ := session.Get("user_id").(uint)
+	sessionVersion, _ := session.Get("session_version").(uint)
+
+	currentVersion, err := h.svc.GetSessionVersion(userID)
+	if err != nil && !errors.Is(err, services.ErrUserNotFound) {
+		services.WriteError(c, err)
+		return
+	}
+	if err != nil || currentVersion != sessionVersion {
+		session.Clear()
+		session.Save()
+	}
+
+	c.Next()
+}
+
+func (h *UserHandlers) UpdateUserProfile(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var update models.UpdateProfile
+	if !services.BindJSON(c, &update) {
+		return
+	}
+
+	if err := h.svc.UpdateUserProfile(userID, update); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "User profile updated successfully"})
+}
+
+func (h *UserHandlers) RequestEmailChange(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var change models.ChangeEmail
+	if !services.BindJSON(c, &change) {
+		return
+	}
+
+	if err := h.svc.RequestEmailChange(userID, change); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Confirmation link sent to the new email address"})
+}
+
+func (h *UserHandlers) ConfirmEmailChange(c *gin.Context) {
+
+	var confirm models.ConfirmEmailChange
+	if !services.BindJSON(c, &confirm) {
+		return
+	}
+
+	if err := h.svc.ConfirmEmailChange(confirm.Token); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Email address updated successfully"})
+}
+
+func (h *UserHandlers) ChangePassword(c *gin.Context) {
+
+	session, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var change models.ChangePassword
+	if !services.BindJSON(c, &change) {
+		return
+	}
+
+	newVersion, err := h.svc.ChangePassword(userID, change)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	// keep this session alive, every other session is now stale
+	session.Set("session_version", newVersion)
+	if err := session.Save(); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Password changed, other sessions have been logged out"})
+}
+
+func (h *UserHandlers) UploadProfilePicture(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("avatar")
+	if err != nil {
+		services.WriteError(c, services.FieldValidationError("avatar", "avatar file is required"))
+		return
+	}
+
+	upload, err := file.Open()
+	if err != nil {
+		services.WriteError(c, services.ValidationError(err.Error()))
+		return
+	}
+	defer upload.Close()
+
+	picture, err := h.svc.UpdateProfilePicture(userID, upload)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Profile picture updated successfully", "data": picture})
 }
@@ -3,6 +3,7 @@ package handlers_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -52,10 +53,15 @@ func TestRegisterUser(t *testing.T) {
 	r := gin.Default()
 	r.POST("/register", handlersObj.RegisterUser)
 
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "users"`).
+		WithArgs("test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO "users"`).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
-			"test@example.com", sqlmock.AnyArg(), "John", "Doe", "resident", uint(1), "+63 912 345 6789").
+			"test@example.com", sqlmock.AnyArg(), "John", "Doe", models.RoleCitizen, 0, "+639123456789", false, uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`INSERT INTO "residency_claims"`).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
@@ -64,7 +70,6 @@ func TestRegisterUser(t *testing.T) {
 		Password:    "password123",
 		FirstName:   "John",
 		LastName:    "Doe",
-		Role:        "resident",
 		Barangay_ID: "1",
 		Contact:     "+63 912 345 6789",
 	}
@@ -110,10 +115,10 @@ func TestLoginUser(t *testing.T) {
 	r.POST("/login", handlersObj.LoginUser)
 
 	hashedPassword, _ := services.HashPassword("password123")
-	mock.ExpectQuery(`SELECT id, password, role, barangay_id FROM "users" WHERE email = \$1`).
+	mock.ExpectQuery(`SELECT id, password, role, barangay_id, session_version FROM "users" WHERE email = \$1`).
 		WithArgs("test@example.com").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "role", "barangay_id"}).
-			AddRow(1, hashedPassword, "resident", 1))
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "role", "barangay_id", "session_version"}).
+			AddRow(1, hashedPassword, "resident", 1, 0))
 	mock.ExpectQuery(`SELECT name FROM "barangays" WHERE id = \$1`).
 		WithArgs(1).
 		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Barangay Uno"))
@@ -250,9 +255,9 @@ func TestGetUserProfile(t *testing.T) {
 	svc := services.NewUserService(gormDB)
 	handlersObj := handlers.NewUserHandlers(svc)
 
-	profileRows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "role", "contact"}).
-		AddRow(1, "test@example.com", "John", "Doe", "resident", "+63 912 345 6789")
-	mock.ExpectQuery(`SELECT id, email, first_name, last_name, role, contact FROM "users" WHERE id = \$1`).
+	profileRows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "role", "contact", "profile_picture", "profile_thumbnail", "pending_email", "residency_verified"}).
+		AddRow(1, "test@example.com", "John", "Doe", "resident", "+63 912 345 6789", nil, nil, nil, false)
+	mock.ExpectQuery(`SELECT id, email, first_name, last_name, role, contact, profile_picture, profile_thumbnail, pending_email, residency_verified FROM "users" WHERE id = \$1`).
 		WithArgs(1).
 		WillReturnRows(profileRows)
 
@@ -281,3 +286,192 @@ func TestGetUserProfile(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestUpdateUserProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	dialector := postgres.New(postgres.Config{
+		Conn:       db,
+		DriverName: "postgres",
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	svc := services.NewUserService(gormDB)
+	handlersObj := handlers.NewUserHandlers(svc)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET "last_name"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs("Dela Cruz", sqlmock.AnyArg(), uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	r.PUT("/profile/update", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
+		sess.Save()
+		handlersObj.UpdateUserProfile(c)
+	})
+
+	jsonValue, _ := json.Marshal(models.UpdateProfile{LastName: "Dela Cruz"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/profile/update", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("User profile updated successfully")) {
+		t.Errorf("Expected success message, got %s", w.Body.String())
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestChangePasswordUnauthenticated(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	dialector := postgres.New(postgres.Config{
+		Conn:       db,
+		DriverName: "postgres",
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	svc := services.NewUserService(gormDB)
+	handlersObj := handlers.NewUserHandlers(svc)
+
+	r.PUT("/profile/change-password", handlersObj.ChangePassword)
+
+	jsonValue, _ := json.Marshal(models.ChangePassword{CurrentPassword: "password123", NewPassword: "newPassword123"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/profile/change-password", bytes.NewBuffer(jsonValue))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401, got %d", w.Code)
+	}
+}
+
+func TestValidateSessionClearsStaleSession(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	dialector := postgres.New(postgres.Config{
+		Conn:       db,
+		DriverName: "postgres",
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	svc := services.NewUserService(gormDB)
+	handlersObj := handlers.NewUserHandlers(svc)
+
+	mock.ExpectQuery(`SELECT id, session_version FROM "users" WHERE id = \$1`).
+		WithArgs(uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "session_version"}).AddRow(1, 2))
+
+	r.GET("/checkAuth", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
+		sess.Set("session_version", uint(1))
+		handlersObj.ValidateSession(c)
+	}, handlersObj.CheckAuth)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/checkAuth", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a session issued before a password change, got %d", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestValidateSessionKeepsSessionOnDatabaseError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	dialector := postgres.New(postgres.Config{
+		Conn:       db,
+		DriverName: "postgres",
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	svc := services.NewUserService(gormDB)
+	handlersObj := handlers.NewUserHandlers(svc)
+
+	mock.ExpectQuery(`SELECT id, session_version FROM "users" WHERE id = \$1`).
+		WithArgs(uint(1), 1).
+		WillReturnError(errors.New("connection reset"))
+
+	var cleared bool
+	r.GET("/checkAuth", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
+		sess.Set("session_version", uint(1))
+		handlersObj.ValidateSession(c)
+		cleared = sess.Get("authenticated") == nil
+	}, handlersObj.CheckAuth)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/checkAuth", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the session version cannot be read, got %d", w.Code)
+	}
+	if cleared {
+		t.Error("Expected the session to survive a database error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
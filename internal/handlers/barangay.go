@@ -18,13 +18,19 @@ func NewBarangayHandlers(svc *services.BarangayService) *BarangayHandlers {
 
 func (h *BarangayHandlers) AddBarangay(c *gin.Context) {
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	var newBarangay models.AddBarangay
-	services.BindJSON(c, &newBarangay)
+	if !services.BindJSON(c, &newBarangay) {
+		return
+	}
 
 	err := h.svc.AddNewBarangay(newBarangay)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Successfully Added New Barangay"})
 
@@ -32,25 +38,30 @@ func (h *BarangayHandlers) AddBarangay(c *gin.Context) {
 
 func (h *BarangayHandlers) GetAllBarangay(c *gin.Context){
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
-	page := c.Query("page")
-	limit := c.Query("limit")
+	barangay, meta, err := h.svc.GetAllBarangay(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
-	barangay, err := h.svc.GetAllBarangay(limit, page)
-	services.CheckServiceError(c, err)
-
-	c.IndentedJSON(http.StatusOK, gin.H{"message": "Successfully fetched Barangays", "data": barangay})
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Successfully fetched Barangays", "data": barangay, "meta": meta})
 }
 
 func (h *BarangayHandlers) GetSingleBarangay(c *gin.Context){
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	barangay_ID := c.Param("barangay_ID")
 
 	barangay, err := h.svc.GetSingleBarangay(barangay_ID)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Retrieved specific barangay", "data": barangay})
 }
@@ -58,12 +69,16 @@ func (h *BarangayHandlers) GetSingleBarangay(c *gin.Context){
 
 func (h *BarangayHandlers) DeleteBarangay(c *gin.Context) {
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	barangay_ID := c.Param("barangay_ID")
 
 	err := h.svc.DeleteBarangay(barangay_ID)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Successfully deleted the Barangay"})
 }
@@ -71,15 +86,21 @@ func (h *BarangayHandlers) DeleteBarangay(c *gin.Context) {
 
 func (h *BarangayHandlers) UpdateBarangay(c *gin.Context) {
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	barangay_ID := c.Param("barangay_ID")
 
 	var barangayUpdate models.UpdateBarangay
-	services.BindJSON(c, &barangayUpdate)
+	if !services.BindJSON(c, &barangayUpdate) {
+		return
+	}
 
 	err := h.svc.UpdateBarangay(barangay_ID, barangayUpdate)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Successfully Updated Barangay"})
 }
@@ -87,7 +108,9 @@ func (h *BarangayHandlers) UpdateBarangay(c *gin.Context) {
 func (h *BarangayHandlers) GetBarangayOptions(c *gin.Context){
    
     barangay, err := h.svc.OptionBarangay()
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Barangays found", "data": barangay})
 }
@@ -95,8 +118,9 @@ func (h *BarangayHandlers) GetBarangayOptions(c *gin.Context){
 func (h *BarangayHandlers) GetPublicBarangay(c *gin.Context){
 
     barangays, err := h.svc.AllBarangaysPublic()
-	services.CheckServiceError(c, err)
-
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "All barangays retrieved","data": barangays})
 }
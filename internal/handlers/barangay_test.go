@@ -108,7 +108,7 @@ func TestGetAllBarangay(t *testing.T) {
 		handlersObj.GetAllBarangay(c)
 	})
 
-	mock.ExpectQuery(`SELECT id, name, city, region FROM "barangays" LIMIT \$1 OFFSET \$2`).
+	mock.ExpectQuery(`SELECT id, name, city, region, psgc_code FROM "barangays" LIMIT \$1 OFFSET \$2`).
 		WithArgs(10, 0).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "city", "region"}).
 			AddRow(1, "Barangay1", "City1", "Region1").
@@ -162,7 +162,7 @@ func TestGetSingleBarangay(t *testing.T) {
 		handlersObj.GetSingleBarangay(c)
 	})
 
-	mock.ExpectQuery(`SELECT id, name, city, region FROM "barangays" WHERE ID = \$1 LIMIT 1`).
+	mock.ExpectQuery(`SELECT id, name, city, region, psgc_code FROM "barangays" WHERE ID = \$1 LIMIT 1`).
 		WithArgs(1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "city", "region"}).
 			AddRow(1, "Barangay1", "City1", "Region1"))
@@ -399,3 +399,76 @@ func TestGetPublicBarangay(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestGetSingleBarangayErrorEnvelope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	dialector := postgres.New(postgres.Config{
+		Conn:       db,
+		DriverName: "postgres",
+	})
+	gormDB, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	svc := services.NewBarangayService(gormDB)
+	handlersObj := handlers.NewBarangayHandlers(svc)
+
+	tests := []struct {
+		name          string
+		authenticated bool
+		barangayID    string
+		wantStatus    int
+		wantCode      services.ErrorKind
+	}{
+		{name: "Unauthenticated", barangayID: "1", wantStatus: http.StatusUnauthorized, wantCode: services.KindUnauthorized},
+		{name: "Invalid ID", authenticated: true, barangayID: "abc", wantStatus: http.StatusBadRequest, wantCode: services.KindValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(services.RequestID())
+			store := cookie.NewStore([]byte("secret"))
+			r.Use(sessions.Sessions("mysession", store))
+
+			r.GET("/barangay/:barangay_ID", func(c *gin.Context) {
+				if tt.authenticated {
+					sessions.Default(c).Set("authenticated", true)
+				}
+				handlersObj.GetSingleBarangay(c)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/barangay/"+tt.barangayID, nil)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			// a single JSON document proves the handler stopped after the error
+			var body struct {
+				Error services.ErrorResponse `json:"error"`
+			}
+			decoder := json.NewDecoder(w.Body)
+			if err := decoder.Decode(&body); err != nil {
+				t.Fatalf("Failed to decode envelope: %v", err)
+			}
+			if decoder.More() {
+				t.Errorf("Expected a single response body, got trailing data")
+			}
+			if body.Error.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, body.Error.Code)
+			}
+			if body.Error.RequestID == "" || body.Error.RequestID != w.Header().Get(services.REQUEST_ID_HEADER) {
+				t.Errorf("Expected request ID %q in envelope, got %q", w.Header().Get(services.REQUEST_ID_HEADER), body.Error.RequestID)
+			}
+		})
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"wow-bato-backend/internal/models"
 	"wow-bato-backend/internal/services"
 
-	"sync"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,108 +19,87 @@ func NewBudgetCategoryHandlers(svc *services.BudgetCategoryService) *BudgetCateg
 
 func (h *BudgetCategoryHandlers) AddBudgetCategory(c *gin.Context){
 	
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	var newBudgetCategory models.NewBudgetCategory
-	services.BindJSON(c, &newBudgetCategory)
+	if !services.BindJSON(c, &newBudgetCategory) {
+		return
+	}
 
 	err := h.svc.AddBudgetCategory(newBudgetCategory)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "New Budget Category Added"})
 }
 
 func (h *BudgetCategoryHandlers) DeleteBudgetCategory(c *gin.Context){
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	budget_ID := c.Param("budget_ID")
 
 	err := h.svc.DeleteBudgetCategory(budget_ID)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Budget Category Deleted"})
 }
 
 func (h *BudgetCategoryHandlers) UpdateBudgetCategory(c *gin.Context){
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	budget_ID := c.Param("budget_ID")
 
 	var updateBudgetCategory models.UpdateBudgetCategory
-	services.BindJSON(c, &updateBudgetCategory)
+	if !services.BindJSON(c, &updateBudgetCategory) {
+		return
+	}
 
 	err := h.svc.UpdateBudgetCategory(budget_ID, updateBudgetCategory)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Budget Category Updated"})
 }
 
 func (h *BudgetCategoryHandlers) GetAllBudgetCategory(c *gin.Context) {
 	
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
-	limit := c.Query("limit")
-	page := c.Query("page")
 	barangay_ID := c.Param("barangay_ID")
 
-	var (
-		budgetCategories []models.BudgetCategoryResponse
-		count            int64
-		errors           []error
-	)
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex 
-
-	wg.Add(2) 
-
-	go func() {
-		defer wg.Done()
-		result, err := h.svc.GetAllBudgetCategory(barangay_ID, limit, page)
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, err)
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		budgetCategories = result
-		mu.Unlock()
-	}()
-
-	go func() {
-		defer wg.Done()
-		result, err := h.svc.GetBudgetCategoryCount(barangay_ID)
-		if err != nil {
-			mu.Lock()
-			errors = append(errors, err)
-			mu.Unlock()
-			return
-		}
-		mu.Lock()
-		count = result
-		mu.Unlock()
-	}()
-
-	wg.Wait()
-
-	if len(errors) > 0 {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": errors[0].Error()})
+	budgetCategories, meta, err := h.svc.GetAllBudgetCategory(barangay_ID, c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
 		return
 	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{
 		"message": "All Budget Categories Retrieved",
 		"data":    budgetCategories,
-		"count":   count,
+		"count":   meta.Total,
+		"meta":    meta,
 	})
 }
 
 func (h *BudgetCategoryHandlers) GetSingleBudgetCategory(c *gin.Context){
 	
 	session := services.CheckAuthentication(c)
+	if session == nil {
+		return
+	}
 
 	barangay_ID := session.Get("barangay_id").(uint)
 	budget_ID := c.Param("budget_ID")
@@ -129,7 +107,7 @@ func (h *BudgetCategoryHandlers) GetSingleBudgetCategory(c *gin.Context){
 	budgetCategory, err := h.svc.GetBudgetCategory(barangay_ID, budget_ID)
 
 	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		services.WriteError(c, err)
 		return
 	}
 
@@ -11,6 +11,8 @@ import (
 	"wow-bato-backend/internal/services"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -198,17 +200,36 @@ func TestUpdateStatusBudgetItem(t *testing.T) {
 	svc := services.NewBudgetItemService(gormDB)
 	handlersObj := handlers.NewBudgetItemHandlers(svc)
 
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
 	r.PUT("/budget-item/status/:budgetItemID", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(3))
+		sess.Set("user_role", c.GetHeader("X-Test-Role"))
+		sess.Set("barangay_id", uint(1))
 		handlersObj.UpdateStatusBudgetItem(c)
 	})
 
-	mock.ExpectQuery(`SELECT \* FROM "budget_items" WHERE id = \$1 ORDER BY "budget_items"."id" LIMIT 1`).
-		WithArgs(2).
+	// citizens cannot approve or reject budget items
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/budget-item/status/2", bytes.NewBufferString(`{"status":"approve"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", models.RoleCitizen)
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for a citizen, got %d", w.Code)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "budget_items" WHERE \(id = \$1 AND project_id IN \(SELECT "id" FROM "projects" WHERE barangay_id = \$2 AND "projects"."deleted_at" IS NULL\)\)`).
+		WithArgs(2, 1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "amount_allocated", "description", "status", "approval_date", "project_id"}).
 			AddRow(2, "Budget Item 2", 1000.0, "Desc 2", "pending", nil, 1))
+	mock.ExpectQuery(`SELECT "id" FROM "officials"`).
+		WithArgs(3, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
 	mock.ExpectBegin()
-	mock.ExpectExec(`UPDATE "budget_items" SET (.+) WHERE "id" = \$1`).
-		WithArgs("Approved", 2).
+	mock.ExpectExec(`UPDATE "budget_items" SET (.+) WHERE "budget_items"."deleted_at" IS NULL AND "id" = \$13`).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
@@ -216,9 +237,10 @@ func TestUpdateStatusBudgetItem(t *testing.T) {
 		Status: "approve",
 	}
 	jsonValue, _ := json.Marshal(updateStatus)
-	w := httptest.NewRecorder()
-	req, _ := http.NewRequest("PUT", "/budget-item/status/2", bytes.NewBuffer(jsonValue))
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("PUT", "/budget-item/status/2", bytes.NewBuffer(jsonValue))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Role", models.RoleOfficial)
 
 	r.ServeHTTP(w, req)
 
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ComplianceHandlers struct {
+	svc *services.ComplianceService
+}
+
+func NewComplianceHandlers(svc *services.ComplianceService) *ComplianceHandlers {
+	return &ComplianceHandlers{svc: svc}
+}
+
+func (h *ComplianceHandlers) RecordIncome(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var income models.RecordIncome
+	if !services.BindJSON(c, &income) {
+		return
+	}
+
+	err := h.svc.RecordIncome(barangay_ID, income)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Income recorded"})
+}
+
+func (h *ComplianceHandlers) GetIncome(c *gin.Context) {
+
+	income, err := h.svc.GetIncome(c.Param("barangay_ID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Income retrieved", "data": income})
+}
+
+func (h *ComplianceHandlers) SetStatutoryFund(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var fund models.SetStatutoryFund
+	if !services.BindJSON(c, &fund) {
+		return
+	}
+
+	err := h.svc.SetStatutoryFund(barangay_ID, c.Param("budget_ID"), fund)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Statutory fund updated"})
+}
+
+func (h *ComplianceHandlers) GetReport(c *gin.Context) {
+
+	report, err := h.svc.Report(c.Param("barangay_ID"), c.Param("fiscal_year"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Compliance report retrieved", "data": report})
+}
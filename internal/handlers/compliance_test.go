@@ -0,0 +1,60 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecordIncome(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlersObj := handlers.NewComplianceHandlers(services.NewComplianceService(nil))
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+	r.POST("/compliance/income", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(2))
+		sess.Set("user_role", c.GetHeader("X-Test-Role"))
+		sess.Set("barangay_id", uint(1))
+		handlersObj.RecordIncome(c)
+	})
+
+	tests := []struct {
+		name   string
+		role   string
+		income models.RecordIncome
+		want   int
+	}{
+		{"citizen", models.RoleCitizen, models.RecordIncome{FiscalYear: 2025, NTAShare: 4000000}, http.StatusForbidden},
+		{"fiscal year out of range", models.RoleOfficial, models.RecordIncome{FiscalYear: 25, NTAShare: 4000000}, http.StatusBadRequest},
+		{"negative income", models.RoleOfficial, models.RecordIncome{FiscalYear: 2025, NTAShare: -1}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.income)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/compliance/income", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Test-Role", tt.role)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExpenseClassHandlers struct {
+	svc *services.ExpenseClassService
+}
+
+func NewExpenseClassHandlers(svc *services.ExpenseClassService) *ExpenseClassHandlers {
+	return &ExpenseClassHandlers{svc: svc}
+}
+
+func (h *ExpenseClassHandlers) AddExpenseClass(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	var class models.NewExpenseClass
+	if !services.BindJSON(c, &class) {
+		return
+	}
+
+	err := h.svc.AddExpenseClass(class)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Expense class added"})
+}
+
+func (h *ExpenseClassHandlers) UpdateExpenseClass(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	var class models.UpdateExpenseClass
+	if !services.BindJSON(c, &class) {
+		return
+	}
+
+	err := h.svc.UpdateExpenseClass(c.Param("code"), class)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Expense class updated"})
+}
+
+func (h *ExpenseClassHandlers) GetTree(c *gin.Context) {
+
+	tree, err := h.svc.Tree()
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Expense classification retrieved", "data": tree})
+}
+
+func (h *ExpenseClassHandlers) AssignObjectCode(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var assign models.AssignObjectCode
+	if !services.BindJSON(c, &assign) {
+		return
+	}
+
+	err := h.svc.AssignObjectCode(barangay_ID, c.Param("budgetItemID"), assign)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Object code assigned"})
+}
+
+func (h *ExpenseClassHandlers) GetClassReport(c *gin.Context) {
+
+	report, err := h.svc.ClassReport(c.Param("barangay_ID"), c.Param("fiscal_year"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Expense class report retrieved", "data": report})
+}
@@ -20,66 +20,154 @@ func NewFeedbackHandlers(svc *services.FeedbackService) *FeedbackHandlers {
 func (h *FeedbackHandlers) CreateFeedBack(c *gin.Context) {
     
     session := services.CheckAuthentication(c)
+    if session == nil {
+        return
+    }
 
     var newFeedback models.NewFeedback
-    services.BindJSON(c, &newFeedback)
+    if !services.BindJSON(c, &newFeedback) {
+        return
+    }
 
     project_id := c.Param("projectID")
     user_id := session.Get("user_id").(uint)
     user_role := session.Get("user_role").(string)
 
     project_id_int, err := strconv.Atoi(project_id)
-    services.CheckServiceError(c, err)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     feedback := models.CreateFeedback{
         Content: newFeedback.Content,
+        Type: newFeedback.Type,
+        Tags: newFeedback.Tags,
+        Identity: newFeedback.Identity,
         Role: user_role,
         UserID: user_id,
         ProjectID: uint(project_id_int),
     }
 
     err = h.svc.CreateFeedback(feedback)
-    services.CheckServiceError(c, err)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "New feedback created"})
 }
 
 func (h *FeedbackHandlers) GetAllFeedbacks(c *gin.Context){
     
-    services.CheckAuthentication(c)
+    session := services.CheckAuthentication(c)
+    if session == nil {
+        return
+    }
 
     projectID := c.Param("projectID")
+    viewerID, _ := session.Get("user_id").(uint)
 
-    feedbacks, err := h.svc.GetAllFeedback(projectID)
-    services.CheckServiceError(c, err)
+    feedbacks, meta, err := h.svc.GetAllFeedback(projectID, viewerID, c.Request.URL.Query())
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
-    c.IndentedJSON(http.StatusOK, gin.H{"feedbacks": feedbacks})
+    c.IndentedJSON(http.StatusOK, gin.H{"feedbacks": feedbacks, "meta": meta})
 
 }
 
 func (h *FeedbackHandlers) EditFeedback(c *gin.Context){
     
-    services.CheckAuthentication(c)
-    
+    _, userID, ok := sessionUser(c)
+    if !ok {
+        return
+    }
+
     feedbackID := c.Param("feedbackID")
 
     var newFeedback models.NewFeedback
-    services.BindJSON(c, &newFeedback)
+    if !services.BindJSON(c, &newFeedback) {
+        return
+    }
 
-    err := h.svc.EditFeedback(feedbackID, newFeedback)
-    services.CheckServiceError(c, err)
+    err := h.svc.EditFeedback(userID, feedbackID, newFeedback)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Feedback edited"})
 }
 
 func (h *FeedbackHandlers) DeleteFeedback(c *gin.Context){
 
-    services.CheckAuthentication(c)
+    _, userID, ok := sessionUser(c)
+    if !ok {
+        return
+    }
 
     feedbackID := c.Param("feedbackID")
 
-    err := h.svc.DeleteFeedback(feedbackID)
-    services.CheckServiceError(c, err)
+    err := h.svc.DeleteFeedback(userID, feedbackID)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Feedback deleted"})
-}
\ No newline at end of file
+}
+
+func (h *FeedbackHandlers) UpdateIssue(c *gin.Context) {
+
+    session, barangay_ID, ok := officialBarangay(c)
+    if !ok {
+        return
+    }
+
+    var update models.UpdateIssue
+    if !services.BindJSON(c, &update) {
+        return
+    }
+
+    officialID, _ := session.Get("user_id").(uint)
+    feedbackID := c.Param("feedbackID")
+
+    err := h.svc.UpdateIssue(officialID, barangay_ID, feedbackID, update)
+    if services.CheckServiceError(c, err) {
+        return
+    }
+
+    c.IndentedJSON(http.StatusOK, gin.H{"message": "Complaint marked " + update.Status})
+}
+
+func (h *FeedbackHandlers) React(c *gin.Context) {
+
+    _, userID, ok := sessionUser(c)
+    if !ok {
+        return
+    }
+
+    var react models.React
+    if !services.BindJSON(c, &react) {
+        return
+    }
+
+    err := h.svc.React(userID, c.Param("feedbackID"), react.Reaction)
+    if services.CheckServiceError(c, err) {
+        return
+    }
+
+    c.IndentedJSON(http.StatusOK, gin.H{"message": "Reaction saved"})
+}
+
+func (h *FeedbackHandlers) RemoveReaction(c *gin.Context) {
+
+    _, userID, ok := sessionUser(c)
+    if !ok {
+        return
+    }
+
+    err := h.svc.RemoveReaction(userID, c.Param("feedbackID"))
+    if services.CheckServiceError(c, err) {
+        return
+    }
+
+    c.IndentedJSON(http.StatusOK, gin.H{"message": "Reaction removed"})
+}
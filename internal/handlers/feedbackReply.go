@@ -19,71 +19,93 @@ func NewFeedbackReplyHandlers(svc *services.FeedbackReplyService) *FeedbackReply
 func (h *FeedbackReplyHandlers) CreateFeedbackReply(c *gin.Context){
     
     session := services.CheckAuthentication(c)
+    if session == nil {
+        return
+    }
 
     var reply models.Reply
-    services.BindJSON(c, &reply)
+    if !services.BindJSON(c, &reply) {
+        return
+    }
 
     feedback_id := c.Param("feedbackID")
     userID, ok := session.Get("user_id").(uint)
     if !ok {
-        c.IndentedJSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+        services.WriteError(c, services.ErrInvalidSession)
         return
     }
 
+    role, _ := session.Get("user_role").(string)
+
     newReply := models.NewFeedbackReply {
         Content: reply.Content,
         FeedbackID: feedback_id,
+        ParentID: reply.ParentID,
         UserID: userID,
+        Role: role,
     }
 
     err := h.svc.CreateFeedbackReply(newReply)
-    services.CheckServiceError(c, err)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Reply submitted"})
 }
 
 func (h *FeedbackReplyHandlers) GetAllReplies(c *gin.Context){
     
-    services.CheckAuthentication(c)
+    session := services.CheckAuthentication(c)
+    if session == nil {
+        return
+    }
 
     feedbackID := c.Param("feedbackID")
+    viewerID, _ := session.Get("user_id").(uint)
 
-    replies, err := h.svc.GetAllReplies(feedbackID)
-    services.CheckServiceError(c, err)
+    replies, meta, err := h.svc.GetAllReplies(feedbackID, viewerID, c.Request.URL.Query())
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
-    c.IndentedJSON(http.StatusOK, gin.H{"message": "Replies retrived", "data": replies})
+    c.IndentedJSON(http.StatusOK, gin.H{"message": "Replies retrived", "data": replies, "meta": meta})
 }
 
 func (h *FeedbackReplyHandlers) DeleteFeedbackReply(c *gin.Context){
     
-    services.CheckAuthentication(c)
+    _, userID, ok := sessionUser(c)
+    if !ok {
+        return
+    }
 
-    feedback_id := c.Param("feedbackID")
+    replyID := c.Param("replyID")
 
-    err := h.svc.DeleteFeedbackReply(feedback_id)
-    services.CheckServiceError(c, err)
+    err := h.svc.DeleteFeedbackReply(userID, replyID)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Reply deleted"})
 }
 
 func (h *FeedbackReplyHandlers) EditFeedbackReply(c *gin.Context){
     
-    session := services.CheckAuthentication(c)
+    _, userID, ok := sessionUser(c)
+    if !ok {
+        return
+    }
 
     var editReply models.EditReply
-    services.BindJSON(c, &editReply)
-
-    requestingID := editReply.UserID
-    sessionID := session.Get("user_id").(uint)
-
-    if requestingID != sessionID {
-        c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Invalid User ID/ Unauthorized"})
+    if !services.BindJSON(c, &editReply) {
+        return
     }
 
     replyID := c.Param("replyID")
 
-    err := h.svc.EditFeedbackReply(replyID, editReply.Content)
-    services.CheckServiceError(c, err)
+    err := h.svc.EditFeedbackReply(userID, replyID, editReply.Content)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Reply Edited"})
 }
\ No newline at end of file
@@ -43,18 +43,25 @@ func TestCreateFeedbackReply(t *testing.T) {
 
 	r.POST("/feedback-reply/create/:feedbackID", func(c *gin.Context) {
 		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
 		sess.Set("user_id", uint(1))
+		sess.Set("user_role", models.RoleOfficial)
 		sess.Save()
 		handlersObj.CreateFeedbackReply(c)
 	})
 
+	// official replies skip the content filters
 	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO "feedback_replies"`).
 		WithArgs(
-			sqlmock.AnyArg(), // gorm.Model fields (ID, CreatedAt, etc.)
-			"Test reply",     // content
-			2,                // feedback_id
-			1,                // user_id
+			sqlmock.AnyArg(), sqlmock.AnyArg(), nil, // gorm.Model timestamps
+			"Test reply", // content
+			uint(2),      // feedback_id
+			0,            // depth
+			uint(1),      // user_id
+			models.RoleOfficial,
+			models.ModerationPublished,
+			sqlmock.AnyArg(), // content hash
 		).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
@@ -83,6 +90,8 @@ func TestGetAllReplies(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
 
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -101,14 +110,24 @@ func TestGetAllReplies(t *testing.T) {
 	handlersObj := handlers.NewFeedbackReplyHandlers(svc)
 
 	r.GET("/feedback-reply/all/:feedbackID", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
 		handlersObj.GetAllReplies(c)
 	})
 
-	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE feedback_ID = \$1`).
-		WithArgs(2).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).
-			AddRow(1, "Reply 1", 2, 1).
-			AddRow(2, "Reply 2", 2, 2))
+	columns := []string{"id", "feedback_id", "parent_id", "depth", "content", "role", "state", "author_id", "author_first_name", "author_last_name"}
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedback_replies" WHERE \(feedback_replies.feedback_id = \$1`).
+		WithArgs(2, uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT feedback_replies.id, (.+) FROM "feedback_replies" JOIN users`).
+		WithArgs(2, uint(1), services.DEFAULT_PAGE_LIMIT+1).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 2, nil, 0, "Reply 1", "citizen", "published", 1, "Ana", "Santos").
+			AddRow(2, 2, nil, 0, "Reply 2", "official", "published", 2, "Ben", "Cruz"))
+	mock.ExpectQuery(`WHERE feedback_replies.parent_id IN \(\$1,\$2\)`).
+		WithArgs(1, 2, uint(1)).
+		WillReturnRows(sqlmock.NewRows(columns))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/feedback-reply/all/2", nil)
@@ -121,6 +140,9 @@ func TestGetAllReplies(t *testing.T) {
 	if !bytes.Contains(w.Body.Bytes(), []byte("Reply 1")) || !bytes.Contains(w.Body.Bytes(), []byte("Reply 2")) {
 		t.Errorf("Expected reply contents in response, got %s", w.Body.String())
 	}
+	if bytes.Contains(w.Body.Bytes(), []byte("password")) || bytes.Contains(w.Body.Bytes(), []byte("email")) {
+		t.Errorf("Expected no user internals in response, got %s", w.Body.String())
+	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
@@ -130,6 +152,8 @@ func TestDeleteFeedbackReply(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
 
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -147,13 +171,19 @@ func TestDeleteFeedbackReply(t *testing.T) {
 	svc := services.NewFeedbackReplyService(gormDB)
 	handlersObj := handlers.NewFeedbackReplyHandlers(svc)
 
-	r.DELETE("/feedback-reply/delete/:feedbackID", func(c *gin.Context) {
+	r.DELETE("/feedback-reply/delete/:replyID", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
 		handlersObj.DeleteFeedbackReply(c)
 	})
 
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1`).
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).AddRow(2, "Reply", 2, 1))
 	mock.ExpectBegin()
-	mock.ExpectExec(`DELETE FROM "feedback_replies" WHERE "feedback_replies"."id" = \$1`).
-		WithArgs(2).
+	mock.ExpectExec(`UPDATE "feedback_replies" SET "deleted_at"=\$1 WHERE "feedback_replies"."id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), 2).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
@@ -198,24 +228,27 @@ func TestEditFeedbackReply(t *testing.T) {
 
 	r.PUT("/feedback-reply/edit/:replyID", func(c *gin.Context) {
 		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
 		sess.Set("user_id", uint(1))
 		sess.Save()
 		handlersObj.EditFeedbackReply(c)
 	})
 
-	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1 ORDER BY "feedback_replies"."id" LIMIT 1`).
-		WithArgs(2).
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1 (.+) ORDER BY "feedback_replies"."id" LIMIT \$2`).
+		WithArgs(2, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).
 			AddRow(2, "Old reply", 2, 1))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedback_replies" WHERE (.+) AND id <> \$4`).
+		WithArgs(1, sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectBegin()
-	mock.ExpectExec(`UPDATE "feedback_replies" SET (.+) WHERE "id" = \$1`).
-		WithArgs("Updated reply", 2).
+	mock.ExpectExec(`UPDATE "feedback_replies" SET "content"=\$1,(.+) WHERE (.+)"id" = \$7`).
+		WithArgs("Updated reply", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 2).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
 	editReply := models.EditReply{
 		Content: "Updated reply",
-		UserID:  1,
 	}
 	jsonValue, _ := json.Marshal(editReply)
 	w := httptest.NewRecorder()
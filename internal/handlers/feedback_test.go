@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 	"wow-bato-backend/internal/handlers"
 	"wow-bato-backend/internal/models"
 	"wow-bato-backend/internal/services"
@@ -108,16 +109,25 @@ func TestGetAllFeedbacks(t *testing.T) {
 		handlersObj.GetAllFeedbacks(c)
 	})
 
-	mock.ExpectQuery(`SELECT id, content, role, project_id, user_id FROM "feedbacks" WHERE project_id = \$1`).
-		WithArgs(2).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "role", "project_id", "user_id"}).
-			AddRow(1, "Feedback 1", "resident", 2, 10).
-			AddRow(2, "Feedback 2", "admin", 2, 20))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE project_id = \$1`).
+		WithArgs(2, uint(0)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT id, content, role, project_id, user_id, created_at, (.+) FROM "feedbacks" WHERE project_id = \$1`).
+		WithArgs(2, uint(0), services.DEFAULT_PAGE_LIMIT+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "role", "project_id", "user_id", "created_at", "identity", "author_alias"}).
+			AddRow(1, "Feedback 1", "resident", 2, 10, time.Now(), "named", "").
+			AddRow(2, "Feedback 2", "admin", 2, 20, time.Now(), "named", ""))
 	mock.ExpectQuery(`SELECT id, first_name, last_name FROM "users" WHERE id IN \(\$1,\$2\)`).
 		WithArgs(10, 20).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}).
 			AddRow(10, "John", "Doe").
 			AddRow(20, "Jane", "Smith"))
+	mock.ExpectQuery(`SELECT \* FROM "feedback_tags" WHERE feedback_id IN \(\$1,\$2\)`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "tag"}).AddRow(2, "water"))
+	mock.ExpectQuery(`SELECT feedback_id, reaction, (.+) FROM "feedback_reactions"`).
+		WithArgs(uint(0), 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "reaction", "count", "mine"}).AddRow(2, "upvote", 3, false))
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/feedbacks/2", nil)
@@ -135,6 +145,9 @@ func TestGetAllFeedbacks(t *testing.T) {
 	if !bytes.Contains(w.Body.Bytes(), []byte("John")) || !bytes.Contains(w.Body.Bytes(), []byte("Jane")) {
 		t.Errorf("Expected user names in response, got %s", w.Body.String())
 	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"total": 2`)) {
+		t.Errorf("Expected page meta in response, got %s", w.Body.String())
+	}
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
@@ -166,21 +179,28 @@ func TestEditFeedback(t *testing.T) {
 	r.PUT("/feedback/:feedbackID", func(c *gin.Context) {
 		sess := sessions.Default(c)
 		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
 		sess.Save()
 		handlersObj.EditFeedback(c)
 	})
 
 	feedbackID := 5
 	findRows := sqlmock.NewRows([]string{"id", "content", "user_id", "role", "project_id"}).
-		AddRow(feedbackID, "Old content", 1, "user", 1)
-	mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1 ORDER BY "feedbacks"."id" LIMIT 1`).
-		WithArgs(feedbackID).
+		AddRow(feedbackID, "Old content", 1, models.RoleCitizen, 1)
+	mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1 AND "feedbacks"."deleted_at" IS NULL ORDER BY "feedbacks"."id" LIMIT \$2`).
+		WithArgs(feedbackID, 1).
 		WillReturnRows(findRows)
 
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE (.+) AND id <> \$4`).
+		WithArgs(1, sqlmock.AnyArg(), sqlmock.AnyArg(), feedbackID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 	mock.ExpectBegin()
-	mock.ExpectExec(`UPDATE "feedbacks" SET (.+) WHERE "id" = \$1`).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "New content", feedbackID).
+	mock.ExpectExec(`UPDATE "feedbacks" SET "content"=\$1,(.+) WHERE "feedbacks"."deleted_at" IS NULL AND "id" = \$8`).
 		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "feedback_topics" WHERE feedback_id = \$1`).
+		WithArgs(feedbackID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
 	mock.ExpectCommit()
 
 	editBody := models.NewFeedback{
@@ -230,14 +250,18 @@ func TestDeleteFeedback(t *testing.T) {
 	r.DELETE("/feedback/:feedbackID", func(c *gin.Context) {
 		sess := sessions.Default(c)
 		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(1))
 		sess.Save()
 		handlersObj.DeleteFeedback(c)
 	})
 
 	feedbackID := 7
+	mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1`).
+		WithArgs(feedbackID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "user_id"}).AddRow(feedbackID, "Feedback", 1))
 	mock.ExpectBegin()
-	mock.ExpectExec(`DELETE FROM "feedbacks" WHERE id = \$1`).
-		WithArgs(feedbackID).
+	mock.ExpectExec(`UPDATE "feedbacks" SET "deleted_at"=\$1 WHERE "feedbacks"."id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), feedbackID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GeographyHandlers struct {
+	svc *services.PSGCService
+}
+
+func NewGeographyHandlers(svc *services.PSGCService) *GeographyHandlers {
+	return &GeographyHandlers{svc: svc}
+}
+
+func (h *GeographyHandlers) GetRegions(c *gin.Context) {
+
+	regions, err := h.svc.Regions()
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Regions retrieved", "data": regions})
+}
+
+func (h *GeographyHandlers) GetProvinces(c *gin.Context) {
+
+	provinces, err := h.svc.Provinces(c.Query("region"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Provinces retrieved", "data": provinces})
+}
+
+func (h *GeographyHandlers) GetCityMunicipalities(c *gin.Context) {
+
+	cities, err := h.svc.CityMunicipalities(c.Query("region"), c.Query("province"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Cities and municipalities retrieved", "data": cities})
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IdentityHandlers struct {
+	svc *services.IdentityService
+}
+
+func NewIdentityHandlers(svc *services.IdentityService) *IdentityHandlers {
+	return &IdentityHandlers{svc: svc}
+}
+
+func (h *IdentityHandlers) GetModerators(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	moderators, err := h.svc.Moderators()
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Identity moderators retrieved", "data": moderators})
+}
+
+func (h *IdentityHandlers) Designate(c *gin.Context) {
+
+	session, adminID, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	err := h.svc.Designate(adminID, c.Param("userID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Identity moderator designated"})
+}
+
+func (h *IdentityHandlers) Revoke(c *gin.Context) {
+
+	session, adminID, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	err := h.svc.Revoke(adminID, c.Param("userID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Identity moderator revoked"})
+}
+
+// Disclose shows a designated moderator who posted anonymous or pseudonymous
+// feedback, after recording why they needed to know.
+func (h *IdentityHandlers) Disclose(c *gin.Context) {
+
+	moderatorID, barangay_ID, ok := moderator(c)
+	if !ok {
+		return
+	}
+
+	var request models.DisclosureRequest
+	if !services.BindJSON(c, &request) {
+		return
+	}
+
+	author, err := h.svc.Disclose(moderatorID, barangay_ID, c.Param("feedbackID"), request.Reason)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Feedback author disclosed", "data": author})
+}
+
+func (h *IdentityHandlers) GetDisclosures(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	disclosures, meta, err := h.svc.Disclosures(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Identity disclosures retrieved", "data": disclosures, "meta": meta})
+}
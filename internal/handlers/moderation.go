@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandlers struct {
+	svc *services.ModerationService
+}
+
+func NewModerationHandlers(svc *services.ModerationService) *ModerationHandlers {
+	return &ModerationHandlers{svc: svc}
+}
+
+// moderator returns the signed in moderator and the barangay they moderate.
+// Officials moderate their own barangay, admins moderate every barangay and
+// get 0.
+func moderator(c *gin.Context) (uint, uint, bool) {
+
+	session, userID, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleOfficial, models.RoleAdmin) {
+		return 0, 0, false
+	}
+
+	if session.Get("user_role") == models.RoleAdmin {
+		return userID, 0, true
+	}
+
+	barangay_ID, ok := session.Get("barangay_id").(uint)
+	if !ok {
+		services.WriteError(c, services.ErrInvalidSession)
+		return 0, 0, false
+	}
+
+	return userID, barangay_ID, true
+}
+
+// moderatesBarangay keeps officials to the queue of their own barangay.
+func moderatesBarangay(c *gin.Context) bool {
+
+	_, barangay_ID, ok := moderator(c)
+	if !ok {
+		return false
+	}
+
+	if barangay_ID != 0 && c.Param("barangay_ID") != strconv.FormatUint(uint64(barangay_ID), 10) {
+		services.WriteError(c, services.ErrForbidden)
+		return false
+	}
+
+	return true
+}
+
+func (h *ModerationHandlers) GetQueue(c *gin.Context) {
+
+	if !moderatesBarangay(c) {
+		return
+	}
+
+	items, meta, err := h.svc.Queue(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Moderation queue fetched successfully", "data": items, "meta": meta})
+}
+
+func (h *ModerationHandlers) Moderate(c *gin.Context) {
+
+	moderatorID, barangay_ID, ok := moderator(c)
+	if !ok {
+		return
+	}
+
+	var decision models.ModerateContent
+	if !services.BindJSON(c, &decision) {
+		return
+	}
+
+	err := h.svc.Moderate(moderatorID, barangay_ID, c.Param("content_type"), c.Param("contentID"), decision)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Moderation state updated"})
+}
+
+func (h *ModerationHandlers) Appeal(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var appeal models.NewAppeal
+	if !services.BindJSON(c, &appeal) {
+		return
+	}
+
+	appealID, err := h.svc.Appeal(userID, c.Param("content_type"), c.Param("contentID"), appeal)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Appeal submitted", "id": appealID})
+}
+
+func (h *ModerationHandlers) GetAppeals(c *gin.Context) {
+
+	if !moderatesBarangay(c) {
+		return
+	}
+
+	appeals, meta, err := h.svc.ListAppeals(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Appeals fetched successfully", "data": appeals, "meta": meta})
+}
+
+func (h *ModerationHandlers) ResolveAppeal(c *gin.Context) {
+
+	reviewerID, barangay_ID, ok := moderator(c)
+	if !ok {
+		return
+	}
+
+	var resolve models.ResolveAppeal
+	if !services.BindJSON(c, &resolve) {
+		return
+	}
+
+	err := h.svc.ResolveAppeal(reviewerID, barangay_ID, c.Param("appealID"), resolve)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Appeal resolved"})
+}
+
+// GetRemoved lists removed content, which only admins acting as auditors see.
+func (h *ModerationHandlers) GetRemoved(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	items, meta, err := h.svc.Removed(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Removed content fetched successfully", "data": items, "meta": meta})
+}
+
+func (h *ModerationHandlers) GetHistory(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleAdmin) {
+		return
+	}
+
+	history, err := h.svc.History(c.Param("content_type"), c.Param("contentID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Moderation history fetched successfully", "data": history})
+}
@@ -0,0 +1,71 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+func TestModerationAccess(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlersObj := handlers.NewModerationHandlers(services.NewModerationService(nil))
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+	withSession := func(handler gin.HandlerFunc) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			sess := sessions.Default(c)
+			sess.Set("authenticated", true)
+			sess.Set("user_id", uint(2))
+			sess.Set("user_role", c.GetHeader("X-Test-Role"))
+			sess.Set("barangay_id", uint(1))
+			handler(c)
+		}
+	}
+	r.GET("/moderation/queue/:barangay_ID", withSession(handlersObj.GetQueue))
+	r.GET("/moderation/removed/:barangay_ID", withSession(handlersObj.GetRemoved))
+	r.PATCH("/moderation/:content_type/:contentID", withSession(handlersObj.Moderate))
+
+	hide, _ := json.Marshal(models.ModerateContent{State: models.ModerationHidden, Reason: models.ReasonSpam})
+	unknownReason, _ := json.Marshal(models.ModerateContent{State: models.ModerationHidden, Reason: "rude"})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		body   []byte
+		want   int
+	}{
+		{"citizen queue", "GET", "/moderation/queue/1", models.RoleCitizen, nil, http.StatusForbidden},
+		{"queue of another barangay", "GET", "/moderation/queue/2", models.RoleOfficial, nil, http.StatusForbidden},
+		{"removed content for officials", "GET", "/moderation/removed/1", models.RoleOfficial, nil, http.StatusForbidden},
+		{"citizen moderating", "PATCH", "/moderation/feedback/7", models.RoleCitizen, hide, http.StatusForbidden},
+		{"unknown reason", "PATCH", "/moderation/feedback/7", models.RoleOfficial, unknownReason, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBuffer(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Test-Role", tt.role)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type OfficialHandlers struct {
+	svc *services.OfficialService
+}
+
+func NewOfficialHandlers(svc *services.OfficialService) *OfficialHandlers {
+	return &OfficialHandlers{svc: svc}
+}
+
+// officialBarangay returns the barangay of the signed in official, the only
+// one whose directory they may change.
+func officialBarangay(c *gin.Context) (sessions.Session, uint, bool) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleOfficial) {
+		return nil, 0, false
+	}
+
+	barangay_ID, ok := session.Get("barangay_id").(uint)
+	if !ok {
+		services.WriteError(c, services.ErrInvalidSession)
+		return nil, 0, false
+	}
+
+	return session, barangay_ID, true
+}
+
+func (h *OfficialHandlers) AddOfficial(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var newOfficial models.NewOfficial
+	if !services.BindJSON(c, &newOfficial) {
+		return
+	}
+
+	officialID, err := h.svc.AddOfficial(barangay_ID, newOfficial)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Official added", "id": officialID})
+}
+
+func (h *OfficialHandlers) UpdateOfficial(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var update models.UpdateOfficial
+	if !services.BindJSON(c, &update) {
+		return
+	}
+
+	err := h.svc.UpdateOfficial(barangay_ID, c.Param("officialID"), update)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Official updated"})
+}
+
+func (h *OfficialHandlers) EndTerm(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var end models.EndOfficialTerm
+	if !services.BindJSON(c, &end) {
+		return
+	}
+
+	err := h.svc.EndTerm(barangay_ID, c.Param("officialID"), end)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Term ended"})
+}
+
+func (h *OfficialHandlers) UpdateOfficialPhoto(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("photo")
+	if err != nil {
+		services.WriteError(c, services.FieldValidationError("photo", "photo file is required"))
+		return
+	}
+
+	upload, err := file.Open()
+	if err != nil {
+		services.WriteError(c, services.ValidationError(err.Error()))
+		return
+	}
+	defer upload.Close()
+
+	photo, err := h.svc.UpdateOfficialPhoto(barangay_ID, c.Param("officialID"), upload)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Official photo updated", "data": photo})
+}
+
+// public, the directory is shown to residents without an account
+func (h *OfficialHandlers) GetCurrentOfficials(c *gin.Context) {
+
+	officials, err := h.svc.CurrentOfficials(c.Param("barangay_ID"), c.Query("as_of"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Officials retrieved", "data": officials})
+}
+
+func (h *OfficialHandlers) GetOfficialHistory(c *gin.Context) {
+
+	officials, meta, err := h.svc.OfficialHistory(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Official history retrieved", "data": officials, "meta": meta})
+}
+
+func (h *OfficialHandlers) GetOfficial(c *gin.Context) {
+
+	official, err := h.svc.GetOfficial(c.Param("officialID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Official retrieved", "data": official})
+}
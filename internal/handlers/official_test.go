@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestGetCurrentOfficials(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	handlersObj := handlers.NewOfficialHandlers(services.NewOfficialService(gormDB))
+
+	// no session, the directory is public
+	r := gin.Default()
+	r.GET("/officials/barangay/:barangay_ID", handlersObj.GetCurrentOfficials)
+
+	mock.ExpectQuery(`SELECT \* FROM "officials" WHERE barangay_id = \$1`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "position"}).
+			AddRow(3, "Jose", "Reyes", models.PositionPunongBarangay))
+	mock.ExpectQuery(`SELECT \* FROM "official_committees"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "official_id", "name"}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/officials/barangay/1", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte(`"position": "punong_barangay"`)) || !bytes.Contains(w.Body.Bytes(), []byte(`"committees": []`)) {
+		t.Errorf("Unexpected response: %s", w.Body.String())
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAddOfficialRequiresOfficial(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlersObj := handlers.NewOfficialHandlers(services.NewOfficialService(nil))
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+	r.POST("/officials/add", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(2))
+		sess.Set("user_role", models.RoleCitizen)
+		sess.Set("barangay_id", uint(1))
+		handlersObj.AddOfficial(c)
+	})
+
+	body, _ := json.Marshal(models.NewOfficial{FirstName: "Jose", LastName: "Reyes", Position: models.PositionPunongBarangay, TermStart: "2023-11-30", TermEnd: "2026-11-30"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/officials/add", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403, got %d", w.Code)
+	}
+}
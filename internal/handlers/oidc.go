@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandlers struct {
+	svc *services.OIDCService
+}
+
+func NewOIDCHandlers(svc *services.OIDCService) *OIDCHandlers {
+	return &OIDCHandlers{svc: svc}
+}
+
+// Login redirects the browser to the IdP, keeping the state, nonce and PKCE
+// verifier in the session for the callback.
+func (h *OIDCHandlers) Login(c *gin.Context) {
+
+	request, err := h.svc.NewAuthRequest()
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set("oidc_state", request.State)
+	session.Set("oidc_nonce", request.Nonce)
+	session.Set("oidc_verifier", request.CodeVerifier)
+	if err := session.Save(); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, request.URL)
+}
+
+// Callback completes the authorization code flow and establishes the same
+// session a password login does.
+func (h *OIDCHandlers) Callback(c *gin.Context) {
+
+	session := sessions.Default(c)
+	state, _ := session.Get("oidc_state").(string)
+	nonce, _ := session.Get("oidc_nonce").(string)
+	verifier, _ := session.Get("oidc_verifier").(string)
+
+	session.Delete("oidc_state")
+	session.Delete("oidc_nonce")
+	session.Delete("oidc_verifier")
+
+	if idpError := c.Query("error"); idpError != "" {
+		session.Save()
+		services.WriteError(c, services.UnauthorizedError("Single sign-on failed: "+idpError))
+		return
+	}
+
+	if state == "" || c.Query("state") != state {
+		session.Save()
+		services.WriteError(c, services.ErrOIDCInvalidState)
+		return
+	}
+
+	claims, err := h.svc.Exchange(c.Query("code"), verifier, nonce)
+	if err != nil {
+		session.Save()
+		services.WriteError(c, err)
+		return
+	}
+
+	user, err := h.svc.LinkOrProvisionUser(claims)
+	if err != nil {
+		session.Save()
+		services.WriteError(c, err)
+		return
+	}
+
+	// nothing set before signing in carries over into the signed in session
+	session.Clear()
+	services.SetSession(session, user)
+	if err := session.Save(); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.Redirect(http.StatusFound, services.AppURL())
+}
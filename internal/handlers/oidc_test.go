@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+func newOIDCRouter(config services.OIDCConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+
+	handlersObj := handlers.NewOIDCHandlers(services.NewOIDCService(nil, config))
+	r.GET("/oidc/login", handlersObj.Login)
+	r.GET("/oidc/callback", handlersObj.Callback)
+
+	return r
+}
+
+func TestOIDCLoginRedirectsToIdP(t *testing.T) {
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 "http://" + r.Host,
+			"authorization_endpoint": "http://" + r.Host + "/authorize",
+			"token_endpoint":         "http://" + r.Host + "/token",
+			"jwks_uri":               "http://" + r.Host + "/jwks",
+		})
+	}))
+	defer idp.Close()
+
+	r := newOIDCRouter(services.OIDCConfig{
+		Issuer:      idp.URL,
+		ClientID:    "wow-bato",
+		RedirectURL: "http://localhost:8080/api/v1/user/oidc/callback",
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/oidc/login", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("Expected status 302, got %d: %s", w.Code, w.Body.String())
+	}
+
+	location := w.Header().Get("Location")
+	if !strings.HasPrefix(location, idp.URL+"/authorize?") || !strings.Contains(location, "code_challenge_method=S256") {
+		t.Errorf("Expected redirect to the IdP with PKCE, got %s", location)
+	}
+	if w.Header().Get("Set-Cookie") == "" {
+		t.Error("Expected login state to be stored in the session cookie")
+	}
+}
+
+func TestOIDCCallbackRejectsStateMismatch(t *testing.T) {
+	r := newOIDCRouter(services.OIDCConfig{
+		Issuer:      "http://127.0.0.1:1",
+		ClientID:    "wow-bato",
+		RedirectURL: "http://localhost:8080/api/v1/user/oidc/callback",
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/oidc/callback?code=abc&state=forged", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestOIDCLoginNotConfigured(t *testing.T) {
+	r := newOIDCRouter(services.OIDCConfig{})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/oidc/login", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
+	}
+}
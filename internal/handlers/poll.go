@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type PollHandlers struct {
+	svc *services.PollService
+}
+
+func NewPollHandlers(svc *services.PollService) *PollHandlers {
+	return &PollHandlers{svc: svc}
+}
+
+func (h *PollHandlers) CreatePoll(c *gin.Context) {
+
+	session, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var poll models.NewPoll
+	if !services.BindJSON(c, &poll) {
+		return
+	}
+
+	officialID, _ := session.Get("user_id").(uint)
+
+	pollID, err := h.svc.CreatePoll(officialID, barangay_ID, poll)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Poll created", "id": pollID})
+}
+
+func (h *PollHandlers) DeletePoll(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeletePoll(barangay_ID, c.Param("pollID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Poll withdrawn"})
+}
+
+func (h *PollHandlers) GetPolls(c *gin.Context) {
+
+	polls, meta, err := h.svc.ListPolls(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Polls retrieved", "data": polls, "meta": meta})
+}
+
+// GetPoll is public, logged in residents also see whether they have voted.
+func (h *PollHandlers) GetPoll(c *gin.Context) {
+
+	var viewerID uint
+	if session := sessions.Default(c); session.Get("authenticated") == true {
+		viewerID, _ = session.Get("user_id").(uint)
+	}
+
+	poll, err := h.svc.GetPoll(c.Param("pollID"), viewerID)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Poll retrieved", "data": poll})
+}
+
+func (h *PollHandlers) Vote(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var ballot models.CastBallot
+	if !services.BindJSON(c, &ballot) {
+		return
+	}
+
+	err := h.svc.Vote(userID, c.Param("pollID"), ballot)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Ballot cast"})
+}
+
+func (h *PollHandlers) GetResults(c *gin.Context) {
+
+	results, err := h.svc.Results(c.Param("pollID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Poll results retrieved", "data": results})
+}
@@ -21,22 +21,29 @@ func NewProjectHandlers(svc *services.ProjectService, budget *services.BudgetCat
 func (h *ProjectHandlers) AddNewProject(c *gin.Context){
 
 	session := services.CheckAuthentication(c)
+	if session == nil {
+		return
+	}
 
 	categoryID := c.Param("categoryID")
 	barangayIDValue := session.Get("barangay_id")
 
 	barangay_ID, ok := barangayIDValue.(uint)
 	if !ok {
-		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Invalid barangay_ID"})
+		services.WriteError(c, services.ErrInvalidSession)
 		return
 	}
 	
 
 	var newProject models.NewProject
-	services.BindJSON(c, &newProject)
+	if !services.BindJSON(c, &newProject) {
+		return
+	}
 
 	err := h.svc.AddNewProject(barangay_ID, categoryID, newProject)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "New Project Created"})
 }
@@ -44,12 +51,17 @@ func (h *ProjectHandlers) AddNewProject(c *gin.Context){
 func (h *ProjectHandlers) DeleteProject(c *gin.Context){
 
 	session := services.CheckAuthentication(c)
+	if session == nil {
+		return
+	}
 
 	projectID := c.Param("projectID")
 	barangay_ID := session.Get("barangay_ID").(uint)
 
 	err := h.svc.DeleteProject(barangay_ID, projectID)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"message": "Project Deleted"})
 }
@@ -57,15 +69,22 @@ func (h *ProjectHandlers) DeleteProject(c *gin.Context){
 func (h *ProjectHandlers) UpdateProject(c *gin.Context){
 
     session := services.CheckAuthentication(c)
+    if session == nil {
+        return
+    }
 
     projectID := c.Param("projectID")
     barangay_ID := session.Get("barangay_ID").(uint)
 
     var updateProject models.UpdateProject
-    services.BindJSON(c, &updateProject)
+    if !services.BindJSON(c, &updateProject) {
+        return
+    }
 
     err := h.svc.UpdateProject(barangay_ID, projectID, updateProject)
-    services.CheckServiceError(c, err)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 
     c.IndentedJSON(http.StatusOK, gin.H{"message": "Updated Project"})
 }
@@ -73,19 +92,20 @@ func (h *ProjectHandlers) UpdateProject(c *gin.Context){
 func (h *ProjectHandlers) GetAllProjects(c *gin.Context){
 
 	session := services.CheckAuthentication(c)
+	if session == nil {
+		return
+	}
 	
 	categoryID := c.Param("categoryID")
 	barangay_ID, ok := session.Get("barangay_id").(uint)
 	if !ok {
-		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Invalid session data"})
+		services.WriteError(c, services.ErrInvalidSession)
 		return
 	}
 
-	limit := c.Query("limit")
-	page := c.Query("page")
-
 	var (
 		projectList []models.ProjectList
+		meta		models.PageMeta
 		budgetCategory	models.DisplayBudgetCategory
 		errors		[]error
 	)
@@ -97,7 +117,7 @@ func (h *ProjectHandlers) GetAllProjects(c *gin.Context){
 
 	go func(){
 		defer wg.Done()
-		result, err := h.svc.GetAllProjects(barangay_ID, categoryID, limit, page)
+		result, resultMeta, err := h.svc.GetAllProjects(barangay_ID, categoryID, c.Request.URL.Query())
 		if err != nil {
 			mu.Lock()
 			errors = append(errors, err)
@@ -106,6 +126,7 @@ func (h *ProjectHandlers) GetAllProjects(c *gin.Context){
 		}
 		mu.Lock()
 		projectList = result
+		meta = resultMeta
 		mu.Unlock()
 	}()
 
@@ -126,35 +147,46 @@ func (h *ProjectHandlers) GetAllProjects(c *gin.Context){
 	wg.Wait()
 
 	if len(errors) > 0 {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": errors[0].Error()})
+		services.WriteError(c, errors[0])
 		return
 	}
 
-	c.IndentedJSON(http.StatusOK, gin.H{"projects": projectList, "category": budgetCategory})
+	c.IndentedJSON(http.StatusOK, gin.H{"projects": projectList, "category": budgetCategory, "meta": meta})
 }
 
 func (h *ProjectHandlers) UpdateProjectStatus(c *gin.Context){
 
     session := services.CheckAuthentication(c)
+    if session == nil {
+        return
+    }
 
     projectID := c.Param("projectID")
     barangay_ID := session.Get("barangay_ID").(uint)
 
     var newStatus models.NewProjectStatus
-    services.BindJSON(c, &newStatus)
+    if !services.BindJSON(c, &newStatus) {
+        return
+    }
 
     err := h.svc.UpdateProjectStatus(projectID, barangay_ID, newStatus)
-    services.CheckServiceError(c, err)
+    if services.CheckServiceError(c, err) {
+        return
+    }
 }
 
 func (h *ProjectHandlers) GetSingleProject(c *gin.Context){
 
-	services.CheckAuthentication(c)
+	if services.CheckAuthentication(c) == nil {
+		return
+	}
 
 	projectID := c.Param("projectID")
 
 	project, err := h.svc.GetProjectSingle(projectID)
-	services.CheckServiceError(c, err)
+	if services.CheckServiceError(c, err) {
+		return
+	}
 
 	c.IndentedJSON(http.StatusOK, gin.H{"data": project, "message": "Project " + project.Name +" Retrieved"})
 }
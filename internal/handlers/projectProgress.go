@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectProgressHandlers struct {
+	svc *services.ProjectProgressService
+}
+
+func NewProjectProgressHandlers(svc *services.ProjectProgressService) *ProjectProgressHandlers {
+	return &ProjectProgressHandlers{svc: svc}
+}
+
+func (h *ProjectProgressHandlers) AddMilestone(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var milestone models.NewMilestone
+	if !services.BindJSON(c, &milestone) {
+		return
+	}
+
+	milestoneID, err := h.svc.AddMilestone(barangay_ID, c.Param("projectID"), milestone)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Milestone added", "id": milestoneID})
+}
+
+func (h *ProjectProgressHandlers) CompleteMilestone(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var complete models.CompleteMilestone
+	if !services.BindJSON(c, &complete) {
+		return
+	}
+
+	err := h.svc.CompleteMilestone(barangay_ID, c.Param("milestoneID"), complete)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Milestone completed"})
+}
+
+func (h *ProjectProgressHandlers) DeleteMilestone(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeleteMilestone(barangay_ID, c.Param("milestoneID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Milestone deleted"})
+}
+
+func (h *ProjectProgressHandlers) AddPhoto(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("photo")
+	if err != nil {
+		services.WriteError(c, services.FieldValidationError("photo", "photo file is required"))
+		return
+	}
+
+	upload, err := file.Open()
+	if err != nil {
+		services.WriteError(c, services.ValidationError(err.Error()))
+		return
+	}
+	defer upload.Close()
+
+	photo, err := h.svc.AddPhoto(barangay_ID, c.Param("projectID"), c.PostForm("caption"), upload)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Project photo added", "data": photo})
+}
+
+func (h *ProjectProgressHandlers) DeletePhoto(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeletePhoto(barangay_ID, c.Param("photoID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Project photo deleted"})
+}
+
+// public, residents follow a project's progress without an account
+func (h *ProjectProgressHandlers) GetProgress(c *gin.Context) {
+
+	progress, err := h.svc.Progress(c.Param("projectID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Project progress retrieved", "data": progress})
+}
@@ -67,7 +67,7 @@ func TestAddNewProject(t *testing.T) {
 		Description: "A test project",
 		StartDate:   "2024-06-01",
 		EndDate:     "2024-06-30",
-		Status:      "pending",
+		Status:      "planned",
 	}
 	jsonValue, _ := json.Marshal(newProject)
 	w := httptest.NewRecorder()
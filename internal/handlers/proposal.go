@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type ProposalHandlers struct {
+	svc *services.ProposalService
+}
+
+func NewProposalHandlers(svc *services.ProposalService) *ProposalHandlers {
+	return &ProposalHandlers{svc: svc}
+}
+
+func (h *ProposalHandlers) SubmitProposal(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var proposal models.NewProposal
+	if !services.BindJSON(c, &proposal) {
+		return
+	}
+
+	proposalID, err := h.svc.Submit(userID, proposal)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal submitted", "id": proposalID})
+}
+
+func (h *ProposalHandlers) AddPhoto(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("photo")
+	if err != nil {
+		services.WriteError(c, services.FieldValidationError("photo", "photo file is required"))
+		return
+	}
+
+	upload, err := file.Open()
+	if err != nil {
+		services.WriteError(c, services.ValidationError(err.Error()))
+		return
+	}
+	defer upload.Close()
+
+	photo, err := h.svc.AddPhoto(userID, c.Param("proposalID"), c.PostForm("caption"), upload)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal photo added", "data": photo})
+}
+
+func (h *ProposalHandlers) WithdrawProposal(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.Withdraw(userID, c.Param("proposalID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal withdrawn"})
+}
+
+func (h *ProposalHandlers) Endorse(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.Endorse(userID, c.Param("proposalID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal endorsed"})
+}
+
+func (h *ProposalHandlers) RemoveEndorsement(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.RemoveEndorsement(userID, c.Param("proposalID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Endorsement removed"})
+}
+
+func (h *ProposalHandlers) GetProposals(c *gin.Context) {
+
+	proposals, meta, err := h.svc.ListProposals(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposals retrieved", "data": proposals, "meta": meta})
+}
+
+// GetProposal is public, logged in residents also see whether they endorse it.
+func (h *ProposalHandlers) GetProposal(c *gin.Context) {
+
+	var viewerID uint
+	if session := sessions.Default(c); session.Get("authenticated") == true {
+		viewerID, _ = session.Get("user_id").(uint)
+	}
+
+	proposal, err := h.svc.GetProposal(c.Param("proposalID"), viewerID)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal retrieved", "data": proposal})
+}
+
+func (h *ProposalHandlers) ReviewProposal(c *gin.Context) {
+
+	session, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var review models.ReviewProposal
+	if !services.BindJSON(c, &review) {
+		return
+	}
+
+	officialID, _ := session.Get("user_id").(uint)
+
+	err := h.svc.Review(officialID, barangay_ID, c.Param("proposalID"), review)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal reviewed"})
+}
+
+func (h *ProposalHandlers) ConvertProposal(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var convert models.ConvertProposal
+	if !services.BindJSON(c, &convert) {
+		return
+	}
+
+	projectID, err := h.svc.Convert(barangay_ID, c.Param("proposalID"), convert)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Proposal converted into a project", "project_ID": projectID})
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PublicDashboardHandlers struct {
+	svc *services.PublicDashboardService
+}
+
+func NewPublicDashboardHandlers(svc *services.PublicDashboardService) *PublicDashboardHandlers {
+	return &PublicDashboardHandlers{svc: svc}
+}
+
+func (h *PublicDashboardHandlers) GetRollup(c *gin.Context) {
+
+	rollup, err := h.svc.GeoRollup(c.Param("level"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Rollup retrieved", "data": rollup})
+}
+
+func (h *PublicDashboardHandlers) GetStatutoryCompliance(c *gin.Context) {
+
+	reports, err := h.svc.StatutoryCompliance(c.Param("fiscal_year"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Statutory compliance retrieved", "data": reports})
+}
+
+func (h *PublicDashboardHandlers) GetRevenueBySource(c *gin.Context) {
+
+	points, err := h.svc.RevenueBySource(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Revenue by source retrieved", "data": points})
+}
+
+func (h *PublicDashboardHandlers) GetResponsiveness(c *gin.Context) {
+
+	results, err := h.svc.Responsiveness(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Responsiveness retrieved", "data": results})
+}
+
+func (h *PublicDashboardHandlers) GetSupportedConcerns(c *gin.Context) {
+
+	concerns, err := h.svc.MostSupportedConcerns(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Most supported concerns retrieved", "data": concerns})
+}
+
+func (h *PublicDashboardHandlers) GetSentiment(c *gin.Context) {
+
+	sentiment, err := h.svc.Sentiment(c.Param("level"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Feedback sentiment retrieved", "data": sentiment})
+}
+
+func (h *PublicDashboardHandlers) GetTopics(c *gin.Context) {
+
+	topics, err := h.svc.Topics(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Feedback topics retrieved", "data": topics})
+}
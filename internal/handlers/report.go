@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReportHandlers struct {
+	svc *services.ReportService
+}
+
+func NewReportHandlers(svc *services.ReportService) *ReportHandlers {
+	return &ReportHandlers{svc: svc}
+}
+
+func writePDF(c *gin.Context, filename string, document []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	c.Data(http.StatusOK, "application/pdf", document)
+}
+
+// reports are public records, anyone may print them
+
+func (h *ReportHandlers) GetBudgetReport(c *gin.Context) {
+
+	document, err := h.svc.BudgetReport(c.Param("barangay_ID"), c.Param("fiscal_year"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	writePDF(c, fmt.Sprintf("budget-%s-%s.pdf", c.Param("barangay_ID"), c.Param("fiscal_year")), document)
+}
+
+func (h *ReportHandlers) GetProjectReport(c *gin.Context) {
+
+	document, err := h.svc.ProjectReport(c.Param("projectID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	writePDF(c, fmt.Sprintf("project-%s.pdf", c.Param("projectID")), document)
+}
+
+func (h *ReportHandlers) GetFeedbackReport(c *gin.Context) {
+
+	document, err := h.svc.FeedbackReport(c.Param("barangay_ID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	writePDF(c, fmt.Sprintf("feedback-%s.pdf", c.Param("barangay_ID")), document)
+}
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResidencyHandlers struct {
+	svc *services.ResidencyService
+}
+
+func NewResidencyHandlers(svc *services.ResidencyService) *ResidencyHandlers {
+	return &ResidencyHandlers{svc: svc}
+}
+
+func (h *ResidencyHandlers) FileClaim(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	var claim models.NewResidencyClaim
+	if !services.BindJSON(c, &claim) {
+		return
+	}
+
+	if err := h.svc.FileClaim(userID, claim); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Residency claim submitted for review"})
+}
+
+func (h *ResidencyHandlers) GetMyClaims(c *gin.Context) {
+
+	_, userID, ok := sessionUser(c)
+	if !ok {
+		return
+	}
+
+	claims, meta, err := h.svc.GetMyClaims(userID, c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Residency claims retrieved", "data": claims, "meta": meta})
+}
+
+func (h *ResidencyHandlers) GetPendingClaims(c *gin.Context) {
+
+	session, _, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleOfficial) {
+		return
+	}
+
+	barangay_ID, ok := session.Get("barangay_id").(uint)
+	if !ok {
+		services.WriteError(c, services.ErrInvalidSession)
+		return
+	}
+
+	claims, meta, err := h.svc.GetPendingClaims(barangay_ID, c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Pending residency claims retrieved", "data": claims, "meta": meta})
+}
+
+func (h *ResidencyHandlers) ReviewClaim(c *gin.Context) {
+
+	session, userID, ok := sessionUser(c)
+	if !ok || !services.CheckRole(c, session, models.RoleOfficial) {
+		return
+	}
+
+	barangay_ID, ok := session.Get("barangay_id").(uint)
+	if !ok {
+		services.WriteError(c, services.ErrInvalidSession)
+		return
+	}
+
+	var review models.ReviewResidencyClaim
+	if !services.BindJSON(c, &review) {
+		return
+	}
+
+	if err := h.svc.ReviewClaim(userID, barangay_ID, c.Param("claimID"), review); err != nil {
+		services.WriteError(c, err)
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Residency claim reviewed"})
+}
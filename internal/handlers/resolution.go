@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResolutionHandlers struct {
+	svc *services.ResolutionService
+}
+
+func NewResolutionHandlers(svc *services.ResolutionService) *ResolutionHandlers {
+	return &ResolutionHandlers{svc: svc}
+}
+
+func (h *ResolutionHandlers) AddResolution(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var resolution models.NewResolution
+	if !services.BindJSON(c, &resolution) {
+		return
+	}
+
+	resolutionID, err := h.svc.AddResolution(barangay_ID, resolution)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Resolution registered", "id": resolutionID})
+}
+
+func (h *ResolutionHandlers) UploadDocument(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	file, err := c.FormFile("document")
+	if err != nil {
+		services.WriteError(c, services.FieldValidationError("document", "document file is required"))
+		return
+	}
+
+	upload, err := file.Open()
+	if err != nil {
+		services.WriteError(c, services.ValidationError(err.Error()))
+		return
+	}
+	defer upload.Close()
+
+	document, err := h.svc.UploadDocument(barangay_ID, c.Param("resolutionID"), upload)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Resolution document uploaded", "document_url": document})
+}
+
+func (h *ResolutionHandlers) LinkResolution(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var link models.LinkResolution
+	if !services.BindJSON(c, &link) {
+		return
+	}
+
+	err := h.svc.LinkResolution(barangay_ID, c.Param("resolutionID"), link)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Resolution linked"})
+}
+
+// public, residents can trace every appropriation to its measure
+func (h *ResolutionHandlers) GetResolutions(c *gin.Context) {
+
+	resolutions, meta, err := h.svc.ListResolutions(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Resolutions retrieved", "data": resolutions, "meta": meta})
+}
+
+func (h *ResolutionHandlers) GetResolutionByNumber(c *gin.Context) {
+
+	resolution, err := h.svc.GetByNumber(c.Param("barangay_ID"), c.Param("kind"), c.Param("number"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Resolution retrieved", "data": resolution})
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestGetResolutions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	handlersObj := handlers.NewResolutionHandlers(services.NewResolutionService(gormDB))
+
+	// no session, the registry is public
+	r := gin.Default()
+	r.GET("/resolutions/barangay/:barangay_ID", handlersObj.GetResolutions)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "resolutions" WHERE barangay_id = \$1 AND kind IN \(\$2\)`).
+		WithArgs(1, "ordinance").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM "resolutions" WHERE (.+) ORDER BY date_enacted DESC,id DESC LIMIT \$3`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "number", "title"}).
+			AddRow(9, "ordinance", "2024-003", "Annual Budget for Fiscal Year 2025"))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/resolutions/barangay/1?kind=ordinance", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if !bytes.Contains(w.Body.Bytes(), []byte("2024-003")) {
+		t.Errorf("Expected ordinance in response, got %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/resolutions/barangay/1?kind=memo", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown kind, got %d", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ResponseHandlers struct {
+	svc *services.ResponseService
+}
+
+func NewResponseHandlers(svc *services.ResponseService) *ResponseHandlers {
+	return &ResponseHandlers{svc: svc}
+}
+
+func (h *ResponseHandlers) GetTarget(c *gin.Context) {
+
+	target, err := h.svc.GetTarget(c.Param("barangay_ID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Response targets retrieved", "data": target})
+}
+
+func (h *ResponseHandlers) SetTarget(c *gin.Context) {
+
+	session, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var target models.SetResponseTarget
+	if !services.BindJSON(c, &target) {
+		return
+	}
+
+	officialID, _ := session.Get("user_id").(uint)
+
+	err := h.svc.SetTarget(officialID, barangay_ID, target)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Response targets updated"})
+}
+
+func (h *ResponseHandlers) GetOverdue(c *gin.Context) {
+
+	if !moderatesBarangay(c) {
+		return
+	}
+
+	overdue, meta, err := h.svc.Overdue(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Overdue feedback retrieved", "data": overdue, "meta": meta})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RevenueHandlers struct {
+	svc *services.RevenueService
+}
+
+func NewRevenueHandlers(svc *services.RevenueService) *RevenueHandlers {
+	return &RevenueHandlers{svc: svc}
+}
+
+func (h *RevenueHandlers) AddRevenue(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var revenue models.NewRevenue
+	if !services.BindJSON(c, &revenue) {
+		return
+	}
+
+	revenueID, err := h.svc.AddRevenue(barangay_ID, revenue)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Revenue recorded", "id": revenueID})
+}
+
+func (h *RevenueHandlers) UpdateRevenue(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	var revenue models.NewRevenue
+	if !services.BindJSON(c, &revenue) {
+		return
+	}
+
+	err := h.svc.UpdateRevenue(barangay_ID, c.Param("revenueID"), revenue)
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Revenue updated"})
+}
+
+func (h *RevenueHandlers) DeleteRevenue(c *gin.Context) {
+
+	_, barangay_ID, ok := officialBarangay(c)
+	if !ok {
+		return
+	}
+
+	err := h.svc.DeleteRevenue(barangay_ID, c.Param("revenueID"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Revenue deleted"})
+}
+
+func (h *RevenueHandlers) GetRevenue(c *gin.Context) {
+
+	revenue, meta, err := h.svc.ListRevenue(c.Param("barangay_ID"), c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Revenue retrieved", "data": revenue, "meta": meta})
+}
+
+func (h *RevenueHandlers) GetBalance(c *gin.Context) {
+
+	report, err := h.svc.Balance(c.Param("barangay_ID"), c.Param("fiscal_year"))
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Sources and uses retrieved", "data": report})
+}
@@ -0,0 +1,78 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAddRevenue(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlersObj := handlers.NewRevenueHandlers(services.NewRevenueService(nil))
+
+	r := gin.Default()
+	store := cookie.NewStore([]byte("secret"))
+	r.Use(sessions.Sessions("mysession", store))
+	r.POST("/revenue/add", func(c *gin.Context) {
+		sess := sessions.Default(c)
+		sess.Set("authenticated", true)
+		sess.Set("user_id", uint(2))
+		sess.Set("user_role", c.GetHeader("X-Test-Role"))
+		sess.Set("barangay_id", uint(1))
+		handlersObj.AddRevenue(c)
+	})
+
+	tests := []struct {
+		name    string
+		role    string
+		revenue models.NewRevenue
+		want    int
+	}{
+		{"citizen", models.RoleCitizen, models.NewRevenue{FiscalYear: 2025, Source: models.RevenueNTA, Amount: 4000000}, http.StatusForbidden},
+		{"unknown source", models.RoleOfficial, models.NewRevenue{FiscalYear: 2025, Source: "lottery", Amount: 4000000}, http.StatusBadRequest},
+		{"zero amount", models.RoleOfficial, models.NewRevenue{FiscalYear: 2025, Source: models.RevenueFees}, http.StatusBadRequest},
+		{"malformed date received", models.RoleOfficial, models.NewRevenue{FiscalYear: 2025, Source: models.RevenueGrants, Amount: 50000, DateReceived: "03/15/2025"}, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.revenue)
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/revenue/add", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Test-Role", tt.role)
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetBalanceInvalidFiscalYear(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handlersObj := handlers.NewRevenueHandlers(services.NewRevenueService(nil))
+
+	r := gin.Default()
+	r.GET("/revenue/balance/:barangay_ID/:fiscal_year", handlersObj.GetBalance)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/revenue/balance/1/25", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
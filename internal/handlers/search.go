@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+	"wow-bato-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandlers struct {
+	svc *services.SearchService
+}
+
+func NewSearchHandlers(svc *services.SearchService) *SearchHandlers {
+	return &SearchHandlers{svc: svc}
+}
+
+// public, residents can search without an account
+func (h *SearchHandlers) Search(c *gin.Context) {
+
+	results, meta, err := h.svc.Search(c.Request.URL.Query())
+	if services.CheckServiceError(c, err) {
+		return
+	}
+
+	c.IndentedJSON(http.StatusOK, gin.H{"message": "Search results retrieved", "data": results, "meta": meta})
+}
@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wow-bato-backend/internal/handlers"
+	"wow-bato-backend/internal/services"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	handlersObj := handlers.NewSearchHandlers(services.NewSearchService(gormDB))
+
+	// no session, search is public
+	r := gin.Default()
+	r.GET("/search", handlersObj.Search)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM \(WITH search AS (.+)\) AS results WHERE barangay_id IN \(\$3\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \* FROM \(WITH search AS (.+)\) AS results WHERE barangay_id IN \(\$3\) ORDER BY rank DESC,type,id LIMIT \$4`).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "title", "snippet", "rank", "barangay_id", "barangay_name", "project_id"}).
+			AddRow("project", 7, "Drainage Canal", "<mark>Drainage</mark> along Rizal St.", 0.8, 2, "San Isidro", 7))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/search?q=drainage&barangay_ID=2", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Data []struct {
+			Type    string `json:"type"`
+			Snippet string `json:"snippet"`
+		} `json:"data"`
+		Meta struct {
+			Total int64 `json:"total"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Data) != 1 || body.Data[0].Type != "project" || body.Meta.Total != 1 {
+		t.Errorf("Unexpected response: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/search?q=", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an empty query, got %d", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
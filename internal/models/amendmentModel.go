@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// amendment kinds
+const (
+	AmendmentRealignment  = "realignment"  //savings moved from some items to others, nets to zero
+	AmendmentSupplemental = "supplemental" //new money added by a supplemental budget
+)
+
+// change to one budget item, negative where an item gives up savings
+type AmendmentLineInput struct {
+	BudgetItemID uint    `json:"budget_item_ID" binding:"required"`
+	Amount       float64 `json:"amount" binding:"required"`
+}
+
+type NewAmendment struct {
+	Kind          string               `json:"kind" binding:"required,oneof=realignment supplemental"`
+	Justification string               `json:"justification" binding:"required,max=2000"`
+	ResolutionID  uint                 `json:"resolution_ID" binding:"required"`
+	EffectiveDate string               `json:"effectiveDate" binding:"required,datetime=2006-01-02"`
+	Lines         []AmendmentLineInput `json:"lines" binding:"required,min=1,max=50,dive"`
+}
+
+type AmendmentResponse struct {
+	ID            uint      `json:"id"`
+	Kind          string    `json:"kind"`
+	Justification string    `json:"justification"`
+	ResolutionID  uint      `json:"resolution_ID"`
+	EffectiveDate time.Time `json:"effectiveDate"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type AmendmentLineResponse struct {
+	BudgetItemID   uint    `json:"budget_item_ID"`
+	BudgetItemName string  `json:"budget_item_name"`
+	CategoryID     uint    `json:"category_ID"`
+	CategoryName   string  `json:"category_name"`
+	AmountBefore   float64 `json:"amount_before"`
+	Amount         float64 `json:"amount"`
+	AmountAfter    float64 `json:"amount_after"`
+}
+
+type AmendmentDetail struct {
+	AmendmentResponse
+	ResolutionKind   string                  `json:"resolution_kind"`
+	ResolutionNumber string                  `json:"resolution_number"`
+	Lines            []AmendmentLineResponse `json:"lines"`
+}
+
+// original and amended figures of one budget item
+type AmendedBudgetItem struct {
+	ID          uint    `json:"id"`
+	Name        string  `json:"name"`
+	ProjectName string  `json:"project_name"`
+	Original    float64 `json:"original"`
+	Amended     float64 `json:"amended"`
+}
+
+type AmendedBudgetCategory struct {
+	ID       uint                `json:"id"`
+	Name     string              `json:"name"`
+	Original float64             `json:"original"`
+	Amended  float64             `json:"amended"`
+	Items    []AmendedBudgetItem `json:"items"`
+}
+
+// budget of a fiscal year as enacted and as amended
+type AmendedBudget struct {
+	Barangay_ID uint                    `json:"barangay_ID"`
+	FiscalYear  int                     `json:"fiscal_year"`
+	Original    float64                 `json:"original"`
+	Amended     float64                 `json:"amended"`
+	Categories  []AmendedBudgetCategory `json:"categories"`
+}
@@ -1,9 +1,10 @@
 package models
 
 type AddBarangay struct {
-	Name   string `json:"name"`
-	City   string `json:"city"`
-	Region string `json:"region"`
+	Name   string `json:"name" binding:"required,max=100"`
+	City   string `json:"city" binding:"required_without=PSGCCode,max=100"`
+	Region string `json:"region" binding:"required_without=PSGCCode,max=100"`
+	PSGCCode string `json:"psgc_code" binding:"omitempty,len=10,numeric"` //city and region are filled from the PSGC when set
 }
 
 // CHANGE LATER TO NOT PUT BRGY. ID IN THE JSON BODY
@@ -13,9 +14,10 @@ type DeleteBarangay struct {
 }
 
 type UpdateBarangay struct {
-	Name   string `json:"name"`
-	City   string `json:"city"`
-	Region string `json:"region"`
+	Name   string `json:"name" binding:"required,max=100"`
+	City   string `json:"city" binding:"required_without=PSGCCode,max=100"`
+	Region string `json:"region" binding:"required_without=PSGCCode,max=100"`
+	PSGCCode string `json:"psgc_code" binding:"omitempty,len=10,numeric"` //city and region are filled from the PSGC when set
 }
 
 // used for displaying brgy. information
@@ -24,6 +26,7 @@ type AllBarangayResponse struct {
 	Name   string `json:"name"`
 	City   string `json:"city"`
 	Region string `json:"region"`
+	PSGCCode *string `json:"psgc_code"`
 }
 
 // returned to the client for selection inputs
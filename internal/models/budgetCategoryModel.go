@@ -1,14 +1,15 @@
 package models
 
 type NewBudgetCategory struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Barangay_ID uint   `json:"barangay_ID"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"required,max=500"`
+	Barangay_ID uint   `json:"barangay_ID" binding:"required"`
+	StatutoryFund string `json:"statutory_fund" binding:"omitempty,oneof=development sk gad drrm"`
 }
 
 type UpdateBudgetCategory struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"required,max=500"`
 }
 
 // For displaying at the client side BudgetCategoryList.tsx
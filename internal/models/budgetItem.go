@@ -1,12 +1,13 @@
 package models
 
 type NewBudgetItem struct {
-	Name             string  `json:"name"`
-	Amount_Allocated float64 `json:"amount_allocated"`
-	Description      string  `json:"description"`
-	Status           string  `json:"status"`
+	Name             string  `json:"name" binding:"required,max=150"`
+	Amount_Allocated float64 `json:"amount_allocated" binding:"required,gt=0"`
+	Description      string  `json:"description" binding:"max=1000"`
+	Status           string  `json:"status" binding:"omitempty,oneof=pending"` //items are approved or rejected only through review
+	ObjectCode       string  `json:"object_code" binding:"omitempty,max=20"`
 }
 
 type UpdateStatus struct {
-	Status string `json:"status"`
+	Status string `json:"status" binding:"required,oneof=approve reject"`
 }
\ No newline at end of file
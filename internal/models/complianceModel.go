@@ -0,0 +1,51 @@
+package models
+
+// statutory funds a budget category can be earmarked for
+const (
+	FundDevelopment = "development"
+	FundSK          = "sk"
+	FundGAD         = "gad"
+	FundDRRM        = "drrm"
+)
+
+// income of one fiscal year, recorded again to correct it
+type RecordIncome struct {
+	FiscalYear  int     `json:"fiscal_year" binding:"required,gte=1991,lte=2100"`
+	NTAShare    float64 `json:"nta_share" binding:"gte=0"`    //national tax allotment share
+	LocalIncome float64 `json:"local_income" binding:"gte=0"` //local taxes, fees and charges
+	OtherIncome float64 `json:"other_income" binding:"gte=0"`
+}
+
+type IncomeResponse struct {
+	FiscalYear  int     `json:"fiscal_year"`
+	NTAShare    float64 `json:"nta_share"`
+	LocalIncome float64 `json:"local_income"`
+	OtherIncome float64 `json:"other_income"`
+	TotalIncome float64 `json:"total_income"`
+}
+
+type SetStatutoryFund struct {
+	StatutoryFund string `json:"statutory_fund" binding:"omitempty,oneof=development sk gad drrm"` //empty clears the mapping
+}
+
+// required share of one statutory fund against what was budgeted for it
+type StatutoryFundStatus struct {
+	Fund      string  `json:"fund"`
+	Label     string  `json:"label"`
+	Percent   float64 `json:"percent"`
+	Basis     string  `json:"basis"` //nta_share or total_income
+	Required  float64 `json:"required"`
+	Allocated float64 `json:"allocated"`
+	Shortfall float64 `json:"shortfall"`
+	Compliant bool    `json:"compliant"`
+}
+
+type ComplianceReport struct {
+	Barangay_ID  uint                  `json:"barangay_ID"`
+	BarangayName string                `json:"barangay_name"`
+	FiscalYear   int                   `json:"fiscal_year"`
+	Income       IncomeResponse        `json:"income"`
+	Funds        []StatutoryFundStatus `json:"funds"`
+	Shortfall    float64               `json:"shortfall"` //sum over the funds
+	Compliant    bool                  `json:"compliant"`
+}
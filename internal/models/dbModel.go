@@ -14,18 +14,60 @@ type User struct {
 	LastName 	string 	  `gorm:"not null"`
 	Role     	string 	  `gorm:"not null"`
 	ProfilePicture  string    `gorm:"default:null"`
+	ProfileThumbnail string   `gorm:"default:null"`
+	PendingEmail	string 	  `gorm:"default:null"`
+	EmailChangeToken string   `gorm:"default:null"` //sha256 of the token mailed to PendingEmail
+	EmailChangeExpiry *time.Time `gorm:"default:null"`
+	SessionVersion	uint 	  `gorm:"not null;default:0"` //bumped to revoke existing sessions
+	OIDCIssuer	string 	  `gorm:"default:null;uniqueIndex:idx_users_oidc"`
+	OIDCSubject	string 	  `gorm:"default:null;uniqueIndex:idx_users_oidc"` //set once the account is linked to the city IdP
 	Contact  	string 	  `gorm:"not null"`
 	Barangay_ID *uint 	  `gorm:"default:null"`
 	Barangay 	Barangay  `gorm:"foreignKey:Barangay_ID"`
+	ResidencyVerified bool    `gorm:"not null;default:false"` //set once an official approves a residency claim
 	Feedbacks	[]Feedback `gorm:"foreignKey:UserID"`
 	FeedbackReplies []FeedbackReply `gorm:"foreignKey:UserID"`
 }
 
+// PSA Philippine Standard Geographic Code entities. Codes are the 10 digit
+// PSGC, laid out as region (2), province (3), city/municipality (2), barangay (3).
+type Region struct {
+	Code 				string `gorm:"primaryKey;size:10"`
+	Name 				string `gorm:"not null"`
+	Provinces 			[]Province `gorm:"foreignKey:RegionCode"`
+	CityMunicipalities 	[]CityMunicipality `gorm:"foreignKey:RegionCode"`
+	UpdatedAt 			time.Time
+}
+
+type Province struct {
+	Code 				string `gorm:"primaryKey;size:10"`
+	Name 				string `gorm:"not null"`
+	RegionCode 			string `gorm:"size:10;not null;index"`
+	Region 				Region `gorm:"foreignKey:RegionCode"`
+	CityMunicipalities 	[]CityMunicipality `gorm:"foreignKey:ProvinceCode"`
+	UpdatedAt 			time.Time
+}
+
+type CityMunicipality struct {
+	Code 				string `gorm:"primaryKey;size:10"`
+	Name 				string `gorm:"not null"`
+	Level 				string `gorm:"not null"` //City, Mun or SGU
+	RegionCode 			string `gorm:"size:10;not null;index"`
+	Region 				Region `gorm:"foreignKey:RegionCode"`
+	ProvinceCode 		*string `gorm:"size:10;index"` //null for NCR cities and independent cities
+	Province 			*Province `gorm:"foreignKey:ProvinceCode"`
+	Barangays 			[]Barangay `gorm:"foreignKey:CityMunicipalityCode"`
+	UpdatedAt 			time.Time
+}
+
 type Barangay struct {
 	gorm.Model
 	Name     			string `gorm:"not null;unique"`
-	City     			string `gorm:"not null"`
+	City     			string `gorm:"not null"` //kept in sync with CityMunicipality once linked
 	Region   			string `gorm:"not null"`
+	PSGCCode 			*string `gorm:"size:10;uniqueIndex"`
+	CityMunicipalityCode *string `gorm:"size:10;index"`
+	CityMunicipality 	*CityMunicipality `gorm:"foreignKey:CityMunicipalityCode"`
     ImageURL            string `gorm:""`
 	Users    			[]User `gorm:"foreignKey:Barangay_ID"`
 	Projects 			[]Project `gorm:"foreignKey:Barangay_ID"`
@@ -39,9 +81,24 @@ type Budget_Category struct {
 	Description 		string `gorm:"type:text"`
 	Barangay_ID 			uint   `gorm:"not null"`
 	Barangay 			Barangay `gorm:"foreignKey:Barangay_ID"`
+	ResolutionID 		*uint `gorm:"default:null;index"` //measure that appropriated it
+	Resolution 			*Resolution `gorm:"foreignKey:ResolutionID"`
+	StatutoryFund 		*string `gorm:"default:null;index"` //development, sk, gad or drrm
 	Projects 			[]Project `gorm:"foreignKey:CategoryID"`
 }
 
+type Revenue struct {
+	gorm.Model
+	Barangay_ID 		uint `gorm:"not null;index:idx_revenues_barangay_year"`
+	Barangay 			Barangay `gorm:"foreignKey:Barangay_ID"`
+	FiscalYear 			int `gorm:"not null;index:idx_revenues_barangay_year"`
+	Source 				string `gorm:"not null"` //nta, rpt_share, fees, grants, donations or other
+	Description 		string `gorm:"type:text"`
+	Amount 				float64 `gorm:"not null"`
+	DateReceived 		*time.Time `gorm:"type:date"`
+	Summary 			bool `gorm:"not null;default:false"` //a yearly total recorded through the income form rather than an itemized receipt
+}
+
 type Project struct {
 	gorm.Model
 	Name string `gorm:"not null"`
@@ -53,8 +110,30 @@ type Project struct {
 	Barangay Barangay `gorm:"foreignKey:Barangay_ID"`
 	CategoryID uint `gorm:"not null"`
 	Category Budget_Category `gorm:"foreignKey:CategoryID"`
+	ResolutionID *uint `gorm:"default:null;index"`
+	Resolution *Resolution `gorm:"foreignKey:ResolutionID"`
     Feedbacks []Feedback `gorm:"foreignKey:ProjectID"`
 	Budget_Items []Budget_Item `gorm:"foreignKey:ProjectID"`
+	Milestones []ProjectMilestone `gorm:"foreignKey:ProjectID"`
+	Photos []ProjectPhoto `gorm:"foreignKey:ProjectID"`
+}
+
+type ProjectMilestone struct {
+	ID 					uint `gorm:"primaryKey"`
+	ProjectID 			uint `gorm:"not null;index"`
+	Title 				string `gorm:"not null"`
+	DueDate 			time.Time `gorm:"type:date;not null"`
+	CompletedAt 		*time.Time `gorm:"type:date"`
+	CreatedAt 			time.Time
+}
+
+// site photo of a project, printed on its status sheet
+type ProjectPhoto struct {
+	ID 					uint `gorm:"primaryKey"`
+	ProjectID 			uint `gorm:"not null;index"`
+	URL 				string `gorm:"not null"`
+	Caption 			string
+	CreatedAt 			time.Time
 }
 
 type Budget_Item struct {
@@ -64,26 +143,341 @@ type Budget_Item struct {
 	Description 		string `gorm:"type:text"`
 	Status 				string `gorm:"not null"` //pending, approved, rejected
 	Approval_Date 		*time.Time //Nullable, set when approved
+	Reviewed_By_OfficialID *uint `gorm:"default:null"` //term of the official who approved or rejected it
+	Reviewed_By_Official *Official `gorm:"foreignKey:Reviewed_By_OfficialID"`
 	ProjectID 			uint `gorm:"not null"`
 	Project 			Project `gorm:"foreignKey:ProjectID"`
+	ResolutionID 		*uint `gorm:"default:null;index"`
+	Resolution 			*Resolution `gorm:"foreignKey:ResolutionID"`
+	ObjectCode 			*string `gorm:"default:null;size:20;index"` //object of expenditure in the expense classification
+	ExpenseClass 		*ExpenseClass `gorm:"foreignKey:ObjectCode"`
+}
+
+// node of the expense classification, the roots are the allotment classes
+// (PS, MOOE, Capital Outlay) and the leaves the object of expenditure codes
+// budget items are assigned
+type ExpenseClass struct {
+	Code 				string `gorm:"primaryKey;size:20"`
+	ParentCode 			*string `gorm:"size:20;index"`
+	Parent 				*ExpenseClass `gorm:"foreignKey:ParentCode"`
+	Name 				string `gorm:"not null"`
+	Active 				bool `gorm:"not null;default:true"` //retired codes stay on the items that use them
+	CreatedAt 			time.Time
+	UpdatedAt 			time.Time
+}
+
+// realignment or supplemental budget, budget items keep their amended
+// amount and each line keeps the figures before and after
+type Amendment struct {
+	gorm.Model
+	Barangay_ID 		uint `gorm:"not null;index"`
+	Barangay 			Barangay `gorm:"foreignKey:Barangay_ID"`
+	Kind 				string `gorm:"not null"` //realignment or supplemental
+	Justification 		string `gorm:"type:text;not null"`
+	ResolutionID 		uint `gorm:"not null;index"` //measure that authorized it
+	Resolution 			Resolution `gorm:"foreignKey:ResolutionID"`
+	EffectiveDate 		time.Time `gorm:"type:date;not null"`
+	Lines 				[]AmendmentLine `gorm:"foreignKey:AmendmentID"`
+}
+
+type AmendmentLine struct {
+	ID 					uint `gorm:"primaryKey"`
+	AmendmentID 		uint `gorm:"not null;index"`
+	BudgetItemID 		uint `gorm:"not null;index"`
+	BudgetItem 			Budget_Item `gorm:"foreignKey:BudgetItemID"`
+	AmountBefore 		float64 `gorm:"not null"`
+	Amount 				float64 `gorm:"not null"` //change, negative where savings were taken
+	AmountAfter 		float64 `gorm:"not null"`
 }
 
 type Feedback struct {
 	gorm.Model
 	Content string `gorm:"type:text;not null"`
 	Role string `gorm:"not null"`
+	Type string `gorm:"size:20;not null;default:suggestion;index"` //complaint, suggestion, question, commendation
+	Tags []FeedbackTag `gorm:"foreignKey:FeedbackID"`
+	IssueStatus *string `gorm:"size:20;default:null;index"` //open, acknowledged, resolved; complaints only
+	AcknowledgedAt *time.Time `gorm:"default:null"`
+	AcknowledgedByID *uint `gorm:"default:null"`
+	ResolutionNote string `gorm:"type:text"`
+	ResolvedAt *time.Time `gorm:"default:null"`
+	ResolvedByID *uint `gorm:"default:null"`
     ProjectID uint `gorm:"not null"`
     Project Project `gorm:"foreignKey:ProjectID"`
 	UserID uint `gorm:"not null"`
 	User User `gorm:"foreignKey:UserID"`
+	ModerationState string `gorm:"not null;default:published;index"` //pending, published, hidden, removed
+	ModerationReason *string `gorm:"default:null"`
+	ModeratedAt *time.Time `gorm:"default:null"`
+	ModeratedByID *uint `gorm:"default:null"`
+	ContentHash string `gorm:"size:64;index"` //normalized content, finds reposts
+	Identity string `gorm:"size:20;not null;default:named"` //named, pseudonymous or anonymous
+	AuthorAlias string `gorm:"size:40"` //shown in place of the author's name unless named
+	FirstResponseAt *time.Time `gorm:"default:null;index"` //first published reply of an official of the barangay
+	EscalatedAt *time.Time `gorm:"default:null"` //when it went past the escalation target unanswered
+	SupportCount int `gorm:"not null;default:0;index"` //upvote and same_issue reactions
+	Sentiment string `gorm:"size:10;index"` //positive, neutral or negative; empty until analyzed
+	SentimentScore float64 `gorm:"not null;default:0"` //-1 to 1
+	Topics []FeedbackTopic `gorm:"foreignKey:FeedbackID"`
+	Reactions []FeedbackReaction `gorm:"foreignKey:FeedbackID"`
 	FeedbackReplies []FeedbackReply `gorm:"foreignKey:FeedbackID"`
 }
 
+// moderator an admin allowed to see who posted anonymous or pseudonymous
+// feedback, active until revoked
+type IdentityModerator struct {
+	ID 					uint `gorm:"primaryKey"`
+	UserID 				uint `gorm:"not null;index"`
+	User 				User `gorm:"foreignKey:UserID"`
+	DesignatedByID 		uint `gorm:"not null"`
+	RevokedAt 			*time.Time `gorm:"default:null"`
+	RevokedByID 		*uint `gorm:"default:null"`
+	CreatedAt 			time.Time
+}
+
+// every time a moderator saw who posted anonymous or pseudonymous feedback
+type IdentityDisclosure struct {
+	ID 					uint `gorm:"primaryKey"`
+	FeedbackID 			uint `gorm:"not null;index"`
+	ModeratorID 		uint `gorm:"not null;index"`
+	Reason 				string `gorm:"type:text;not null"`
+	CreatedAt 			time.Time
+}
+
+// one reaction per resident and feedback, reacting again changes it
+type FeedbackReaction struct {
+	FeedbackID uint `gorm:"primaryKey"`
+	UserID uint `gorm:"primaryKey;index"`
+	Reaction string `gorm:"size:20;not null"` //upvote, same_issue or thanks
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// how soon a barangay answers citizen feedback, the defaults apply until it
+// sets its own
+type ResponseTarget struct {
+	Barangay_ID 		uint `gorm:"primaryKey"`
+	Barangay 			Barangay `gorm:"foreignKey:Barangay_ID"`
+	ResponseHours 		int `gorm:"not null"`
+	EscalationHours 	int `gorm:"not null"`
+	UpdatedByID 		uint `gorm:"not null"`
+	CreatedAt 			time.Time
+	UpdatedAt 			time.Time
+}
+
+type FeedbackTag struct {
+	FeedbackID uint `gorm:"primaryKey"`
+	Tag string `gorm:"primaryKey;size:30;index"`
+}
+
+// topic the sentiment lexicon found in feedback, unlike tags never chosen by
+// the author
+type FeedbackTopic struct {
+	FeedbackID uint `gorm:"primaryKey"`
+	Topic string `gorm:"primaryKey;size:30;index"`
+}
+
 type FeedbackReply struct {
 	gorm.Model
 	Content string `gorm:"type:text;not null"`
 	FeedbackID uint `gorm:"not null"`
 	Feedback Feedback `gorm:"foreignKey:FeedbackID"`
+	ParentID *uint `gorm:"default:null;index"` //reply being answered, nil for replies to the feedback itself
+	Parent *FeedbackReply `gorm:"foreignKey:ParentID"`
+	Depth int `gorm:"not null;default:0"` //0 for replies to the feedback itself
 	UserID uint `gorm:"not null"`
 	User User `gorm:"foreignKey:UserID"`
+	Role string `gorm:"not null;default:citizen"` //role of the author when replying
+	EditedAt *time.Time `gorm:"default:null"`
+	ModerationState string `gorm:"not null;default:published;index"` //pending, published, hidden, removed
+	ModerationReason *string `gorm:"default:null"`
+	ModeratedAt *time.Time `gorm:"default:null"`
+	ModeratedByID *uint `gorm:"default:null"`
+	ContentHash string `gorm:"size:64;index"` //normalized content, finds reposts
+}
+
+// why the content filters held a feedback or reply for moderation
+type ContentFlag struct {
+	ID 					uint `gorm:"primaryKey"`
+	ContentType 		string `gorm:"size:20;not null;index:idx_content_flags_content"` //feedback or reply
+	ContentID 			uint `gorm:"not null;index:idx_content_flags_content"`
+	Filter 				string `gorm:"size:30;not null"` //words, links, phone, duplicate, rate
+	Reason 				string `gorm:"size:30;not null"` //moderation reason code
+	Detail 				string `gorm:"type:text"`
+	CreatedAt 			time.Time
+}
+
+// every moderation decision on a feedback or reply, kept for auditors
+type ModerationAction struct {
+	ID 					uint `gorm:"primaryKey"`
+	ContentType 		string `gorm:"size:20;not null;index:idx_moderation_actions_content"` //feedback or reply
+	ContentID 			uint `gorm:"not null;index:idx_moderation_actions_content"`
+	ModeratorID 		uint `gorm:"not null"`
+	Moderator 			User `gorm:"foreignKey:ModeratorID"`
+	FromState 			string `gorm:"not null"`
+	ToState 			string `gorm:"not null"`
+	Reason 				*string `gorm:"default:null"`
+	Note 				string `gorm:"type:text"`
+	CreatedAt 			time.Time
+}
+
+// author's request to restore hidden or removed content
+type ModerationAppeal struct {
+	ID 					uint `gorm:"primaryKey"`
+	ContentType 		string `gorm:"size:20;not null;index:idx_moderation_appeals_content"`
+	ContentID 			uint `gorm:"not null;index:idx_moderation_appeals_content"`
+	Barangay_ID 		uint `gorm:"not null;index"`
+	UserID 				uint `gorm:"not null;index"`
+	User 				User `gorm:"foreignKey:UserID"`
+	Statement 			string `gorm:"type:text;not null"`
+	Status 				string `gorm:"not null;default:open"` //open, upheld, overturned
+	ReviewedByID 		*uint `gorm:"default:null"`
+	DecisionNote 		string `gorm:"type:text"`
+	ReviewedAt 			*time.Time `gorm:"default:null"`
+	CreatedAt 			time.Time
+	UpdatedAt 			time.Time
+}
+
+type ResidencyClaim struct {
+	gorm.Model
+	UserID 			uint `gorm:"not null;index;uniqueIndex:idx_residency_claims_pending,where:status = 'pending' AND deleted_at IS NULL"` //at most one pending claim per user
+	User 			User `gorm:"foreignKey:UserID"`
+	Barangay_ID 		uint `gorm:"not null;index"`
+	Barangay 		Barangay `gorm:"foreignKey:Barangay_ID"`
+	Status 			string `gorm:"not null;default:pending"` //pending, approved, rejected
+	Note 			string `gorm:"type:text"`
+	ReviewedByID 		*uint `gorm:"default:null"`
+	ReviewedAt 		*time.Time `gorm:"default:null"`
+}
+
+// One term of office. A re-elected official gets a new row so approvals made
+// in earlier terms keep pointing at the term they were made in.
+type Official struct {
+	gorm.Model
+	Barangay_ID 		uint `gorm:"not null;index"`
+	Barangay 		Barangay `gorm:"foreignKey:Barangay_ID"`
+	UserID 			*uint `gorm:"default:null;index"`
+	User 			*User `gorm:"foreignKey:UserID"`
+	FirstName 		string `gorm:"not null"`
+	LastName 		string `gorm:"not null"`
+	Position 		string `gorm:"not null"` //punong_barangay, kagawad, sk_chairperson, secretary, treasurer
+	TermStart 		time.Time `gorm:"not null"`
+	TermEnd 		time.Time `gorm:"not null"` //scheduled end of the term
+	EndedAt 		*time.Time `gorm:"default:null"` //set when the term ended early
+	EndReason 		string `gorm:"default:null"`
+	PhotoURL 		string `gorm:"default:null"`
+	PhotoThumbnail 		string `gorm:"default:null"`
+	Committees 		[]OfficialCommittee `gorm:"foreignKey:OfficialID"`
+}
+
+type OfficialCommittee struct {
+	ID 			uint `gorm:"primaryKey"`
+	OfficialID 		uint `gorm:"not null;index"`
+	Name 			string `gorm:"not null"` //committee the official chairs
+}
+
+// Sangguniang Barangay resolution or ordinance. Numbers restart per kind so
+// the pair is unique within a barangay.
+type Resolution struct {
+	gorm.Model
+	Barangay_ID 		uint `gorm:"not null;uniqueIndex:idx_resolutions_number"`
+	Barangay 		Barangay `gorm:"foreignKey:Barangay_ID"`
+	Kind 			string `gorm:"not null;uniqueIndex:idx_resolutions_number"` //resolution, ordinance
+	Number 			string `gorm:"not null;uniqueIndex:idx_resolutions_number"`
+	Title 			string `gorm:"not null"`
+	Summary 		string `gorm:"type:text"`
+	DateEnacted 		time.Time `gorm:"not null"`
+	VotesFor 		int `gorm:"not null;default:0"`
+	VotesAgainst 		int `gorm:"not null;default:0"`
+	Abstentions 		int `gorm:"not null;default:0"`
+	DocumentURL 		string `gorm:"default:null"` //signed PDF copy
+	Votes 			[]ResolutionVote `gorm:"foreignKey:ResolutionID"`
+}
+
+type ResolutionVote struct {
+	ID 			uint `gorm:"primaryKey"`
+	ResolutionID 		uint `gorm:"not null;uniqueIndex:idx_resolution_votes_member"`
+	OfficialID 		uint `gorm:"not null;uniqueIndex:idx_resolution_votes_member"`
+	Official 		Official `gorm:"foreignKey:OfficialID"`
+	Vote 			string `gorm:"not null"` //yes, no, abstain
+}
+
+// project idea a resident puts to their barangay. Officials accept or
+// decline it, an accepted proposal is converted into a project.
+type Proposal struct {
+	gorm.Model
+	Barangay_ID 		uint `gorm:"not null;index"`
+	Barangay 		Barangay `gorm:"foreignKey:Barangay_ID"`
+	UserID 			uint `gorm:"not null;index"`
+	User 			User `gorm:"foreignKey:UserID"`
+	Title 			string `gorm:"not null"`
+	Description 		string `gorm:"type:text;not null"`
+	Location 		string //purok, street or landmark
+	EstimatedCost 		float64 `gorm:"not null;default:0"`
+	Status 			string `gorm:"size:20;not null;default:submitted;index"` //submitted, accepted, declined
+	ReviewNote 		string `gorm:"type:text"`
+	ReviewedByID 		*uint `gorm:"default:null"`
+	ReviewedAt 		*time.Time `gorm:"default:null"`
+	ProjectID 		*uint `gorm:"default:null;uniqueIndex"` //project the proposal was converted into
+	Project 		*Project `gorm:"foreignKey:ProjectID"`
+	EndorsementCount 	int `gorm:"not null;default:0;index"`
+	Photos 			[]ProposalPhoto `gorm:"foreignKey:ProposalID"`
+	Endorsements 		[]ProposalEndorsement `gorm:"foreignKey:ProposalID"`
+}
+
+type ProposalPhoto struct {
+	ID 			uint `gorm:"primaryKey"`
+	ProposalID 		uint `gorm:"not null;index"`
+	URL 			string `gorm:"not null"`
+	Caption 		string
+	CreatedAt 		time.Time
+}
+
+// one resident backing a proposal
+type ProposalEndorsement struct {
+	ProposalID 		uint `gorm:"primaryKey"`
+	UserID 			uint `gorm:"primaryKey;index"`
+	CreatedAt 		time.Time
+}
+
+// participatory budgeting poll, verified residents of the barangay vote on
+// which proposed projects to fund in a fiscal year
+type Poll struct {
+	gorm.Model
+	Barangay_ID 		uint `gorm:"not null;index"`
+	Barangay 		Barangay `gorm:"foreignKey:Barangay_ID"`
+	FiscalYear 		int `gorm:"not null;index"`
+	Title 			string `gorm:"not null"`
+	Description 		string `gorm:"type:text"`
+	Method 			string `gorm:"size:20;not null"` //single, ranked
+	OpensAt 		time.Time `gorm:"not null"`
+	ClosesAt 		time.Time `gorm:"not null"`
+	CreatedByID 		uint `gorm:"not null"`
+	Candidates 		[]PollCandidate `gorm:"foreignKey:PollID"`
+}
+
+type PollCandidate struct {
+	ID 			uint `gorm:"primaryKey"`
+	PollID 			uint `gorm:"not null;uniqueIndex:idx_poll_candidates_project"`
+	ProjectID 		uint `gorm:"not null;uniqueIndex:idx_poll_candidates_project"`
+	Project 		Project `gorm:"foreignKey:ProjectID"`
+}
+
+// resident who cast a ballot, kept apart from the ballot so the API never shows how someone voted
+type PollVoter struct {
+	PollID 			uint `gorm:"primaryKey"`
+	UserID 			uint `gorm:"primaryKey"`
+}
+
+type PollBallot struct {
+	ID 			uint `gorm:"primaryKey"`
+	PollID 			uint `gorm:"not null;index"`
+	Choices 		[]PollChoice `gorm:"foreignKey:BallotID"`
+}
+
+// one candidate on a ballot, rank 1 is the first choice
+type PollChoice struct {
+	BallotID 		uint `gorm:"primaryKey"`
+	Rank 			int `gorm:"primaryKey"`
+	CandidateID 		uint `gorm:"not null"`
 }
@@ -0,0 +1,46 @@
+package models
+
+type NewExpenseClass struct {
+	Code       string `json:"code" binding:"required,max=20"`
+	ParentCode string `json:"parent_code" binding:"omitempty,max=20"` //empty for a new allotment class
+	Name       string `json:"name" binding:"required,max=150"`
+}
+
+type UpdateExpenseClass struct {
+	Name   string `json:"name" binding:"required,max=150"`
+	Active *bool  `json:"active"` //left as is when omitted
+}
+
+type AssignObjectCode struct {
+	ObjectCode string `json:"object_code" binding:"required,max=20"`
+}
+
+type ExpenseClassNode struct {
+	Code     string             `json:"code"`
+	Name     string             `json:"name"`
+	Active   bool               `json:"active"`
+	Children []ExpenseClassNode `json:"children"`
+}
+
+type ObjectCodeTotal struct {
+	Code   string  `json:"code"`
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// budget items of one allotment class, by object code
+type ExpenseClassTotal struct {
+	Code    string            `json:"code"`
+	Name    string            `json:"name"`
+	Amount  float64           `json:"amount"`
+	Share   float64           `json:"share"` //as a percentage of the fiscal year's budget
+	Objects []ObjectCodeTotal `json:"objects"`
+}
+
+type ExpenseClassReport struct {
+	Barangay_ID  uint                `json:"barangay_ID"`
+	FiscalYear   int                 `json:"fiscal_year"`
+	Total        float64             `json:"total"`
+	Classes      []ExpenseClassTotal `json:"classes"`
+	Unclassified float64             `json:"unclassified"` //budget items without an object code
+}
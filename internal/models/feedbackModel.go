@@ -1,25 +1,108 @@
 package models
 
+import "time"
+
+// kinds of feedback
+const (
+	FeedbackComplaint    = "complaint" //tracked as an issue until resolved
+	FeedbackSuggestion   = "suggestion"
+	FeedbackQuestion     = "question"
+	FeedbackCommendation = "commendation"
+)
+
+// lifecycle of a complaint
+const (
+	IssueOpen         = "open"
+	IssueAcknowledged = "acknowledged"
+	IssueResolved     = "resolved"
+)
+
+// how the author of feedback is shown to the public
+const (
+	IdentityNamed        = "named"
+	IdentityPseudonymous = "pseudonymous" //a pseudonym that stays the same within a project
+	IdentityAnonymous    = "anonymous"
+)
+
+// tone of feedback as scored by the sentiment lexicon
+const (
+	SentimentPositive = "positive"
+	SentimentNeutral  = "neutral"
+	SentimentNegative = "negative"
+)
+
+// reactions to feedback, upvote and same_issue count as support
+const (
+	ReactionUpvote    = "upvote"
+	ReactionSameIssue = "same_issue" //the resident has the same concern
+	ReactionThanks    = "thanks"
+)
+
 type NewFeedback struct {
-	Content string `json:"content"`
+	Content string   `json:"content" binding:"required,max=2000"`
+	Type    string   `json:"type" binding:"omitempty,oneof=complaint suggestion question commendation"` //suggestion when left out
+	Tags    []string `json:"tags" binding:"omitempty,max=5,dive,min=1,max=30"`
+
+	Identity string `json:"identity" binding:"omitempty,oneof=named pseudonymous anonymous"` //named when left out
 }
 
 // struct used for inputting data in the database
 type CreateFeedback struct {
 	Content   string
+	Type      string
+	Tags      []string
+	Identity  string
 	Role      string
 	UserID    uint
 	ProjectID uint
 }
 
+type React struct {
+	Reaction string `json:"reaction" binding:"required,oneof=upvote same_issue thanks"`
+}
+
+// an official acknowledging or resolving a complaint
+type UpdateIssue struct {
+	Status string `json:"status" binding:"required,oneof=acknowledged resolved"`
+	Note   string `json:"note" binding:"max=2000"` //required to resolve
+}
+
 type GetAllFeedbacks struct {
-	ID        uint   `json:"feedback_id"`
-	Content   string `json:"content"`
-	Role      string `json:"role"`
-	UserID    uint   `json:"user_id"`
-	ProjectID uint   `json:"project_id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	ID             uint       `json:"feedback_id"`
+	Content        string     `json:"content"`
+	Type           string     `json:"type"`
+	Tags           []string   `json:"tags" gorm:"-"`
+	Role           string     `json:"role"`
+	UserID         uint       `json:"user_id"` //0 unless named
+	ProjectID      uint       `json:"project_id"`
+	FirstName      string     `json:"first_name"` //the alias unless named
+	LastName       string     `json:"last_name"`
+	Identity       string     `json:"identity"`
+	AuthorAlias    string     `json:"-"`
+	Mine           bool       `json:"mine" gorm:"-"` //posted by the viewer
+	IssueStatus    *string    `json:"issue_status"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	SupportCount   int        `json:"support"`
+	CreatedAt      time.Time  `json:"created_at"`
+
+	Reactions  map[string]int64 `json:"reactions" gorm:"-"`
+	MyReaction *string          `json:"my_reaction" gorm:"-"` //the viewer's reaction
+}
+
+// published feedback residents back the most
+type SupportedConcern struct {
+	FeedbackID   uint      `json:"feedback_id"`
+	Content      string    `json:"content"`
+	Type         string    `json:"type"`
+	IssueStatus  *string   `json:"issue_status"`
+	Support      int       `json:"support"`
+	ProjectID    uint      `json:"project_id"`
+	ProjectName  string    `json:"project_name"`
+	Barangay_ID  uint      `json:"barangay_ID"`
+	BarangayName string    `json:"barangay_name"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type FeedbackUser struct {
@@ -27,3 +110,60 @@ type FeedbackUser struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
 }
+
+type DisclosureRequest struct {
+	Reason string `json:"reason" binding:"required,min=10,max=1000"`
+}
+
+// author of anonymous or pseudonymous feedback as a designated moderator
+// sees them
+type DisclosedAuthor struct {
+	FeedbackID uint   `json:"feedback_id"`
+	UserID     uint   `json:"user_id"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Email      string `json:"email"`
+	Contact    string `json:"contact"`
+	Identity   string `json:"identity"`
+	Alias      string `json:"alias"`
+}
+
+type IdentityModeratorResponse struct {
+	ID             uint      `json:"id"`
+	UserID         uint      `json:"user_id"`
+	FirstName      string    `json:"first_name"`
+	LastName       string    `json:"last_name"`
+	Role           string    `json:"role"`
+	Barangay_ID    *uint     `json:"barangay_ID"`
+	DesignatedByID uint      `json:"designated_by_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type IdentityDisclosureResponse struct {
+	ID          uint      `json:"id"`
+	FeedbackID  uint      `json:"feedback_id"`
+	ModeratorID uint      `json:"moderator_id"`
+	FirstName   string    `json:"first_name"` //of the moderator
+	LastName    string    `json:"last_name"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// tone of published citizen feedback on a project or in a barangay
+type SentimentSummary struct {
+	ID            uint    `json:"id"`
+	Name          string  `json:"name"`
+	Feedbacks     int64   `json:"feedbacks"`
+	Positive      int64   `json:"positive"`
+	Neutral       int64   `json:"neutral"`
+	Negative      int64   `json:"negative"`
+	AverageScore  float64 `json:"average_score"`  //-1 to 1
+	NegativeShare float64 `json:"negative_share"` //percent of the feedback that is negative
+}
+
+type TopicSummary struct {
+	Topic        string  `json:"topic"`
+	Feedbacks    int64   `json:"feedbacks"`
+	Negative     int64   `json:"negative"`
+	AverageScore float64 `json:"average_score"`
+}
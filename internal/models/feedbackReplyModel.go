@@ -1,17 +1,44 @@
 package models
 
+import "time"
+
 type Reply struct {
-	Content string `json:"feedback_reply"`
+	Content  string `json:"feedback_reply" binding:"required,max=2000"`
+	ParentID *uint  `json:"parent_ID" binding:"omitempty,min=1"` //answers another reply
 }
 
 type EditReply struct {
-	Content string `json:"content"`
-	UserID  uint   `json:"userID"`
+	Content string `json:"content" binding:"required,max=2000"`
 }
 
 // struct to be stored in database
 type NewFeedbackReply struct {
 	Content    string
 	FeedbackID string
+	ParentID   *uint
 	UserID     uint
+	Role       string //decides whether the reply is screened for moderation
+}
+
+// author of a reply as the public sees them
+type ReplyAuthor struct {
+	ID        uint   `json:"user_ID"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type ReplyResponse struct {
+	ID         uint        `json:"id"`
+	FeedbackID uint        `json:"feedback_ID"`
+	ParentID   *uint       `json:"parent_ID"`
+	Depth      int         `json:"depth"`
+	Content    string      `json:"content"`
+	Role       string      `json:"role"`
+	Official   bool        `json:"official" gorm:"-"` //posted on behalf of the barangay
+	State      string      `json:"state"`             //anything but published is only shown to the author
+	Author     ReplyAuthor `json:"author" gorm:"embedded;embeddedPrefix:author_"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	EditedAt   *time.Time  `json:"editedAt"`
+
+	Replies []ReplyResponse `json:"replies" gorm:"-"`
 }
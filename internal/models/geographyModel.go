@@ -0,0 +1,30 @@
+package models
+
+// region, province or city/municipality returned for selection inputs
+type GeoArea struct {
+	Code  string `json:"code"`
+	Name  string `json:"name"`
+	Level string `json:"level,omitempty"`
+}
+
+// returned by the PSGC importer
+type PSGCImportSummary struct {
+	Regions            int      `json:"regions"`
+	Provinces          int      `json:"provinces"`
+	CityMunicipalities int      `json:"city_municipalities"`
+	BarangaysLinked    int      `json:"barangays_linked"`
+	BarangaysUnmatched []string `json:"barangays_unmatched"` //existing barangays that still need a psgc_code
+}
+
+// project, budget and feedback totals of one region, province,
+// city/municipality or barangay
+type GeoRollup struct {
+	Code              string  `json:"code"`
+	Name              string  `json:"name"`
+	Barangays         int64   `json:"barangays"`
+	Projects          int64   `json:"projects"`
+	CompletedProjects int64   `json:"completed_projects"`
+	CompletionRate    float64 `json:"completion_rate"` //as a percentage
+	BudgetAllocated   float64 `json:"budget_allocated"`
+	Feedbacks         int64   `json:"feedbacks"`
+}
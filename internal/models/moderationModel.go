@@ -0,0 +1,110 @@
+package models
+
+import "time"
+
+// moderation states of feedback and replies
+const (
+	ModerationPending   = "pending"   //waiting for a moderator, only its author sees it
+	ModerationPublished = "published" //visible to everyone
+	ModerationHidden    = "hidden"    //kept from the public, its author still sees it
+	ModerationRemoved   = "removed"   //visible to auditors only
+)
+
+// reason codes a moderator gives for hiding or removing content
+const (
+	ReasonSpam           = "spam"
+	ReasonHarassment     = "harassment"
+	ReasonHateSpeech     = "hate_speech"
+	ReasonPersonalInfo   = "personal_info"
+	ReasonOffTopic       = "off_topic"
+	ReasonMisinformation = "misinformation"
+	ReasonOther          = "other"
+)
+
+// kinds of content that are moderated
+const (
+	ContentFeedback = "feedback"
+	ContentReply    = "reply"
+)
+
+// appeal statuses
+const (
+	AppealOpen       = "open"
+	AppealUpheld     = "upheld"     //the moderator's decision stands
+	AppealOverturned = "overturned" //the content is published again
+)
+
+type ModerateContent struct {
+	State  string `json:"state" binding:"required,oneof=published hidden removed"`
+	Reason string `json:"reason" binding:"omitempty,oneof=spam harassment hate_speech personal_info off_topic misinformation other"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+type NewAppeal struct {
+	Statement string `json:"statement" binding:"required,max=2000"`
+}
+
+type ResolveAppeal struct {
+	Decision string `json:"decision" binding:"required,oneof=upheld overturned"`
+	Note     string `json:"note" binding:"max=500"`
+}
+
+// feedback or reply as a moderator sees it
+type ModerationItem struct {
+	ID               uint       `json:"id"`
+	ContentType      string     `json:"contentType"`
+	Content          string     `json:"content"`
+	ModerationState  string     `json:"state"`
+	ModerationReason *string    `json:"reason"`
+	ModeratedAt      *time.Time `json:"moderatedAt"`
+	UserID           uint       `json:"user_ID"` //0 for feedback posted without the author's name
+	FirstName        string     `json:"firstName"`
+	LastName         string     `json:"lastName"`
+	Identity         string     `json:"identity"`
+	AuthorAlias      string     `json:"-"`
+	ProjectID        uint       `json:"project_ID"`
+	ProjectName      string     `json:"projectName"`
+	FeedbackID       *uint      `json:"feedback_ID,omitempty"` //set on replies
+	CreatedAt        time.Time  `json:"createdAt"`
+
+	Flags []ContentFlagResponse `json:"flags" gorm:"-"`
+}
+
+// what a content filter found in a held post
+type ContentFlagResponse struct {
+	ContentID uint   `json:"-"`
+	Filter    string `json:"filter"`
+	Reason    string `json:"reason"`
+	Detail    string `json:"detail"`
+}
+
+type ModerationActionResponse struct {
+	ID          uint      `json:"id"`
+	ModeratorID uint      `json:"moderator_ID"`
+	FirstName   string    `json:"firstName"`
+	LastName    string    `json:"lastName"`
+	FromState   string    `json:"fromState"`
+	ToState     string    `json:"toState"`
+	Reason      *string   `json:"reason"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type AppealResponse struct {
+	ID           uint       `json:"id"`
+	ContentType  string     `json:"contentType"`
+	ContentID    uint       `json:"content_ID"`
+	UserID       uint       `json:"user_ID"`
+	Statement    string     `json:"statement"`
+	Status       string     `json:"status"`
+	DecisionNote string     `json:"decisionNote"`
+	ReviewedAt   *time.Time `json:"reviewedAt"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// removed content with the decisions that led to it, for auditors
+type ModerationHistory struct {
+	Item    ModerationItem             `json:"item"`
+	Actions []ModerationActionResponse `json:"actions"`
+	Appeals []AppealResponse           `json:"appeals"`
+}
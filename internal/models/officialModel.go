@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// elective and appointive barangay positions
+const (
+	PositionPunongBarangay = "punong_barangay"
+	PositionKagawad        = "kagawad"
+	PositionSKChairperson  = "sk_chairperson"
+	PositionSecretary      = "secretary"
+	PositionTreasurer      = "treasurer"
+)
+
+// JSON struct for recording an official's term, one per person per term
+type NewOfficial struct {
+	FirstName  string   `json:"first_name" binding:"required,max=100"`
+	LastName   string   `json:"last_name" binding:"required,max=100"`
+	Position   string   `json:"position" binding:"required,oneof=punong_barangay kagawad sk_chairperson secretary treasurer"`
+	Committees []string `json:"committees" binding:"max=10,dive,required,max=100"` //committee chairmanships
+	TermStart  string   `json:"termStart" binding:"required,datetime=2006-01-02"`
+	TermEnd    string   `json:"termEnd" binding:"required,datetime=2006-01-02"`
+	UserID     *uint    `json:"user_id"` //set when the official has an account
+}
+
+type UpdateOfficial struct {
+	FirstName  string   `json:"first_name" binding:"required,max=100"`
+	LastName   string   `json:"last_name" binding:"required,max=100"`
+	Committees []string `json:"committees" binding:"max=10,dive,required,max=100"`
+	UserID     *uint    `json:"user_id"`
+}
+
+// JSON struct for ending a term early, on resignation, recall or death
+type EndOfficialTerm struct {
+	EndedAt   string `json:"endedAt" binding:"required,datetime=2006-01-02"`
+	EndReason string `json:"end_reason" binding:"required,max=200"`
+}
+
+// displayed in the public officials directory
+type OfficialResponse struct {
+	ID             uint       `json:"id"`
+	Barangay_ID    uint       `json:"barangay_ID"`
+	UserID         *uint      `json:"user_id"`
+	FirstName      string     `json:"first_name"`
+	LastName       string     `json:"last_name"`
+	Position       string     `json:"position"`
+	Committees     []string   `json:"committees" gorm:"-"`
+	TermStart      time.Time  `json:"termStart"`
+	TermEnd        time.Time  `json:"termEnd"`
+	EndedAt        *time.Time `json:"endedAt"`
+	EndReason      string     `json:"end_reason"`
+	PhotoURL       string     `json:"photo_url"`
+	PhotoThumbnail string     `json:"photo_thumbnail"`
+}
+
+type OfficialPhotoResponse struct {
+	PhotoURL       string `json:"photo_url"`
+	PhotoThumbnail string `json:"photo_thumbnail"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// how residents vote in a poll
+const (
+	PollSingle = "single" //one vote for one project
+	PollRanked = "ranked" //projects ranked by preference, counted by instant runoff
+)
+
+// where a poll is in its window
+const (
+	PollUpcoming = "upcoming"
+	PollOpen     = "open"
+	PollClosed   = "closed"
+)
+
+// JSON struct for an official opening a poll on proposed projects
+type NewPoll struct {
+	FiscalYear  int       `json:"fiscal_year" binding:"required,gte=1991,lte=2100"`
+	Title       string    `json:"title" binding:"required,max=200"`
+	Description string    `json:"description" binding:"max=2000"`
+	Method      string    `json:"method" binding:"required,oneof=single ranked"`
+	OpensAt     time.Time `json:"opens_at" binding:"required"`
+	ClosesAt    time.Time `json:"closes_at" binding:"required,gtfield=OpensAt"`
+	ProjectIDs  []uint    `json:"project_IDs" binding:"required,min=2,max=30,dive,required"`
+}
+
+// JSON struct for a resident's ballot, candidate IDs in order of preference.
+// Single choice polls take exactly one.
+type CastBallot struct {
+	Choices []uint `json:"choices" binding:"required,min=1,max=30,dive,required"`
+}
+
+type PollCandidateResponse struct {
+	ID          uint   `json:"id"`
+	ProjectID   uint   `json:"project_ID"`
+	ProjectName string `json:"project_name"`
+	Description string `json:"description"`
+}
+
+type PollResponse struct {
+	ID          uint                    `json:"id"`
+	Barangay_ID uint                    `json:"barangay_ID"`
+	FiscalYear  int                     `json:"fiscal_year"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	Method      string                  `json:"method"`
+	OpensAt     time.Time               `json:"opens_at"`
+	ClosesAt    time.Time               `json:"closes_at"`
+	Status      string                  `json:"status" gorm:"-"`
+	Candidates  []PollCandidateResponse `json:"candidates,omitempty" gorm:"-"`
+	Voted       bool                    `json:"voted" gorm:"-"` //the viewer has cast a ballot
+}
+
+type CandidateTally struct {
+	CandidateID uint   `json:"candidate_ID"`
+	ProjectID   uint   `json:"project_ID"`
+	ProjectName string `json:"project_name"`
+	Votes       int    `json:"votes"`
+}
+
+// one counting round of an instant runoff
+type RunoffRound struct {
+	Round      int              `json:"round"`
+	Tallies    []CandidateTally `json:"tallies"`
+	Exhausted  int              `json:"exhausted"` //ballots with no remaining choice
+	Eliminated *uint            `json:"eliminated_candidate_ID"`
+}
+
+// results published once a poll closes. Ranking lists every candidate from
+// first to last place.
+type PollResults struct {
+	PollID   uint             `json:"poll_ID"`
+	Method   string           `json:"method"`
+	Ballots  int              `json:"ballots"`
+	Eligible int64            `json:"eligible"` //verified residents of the barangay
+	Winner   *CandidateTally  `json:"winner"`
+	Ranking  []CandidateTally `json:"ranking"`
+	Rounds   []RunoffRound    `json:"rounds,omitempty"`
+}
@@ -3,22 +3,22 @@ package models
 import "time"
 
 type NewProject struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	StartDate   string `json:"startDate"`
-    EndDate     string `json:"endDate"`
-	Status string `json:"status"`
+	Name        string `json:"name" binding:"required,max=150"`
+	Description string `json:"description" binding:"max=2000"`
+	StartDate   string `json:"startDate" binding:"required,datetime=2006-01-02"`
+    EndDate     string `json:"endDate" binding:"required,datetime=2006-01-02"`
+	Status string `json:"status" binding:"required,oneof=planned ongoing completed"`
 }
 
 type UpdateProject struct {
-    Name string `json:"name"`
-    Description string `json:"description"`
+    Name string `json:"name" binding:"required,max=150"`
+    Description string `json:"description" binding:"max=2000"`
 }
 
 // For displaying Project Status at the Client Side
 type NewProjectStatus struct {
-    Status string `json:"status"`
-    FlexDate time.Time `json:"flexdate"`
+    Status string `json:"status" binding:"required,oneof=planned ongoing completed"`
+    FlexDate time.Time `json:"flexdate" binding:"required"`
 }
 
 // Projects are displayed in projectList.jsx
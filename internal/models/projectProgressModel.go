@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type NewMilestone struct {
+	Title   string `json:"title" binding:"required,max=150"`
+	DueDate string `json:"dueDate" binding:"required,datetime=2006-01-02"`
+}
+
+type CompleteMilestone struct {
+	CompletedAt string `json:"completedAt" binding:"required,datetime=2006-01-02"`
+}
+
+type MilestoneResponse struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	DueDate     time.Time  `json:"dueDate"`
+	CompletedAt *time.Time `json:"completedAt"`
+}
+
+type ProjectPhotoResponse struct {
+	ID        uint      `json:"id"`
+	URL       string    `json:"url"`
+	Caption   string    `json:"caption"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// milestones and site photos shown on a project's page and status sheet
+type ProjectProgress struct {
+	ProjectID  uint                   `json:"project_ID"`
+	Milestones []MilestoneResponse    `json:"milestones"`
+	Photos     []ProjectPhotoResponse `json:"photos"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// where a proposal is in review
+const (
+	ProposalSubmitted = "submitted"
+	ProposalAccepted  = "accepted"
+	ProposalDeclined  = "declined"
+)
+
+// JSON struct for a resident proposing a project to their barangay
+type NewProposal struct {
+	Title         string  `json:"title" binding:"required,max=150"`
+	Description   string  `json:"description" binding:"required,min=20,max=4000"`
+	Location      string  `json:"location" binding:"max=200"`
+	EstimatedCost float64 `json:"estimated_cost" binding:"gte=0,lte=1000000000"`
+}
+
+// JSON struct for an official accepting or declining a proposal
+type ReviewProposal struct {
+	Decision string `json:"decision" binding:"required,oneof=accepted declined"`
+	Note     string `json:"note" binding:"max=1000"`
+}
+
+// JSON struct for turning an accepted proposal into a project. The project
+// is named after the proposal unless a name is given.
+type ConvertProposal struct {
+	CategoryID uint   `json:"category_ID" binding:"required"`
+	Name       string `json:"name" binding:"max=150"`
+	StartDate  string `json:"startDate" binding:"required,datetime=2006-01-02"`
+	EndDate    string `json:"endDate" binding:"required,datetime=2006-01-02"`
+}
+
+type ProposalResponse struct {
+	ID            uint                   `json:"id"`
+	Barangay_ID   uint                   `json:"barangay_ID"`
+	UserID        uint                   `json:"user_id"`
+	FirstName     string                 `json:"first_name"`
+	LastName      string                 `json:"last_name"`
+	Title         string                 `json:"title"`
+	Description   string                 `json:"description"`
+	Location      string                 `json:"location"`
+	EstimatedCost float64                `json:"estimated_cost"`
+	Status        string                 `json:"status"`
+	ReviewNote    string                 `json:"review_note"`
+	ReviewedAt    *time.Time             `json:"reviewed_at"`
+	ProjectID     *uint                  `json:"project_ID"`
+	Endorsements  int                    `json:"endorsements"`
+	CreatedAt     time.Time              `json:"created_at"`
+	Photos        []ProjectPhotoResponse `json:"photos,omitempty" gorm:"-"`
+	Endorsed      bool                   `json:"endorsed" gorm:"-"` //the viewer endorses it
+}
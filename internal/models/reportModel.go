@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// heading printed at the top of every report, taken from the barangay record
+type Letterhead struct {
+	Barangay string
+	City     string
+	Region   string
+	SealURL  string
+}
+
+type ReportBudgetItem struct {
+	Name   string
+	Status string
+	Amount float64
+}
+
+type ProjectStatusSheet struct {
+	Letterhead  Letterhead
+	Name        string
+	Category    string
+	Status      string
+	StartDate   time.Time
+	EndDate     time.Time
+	Description string
+	Items       []ReportBudgetItem
+	Milestones  []MilestoneResponse
+	Photos      []ProjectPhotoResponse
+}
+
+type ReportProjectFeedback struct {
+	ProjectID uint
+	Name      string
+	Status    string
+	Feedback  int
+	Replies   int
+	LatestAt  *time.Time
+}
+
+type ReportFeedbackExcerpt struct {
+	ProjectName string
+	FirstName   string
+	LastName    string
+	Role        string
+	Content     string
+	CreatedAt   time.Time
+}
+
+type FeedbackSummary struct {
+	Letterhead Letterhead
+	Projects   []ReportProjectFeedback
+	Recent     []ReportFeedbackExcerpt
+	Feedback   int
+	Replies    int
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// residency claim states
+const (
+	ResidencyPending  = "pending"
+	ResidencyApproved = "approved"
+	ResidencyRejected = "rejected"
+)
+
+// JSON struct for a citizen claiming residency in a barangay
+type NewResidencyClaim struct {
+	Barangay_ID uint `json:"barangay_ID" binding:"required"`
+}
+
+// JSON struct for an official approving or rejecting a claim
+type ReviewResidencyClaim struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note" binding:"max=500"`
+}
+
+// pending claims listed for barangay officials
+type ResidencyClaimResponse struct {
+	ID          uint      `json:"id"`
+	UserID      uint      `json:"user_id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	Contact     string    `json:"contact"`
+	Barangay_ID uint      `json:"barangay_ID"`
+	Status      string    `json:"status"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Sangguniang Barangay measure kinds
+const (
+	MeasureResolution = "resolution"
+	MeasureOrdinance  = "ordinance"
+)
+
+// roll call votes
+const (
+	VoteYes     = "yes"
+	VoteNo      = "no"
+	VoteAbstain = "abstain"
+)
+
+// JSON struct for one member's vote on a measure
+type ResolutionVoteInput struct {
+	OfficialID uint   `json:"official_ID" binding:"required"`
+	Vote       string `json:"vote" binding:"required,oneof=yes no abstain"`
+}
+
+// JSON struct for registering an enacted resolution or ordinance
+type NewResolution struct {
+	Kind         string                `json:"kind" binding:"required,oneof=resolution ordinance"`
+	Number       string                `json:"number" binding:"required,max=50"` //as printed, e.g. 2024-015
+	Title        string                `json:"title" binding:"required,max=300"`
+	Summary      string                `json:"summary" binding:"max=2000"`
+	DateEnacted  string                `json:"dateEnacted" binding:"required,datetime=2006-01-02"`
+	VotesFor     int                   `json:"votes_for" binding:"gte=0"`
+	VotesAgainst int                   `json:"votes_against" binding:"gte=0"`
+	Abstentions  int                   `json:"abstentions" binding:"gte=0"`
+	RollCall     []ResolutionVoteInput `json:"roll_call" binding:"max=20,dive"` //optional, must agree with the tallies
+}
+
+// JSON struct for tying a budget category, project or budget item to the
+// measure that authorized it
+type LinkResolution struct {
+	Type string `json:"type" binding:"required,oneof=budget_category project budget_item"`
+	ID   uint   `json:"id" binding:"required"`
+}
+
+type ResolutionVoteResponse struct {
+	OfficialID uint   `json:"official_ID"`
+	FirstName  string `json:"first_name"`
+	LastName   string `json:"last_name"`
+	Vote       string `json:"vote"`
+}
+
+// shown in the public registry listing
+type ResolutionResponse struct {
+	ID           uint      `json:"id"`
+	Barangay_ID  uint      `json:"barangay_ID"`
+	Kind         string    `json:"kind"`
+	Number       string    `json:"number"`
+	Title        string    `json:"title"`
+	Summary      string    `json:"summary"`
+	DateEnacted  time.Time `json:"dateEnacted"`
+	VotesFor     int       `json:"votes_for"`
+	VotesAgainst int       `json:"votes_against"`
+	Abstentions  int       `json:"abstentions"`
+	DocumentURL  string    `json:"document_url"`
+}
+
+// what a measure authorized
+type ResolutionLink struct {
+	Type string `json:"type"`
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+// single measure looked up by number
+type ResolutionDetail struct {
+	ResolutionResponse
+	RollCall   []ResolutionVoteResponse `json:"roll_call"`
+	Authorizes []ResolutionLink         `json:"authorizes"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type SetResponseTarget struct {
+	ResponseHours   int `json:"response_hours" binding:"required,gte=1,lte=720"`
+	EscalationHours int `json:"escalation_hours" binding:"required,gtefield=ResponseHours,lte=2160"`
+}
+
+// targets are counted in calendar hours, weekends and holidays included
+type ResponseTargetResponse struct {
+	Barangay_ID     uint `json:"barangay_ID"`
+	ResponseHours   int  `json:"response_hours"`
+	EscalationHours int  `json:"escalation_hours"`
+	Default         bool `json:"default"` //the barangay has not set its own
+}
+
+// citizen feedback still waiting for an official past its response target
+type OverdueFeedback struct {
+	ID           uint       `json:"id"`
+	Content      string     `json:"content"`
+	Type         string     `json:"type"`
+	ProjectID    uint       `json:"project_ID"`
+	ProjectName  string     `json:"projectName"`
+	Barangay_ID  uint       `json:"barangay_ID"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	DueAt        time.Time  `json:"dueAt"`
+	EscalatedAt  *time.Time `json:"escalatedAt"`
+	HoursOverdue float64    `json:"hoursOverdue"`
+}
+
+// how a barangay keeps up with citizen feedback, counted over published
+// feedback of citizens
+type BarangayResponsiveness struct {
+	Barangay_ID         uint     `json:"barangay_ID"`
+	BarangayName        string   `json:"barangay_name"`
+	ResponseHours       int      `json:"response_hours"` //calendar hours, weekends and holidays included
+	Feedbacks           int64    `json:"feedbacks"`
+	Answered            int64    `json:"answered"`
+	AnsweredOnTime      int64    `json:"answered_on_time"`
+	Overdue             int64    `json:"overdue"` //unanswered past the target
+	MedianResponseHours *float64 `json:"median_response_hours"`
+	OnTimeRate          float64  `json:"on_time_rate"` //percent of the feedback due so far answered on time
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// revenue source classification
+const (
+	RevenueNTA       = "nta"       //national tax allotment share
+	RevenueRPTShare  = "rpt_share" //share of the real property tax collected by the city/municipality
+	RevenueFees      = "fees"      //clearances, permits and other fees and charges
+	RevenueGrants    = "grants"
+	RevenueDonations = "donations"
+	RevenueOther     = "other"
+)
+
+type NewRevenue struct {
+	FiscalYear   int     `json:"fiscal_year" binding:"required,gte=1991,lte=2100"`
+	Source       string  `json:"source" binding:"required,oneof=nta rpt_share fees grants donations other"`
+	Description  string  `json:"description" binding:"max=500"`
+	Amount       float64 `json:"amount" binding:"required,gt=0"`
+	DateReceived string  `json:"dateReceived" binding:"omitempty,datetime=2006-01-02"`
+}
+
+type RevenueResponse struct {
+	ID           uint       `json:"id"`
+	FiscalYear   int        `json:"fiscal_year"`
+	Source       string     `json:"source"`
+	Description  string     `json:"description"`
+	Amount       float64    `json:"amount"`
+	DateReceived *time.Time `json:"dateReceived"`
+	Summary      bool       `json:"summary"` //a yearly total recorded as income
+}
+
+type RevenueSourceTotal struct {
+	Source string  `json:"source"`
+	Amount float64 `json:"amount"`
+	Share  float64 `json:"share"` //as a percentage of total sources
+}
+
+type RevenueUseTotal struct {
+	Category_ID uint    `json:"category_ID"`
+	Name        string  `json:"name"`
+	Amount      float64 `json:"amount"`
+}
+
+// where a fiscal year's money came from against what it was budgeted for
+type SourcesUsesReport struct {
+	Barangay_ID  uint                 `json:"barangay_ID"`
+	FiscalYear   int                  `json:"fiscal_year"`
+	Sources      []RevenueSourceTotal `json:"sources"`
+	TotalSources float64              `json:"total_sources"`
+	Uses         []RevenueUseTotal    `json:"uses"`
+	TotalUses    float64              `json:"total_uses"`
+	Balance      float64              `json:"balance"` //negative when uses exceed sources
+}
+
+// one bar of the public revenue charts
+type RevenueChartPoint struct {
+	FiscalYear int     `json:"fiscal_year"`
+	Source     string  `json:"source"`
+	Amount     float64 `json:"amount"`
+}
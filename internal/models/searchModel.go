@@ -0,0 +1,14 @@
+package models
+
+// one ranked hit from the unified search, snippet is HTML escaped
+// with the matched words wrapped in <mark>
+type SearchResult struct {
+	Type         string  `json:"type"` //project, budget_item, budget_category, feedback
+	ID           uint    `json:"id"`
+	Title        string  `json:"title"`
+	Snippet      string  `json:"snippet"`
+	Rank         float64 `json:"rank"`
+	Barangay_ID  uint    `json:"barangay_id"`
+	BarangayName string  `json:"barangay_name"`
+	ProjectID    *uint   `json:"project_id,omitempty"` //null for budget categories
+}
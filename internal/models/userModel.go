@@ -1,37 +1,92 @@
 package models
 
-// JSON struct for creating new user
+// user roles, registration only ever creates citizens
+const (
+	RoleCitizen  = "citizen"
+	RoleOfficial = "official"
+	RoleAdmin    = "admin"
+)
+
+// JSON struct for creating new user, the role is always citizen
 type RegisterUser struct {
-	Email       string `json:"email"`
-	Password    string `json:"password"`
-	FirstName   string `json:"firstName"`
-	LastName    string `json:"lastName"`
-	Barangay_ID string `json:"barangay"`
-	Role        string `json:"role"`
-	Contact     string `json:"contact"`
+	Email       string `json:"email" binding:"required,max=254"`
+	Password    string `json:"password" binding:"required,strong_password"`
+	FirstName   string `json:"firstName" binding:"required,max=100"`
+	LastName    string `json:"lastName" binding:"required,max=100"`
+	Barangay_ID string `json:"barangay" binding:"required,numeric"`
+	Contact     string `json:"contact" binding:"required,ph_mobile"`
 }
 
 // JSON struct for logging in
 type LoginUser struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" binding:"required"`
+	Password string `json:"password" binding:"required"`
 }
 
 // struct used for storing session data
 type UserStruct struct {
-	ID            uint
-	Password      string
-	Role          string
-	Barangay_ID   uint
-	Barangay_Name string
+	ID             uint
+	Password       string
+	Role           string
+	Barangay_ID    uint
+	Barangay_Name  string
+	SessionVersion uint
 }
 
 // struct to be returned for user profile display
 type UserProfile struct {
-	ID        uint
-	Email     string
-	FirstName string
-	LastName  string
-	Role      string
-	Contact   string
+	ID                uint
+	Email             string
+	FirstName         string
+	LastName          string
+	Role              string
+	Contact           string
+	ProfilePicture    string
+	ProfileThumbnail  string
+	PendingEmail      string
+	ResidencyVerified bool
+}
+
+// JSON struct for editing non-sensitive profile fields,
+// empty fields are left unchanged
+type UpdateProfile struct {
+	FirstName string `json:"firstName" binding:"omitempty,max=100"`
+	LastName  string `json:"lastName" binding:"omitempty,max=100"`
+	Contact   string `json:"contact" binding:"omitempty,ph_mobile"`
+}
+
+// JSON struct for requesting an email change, the current
+// password is required before a confirmation mail is sent
+type ChangeEmail struct {
+	NewEmail        string `json:"newEmail" binding:"required,max=254"`
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+}
+
+// JSON struct for confirming an email change with the mailed token
+type ConfirmEmailChange struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// JSON struct for changing password
+type ChangePassword struct {
+	CurrentPassword string `json:"currentPassword" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,strong_password"`
+}
+
+// returned after uploading a new profile picture
+type ProfilePictureResponse struct {
+	ProfilePicture   string `json:"profile_picture"`
+	ProfileThumbnail string `json:"profile_thumbnail"`
+}
+
+// claims read from a verified OpenID Connect ID token
+type OIDCClaims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+	Groups        []string
+	Barangay      string
 }
@@ -0,0 +1,329 @@
+// Package pdf writes simple A4 documents of text, rules and images using the
+// standard Helvetica fonts. Output depends only on what is drawn, there are
+// no timestamps or random IDs, so the same input always gives the same bytes.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A4 in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Font int
+
+const (
+	Regular Font = iota
+	Bold
+)
+
+var fontNames = []string{"Helvetica", "Helvetica-Bold"}
+
+type imageObject struct {
+	width, height int
+	data          []byte //zlib compressed RGB samples
+}
+
+type Document struct {
+	pages  []*bytes.Buffer
+	page   int
+	images []imageObject
+}
+
+func New() *Document {
+	return &Document{page: -1}
+}
+
+// AddPage starts a new page and makes it the current one.
+func (d *Document) AddPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.page = len(d.pages) - 1
+}
+
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetPage makes page n, counted from 1, the current page so footers can be
+// drawn once the page count is known.
+func (d *Document) SetPage(n int) {
+	if n >= 1 && n <= len(d.pages) {
+		d.page = n - 1
+	}
+}
+
+func (d *Document) content() *bytes.Buffer {
+	if d.page < 0 {
+		d.AddPage()
+	}
+	return d.pages[d.page]
+}
+
+func number(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// point rounds to hundredths so streams do not carry float noise
+func point(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// Text draws text with its baseline y points from the top of the page.
+func (d *Document) Text(x, y float64, font Font, size float64, text string, gray float64) {
+	fmt.Fprintf(d.content(), "BT %s g /F%d %s Tf %s %s Td (%s) Tj ET\n",
+		number(gray), font+1, number(size), point(x), point(PageHeight-y), escape(text))
+}
+
+// Line draws a rule between two points measured from the top of the page.
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.content(), "%s w 0 G %s %s m %s %s l S\n",
+		number(width), point(x1), point(PageHeight-y1), point(x2), point(PageHeight-y2))
+}
+
+// FillRect fills a rectangle whose top left corner is x, y.
+func (d *Document) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(d.content(), "%s g %s %s %s %s re f\n",
+		number(gray), point(x), point(PageHeight-y-h), point(w), point(h))
+}
+
+// Image draws img scaled into the box whose top left corner is x, y.
+// Transparent pixels are drawn over white.
+func (d *Document) Image(img image.Image, x, y, w, h float64) error {
+	bounds := img.Bounds()
+	samples := make([]byte, 0, bounds.Dx()*bounds.Dy()*3)
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			r, g, b, a := img.At(px, py).RGBA()
+			white := 0xffff - a
+			samples = append(samples, byte((r+white)>>8), byte((g+white)>>8), byte((b+white)>>8))
+		}
+	}
+
+	var compressed bytes.Buffer
+	zw, err := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(samples); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	d.images = append(d.images, imageObject{width: bounds.Dx(), height: bounds.Dy(), data: compressed.Bytes()})
+	fmt.Fprintf(d.content(), "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+		point(w), point(h), point(x), point(PageHeight-y-h), len(d.images))
+	return nil
+}
+
+// Bytes renders the document.
+func (d *Document) Bytes() []byte {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string, stream []byte) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s", len(offsets), body)
+		if stream != nil {
+			out.WriteString("\nstream\n")
+			out.Write(stream)
+			out.WriteString("\nendstream")
+		}
+		out.WriteString("\nendobj\n")
+	}
+
+	// objects: catalog, page tree, two fonts, images, then a page and its
+	// content stream for every page
+	firstImage := 5
+	firstPage := firstImage + len(d.images)
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>", nil)
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), number(PageWidth), number(PageHeight)), nil)
+
+	for _, name := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", name), nil)
+	}
+
+	var xobjects []string
+	for i, img := range d.images {
+		object(fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>",
+			img.width, img.height, len(img.data)), img.data)
+		xobjects = append(xobjects, fmt.Sprintf("/Im%d %d 0 R", i+1, firstImage+i))
+	}
+
+	resources := "/Font << /F1 3 0 R /F2 4 0 R >>"
+	if len(xobjects) > 0 {
+		resources += " /XObject << " + strings.Join(xobjects, " ") + " >>"
+	}
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << %s >> /Contents %d 0 R >>", resources, firstPage+i*2+1), nil)
+		object(fmt.Sprintf("<< /Length %d >>", page.Len()), page.Bytes())
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(d.Bytes())
+	return int64(n), err
+}
+
+// winAnsi maps the text to the WinAnsi code page of the standard fonts,
+// characters it lacks become '?'.
+func winAnsi(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		switch {
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			encoded = append(encoded, byte(r))
+		case r == '‘':
+			encoded = append(encoded, 0x91)
+		case r == '’':
+			encoded = append(encoded, 0x92)
+		case r == '“':
+			encoded = append(encoded, 0x93)
+		case r == '”':
+			encoded = append(encoded, 0x94)
+		case r == '•':
+			encoded = append(encoded, 0x95)
+		case r == '–':
+			encoded = append(encoded, 0x96)
+		case r == '—':
+			encoded = append(encoded, 0x97)
+		case r == '\t', r == '\n', r == '\r':
+			encoded = append(encoded, ' ')
+		default:
+			encoded = append(encoded, '?')
+		}
+	}
+	return encoded
+}
+
+func escape(text string) string {
+	var out strings.Builder
+	for _, b := range winAnsi(text) {
+		switch b {
+		case '(', ')', '\\':
+			out.WriteByte('\\')
+			out.WriteByte(b)
+		default:
+			if b < 0x80 {
+				out.WriteByte(b)
+			} else {
+				fmt.Fprintf(&out, "\\%03o", b)
+			}
+		}
+	}
+	return out.String()
+}
+
+// Width is the advance of text in points.
+func Width(font Font, size float64, text string) float64 {
+	widths := helveticaWidths
+	if font == Bold {
+		widths = helveticaBoldWidths
+	}
+
+	total := 0
+	for _, b := range winAnsi(text) {
+		if b >= 0x20 && b < 0x7f {
+			total += widths[b-0x20]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// Wrap breaks text into lines no wider than width, splitting words only
+// when a single word does not fit.
+func Wrap(font Font, size float64, text string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if Width(font, size, candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			runes := []rune(word)
+			for Width(font, size, string(runes)) > width && len(runes) > 1 {
+				cut := len(runes) - 1
+				for cut > 1 && Width(font, size, string(runes[:cut])) > width {
+					cut--
+				}
+				lines = append(lines, string(runes[:cut]))
+				runes = runes[cut:]
+			}
+			line = string(runes)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Truncate shortens text with an ellipsis to fit width.
+func Truncate(font Font, size float64, text string, width float64) string {
+	if Width(font, size, text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && Width(font, size, string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
+
+// advance widths of ' ' through '~' from the Adobe font metrics
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
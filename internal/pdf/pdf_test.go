@@ -0,0 +1,60 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	lines := Wrap(Regular, 10, "the quick brown fox jumps over the lazy dog", 80)
+	for _, line := range lines {
+		if Width(Regular, 10, line) > 80 {
+			t.Errorf("Wrap() line %q is wider than 80", line)
+		}
+	}
+	if len(lines) < 2 {
+		t.Errorf("Wrap() = %q, want more than one line", lines)
+	}
+
+	long := Wrap(Bold, 10, "Pneumonoultramicroscopicsilicovolcanoconiosis", 60)
+	if len(long) < 2 {
+		t.Errorf("Wrap() did not split a word wider than the line: %q", long)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got, want := escape(`a (b) \ ñ ₱`), `a \(b\) \\ \361 ?`; got != want {
+		t.Errorf("escape() = %q, want %q", got, want)
+	}
+}
+
+func TestBytesCrossReference(t *testing.T) {
+	doc := New()
+	doc.Text(50, 50, Bold, 12, "First page", 0)
+	doc.AddPage()
+	doc.Line(50, 60, 200, 60, 1)
+
+	out := doc.Bytes()
+	if !bytes.Equal(out, doc.Bytes()) {
+		t.Fatal("Bytes() is not deterministic")
+	}
+
+	start := regexp.MustCompile(`startxref\n(\d+)`).FindSubmatch(out)
+	if start == nil {
+		t.Fatal("Bytes() has no startxref")
+	}
+	xref, _ := strconv.Atoi(string(start[1]))
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out[xref:], -1)
+	if len(offsets) != 8 {
+		t.Fatalf("Bytes() has %d objects, want 8", len(offsets))
+	}
+	for i, offset := range offsets {
+		at, _ := strconv.Atoi(string(offset[1]))
+		if !bytes.HasPrefix(out[at:], []byte(fmt.Sprintf("%d 0 obj", i+1))) {
+			t.Errorf("xref entry %d does not point at its object", i+1)
+		}
+	}
+}
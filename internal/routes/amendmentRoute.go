@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAmendmentRoutes(router *gin.RouterGroup, handlers *handlers.AmendmentHandlers) {
+	amendments := router.Group("/amendments")
+	{
+		amendments.POST("/add", handlers.AddAmendment)
+		amendments.GET("/barangay/:barangay_ID", handlers.GetAmendments)
+		amendments.GET("/single/:amendmentID", handlers.GetAmendment)
+		amendments.GET("/budget/:barangay_ID/:fiscal_year", handlers.GetAmendedBudget)
+	}
+}
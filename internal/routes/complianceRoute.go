@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterComplianceRoutes(router *gin.RouterGroup, handlers *handlers.ComplianceHandlers) {
+	compliance := router.Group("/compliance")
+	{
+		compliance.POST("/income", handlers.RecordIncome)
+		compliance.GET("/income/:barangay_ID", handlers.GetIncome)
+		compliance.PUT("/fund/:budget_ID", handlers.SetStatutoryFund)
+		compliance.GET("/barangay/:barangay_ID/:fiscal_year", handlers.GetReport)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterExpenseClassRoutes(router *gin.RouterGroup, handlers *handlers.ExpenseClassHandlers) {
+	expenseClasses := router.Group("/expense-classes")
+	{
+		expenseClasses.POST("/add", handlers.AddExpenseClass)
+		expenseClasses.PUT("/update/:code", handlers.UpdateExpenseClass)
+		expenseClasses.GET("/tree", handlers.GetTree)
+		expenseClasses.PUT("/assign/:budgetItemID", handlers.AssignObjectCode)
+		expenseClasses.GET("/report/:barangay_ID/:fiscal_year", handlers.GetClassReport)
+	}
+}
@@ -11,7 +11,7 @@ func RegisterFeedbackReplyRoutes(router *gin.RouterGroup, handlers *handlers.Fee
 	{
 		feedbackReply.POST("/create/:feedbackID", handlers.CreateFeedbackReply)
 		feedbackReply.GET("/get/:feedbackID", handlers.GetAllReplies)
-		feedbackReply.DELETE("/delete/:feedbackID", handlers.DeleteFeedbackReply)
+		feedbackReply.DELETE("/delete/:replyID", handlers.DeleteFeedbackReply)
 		feedbackReply.PUT("/edit/:replyID", handlers.EditFeedbackReply)
 	}
 }
@@ -13,5 +13,8 @@ func RegisterFeedbackRoutes(router *gin.RouterGroup, handlers *handlers.Feedback
 		feedback.GET("/all/:projectID", handlers.GetAllFeedbacks)
 		feedback.PUT("/update/:feedbackID", handlers.EditFeedback)
 		feedback.DELETE("/delete/:feedbackID", handlers.DeleteFeedback)
+		feedback.PATCH("/issue/:feedbackID", handlers.UpdateIssue)
+		feedback.PUT("/react/:feedbackID", handlers.React)
+		feedback.DELETE("/react/:feedbackID", handlers.RemoveReaction)
 	}
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterGeographyRoutes(router *gin.RouterGroup, handlers *handlers.GeographyHandlers) {
+	geo := router.Group("/geo")
+	{
+		geo.GET("/regions", handlers.GetRegions)
+		geo.GET("/provinces", handlers.GetProvinces)
+		geo.GET("/cities", handlers.GetCityMunicipalities)
+	}
+}
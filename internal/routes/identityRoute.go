@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterIdentityRoutes(router *gin.RouterGroup, handlers *handlers.IdentityHandlers) {
+	identity := router.Group("/identity")
+	{
+		identity.GET("/moderators", handlers.GetModerators)
+		identity.POST("/moderators/:userID", handlers.Designate)
+		identity.DELETE("/moderators/:userID", handlers.Revoke)
+		identity.POST("/disclose/:feedbackID", handlers.Disclose)
+		identity.GET("/disclosures", handlers.GetDisclosures)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterModerationRoutes(router *gin.RouterGroup, handlers *handlers.ModerationHandlers) {
+	moderation := router.Group("/moderation")
+	{
+		moderation.GET("/queue/:barangay_ID", handlers.GetQueue)
+		moderation.GET("/appeals/:barangay_ID", handlers.GetAppeals)
+		moderation.GET("/removed/:barangay_ID", handlers.GetRemoved)
+		moderation.GET("/history/:content_type/:contentID", handlers.GetHistory)
+		moderation.PATCH("/appeal/resolve/:appealID", handlers.ResolveAppeal)
+		moderation.POST("/appeal/:content_type/:contentID", handlers.Appeal)
+		moderation.PATCH("/:content_type/:contentID", handlers.Moderate)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterOfficialRoutes(router *gin.RouterGroup, handlers *handlers.OfficialHandlers) {
+	officials := router.Group("/officials")
+	{
+		officials.POST("/add", handlers.AddOfficial)
+		officials.PUT("/update/:officialID", handlers.UpdateOfficial)
+		officials.PUT("/end-term/:officialID", handlers.EndTerm)
+		officials.PUT("/photo/:officialID", handlers.UpdateOfficialPhoto)
+		officials.GET("/barangay/:barangay_ID", handlers.GetCurrentOfficials)
+		officials.GET("/barangay/:barangay_ID/history", handlers.GetOfficialHistory)
+		officials.GET("/single/:officialID", handlers.GetOfficial)
+	}
+}
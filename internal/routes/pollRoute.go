@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPollRoutes(router *gin.RouterGroup, handlers *handlers.PollHandlers) {
+	polls := router.Group("/polls")
+	{
+		polls.POST("/add", handlers.CreatePoll)
+		polls.DELETE("/delete/:pollID", handlers.DeletePoll)
+		polls.GET("/barangay/:barangay_ID", handlers.GetPolls)
+		polls.GET("/:pollID", handlers.GetPoll)
+		polls.POST("/vote/:pollID", handlers.Vote)
+		polls.GET("/results/:pollID", handlers.GetResults)
+	}
+}
@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterProjectProgressRoutes(router *gin.RouterGroup, handlers *handlers.ProjectProgressHandlers) {
+	progress := router.Group("/project-progress")
+	{
+		progress.POST("/milestone/:projectID", handlers.AddMilestone)
+		progress.PATCH("/milestone/complete/:milestoneID", handlers.CompleteMilestone)
+		progress.DELETE("/milestone/:milestoneID", handlers.DeleteMilestone)
+		progress.POST("/photo/:projectID", handlers.AddPhoto)
+		progress.DELETE("/photo/:photoID", handlers.DeletePhoto)
+		progress.GET("/:projectID", handlers.GetProgress)
+	}
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterProposalRoutes(router *gin.RouterGroup, handlers *handlers.ProposalHandlers) {
+	proposals := router.Group("/proposals")
+	{
+		proposals.POST("/add", handlers.SubmitProposal)
+		proposals.POST("/photo/:proposalID", handlers.AddPhoto)
+		proposals.DELETE("/withdraw/:proposalID", handlers.WithdrawProposal)
+		proposals.PUT("/endorse/:proposalID", handlers.Endorse)
+		proposals.DELETE("/endorse/:proposalID", handlers.RemoveEndorsement)
+		proposals.GET("/barangay/:barangay_ID", handlers.GetProposals)
+		proposals.GET("/:proposalID", handlers.GetProposal)
+		proposals.PUT("/review/:proposalID", handlers.ReviewProposal)
+		proposals.POST("/convert/:proposalID", handlers.ConvertProposal)
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPublicDashboardRoutes(router *gin.RouterGroup, handlers *handlers.PublicDashboardHandlers) {
+	dashboard := router.Group("/dashboard")
+	{
+		dashboard.GET("/rollup/:level", handlers.GetRollup)
+		dashboard.GET("/compliance/:fiscal_year", handlers.GetStatutoryCompliance)
+		dashboard.GET("/revenue", handlers.GetRevenueBySource)
+		dashboard.GET("/responsiveness", handlers.GetResponsiveness)
+		dashboard.GET("/supported", handlers.GetSupportedConcerns)
+		dashboard.GET("/sentiment/:level", handlers.GetSentiment)
+		dashboard.GET("/topics", handlers.GetTopics)
+	}
+}
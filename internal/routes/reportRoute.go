@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReportRoutes(router *gin.RouterGroup, handlers *handlers.ReportHandlers) {
+	reports := router.Group("/reports")
+	{
+		reports.GET("/budget/:barangay_ID/:fiscal_year", handlers.GetBudgetReport)
+		reports.GET("/project/:projectID", handlers.GetProjectReport)
+		reports.GET("/feedback/:barangay_ID", handlers.GetFeedbackReport)
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterResidencyRoutes(router *gin.RouterGroup, handlers *handlers.ResidencyHandlers) {
+	residency := router.Group("/residency")
+	{
+		residency.POST("/claim", handlers.FileClaim)
+		residency.GET("/my-claims", handlers.GetMyClaims)
+		residency.GET("/pending", handlers.GetPendingClaims)
+		residency.PUT("/review/:claimID", handlers.ReviewClaim)
+	}
+}
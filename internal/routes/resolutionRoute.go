@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterResolutionRoutes(router *gin.RouterGroup, handlers *handlers.ResolutionHandlers) {
+	resolutions := router.Group("/resolutions")
+	{
+		resolutions.POST("/add", handlers.AddResolution)
+		resolutions.PUT("/document/:resolutionID", handlers.UploadDocument)
+		resolutions.PUT("/link/:resolutionID", handlers.LinkResolution)
+		resolutions.GET("/barangay/:barangay_ID", handlers.GetResolutions)
+		resolutions.GET("/barangay/:barangay_ID/:kind/:number", handlers.GetResolutionByNumber)
+	}
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterResponseRoutes(router *gin.RouterGroup, handlers *handlers.ResponseHandlers) {
+	response := router.Group("/response")
+	{
+		response.GET("/target/:barangay_ID", handlers.GetTarget)
+		response.PUT("/target", handlers.SetTarget)
+		response.GET("/overdue/:barangay_ID", handlers.GetOverdue)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRevenueRoutes(router *gin.RouterGroup, handlers *handlers.RevenueHandlers) {
+	revenue := router.Group("/revenue")
+	{
+		revenue.POST("/add", handlers.AddRevenue)
+		revenue.PUT("/update/:revenueID", handlers.UpdateRevenue)
+		revenue.DELETE("/delete/:revenueID", handlers.DeleteRevenue)
+		revenue.GET("/barangay/:barangay_ID", handlers.GetRevenue)
+		revenue.GET("/balance/:barangay_ID/:fiscal_year", handlers.GetBalance)
+	}
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"wow-bato-backend/internal/handlers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSearchRoutes(router *gin.RouterGroup, handlers *handlers.SearchHandlers) {
+	router.GET("/search", handlers.Search)
+}
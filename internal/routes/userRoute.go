@@ -14,5 +14,18 @@ func RegisterUserRoute(router *gin.RouterGroup, handlers *handlers.UserHandlers)
 		user.POST("/logout", handlers.LogoutUser)
 		user.GET("/checkAuth", handlers.CheckAuth)
 		user.GET("/profile", handlers.GetUserProfile)
+		user.PUT("/profile/update", handlers.UpdateUserProfile)
+		user.POST("/profile/change-email", handlers.RequestEmailChange)
+		user.POST("/profile/confirm-email", handlers.ConfirmEmailChange)
+		user.PUT("/profile/change-password", handlers.ChangePassword)
+		user.POST("/profile/avatar", handlers.UploadProfilePicture)
+	}
+}
+
+func RegisterOIDCRoutes(router *gin.RouterGroup, handlers *handlers.OIDCHandlers) {
+	oidc := router.Group("/user/oidc")
+	{
+		oidc.GET("/login", handlers.Login)
+		oidc.GET("/callback", handlers.Callback)
 	}
 }
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Residents search in a mix of Filipino and English. Postgres ships no Filipino
+// stemmer, so every document is indexed twice: once with the english config for
+// stemmed English words and once with filipino_simple, which only lowercases and
+// strips accents, so Tagalog words and place names match as typed.
+var searchSchema = []string{
+	`CREATE EXTENSION IF NOT EXISTS unaccent`,
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM pg_ts_config WHERE cfgname = 'filipino_simple') THEN
+			CREATE TEXT SEARCH CONFIGURATION filipino_simple (COPY = simple);
+			ALTER TEXT SEARCH CONFIGURATION filipino_simple
+				ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+		END IF;
+	END
+	$$`,
+	`CREATE OR REPLACE FUNCTION search_document(body text, weight "char") RETURNS tsvector
+		LANGUAGE sql IMMUTABLE AS $$
+		SELECT setweight(to_tsvector('english'::regconfig, coalesce(body, '')), weight) ||
+			setweight(to_tsvector('filipino_simple'::regconfig, coalesce(body, '')), weight)
+	$$`,
+	`CREATE OR REPLACE FUNCTION search_query(text) RETURNS tsquery
+		LANGUAGE sql IMMUTABLE AS $$
+		SELECT websearch_to_tsquery('english'::regconfig, $1) || websearch_to_tsquery('filipino_simple'::regconfig, $1)
+	$$`,
+
+	`ALTER TABLE projects ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (search_document(name, 'A') || search_document(description, 'B')) STORED`,
+	`ALTER TABLE budget_items ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (search_document(name, 'A') || search_document(description, 'B')) STORED`,
+	`ALTER TABLE budget_categories ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (search_document(name, 'A') || search_document(description, 'B')) STORED`,
+	`ALTER TABLE feedbacks ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (search_document(content, 'B')) STORED`,
+
+	`CREATE INDEX IF NOT EXISTS idx_projects_search ON projects USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_budget_items_search ON budget_items USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_budget_categories_search ON budget_categories USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_feedbacks_search ON feedbacks USING GIN (search_vector)`,
+
+	// trigram indexes back the typo fallback on titles
+	`CREATE INDEX IF NOT EXISTS idx_projects_name_trgm ON projects USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_budget_items_name_trgm ON budget_items USING GIN (name gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS idx_budget_categories_name_trgm ON budget_categories USING GIN (name gin_trgm_ops)`,
+}
+
+// migrateSearch creates the full-text search columns and indexes AutoMigrate
+// cannot express. Every statement is idempotent so it runs on each start.
+func migrateSearch(db *gorm.DB) error {
+	for _, statement := range searchSchema {
+		if err := db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to prepare search schema: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,289 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAmendmentNotFound         = NotFoundError("amendment not found")
+	ErrInvalidAmendmentID        = ValidationError("invalid amendment ID format")
+	ErrAmendmentResolution       = NotFoundError("authorizing resolution was not found in this barangay")
+	ErrAmendmentBeforeResolution = FieldValidationError("effectiveDate", "effectiveDate cannot be before the authorizing resolution was enacted")
+	ErrAmendmentItemNotFound     = NotFoundError("budget item to amend was not found in this barangay")
+	ErrAmendmentDuplicateItem    = FieldValidationError("lines", "lines list a budget item more than once")
+	ErrAmendmentUnbalanced       = FieldValidationError("lines", "a realignment must take from and give to budget items in equal amounts")
+	ErrSupplementalReduction     = FieldValidationError("lines", "a supplemental budget can only add to budget items")
+	ErrAmendmentOverdraw         = FieldValidationError("lines", "a budget item cannot give up more than its allocated amount")
+	ErrAmendmentRejectedItem     = FieldValidationError("lines", "rejected budget items cannot be amended")
+	ErrBudgetItemAmended         = ConflictError("budget item has amendments, realign its amount instead of deleting it")
+)
+
+var AMENDMENT_LIST_SPEC = ListSpec{
+	Sorts: map[string]SortField{
+		"effective_date": {Column: "effective_date", Field: "EffectiveDate"},
+	},
+	DefaultSort: "-effective_date",
+	Filters: map[string]FilterField{
+		"kind":           {Column: "kind", Kind: FilterEnum, Values: []string{models.AmendmentRealignment, models.AmendmentSupplemental}},
+		"effective_date": {Column: "effective_date", Kind: FilterDateRange},
+	},
+	TextColumns: []string{"justification"},
+}
+
+type AmendmentService struct {
+	db *gorm.DB
+}
+
+func NewAmendmentService(db *gorm.DB) *AmendmentService {
+	return &AmendmentService{db: db}
+}
+
+// checkAmendmentLines applies the rules of each kind before anything is
+// read from the database.
+func checkAmendmentLines(amendment models.NewAmendment) error {
+	seen := map[uint]bool{}
+	var net float64
+	for _, line := range amendment.Lines {
+		if seen[line.BudgetItemID] {
+			return ErrAmendmentDuplicateItem
+		}
+		seen[line.BudgetItemID] = true
+		net += line.Amount
+
+		if amendment.Kind == models.AmendmentSupplemental && line.Amount < 0 {
+			return ErrSupplementalReduction
+		}
+	}
+
+	if amendment.Kind == models.AmendmentRealignment && (len(amendment.Lines) < 2 || math.Abs(net) >= 0.005) {
+		return ErrAmendmentUnbalanced
+	}
+	return nil
+}
+
+// AddAmendment records the amendment and moves the amounts of its budget
+// items in one transaction, the items are locked so concurrent amendments
+// see each other's figures.
+func (s *AmendmentService) AddAmendment(barangay_ID uint, amendment models.NewAmendment) (uint, error) {
+	effective, err := time.Parse(GO_DATE_FORMAT, amendment.EffectiveDate)
+	if err != nil {
+		return 0, FieldValidationError("effectiveDate", "effectiveDate must be a date in YYYY-MM-DD format")
+	}
+	if err := checkAmendmentLines(amendment); err != nil {
+		return 0, err
+	}
+
+	var resolution models.Resolution
+	if err := s.db.Where("id = ? AND barangay_id = ?", amendment.ResolutionID, barangay_ID).First(&resolution).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrAmendmentResolution
+		}
+		return 0, fmt.Errorf("failed to find resolution: %w", err)
+	}
+	if effective.Before(resolution.DateEnacted) {
+		return 0, ErrAmendmentBeforeResolution
+	}
+
+	ids := make([]uint, 0, len(amendment.Lines))
+	for _, line := range amendment.Lines {
+		ids = append(ids, line.BudgetItemID)
+	}
+
+	newAmendment := models.Amendment{
+		Barangay_ID:   barangay_ID,
+		Kind:          amendment.Kind,
+		Justification: amendment.Justification,
+		ResolutionID:  resolution.ID,
+		EffectiveDate: effective,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var items []struct {
+			ID               uint
+			Amount_Allocated float64
+			Status           string
+		}
+		if err := tx.Table("budget_items").
+			Select("budget_items.id, budget_items.amount_allocated, budget_items.status").
+			Joins("JOIN projects ON projects.id = budget_items.project_id AND projects.deleted_at IS NULL").
+			Where("budget_items.id IN ? AND projects.barangay_id = ? AND budget_items.deleted_at IS NULL", ids, barangay_ID).
+			Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "budget_items"}}).
+			Scan(&items).Error; err != nil {
+			return fmt.Errorf("failed to find budget items: %w", err)
+		}
+		if len(items) != len(ids) {
+			return ErrAmendmentItemNotFound
+		}
+
+		current := make(map[uint]float64, len(items))
+		for _, item := range items {
+			if strings.EqualFold(item.Status, "rejected") {
+				return ErrAmendmentRejectedItem
+			}
+			current[item.ID] = item.Amount_Allocated
+		}
+
+		for _, line := range amendment.Lines {
+			before := current[line.BudgetItemID]
+			after := math.Round((before+line.Amount)*100) / 100
+			if after < 0 {
+				return ErrAmendmentOverdraw
+			}
+			newAmendment.Lines = append(newAmendment.Lines, models.AmendmentLine{
+				BudgetItemID: line.BudgetItemID,
+				AmountBefore: before,
+				Amount:       line.Amount,
+				AmountAfter:  after,
+			})
+
+			if err := tx.Model(&models.Budget_Item{}).Where("id = ?", line.BudgetItemID).
+				Update("amount_allocated", after).Error; err != nil {
+				return fmt.Errorf("failed to amend budget item: %w", err)
+			}
+		}
+
+		if err := tx.Create(&newAmendment).Error; err != nil {
+			return fmt.Errorf("failed to create amendment: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newAmendment.ID, nil
+}
+
+func (s *AmendmentService) ListAmendments(barangay_ID string, params url.Values) ([]models.AmendmentResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, AMENDMENT_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var amendments []models.AmendmentResponse
+	meta, err := ListPage(s.db.Model(&models.Amendment{}).Where("barangay_id = ?", barangay_ID_int), query, &amendments, nil)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return amendments, meta, nil
+}
+
+func (s *AmendmentService) GetAmendment(amendmentID string) (models.AmendmentDetail, error) {
+	id, err := strconv.ParseUint(amendmentID, 10, 32)
+	if err != nil {
+		return models.AmendmentDetail{}, fmt.Errorf("%w: %s", ErrInvalidAmendmentID, amendmentID)
+	}
+
+	var amendment models.Amendment
+	if err := s.db.Preload("Resolution").Where("id = ?", id).First(&amendment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AmendmentDetail{}, fmt.Errorf("%w: ID %d", ErrAmendmentNotFound, id)
+		}
+		return models.AmendmentDetail{}, fmt.Errorf("failed to find amendment: %w", err)
+	}
+
+	detail := models.AmendmentDetail{
+		AmendmentResponse: models.AmendmentResponse{
+			ID:            amendment.ID,
+			Kind:          amendment.Kind,
+			Justification: amendment.Justification,
+			ResolutionID:  amendment.ResolutionID,
+			EffectiveDate: amendment.EffectiveDate,
+			CreatedAt:     amendment.CreatedAt,
+		},
+		ResolutionKind:   amendment.Resolution.Kind,
+		ResolutionNumber: amendment.Resolution.Number,
+	}
+
+	if err := s.db.Table("amendment_lines").
+		Select(`amendment_lines.budget_item_id, budget_items.name AS budget_item_name,
+			budget_categories.id AS category_id, budget_categories.name AS category_name,
+			amendment_lines.amount_before, amendment_lines.amount, amendment_lines.amount_after`).
+		Joins("JOIN budget_items ON budget_items.id = amendment_lines.budget_item_id").
+		Joins("JOIN projects ON projects.id = budget_items.project_id").
+		Joins("JOIN budget_categories ON budget_categories.id = projects.category_id").
+		Where("amendment_lines.amendment_id = ?", amendment.ID).
+		Order("amendment_lines.amount").
+		Scan(&detail.Lines).Error; err != nil {
+		return models.AmendmentDetail{}, fmt.Errorf("failed to retrieve amendment lines: %w", err)
+	}
+
+	if detail.Lines == nil {
+		detail.Lines = []models.AmendmentLineResponse{}
+	}
+	return detail, nil
+}
+
+// AmendedBudget reports every budget item of a fiscal year with the amount
+// it was enacted with and the amount after all amendments, by category.
+func (s *AmendmentService) AmendedBudget(barangay_ID string, fiscalYear string) (models.AmendedBudget, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return models.AmendedBudget{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+	year, err := parseFiscalYear(fiscalYear)
+	if err != nil {
+		return models.AmendedBudget{}, err
+	}
+
+	changes := s.db.Table("amendment_lines").
+		Select("amendment_lines.budget_item_id, SUM(amendment_lines.amount) AS total").
+		Joins("JOIN amendments ON amendments.id = amendment_lines.amendment_id AND amendments.deleted_at IS NULL").
+		Group("amendment_lines.budget_item_id")
+
+	var rows []struct {
+		models.AmendedBudgetItem
+		CategoryID   uint
+		CategoryName string
+	}
+	if err := s.db.Table("budget_items").
+		Select(`budget_items.id, budget_items.name, projects.name AS project_name,
+			budget_categories.id AS category_id, budget_categories.name AS category_name,
+			budget_items.amount_allocated - COALESCE(changes.total, 0) AS original,
+			budget_items.amount_allocated AS amended`).
+		Joins("JOIN projects ON projects.id = budget_items.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN budget_categories ON budget_categories.id = projects.category_id AND budget_categories.deleted_at IS NULL").
+		Joins("LEFT JOIN (?) AS changes ON changes.budget_item_id = budget_items.id", changes).
+		Where("budget_items.deleted_at IS NULL AND projects.barangay_id = ?", barangay_ID_int).
+		Where(allocatedInFiscalYear, year).
+		Order("budget_categories.name").
+		Order("budget_categories.id").
+		Order("budget_items.name").
+		Scan(&rows).Error; err != nil {
+		return models.AmendedBudget{}, fmt.Errorf("failed to retrieve amended budget: %w", err)
+	}
+
+	budget := models.AmendedBudget{Barangay_ID: uint(barangay_ID_int), FiscalYear: year, Categories: []models.AmendedBudgetCategory{}}
+	for _, row := range rows {
+		last := len(budget.Categories) - 1
+		if last < 0 || budget.Categories[last].ID != row.CategoryID {
+			budget.Categories = append(budget.Categories, models.AmendedBudgetCategory{ID: row.CategoryID, Name: row.CategoryName})
+			last++
+		}
+
+		category := &budget.Categories[last]
+		category.Items = append(category.Items, row.AmendedBudgetItem)
+		category.Original += row.Original
+		category.Amended += row.Amended
+		budget.Original += row.Original
+		budget.Amended += row.Amended
+	}
+
+	return budget, nil
+}
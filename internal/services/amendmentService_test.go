@@ -0,0 +1,128 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCheckAmendmentLines(t *testing.T) {
+	line := func(budgetItemID uint, amount float64) models.AmendmentLineInput {
+		return models.AmendmentLineInput{BudgetItemID: budgetItemID, Amount: amount}
+	}
+
+	tests := []struct {
+		name      string
+		amendment models.NewAmendment
+		want      error
+	}{
+		{"balanced realignment", models.NewAmendment{Kind: models.AmendmentRealignment, Lines: []models.AmendmentLineInput{line(1, -5000), line(2, 3000), line(3, 2000)}}, nil},
+		{"unbalanced realignment", models.NewAmendment{Kind: models.AmendmentRealignment, Lines: []models.AmendmentLineInput{line(1, -5000), line(2, 4000)}}, ErrAmendmentUnbalanced},
+		{"single line realignment", models.NewAmendment{Kind: models.AmendmentRealignment, Lines: []models.AmendmentLineInput{line(1, 0.001)}}, ErrAmendmentUnbalanced},
+		{"duplicate item", models.NewAmendment{Kind: models.AmendmentRealignment, Lines: []models.AmendmentLineInput{line(1, -5000), line(1, 5000)}}, ErrAmendmentDuplicateItem},
+		{"supplemental", models.NewAmendment{Kind: models.AmendmentSupplemental, Lines: []models.AmendmentLineInput{line(1, 10000)}}, nil},
+		{"supplemental reduction", models.NewAmendment{Kind: models.AmendmentSupplemental, Lines: []models.AmendmentLineInput{line(1, 10000), line(2, -500)}}, ErrSupplementalReduction},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkAmendmentLines(tt.amendment); !errors.Is(err, tt.want) {
+				t.Errorf("checkAmendmentLines() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmendmentService_AddAmendment(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewAmendmentService(gormDB)
+
+	amendment := models.NewAmendment{
+		Kind:          models.AmendmentRealignment,
+		Justification: "Savings from the covered court bidding moved to drainage repair",
+		ResolutionID:  9,
+		EffectiveDate: "2025-07-01",
+		Lines:         []models.AmendmentLineInput{{BudgetItemID: 4, Amount: -50000}, {BudgetItemID: 5, Amount: 50000}},
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "resolutions" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+		WithArgs(9, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "barangay_id", "date_enacted"}).AddRow(9, 1, time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT budget_items.id, budget_items.amount_allocated, budget_items.status FROM "budget_items" (.+) FOR UPDATE OF "budget_items"`).
+		WithArgs(4, 5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount_allocated", "status"}).
+			AddRow(4, 30000.0, "Approved").
+			AddRow(5, 120000.0, "Approved"))
+	mock.ExpectRollback()
+
+	if _, err := svc.AddAmendment(1, amendment); !errors.Is(err, ErrAmendmentOverdraw) {
+		t.Errorf("Expected ErrAmendmentOverdraw, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "resolutions"`).
+		WithArgs(9, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "barangay_id", "date_enacted"}).AddRow(9, 1, time.Date(2025, 6, 20, 0, 0, 0, 0, time.UTC)))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT budget_items.id, (.+) FOR UPDATE OF "budget_items"`).
+		WithArgs(4, 5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "amount_allocated", "status"}).
+			AddRow(4, 80000.0, "Approved").
+			AddRow(5, 120000.0, "Pending"))
+	mock.ExpectExec(`UPDATE "budget_items" SET "amount_allocated"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(30000.0, sqlmock.AnyArg(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "budget_items" SET "amount_allocated"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(170000.0, sqlmock.AnyArg(), 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "amendments"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectQuery(`INSERT INTO "amendment_lines" (.+) ON CONFLICT`).
+		WithArgs(3, 4, 80000.0, -50000.0, 30000.0, 3, 5, 120000.0, 50000.0, 170000.0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	amendmentID, err := svc.AddAmendment(1, amendment)
+	if err != nil {
+		t.Fatalf("AddAmendment() error = %v", err)
+	}
+	if amendmentID != 3 {
+		t.Errorf("Expected amendment 3, got %d", amendmentID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestAmendmentService_AmendedBudget(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewAmendmentService(gormDB)
+
+	mock.ExpectQuery(`SELECT budget_items.id, budget_items.name, (.+) LEFT JOIN \(SELECT amendment_lines.budget_item_id, SUM\(amendment_lines.amount\) AS total FROM "amendment_lines" (.+)\) AS changes (.+) ORDER BY budget_categories.name,budget_categories.id,budget_items.name`).
+		WithArgs(1, 2025).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "project_name", "category_id", "category_name", "original", "amended"}).
+			AddRow(5, "Drainage repair", "Purok 3 drainage", 2, "Infrastructure", 120000.0, 170000.0).
+			AddRow(4, "Court flooring", "Covered court", 2, "Infrastructure", 80000.0, 30000.0).
+			AddRow(7, "Medicines", "Health center", 6, "Health", 40000.0, 40000.0))
+
+	budget, err := svc.AmendedBudget("1", "2025")
+	if err != nil {
+		t.Fatalf("AmendedBudget() error = %v", err)
+	}
+	if len(budget.Categories) != 2 || len(budget.Categories[0].Items) != 2 {
+		t.Fatalf("Unexpected categories: %+v", budget.Categories)
+	}
+	if budget.Categories[0].Original != 200000 || budget.Categories[0].Amended != 200000 || budget.Original != 240000 {
+		t.Errorf("Unexpected totals: %+v", budget)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,22 +1,31 @@
 package services
 
 import (
-	"errors"
 	"fmt"
+	"net/url"
 	"wow-bato-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrBarangayNotFound    = errors.New("barangay not found")
-	ErrInvalidBarangayID   = errors.New("invalid barangay ID format")
-	ErrInvalidPagination   = errors.New("invalid pagination parameters")
-	ErrEmptyBarangayName   = errors.New("barangay name cannot be empty")
-	ErrEmptyBarangayCity   = errors.New("barangay city cannot be empty")
-	ErrEmptyBarangayRegion = errors.New("barangay region cannot be empty")
+	ErrBarangayNotFound    = NotFoundError("barangay not found")
+	ErrInvalidBarangayID   = ValidationError("invalid barangay ID format")
+	ErrEmptyBarangayName   = FieldValidationError("name", "barangay name cannot be empty")
+	ErrEmptyBarangayCity   = FieldValidationError("city", "barangay city cannot be empty")
+	ErrEmptyBarangayRegion = FieldValidationError("region", "barangay region cannot be empty")
 )
 
+var BARANGAY_LIST_SPEC = ListSpec{
+	Sorts: map[string]SortField{
+		"name":   {Column: "name", Field: "Name"},
+		"city":   {Column: "city", Field: "City"},
+		"region": {Column: "region", Field: "Region"},
+	},
+	DefaultSort: "name",
+	TextColumns: []string{"name", "city", "region"},
+}
+
 type BarangayService struct {
 	db *gorm.DB
 }
@@ -29,6 +38,9 @@ func validateBarangayData(barangay models.AddBarangay) error {
 	if barangay.Name == "" {
 		return ErrEmptyBarangayName
 	}
+	if barangay.PSGCCode != "" {
+		return nil
+	}
 	if barangay.City == "" {
 		return ErrEmptyBarangayCity
 	}
@@ -49,6 +61,12 @@ func (s *BarangayService) AddNewBarangay(newBarangay models.AddBarangay) error {
 		Region: newBarangay.Region,
 	}
 
+	if newBarangay.PSGCCode != "" {
+		if err := applyPSGCCode(s.db, &barangay, newBarangay.PSGCCode); err != nil {
+			return err
+		}
+	}
+
 	if err := s.db.Create(&barangay).Error; err != nil {
 		return fmt.Errorf("failed to create barangay: %w", err)
 	}
@@ -96,6 +114,12 @@ func (s *BarangayService) UpdateBarangay(barangay_ID string, barangayUpdate mode
 		barangay.Region = barangayUpdate.Region
 	}
 
+	if barangayUpdate.PSGCCode != "" {
+		if err := applyPSGCCode(s.db, &barangay, barangayUpdate.PSGCCode); err != nil {
+			return err
+		}
+	}
+
 	if err := s.db.Save(&barangay).Error; err != nil {
 		return fmt.Errorf("failed to save new barangay info changes: %w", err)
 	}
@@ -103,28 +127,22 @@ func (s *BarangayService) UpdateBarangay(barangay_ID string, barangayUpdate mode
 	return nil
 }
 
-func (s *BarangayService) GetAllBarangay(limit string, page string) ([]models.AllBarangayResponse, error) {
+func (s *BarangayService) GetAllBarangay(params url.Values) ([]models.AllBarangayResponse, models.PageMeta, error) {
 
-	limitInt, err := ConvertToInt(limit)
+	query, err := ParseListQuery(params, BARANGAY_LIST_SPEC)
 	if err != nil {
-		return nil, err
+		return nil, models.PageMeta{}, err
 	}
 
-	pageInt, err := ConvertToInt(page)
+	var barangay []models.AllBarangayResponse
+	meta, err := ListPage(s.db.Model(&models.Barangay{}), query, &barangay, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id, name, city, region, psgc_code")
+	})
 	if err != nil {
-		return nil, err
+		return nil, models.PageMeta{}, err
 	}
 
-	offset := (pageInt - 1) * limitInt
-
-	var barangay []models.AllBarangayResponse
-	if err := s.db.Model(&models.Barangay{}).
-		Select("id, name, city, region").
-		Limit(limitInt).Offset(offset).
-		Find(&barangay).Error; 
-		err != nil { return nil, err }
-
-	return barangay, nil
+	return barangay, meta, nil
 }
 
 func (s *BarangayService) OptionBarangay() ([]models.OptionBarangay, error) {
@@ -148,7 +166,7 @@ func (s *BarangayService) GetSingleBarangay(barangay_ID string) (models.AllBaran
 
 	var barangay models.AllBarangayResponse
 	if err := s.db.Model(&models.Barangay{}).
-		Select("id, name, city, region").
+		Select("id, name, city, region, psgc_code").
 		Where("ID = ?", barangay_ID_int).
 		First(&barangay).Error; err != nil {
 		return models.AllBarangayResponse{}, fmt.Errorf("%w: ID %d", ErrBarangayNotFound, barangay_ID_int)
@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"net/url"
 	"testing"
 	"wow-bato-backend/internal/models"
 
@@ -42,7 +43,7 @@ func TestBarangayService_AddNewBarangay(t *testing.T) {
 	// Use AnyArg() for timestamps and other auto-generated fields
 	mock.ExpectQuery(`INSERT INTO "barangays"`).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
-			"Test", "TestCity", "TestRegion", ""). // Match the actual params
+			"Test", "TestCity", "TestRegion", nil, nil, ""). // Match the actual params
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
@@ -190,26 +191,32 @@ func TestBarangayService_GetAllBarangay(t *testing.T) {
 	svc := NewBarangayService(gormDB)
 
 	// Test parameters
-	limit := "10"
-	page := "1"
+	params := url.Values{"limit": {"2"}, "page": {"2"}}
 
 	// Setup mock rows
 	rows := sqlmock.NewRows([]string{"id", "name", "city", "region"}).
 		AddRow(1, "Barangay 1", "City 1", "Region 1").
 		AddRow(2, "Barangay 2", "City 2", "Region 2")
 
-	// Expect query with LIMIT and OFFSET
-	// For page 1, offset should be 0 (calculated as (1-1)*10)
-	mock.ExpectQuery(`SELECT (.+) FROM "barangays" LIMIT \$1 OFFSET \$2`).
-		WithArgs(10, 0).
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "barangays"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	// One row past the limit is fetched to know whether more remain
+	// For page 2, offset should be 2 (calculated as (2-1)*2)
+	mock.ExpectQuery(`SELECT id, name, city, region, psgc_code FROM "barangays" (.+) ORDER BY name ASC,id ASC LIMIT \$1 OFFSET \$2`).
+		WithArgs(3, 2).
 		WillReturnRows(rows)
 
 	// Call the method
-	results, err := svc.GetAllBarangay(limit, page)
+	results, meta, err := svc.GetAllBarangay(params)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	if meta.Total != 5 || meta.TotalPages != 3 || meta.Page != 2 || meta.HasMore {
+		t.Errorf("Unexpected page meta %+v", meta)
+	}
+
 	// Check results
 	if len(results) != 2 {
 		t.Errorf("Expected 2 results, got %d", len(results))
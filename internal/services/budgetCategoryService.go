@@ -1,8 +1,8 @@
 package services
 
 import (
-	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"wow-bato-backend/internal/models"
 
@@ -10,14 +10,23 @@ import (
 )
 
 var (
-	ErrBudgetCategoryNotFound  = errors.New("budget category not found")
-	ErrInvalidBudgetCategoryID = errors.New("invalid budget category ID format")
-	ErrEmptyBudgetCategoryName = errors.New("budget category name cannot be empty")
-	ErrEmptyBudgetDescription  = errors.New("budget category description cannot be empty")
-	ErrInvalidBudgetBarangayID = errors.New("invalid barangay ID for budget category")
-	ErrorInvalidBarangayID     = errors.New("invalid barangay ID format")
+	ErrBudgetCategoryNotFound  = NotFoundError("budget category not found")
+	ErrInvalidBudgetCategoryID = ValidationError("invalid budget category ID format")
+	ErrEmptyBudgetCategoryName = FieldValidationError("name", "budget category name cannot be empty")
+	ErrEmptyBudgetDescription  = FieldValidationError("description", "budget category description cannot be empty")
+	ErrInvalidBudgetBarangayID = FieldValidationError("barangay_ID", "invalid barangay ID for budget category")
+	ErrorInvalidBarangayID     = ValidationError("invalid barangay ID format")
 )
 
+var BUDGET_CATEGORY_LIST_SPEC = ListSpec{
+	IDColumn: "budget_categories.id",
+	Sorts: map[string]SortField{
+		"name": {Column: "budget_categories.name", Field: "Name"},
+	},
+	DefaultSort: "name",
+	TextColumns: []string{"budget_categories.name", "budget_categories.description"},
+}
+
 type BudgetCategoryService struct {
 	db *gorm.DB
 }
@@ -49,6 +58,9 @@ func (s *BudgetCategoryService) AddBudgetCategory(budgetCategory models.NewBudge
 		Description: budgetCategory.Description,
 		Barangay_ID: budgetCategory.Barangay_ID,
 	}
+	if budgetCategory.StatutoryFund != "" {
+		newBudgetCategory.StatutoryFund = &budgetCategory.StatutoryFund
+	}
 
 	if result := s.db.Create(&newBudgetCategory); result.Error != nil {
 		return fmt.Errorf("failed to create budget category: %w", result.Error)
@@ -104,44 +116,31 @@ func (s *BudgetCategoryService) UpdateBudgetCategory(budget_ID string, updateBud
 	return nil
 }
 
-func (s *BudgetCategoryService) GetAllBudgetCategory(barangay_ID string, limit string, page string) ([]models.BudgetCategoryResponse, error) {
+func (s *BudgetCategoryService) GetAllBudgetCategory(barangay_ID string, params url.Values) ([]models.BudgetCategoryResponse, models.PageMeta, error) {
 
 	barangay_ID_int, err := strconv.Atoi(barangay_ID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrorInvalidBarangayID, barangay_ID)
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrorInvalidBarangayID, barangay_ID)
 	}
 
-	limitInt, err := strconv.Atoi(limit)
+	query, err := ParseListQuery(params, BUDGET_CATEGORY_LIST_SPEC)
 	if err != nil {
-		return nil, fmt.Errorf("invalid limit parameter: %w", err)
-	}
-
-	pageInt, err := strconv.Atoi(page)
-	if err != nil {
-		return nil, fmt.Errorf("invalid page parameter: %w", err)
-	}
-
-	if pageInt < 1 {
-		return nil, fmt.Errorf("page number must be greater than zero")
+		return nil, models.PageMeta{}, err
 	}
 
-	offset := (pageInt - 1) * limitInt
+	base := s.db.Model(&models.Budget_Category{}).Where("budget_categories.barangay_ID = ?", barangay_ID_int)
 
 	var budgetCategory []models.BudgetCategoryResponse
-	result := s.db.Model(&models.Budget_Category{}).
-		Select("budget_categories.id, budget_categories.name, budget_categories.description, budget_categories.barangay_ID, COUNT(projects.id) as project_count").
-		Joins("LEFT JOIN projects ON projects.category_id = budget_categories.id").
-		Where("budget_categories.barangay_ID = ?", barangay_ID_int).
-		Group("budget_categories.id").
-		Limit(limitInt).
-		Offset(offset).
-		Scan(&budgetCategory)
-
-	if result.Error != nil {
-		return nil, fmt.Errorf("failed to retrieve budget categories: %w", result.Error)
+	meta, err := ListPage(base, query, &budgetCategory, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("budget_categories.id, budget_categories.name, budget_categories.description, budget_categories.barangay_ID, COUNT(projects.id) as project_count").
+			Joins("LEFT JOIN projects ON projects.category_id = budget_categories.id").
+			Group("budget_categories.id")
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("failed to retrieve budget categories: %w", err)
 	}
 
-	return budgetCategory, nil
+	return budgetCategory, meta, nil
 }
 
 func (s *BudgetCategoryService) GetBudgetCategoryCount(barangay_ID string) (int64, error) {
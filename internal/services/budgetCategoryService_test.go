@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"net/url"
 	"testing"
 	"wow-bato-backend/internal/models"
 
@@ -199,8 +200,7 @@ func TestBudgetCategoryService_GetAllBudgetCategory(t *testing.T) {
 
 	// Test parameters
 	barangayID := "1"
-	limit := "10"
-	page := "1"
+	params := url.Values{"limit": {"10"}, "page": {"1"}}
 
 	// Setup mock rows with the expected columns from the JOIN query
 	rows := sqlmock.NewRows([]string{
@@ -209,17 +209,26 @@ func TestBudgetCategoryService_GetAllBudgetCategory(t *testing.T) {
 		AddRow(1, "Category 1", "Description 1", 1, 2).
 		AddRow(2, "Category 2", "Description 2", 1, 0)
 
+	// The count skips the join and grouping
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "budget_categories" WHERE budget_categories.barangay_ID = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
 	// The query is a complex JOIN with GROUP BY, so use a generic pattern
-	mock.ExpectQuery(`SELECT (.+) FROM "budget_categories" LEFT JOIN projects (.+) WHERE (.+) GROUP BY (.+) LIMIT (.+) OFFSET (.+)`).
-		WithArgs(1, 10, 0). // barangay_id, limit, offset
+	mock.ExpectQuery(`SELECT (.+) FROM "budget_categories" LEFT JOIN projects (.+) WHERE (.+) GROUP BY (.+) ORDER BY budget_categories.name ASC,budget_categories.id ASC LIMIT (.+)`).
+		WithArgs(1, 11). // barangay_id, limit plus one
 		WillReturnRows(rows)
 
 	// Call the method
-	results, err := svc.GetAllBudgetCategory(barangayID, limit, page)
+	results, meta, err := svc.GetAllBudgetCategory(barangayID, params)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	if meta.Total != 2 || meta.HasMore {
+		t.Errorf("Unexpected page meta %+v", meta)
+	}
+
 	// Check results length
 	if len(results) != 2 {
 		t.Errorf("Expected 2 results, got %d", len(results))
@@ -1,19 +1,37 @@
 package services
 
 import (
+	"errors"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 	"wow-bato-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
+var ErrBudgetItemNotFound = NotFoundError("budget item not found")
+
 type BudgetItemService struct {
 	db *gorm.DB
 }
 
-var (
-	PAGE_LIMIT = 5
-)
+// statuses are stored capitalized once reviewed, so they are compared lowercased
+var BUDGET_ITEM_LIST_SPEC = ListSpec{
+	Sorts: map[string]SortField{
+		"name":       {Column: "name", Field: "Name"},
+		"amount":     {Column: "amount_allocated", Field: "Amount_Allocated"},
+		"created_at": {Column: "created_at", Field: "CreatedAt"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]FilterField{
+		"status":     {Column: "LOWER(status)", Kind: FilterEnum, Values: []string{"pending", "approved", "rejected"}},
+		"amount":     {Column: "amount_allocated", Kind: FilterNumberRange},
+		"created_at": {Column: "created_at", Kind: FilterDateRange},
+	},
+	TextColumns: []string{"name", "description"},
+}
 
 func NewBudgetItemService (db *gorm.DB) *BudgetItemService {
 	return &BudgetItemService{db: db}
@@ -30,46 +48,61 @@ func (s *BudgetItemService) AddBudgetItem(projectID string, budgetItem models.Ne
 		Name:             budgetItem.Name,
 		Amount_Allocated: budgetItem.Amount_Allocated,
 		Description:      budgetItem.Description,
-		Status:           budgetItem.Status,
+		Status:           "pending",
 		ProjectID:       uint(projectID_int),
 	}
+	if budgetItem.ObjectCode != "" {
+		if err := checkObjectCode(s.db, budgetItem.ObjectCode); err != nil {
+			return err
+		}
+		newBudgetItem.ObjectCode = &budgetItem.ObjectCode
+	}
 
 	result := s.db.Create(&newBudgetItem)
 
 	return result.Error
 }
 
-func (s *BudgetItemService) GetAllBudgetItem(projectID string, filter string, page string) ([]models.Budget_Item, error) {
+func (s *BudgetItemService) GetAllBudgetItem(projectID string, params url.Values) ([]models.Budget_Item, models.PageMeta, error) {
 
 	projectID_int, err := strconv.Atoi(projectID)
 	if err != nil {
-		return []models.Budget_Item{}, err
+		return []models.Budget_Item{}, models.PageMeta{}, err
 	}
 
-	page_int, err := strconv.Atoi(page)
+	query, err := ParseListQuery(legacyStatusFilter(params), BUDGET_ITEM_LIST_SPEC)
 	if err != nil {
-		return []models.Budget_Item{}, err
+		return []models.Budget_Item{}, models.PageMeta{}, err
 	}
 
-	limit := PAGE_LIMIT
+	var budgetItem []models.Budget_Item
+	meta, err := ListPage(s.db.Model(&models.Budget_Item{}).Where("project_id = ?", projectID_int), query, &budgetItem, nil)
+	if err != nil {
+		return []models.Budget_Item{}, models.PageMeta{}, err
+	}
 
-	offset := (page_int - 1) * limit
+	return budgetItem, meta, nil
+}
 
+// legacyStatusFilter maps the filter parameter older clients send, a status
+// name or All, onto the status filter. An explicit status takes precedence.
+func legacyStatusFilter(params url.Values) url.Values {
+	filter := strings.TrimSpace(params.Get("filter"))
+	if filter == "" {
+		return params
+	}
 
-	var budgetItem []models.Budget_Item
-	if filter == "All" {
-		if err := s.db.Where("project_id = ?", projectID_int).Find(&budgetItem).Limit(limit).Offset(offset).Error; err != nil {
-			return []models.Budget_Item{}, err
+	mapped := url.Values{}
+	for key, values := range params {
+		if key != "filter" {
+			mapped[key] = values
 		}
-
-		return budgetItem, nil
 	}
-
-	if err := s.db.Where("project_id = ? AND status = ?", projectID_int, filter).Find(&budgetItem).Limit(limit).Offset(offset).Error; err != nil {
-		return []models.Budget_Item{}, err
+	if !strings.EqualFold(filter, "All") && mapped.Get("status") == "" {
+		mapped.Set("status", strings.ToLower(filter))
 	}
 
-	return budgetItem, nil
+	return mapped
 }
 
 func (s *BudgetItemService) CountBudgetItem(projectID string) (int64, error) {
@@ -107,7 +140,11 @@ func (s *BudgetItemService) GetSingleBudgetItem(categoryID string, budgetItemID
 	return budgetItem, nil
 }
 
-func (s *BudgetItemService) UpdateBudgetItemStatus(budgetItemID string, newStatus models.UpdateStatus) error {
+// UpdateBudgetItemStatus approves or rejects a budget item of barangay_ID and
+// records the term of the official who decided, so the decision stays
+// attributable after the next election. Users without a term in office there
+// cannot decide.
+func (s *BudgetItemService) UpdateBudgetItemStatus(userID uint, barangay_ID uint, budgetItemID string, newStatus models.UpdateStatus) error {
 
 	var updateStatus string
 	if newStatus.Status == "approve" {
@@ -121,14 +158,30 @@ func (s *BudgetItemService) UpdateBudgetItemStatus(budgetItemID string, newStatu
 		return err
 	}
 
+	projects := s.db.Model(&models.Project{}).Select("id").Where("barangay_id = ?", barangay_ID)
+
 	var budgetItem models.Budget_Item
-	if err := s.db.Where("id = ?", budgetItemID_int).First(&budgetItem).Error; err != nil {
+	if err := s.db.Where("id = ? AND project_id IN (?)", budgetItemID_int, projects).First(&budgetItem).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrBudgetItemNotFound
+		}
+		return err
+	}
+
+	now := time.Now()
+	officialID, err := officialTermOf(s.db, userID, barangay_ID, now)
+	if err != nil {
 		return err
 	}
 
 	budgetItem.Status = updateStatus
+	budgetItem.Reviewed_By_OfficialID = &officialID
+	budgetItem.Approval_Date = nil
+	if updateStatus == "Approved" {
+		budgetItem.Approval_Date = &now
+	}
 
-	result := s.db.Save(&budgetItem)
+	result := s.db.Omit("Project").Save(&budgetItem)
 
 	return result.Error
 }
@@ -140,6 +193,14 @@ func (s *BudgetItemService) DeleteBudgetItem(budgetItemID string) error {
 		return err
 	}
 
+	var amendments int64
+	if err := s.db.Model(&models.AmendmentLine{}).Where("budget_item_id = ?", budgetItemID_int).Count(&amendments).Error; err != nil {
+		return err
+	}
+	if amendments > 0 {
+		return ErrBudgetItemAmended
+	}
+
 	if err := s.db.Where("id = ?", budgetItemID_int).Delete(&models.Budget_Item{}).Error; err != nil {
 		return err
 	}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"database/sql"
+	"errors"
+	"net/url"
 	"testing"
 	"wow-bato-backend/internal/models"
 
@@ -48,7 +50,7 @@ func TestBudgetItemService_AddBudgetItem(t *testing.T) {
 			budgetItem.Name,
 			budgetItem.Amount_Allocated,
 			budgetItem.Description,
-			budgetItem.Status,
+			"pending", // new items always wait for review
 			uint(1), // ProjectID as uint
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
@@ -87,25 +89,33 @@ func TestBudgetItemService_GetAllBudgetItem(t *testing.T) {
 	svc := NewBudgetItemService(gormDB)
 
 	projectID := "1"
-	filter := "All"
-	page := "1"
+	params := url.Values{"limit": {"2"}, "status": {"pending,approved"}, "amount_min": {"100"}, "sort": {"-amount"}}
 
-	// Setup mock rows
+	// Setup mock rows, one more than the limit
 	rows := sqlmock.NewRows([]string{"id", "name", "amount_allocated", "description", "status", "project_id"}).
 		AddRow(1, "Item 1", 500.0, "Desc 1", "Pending", 1).
-		AddRow(2, "Item 2", 1500.0, "Desc 2", "Approved", 1)
+		AddRow(2, "Item 2", 400.0, "Desc 2", "Approved", 1).
+		AddRow(3, "Item 3", 300.0, "Desc 3", "Pending", 1)
 
-	mock.ExpectQuery(`SELECT \* FROM "budget_items" WHERE project_id = \$1 LIMIT \$2 OFFSET \$3`).
-		WithArgs(1, 5, 0).
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "budget_items" WHERE project_id = \$1 AND amount_allocated >= \$2 AND LOWER\(status\) IN \(\$3,\$4\)`).
+		WithArgs(1, 100.0, "pending", "approved").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	mock.ExpectQuery(`SELECT \* FROM "budget_items" WHERE (.+) ORDER BY amount_allocated DESC,id DESC LIMIT \$5`).
+		WithArgs(1, 100.0, "pending", "approved", 3).
 		WillReturnRows(rows)
 
-	items, err := svc.GetAllBudgetItem(projectID, filter, page)
+	items, meta, err := svc.GetAllBudgetItem(projectID, params)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
 	if len(items) != 2 {
-		t.Errorf("Expected 2 items, got %d", len(items))
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	if !meta.HasMore || meta.NextCursor == "" || meta.Total != 3 {
+		t.Errorf("Unexpected page meta %+v", meta)
 	}
 
 	if items[0].Name != "Item 1" || items[1].Name != "Item 2" {
@@ -234,21 +244,45 @@ func TestBudgetItemService_UpdateBudgetItemStatus(t *testing.T) {
 	budgetItemID := "5"
 	newStatus := models.UpdateStatus{Status: "approve"}
 
-	// Mock finding the budget item
-	findRows := sqlmock.NewRows([]string{"id", "name", "amount_allocated", "description", "status", "project_id"}).
-		AddRow(5, "Item", 1000.0, "Desc", "Pending", 1)
-	mock.ExpectQuery(`SELECT \* FROM "budget_items" WHERE id = \$1 ORDER BY "budget_items"."id" LIMIT 1`).
-		WithArgs(5).
-		WillReturnRows(findRows)
+	find := `SELECT \* FROM "budget_items" WHERE \(id = \$1 AND project_id IN \(SELECT "id" FROM "projects" WHERE barangay_id = \$2 AND "projects"."deleted_at" IS NULL\)\) AND "budget_items"."deleted_at" IS NULL ORDER BY "budget_items"."id" LIMIT \$3`
+	term := `SELECT "id" FROM "officials" WHERE \(user_id = \$1 AND barangay_id = \$2\) AND \(term_start <= \$3 AND COALESCE\(ended_at, term_end\) > \$4\)`
+	itemRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "name", "amount_allocated", "description", "status", "project_id"}).
+			AddRow(5, "Item", 1000.0, "Desc", "Pending", 1)
+	}
+
+	// items of another barangay's projects are not found
+	mock.ExpectQuery(find).
+		WithArgs(5, 2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if err := svc.UpdateBudgetItemStatus(1, 2, budgetItemID, newStatus); !errors.Is(err, ErrBudgetItemNotFound) {
+		t.Errorf("Expected ErrBudgetItemNotFound, got %v", err)
+	}
+
+	// without a term in office the decision could not be attributed
+	mock.ExpectQuery(find).
+		WithArgs(5, 1, 1).
+		WillReturnRows(itemRows())
+	mock.ExpectQuery(term).
+		WithArgs(1, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	if err := svc.UpdateBudgetItemStatus(1, 1, budgetItemID, newStatus); !errors.Is(err, ErrOfficialNotInOffice) {
+		t.Errorf("Expected ErrOfficialNotInOffice, got %v", err)
+	}
 
-	// Mock the update (save) operation
+	mock.ExpectQuery(find).
+		WithArgs(5, 1, 1).
+		WillReturnRows(itemRows())
+	mock.ExpectQuery(term).
+		WithArgs(1, 1, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
 	mock.ExpectBegin()
-	mock.ExpectExec(`UPDATE "budget_items" SET (.+) WHERE "id" = \$1`).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "Approved", 5).
+	mock.ExpectExec(`UPDATE "budget_items" SET (.+) WHERE "budget_items"."deleted_at" IS NULL AND "id" = \$13`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Item", 1000.0, "Desc", "Approved", sqlmock.AnyArg(), 9, 1, nil, nil, 5).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err = svc.UpdateBudgetItemStatus(budgetItemID, newStatus)
+	err = svc.UpdateBudgetItemStatus(1, 1, budgetItemID, newStatus)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -282,6 +316,10 @@ func TestBudgetItemService_DeleteBudgetItem(t *testing.T) {
 
 	budgetItemID := "8"
 
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "amendment_lines" WHERE budget_item_id = \$1`).
+		WithArgs(8).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
 	// Mock the delete operation
 	mock.ExpectBegin()
 	mock.ExpectExec(`DELETE FROM "budget_items" WHERE id = \$1`).
@@ -298,3 +336,22 @@ func TestBudgetItemService_DeleteBudgetItem(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func TestLegacyStatusFilter(t *testing.T) {
+	tests := []struct {
+		params url.Values
+		want   string
+	}{
+		{url.Values{"filter": {"Approved"}}, "approved"},
+		{url.Values{"filter": {"All"}}, ""},
+		{url.Values{"filter": {"Approved"}, "status": {"pending"}}, "pending"},
+		{url.Values{}, ""},
+	}
+
+	for _, tt := range tests {
+		got := legacyStatusFilter(tt.params)
+		if got.Get("status") != tt.want || got.Has("filter") {
+			t.Errorf("legacyStatusFilter(%v) = %v, want status %q", tt.params, got, tt.want)
+		}
+	}
+}
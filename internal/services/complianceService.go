@@ -0,0 +1,271 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrIncomeNotRecorded = NotFoundError("no income recorded for this fiscal year")
+	ErrInvalidFiscalYear = FieldValidationError("fiscal_year", "fiscal_year must be a year between 1991 and 2100")
+)
+
+// budget items a fiscal year allocates: those of projects starting in that
+// year, other than rejected ones
+const allocatedInFiscalYear = "EXTRACT(YEAR FROM projects.start_date) = ? AND LOWER(budget_items.status) <> 'rejected'"
+
+// income of a fiscal year is the revenue received in it, grouped into the
+// national tax allotment, local sources (real property tax share, fees and
+// charges) and everything else
+const incomeColumns = "SUM(CASE WHEN revenues.source = 'nta' THEN revenues.amount ELSE 0 END) AS nta_share, " +
+	"SUM(CASE WHEN revenues.source IN ('rpt_share', 'fees') THEN revenues.amount ELSE 0 END) AS local_income, " +
+	"SUM(CASE WHEN revenues.source NOT IN ('nta', 'rpt_share', 'fees') THEN revenues.amount ELSE 0 END) AS other_income"
+
+// yearly totals recorded as income stand in for itemized revenue, once a
+// barangay itemizes a fiscal year its totals stop counting so no peso is
+// counted twice
+const countedRevenue = "NOT revenues.summary OR NOT EXISTS (SELECT 1 FROM revenues itemized " +
+	"WHERE itemized.barangay_id = revenues.barangay_id AND itemized.fiscal_year = revenues.fiscal_year " +
+	"AND NOT itemized.summary AND itemized.deleted_at IS NULL)"
+
+type statutoryFund struct {
+	Fund    string
+	Label   string
+	Percent float64
+	Basis   string
+}
+
+// shares the Local Government Code (RA 7160), the SK Reform Act (RA 10742),
+// the GAD budget policy (RA 9710) and the DRRM Act (RA 10121) require a
+// barangay to set aside
+var STATUTORY_FUNDS = []statutoryFund{
+	{models.FundDevelopment, "20% Development Fund", 20, "nta_share"},
+	{models.FundSK, "Sangguniang Kabataan Fund", 10, "total_income"},
+	{models.FundGAD, "Gender and Development", 5, "total_income"},
+	{models.FundDRRM, "Disaster Risk Reduction and Management Fund", 5, "total_income"},
+}
+
+type ComplianceService struct {
+	db *gorm.DB
+}
+
+func NewComplianceService(db *gorm.DB) *ComplianceService {
+	return &ComplianceService{db: db}
+}
+
+func parseFiscalYear(fiscalYear string) (int, error) {
+	year, err := strconv.Atoi(fiscalYear)
+	if err != nil || year < 1991 || year > 2100 {
+		return 0, ErrInvalidFiscalYear
+	}
+	return year, nil
+}
+
+// RecordIncome saves the income of a fiscal year as summary revenue entries,
+// replacing the totals recorded earlier for the same year. Itemized revenue
+// of the year is left as is and, when there is any, counts instead of them.
+func (s *ComplianceService) RecordIncome(barangay_ID uint, income models.RecordIncome) error {
+	summaries := []models.Revenue{
+		{Source: models.RevenueNTA, Description: "National tax allotment share", Amount: income.NTAShare},
+		{Source: models.RevenueFees, Description: "Local taxes, fees and charges", Amount: income.LocalIncome},
+		{Source: models.RevenueOther, Description: "Other income", Amount: income.OtherIncome},
+	}
+
+	records := make([]models.Revenue, 0, len(summaries))
+	for _, summary := range summaries {
+		if summary.Amount <= 0 {
+			continue
+		}
+		summary.Barangay_ID = barangay_ID
+		summary.FiscalYear = income.FiscalYear
+		summary.Summary = true
+		records = append(records, summary)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("barangay_id = ? AND fiscal_year = ? AND summary", barangay_ID, income.FiscalYear).
+			Delete(&models.Revenue{}).Error; err != nil {
+			return fmt.Errorf("failed to replace recorded income: %w", err)
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		if err := tx.Create(&records).Error; err != nil {
+			return fmt.Errorf("failed to record income: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *ComplianceService) GetIncome(barangay_ID string) ([]models.IncomeResponse, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	var income []models.IncomeResponse
+	if err := s.db.Model(&models.Revenue{}).
+		Select("fiscal_year, "+incomeColumns+", SUM(amount) AS total_income").
+		Where("barangay_id = ?", barangay_ID_int).
+		Where(countedRevenue).
+		Group("fiscal_year").
+		Order("fiscal_year DESC").
+		Scan(&income).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve income: %w", err)
+	}
+
+	if income == nil {
+		income = []models.IncomeResponse{}
+	}
+	return income, nil
+}
+
+// SetStatutoryFund earmarks a budget category of the barangay for one of the
+// statutory funds, an empty fund clears it.
+func (s *ComplianceService) SetStatutoryFund(barangay_ID uint, budget_ID string, fund models.SetStatutoryFund) error {
+	budget_ID_int, err := strconv.Atoi(budget_ID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidBudgetCategoryID, budget_ID)
+	}
+
+	var value interface{}
+	if fund.StatutoryFund != "" {
+		value = fund.StatutoryFund
+	}
+
+	result := s.db.Model(&models.Budget_Category{}).
+		Where("id = ? AND barangay_id = ?", budget_ID_int, barangay_ID).
+		Update("statutory_fund", value)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set statutory fund: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", ErrBudgetCategoryNotFound, budget_ID_int)
+	}
+
+	return nil
+}
+
+// Report checks the budget of one barangay and fiscal year against the
+// statutory funds.
+func (s *ComplianceService) Report(barangay_ID string, fiscalYear string) (models.ComplianceReport, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return models.ComplianceReport{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+	year, err := parseFiscalYear(fiscalYear)
+	if err != nil {
+		return models.ComplianceReport{}, err
+	}
+
+	reports, err := complianceReports(s.db, year, []uint{uint(barangay_ID_int)})
+	if err != nil {
+		return models.ComplianceReport{}, err
+	}
+	if len(reports) == 0 {
+		return models.ComplianceReport{}, fmt.Errorf("%w: %d", ErrIncomeNotRecorded, year)
+	}
+
+	return reports[0], nil
+}
+
+// complianceReports builds the compliance report of every barangay with
+// revenue recorded for fiscalYear, or only of barangayIDs when given.
+func complianceReports(db *gorm.DB, fiscalYear int, barangayIDs []uint) ([]models.ComplianceReport, error) {
+	incomeQuery := db.Table("revenues").
+		Select("revenues.barangay_id, barangays.name AS barangay_name, revenues.fiscal_year, "+incomeColumns).
+		Joins("JOIN barangays ON barangays.id = revenues.barangay_id AND barangays.deleted_at IS NULL").
+		Where("revenues.deleted_at IS NULL AND revenues.fiscal_year = ?", fiscalYear).
+		Where(countedRevenue)
+	allocationQuery := db.Table("budget_items").
+		Select("projects.barangay_id, budget_categories.statutory_fund AS fund, SUM(budget_items.amount_allocated) AS allocated").
+		Joins("JOIN projects ON projects.id = budget_items.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN budget_categories ON budget_categories.id = projects.category_id AND budget_categories.deleted_at IS NULL").
+		Where("budget_items.deleted_at IS NULL AND budget_categories.statutory_fund IS NOT NULL").
+		Where(allocatedInFiscalYear, fiscalYear)
+	if len(barangayIDs) > 0 {
+		incomeQuery = incomeQuery.Where("revenues.barangay_id IN ?", barangayIDs)
+		allocationQuery = allocationQuery.Where("projects.barangay_id IN ?", barangayIDs)
+	}
+
+	var incomes []struct {
+		Barangay_ID  uint
+		BarangayName string
+		FiscalYear   int
+		NTAShare     float64
+		LocalIncome  float64
+		OtherIncome  float64
+	}
+	if err := incomeQuery.Group("revenues.barangay_id, barangays.name, revenues.fiscal_year").
+		Order("barangays.name").Scan(&incomes).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve income: %w", err)
+	}
+	if len(incomes) == 0 {
+		return []models.ComplianceReport{}, nil
+	}
+
+	var allocations []struct {
+		Barangay_ID uint
+		Fund        string
+		Allocated   float64
+	}
+	if err := allocationQuery.Group("projects.barangay_id, budget_categories.statutory_fund").
+		Scan(&allocations).Error; err != nil {
+		return nil, fmt.Errorf("failed to total statutory allocations: %w", err)
+	}
+
+	allocated := map[uint]map[string]float64{}
+	for _, allocation := range allocations {
+		if allocated[allocation.Barangay_ID] == nil {
+			allocated[allocation.Barangay_ID] = map[string]float64{}
+		}
+		allocated[allocation.Barangay_ID][allocation.Fund] = allocation.Allocated
+	}
+
+	reports := make([]models.ComplianceReport, 0, len(incomes))
+	for _, income := range incomes {
+		report := models.ComplianceReport{
+			Barangay_ID:  income.Barangay_ID,
+			BarangayName: income.BarangayName,
+			FiscalYear:   income.FiscalYear,
+			Income: models.IncomeResponse{
+				FiscalYear:  income.FiscalYear,
+				NTAShare:    income.NTAShare,
+				LocalIncome: income.LocalIncome,
+				OtherIncome: income.OtherIncome,
+				TotalIncome: income.NTAShare + income.LocalIncome + income.OtherIncome,
+			},
+			Compliant: true,
+		}
+
+		for _, fund := range STATUTORY_FUNDS {
+			basis := report.Income.TotalIncome
+			if fund.Basis == "nta_share" {
+				basis = report.Income.NTAShare
+			}
+
+			status := models.StatutoryFundStatus{
+				Fund:      fund.Fund,
+				Label:     fund.Label,
+				Percent:   fund.Percent,
+				Basis:     fund.Basis,
+				Required:  math.Round(basis*fund.Percent) / 100,
+				Allocated: allocated[income.Barangay_ID][fund.Fund],
+			}
+			status.Shortfall = math.Max(0, math.Round((status.Required-status.Allocated)*100)/100)
+			status.Compliant = status.Shortfall == 0
+
+			report.Funds = append(report.Funds, status)
+			report.Shortfall += status.Shortfall
+			report.Compliant = report.Compliant && status.Compliant
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
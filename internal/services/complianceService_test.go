@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestComplianceService_Report(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewComplianceService(gormDB)
+
+	if _, err := svc.Report("1", "25"); !errors.Is(err, ErrInvalidFiscalYear) {
+		t.Errorf("Expected ErrInvalidFiscalYear, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "revenues" JOIN barangays (.+) WHERE \(revenues.deleted_at IS NULL AND revenues.fiscal_year = \$1\) AND \(`+regexp.QuoteMeta(countedRevenue)+`\) AND revenues.barangay_id IN \(\$2\) GROUP BY revenues.barangay_id, barangays.name, revenues.fiscal_year ORDER BY barangays.name`).
+		WithArgs(2025, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id", "barangay_name", "fiscal_year", "nta_share", "local_income", "other_income"}).
+			AddRow(1, "Lahug", 2025, 4000000.0, 900000.0, 100000.0))
+	mock.ExpectQuery(`SELECT projects.barangay_id, budget_categories.statutory_fund AS fund, (.+) FROM "budget_items" (.+) GROUP BY projects.barangay_id, budget_categories.statutory_fund`).
+		WithArgs(2025, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id", "fund", "allocated"}).
+			AddRow(1, models.FundDevelopment, 800000.0).
+			AddRow(1, models.FundSK, 350000.0).
+			AddRow(1, models.FundDRRM, 300000.0))
+
+	report, err := svc.Report("1", "2025")
+	if err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	want := map[string][2]float64{
+		models.FundDevelopment: {800000, 0},
+		models.FundSK:          {500000, 150000},
+		models.FundGAD:         {250000, 250000},
+		models.FundDRRM:        {250000, 0},
+	}
+	for _, fund := range report.Funds {
+		if fund.Required != want[fund.Fund][0] || fund.Shortfall != want[fund.Fund][1] || fund.Compliant != (want[fund.Fund][1] == 0) {
+			t.Errorf("Unexpected %s status: %+v", fund.Fund, fund)
+		}
+	}
+	if report.Compliant || report.Shortfall != 400000 || report.Income.TotalIncome != 5000000 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "revenues"`).
+		WithArgs(2024, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id"}))
+
+	if _, err := svc.Report("1", "2024"); !errors.Is(err, ErrIncomeNotRecorded) {
+		t.Errorf("Expected ErrIncomeNotRecorded, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestComplianceService_SetStatutoryFund(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewComplianceService(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "budget_categories" SET "statutory_fund"=\$1,"updated_at"=\$2 WHERE \(id = \$3 AND barangay_id = \$4\)`).
+		WithArgs(models.FundGAD, sqlmock.AnyArg(), 4, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := svc.SetStatutoryFund(1, "4", models.SetStatutoryFund{StatutoryFund: models.FundGAD})
+	if !errors.Is(err, ErrBudgetCategoryNotFound) {
+		t.Errorf("Expected ErrBudgetCategoryNotFound for another barangay's category, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestComplianceService_RecordIncome(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewComplianceService(gormDB)
+
+	// earlier totals are replaced, itemized revenue is kept, zero figures are skipped
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "revenues" SET "deleted_at"=\$1 WHERE \(barangay_id = \$2 AND fiscal_year = \$3 AND summary\) AND "revenues"."deleted_at" IS NULL`).
+		WithArgs(sqlmock.AnyArg(), uint(1), 2025).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectQuery(`INSERT INTO "revenues" (.+) VALUES (.+),(.+) RETURNING "id"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, uint(1), 2025, models.RevenueNTA, "National tax allotment share", 4000000.0, nil, true,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), nil, uint(1), 2025, models.RevenueFees, "Local taxes, fees and charges", 900000.0, nil, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	if err := svc.RecordIncome(1, models.RecordIncome{FiscalYear: 2025, NTAShare: 4000000, LocalIncome: 900000}); err != nil {
+		t.Errorf("RecordIncome() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestComplianceService_GetIncome(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewComplianceService(gormDB)
+
+	// recorded totals of a year count only until the year is itemized
+	mock.ExpectQuery(`SELECT fiscal_year, (.+), SUM\(amount\) AS total_income FROM "revenues" WHERE barangay_id = \$1 AND \(` + regexp.QuoteMeta(countedRevenue) + `\) AND "revenues"."deleted_at" IS NULL GROUP BY "fiscal_year" ORDER BY fiscal_year DESC`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"fiscal_year", "nta_share", "local_income", "other_income", "total_income"}).
+			AddRow(2025, 4000000.0, 900000.0, 100000.0, 5000000.0))
+
+	income, err := svc.GetIncome("1")
+	if err != nil {
+		t.Fatalf("GetIncome() error = %v", err)
+	}
+	if len(income) != 1 || income[0].TotalIncome != 5000000 {
+		t.Errorf("Unexpected income: %+v", income)
+	}
+
+	if _, err := svc.GetIncome("one"); !errors.Is(err, ErrInvalidBarangayID) {
+		t.Errorf("Expected ErrInvalidBarangayID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	CONTENT_FILTER_MAX_LINKS        = 2
+	CONTENT_FILTER_RATE_LIMIT       = 5
+	CONTENT_FILTER_RATE_WINDOW      = 10 * time.Minute
+	CONTENT_FILTER_DUPLICATE_WINDOW = 24 * time.Hour
+
+	// link shorteners hide where a link goes, a favourite of spammers
+	LINK_SHORTENERS = []string{"bit.ly", "tinyurl.com", "goo.gl", "t.co", "cutt.ly", "is.gd", "s.id", "shorturl.at", "rb.gy", "tiny.cc"}
+)
+
+// DEFAULT_FILTER_WORDS holds terms in English, Tagalog and Cebuano that hold
+// a post for review. Terms are matched as whole words after normalizing.
+var DEFAULT_FILTER_WORDS = map[string]string{
+	// English
+	"fuck": models.ReasonHarassment, "fucking": models.ReasonHarassment, "motherfucker": models.ReasonHarassment,
+	"shit": models.ReasonHarassment, "bullshit": models.ReasonHarassment, "bitch": models.ReasonHarassment,
+	"asshole": models.ReasonHarassment, "bastard": models.ReasonHarassment, "dickhead": models.ReasonHarassment,
+	"retard": models.ReasonHateSpeech, "faggot": models.ReasonHateSpeech,
+	// Tagalog
+	"putang ina": models.ReasonHarassment, "putangina": models.ReasonHarassment, "tangina": models.ReasonHarassment,
+	"tang ina": models.ReasonHarassment, "gago": models.ReasonHarassment, "gaga": models.ReasonHarassment,
+	"ulol": models.ReasonHarassment, "tarantado": models.ReasonHarassment, "punyeta": models.ReasonHarassment,
+	"leche": models.ReasonHarassment, "hayop ka": models.ReasonHarassment, "bobo": models.ReasonHarassment,
+	"bakla ka": models.ReasonHateSpeech,
+	// Cebuano
+	"yawa": models.ReasonHarassment, "pisti": models.ReasonHarassment, "piste": models.ReasonHarassment,
+	"giatay": models.ReasonHarassment, "buang ka": models.ReasonHarassment, "bilat": models.ReasonHarassment,
+	"animal ka": models.ReasonHarassment, "pakyu": models.ReasonHarassment,
+}
+
+// FilterPost is a feedback or reply as the content filters see it before it
+// is saved.
+type FilterPost struct {
+	ContentType string //feedback or reply
+	ContentID   uint   //the post being edited, zero for a new post
+	UserID      uint
+	Content     string
+	PostedAt    time.Time
+}
+
+// FilterFlag is a reason to hold a post for a moderator.
+type FilterFlag struct {
+	Filter string
+	Reason string //moderation reason code
+	Detail string
+}
+
+// ContentFilter inspects a post before it is saved. Filters that need
+// earlier posts read them through db.
+type ContentFilter interface {
+	Check(db *gorm.DB, post FilterPost) ([]FilterFlag, error)
+}
+
+// ContentFilters runs its filters in order and collects every flag.
+type ContentFilters []ContentFilter
+
+func (filters ContentFilters) Screen(db *gorm.DB, post FilterPost) ([]FilterFlag, error) {
+	var flags []FilterFlag
+	for _, filter := range filters {
+		found, err := filter.Check(db, post)
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, found...)
+	}
+	return flags, nil
+}
+
+// ContentFilterConfig tunes the default filters.
+type ContentFilterConfig struct {
+	Words           map[string]string //term -> moderation reason code
+	MaxLinks        int
+	RateLimit       int
+	RateWindow      time.Duration
+	DuplicateWindow time.Duration
+}
+
+// ContentFilterConfigFromEnv starts from the defaults and applies
+// CONTENT_FILTER_* variables. CONTENT_FILTER_WORDLIST names a file of extra
+// terms, one per line, optionally prefixed with a reason code such as
+// "hate_speech:term". CONTENT_FILTER_RATE_LIMIT reads like "5/10m".
+func ContentFilterConfigFromEnv() ContentFilterConfig {
+	config := ContentFilterConfig{
+		Words:           map[string]string{},
+		MaxLinks:        CONTENT_FILTER_MAX_LINKS,
+		RateLimit:       CONTENT_FILTER_RATE_LIMIT,
+		RateWindow:      CONTENT_FILTER_RATE_WINDOW,
+		DuplicateWindow: CONTENT_FILTER_DUPLICATE_WINDOW,
+	}
+	for term, reason := range DEFAULT_FILTER_WORDS {
+		config.Words[term] = reason
+	}
+
+	if path := os.Getenv("CONTENT_FILTER_WORDLIST"); path != "" {
+		words, err := readWordList(path)
+		if err != nil {
+			log.Printf("content filter: ignoring word list: %v", err)
+		}
+		for term, reason := range words {
+			config.Words[term] = reason
+		}
+	}
+
+	if value := os.Getenv("CONTENT_FILTER_MAX_LINKS"); value != "" {
+		if maxLinks, err := strconv.Atoi(value); err == nil && maxLinks >= 0 {
+			config.MaxLinks = maxLinks
+		} else {
+			log.Printf("content filter: invalid CONTENT_FILTER_MAX_LINKS %q", value)
+		}
+	}
+
+	if value := os.Getenv("CONTENT_FILTER_RATE_LIMIT"); value != "" {
+		count, window, found := strings.Cut(value, "/")
+		limit, countErr := strconv.Atoi(count)
+		duration, windowErr := time.ParseDuration(window)
+		if found && countErr == nil && windowErr == nil && limit > 0 && duration > 0 {
+			config.RateLimit, config.RateWindow = limit, duration
+		} else {
+			log.Printf("content filter: invalid CONTENT_FILTER_RATE_LIMIT %q", value)
+		}
+	}
+
+	if value := os.Getenv("CONTENT_FILTER_DUPLICATE_WINDOW"); value != "" {
+		if window, err := time.ParseDuration(value); err == nil && window > 0 {
+			config.DuplicateWindow = window
+		} else {
+			log.Printf("content filter: invalid CONTENT_FILTER_DUPLICATE_WINDOW %q", value)
+		}
+	}
+
+	return config
+}
+
+func readWordList(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	words := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		reason, term := models.ReasonHarassment, line
+		if code, rest, found := strings.Cut(line, ":"); found && slices.Contains(MODERATION_REASONS, code) {
+			reason, term = code, rest
+		}
+		if term = strings.Join(filterTokens(term), " "); term != "" {
+			words[term] = reason
+		}
+	}
+	return words, scanner.Err()
+}
+
+// DefaultContentFilters builds the pipeline citizen feedback and replies are
+// run through when they are posted and when they are edited.
+func DefaultContentFilters(config ContentFilterConfig) ContentFilters {
+	return ContentFilters{
+		NewWordFilter(config.Words),
+		LinkFilter{MaxLinks: config.MaxLinks},
+		PhoneFilter{},
+		DuplicateFilter{Window: config.DuplicateWindow},
+		RateFilter{Limit: config.RateLimit, Window: config.RateWindow},
+	}
+}
+
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// filterTokens lowercases text, undoes common letter substitutions and
+// squeezes letters repeated three or more times ("gaaago") so word lists
+// need one spelling.
+func filterTokens(text string) []string {
+	var tokens []string
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '@' && r != '$'
+	})
+	for _, word := range words {
+		// numbers are left alone, only digits standing in for letters count
+		if strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+		tokens = append(tokens, strings.FieldsFunc(leetReplacer.Replace(word), func(r rune) bool { return !unicode.IsLetter(r) })...)
+	}
+
+	for i, token := range tokens {
+		runes := []rune(token)
+		var squeezed []rune
+		for j := 0; j < len(runes); {
+			run := j
+			for run < len(runes) && runes[run] == runes[j] {
+				run++
+			}
+			if run-j >= 3 {
+				squeezed = append(squeezed, runes[j])
+			} else {
+				squeezed = append(squeezed, runes[j:run]...)
+			}
+			j = run
+		}
+		tokens[i] = string(squeezed)
+	}
+	return tokens
+}
+
+// contentHash identifies a post regardless of case and spacing.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(strings.ToLower(content)), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// WordFilter holds posts containing a listed term.
+type WordFilter struct {
+	terms map[string]string
+}
+
+func NewWordFilter(words map[string]string) WordFilter {
+	terms := make(map[string]string, len(words))
+	for term, reason := range words {
+		if normalized := strings.Join(filterTokens(term), " "); normalized != "" {
+			terms[normalized] = reason
+		}
+	}
+	return WordFilter{terms: terms}
+}
+
+func (f WordFilter) Check(_ *gorm.DB, post FilterPost) ([]FilterFlag, error) {
+	text := " " + strings.Join(filterTokens(post.Content), " ") + " "
+
+	found := map[string][]string{}
+	for term, reason := range f.terms {
+		if strings.Contains(text, " "+term+" ") {
+			found[reason] = append(found[reason], term)
+		}
+	}
+
+	var flags []FilterFlag
+	for _, reason := range MODERATION_REASONS {
+		if terms := found[reason]; len(terms) > 0 {
+			slices.Sort(terms)
+			flags = append(flags, FilterFlag{Filter: "words", Reason: reason, Detail: "contains " + strings.Join(terms, ", ")})
+		}
+	}
+	return flags, nil
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+|\b(?:[a-z0-9-]+\.)+(?:com|net|org|info|biz|xyz|top|site|online|shop|click|link|ly|gl|co|me|io|at|id|gd|cc|ph)\b(?:/[^\s]*)?`)
+
+// LinkFilter holds posts with many links or links that hide where they go.
+type LinkFilter struct {
+	MaxLinks int
+}
+
+func (f LinkFilter) Check(_ *gorm.DB, post FilterPost) ([]FilterFlag, error) {
+	links := linkPattern.FindAllString(post.Content, -1)
+
+	for _, link := range links {
+		host := strings.ToLower(link)
+		host = strings.TrimPrefix(strings.TrimPrefix(host, "http://"), "https://")
+		host, _, _ = strings.Cut(strings.TrimPrefix(host, "www."), "/")
+		if slices.Contains(LINK_SHORTENERS, host) {
+			return []FilterFlag{{Filter: "links", Reason: models.ReasonSpam, Detail: "shortened link " + link}}, nil
+		}
+	}
+
+	if len(links) > f.MaxLinks {
+		return []FilterFlag{{Filter: "links", Reason: models.ReasonSpam, Detail: fmt.Sprintf("%d links", len(links))}}, nil
+	}
+	return nil, nil
+}
+
+// Philippine mobile numbers: 0917 123 4567, +63 917-123-4567, 639171234567
+var phonePattern = regexp.MustCompile(`(?:^|[^\d+])(?:\+?63|0)[ -]?9\d{2}[ -]?\d{3}[ -]?\d{4}(?:$|\D)`)
+
+// PhoneFilter holds posts that give out a mobile number.
+type PhoneFilter struct{}
+
+func (PhoneFilter) Check(_ *gorm.DB, post FilterPost) ([]FilterFlag, error) {
+	if phonePattern.MatchString(post.Content) {
+		return []FilterFlag{{Filter: "phone", Reason: models.ReasonPersonalInfo, Detail: "contains a mobile number"}}, nil
+	}
+	return nil, nil
+}
+
+// DuplicateFilter holds a post its author already made recently.
+type DuplicateFilter struct {
+	Window time.Duration
+}
+
+func (f DuplicateFilter) Check(db *gorm.DB, post FilterPost) ([]FilterFlag, error) {
+	table, err := moderatedTable(post.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Table(table).
+		Where("user_id = ? AND content_hash = ? AND created_at > ? AND deleted_at IS NULL", post.UserID, contentHash(post.Content), post.PostedAt.Add(-f.Window))
+	if post.ContentID != 0 {
+		query = query.Where("id <> ?", post.ContentID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check for duplicate posts: %w", err)
+	}
+
+	if count > 0 {
+		return []FilterFlag{{Filter: "duplicate", Reason: models.ReasonSpam, Detail: fmt.Sprintf("same text posted %d time(s) in the last %s", count, f.Window)}}, nil
+	}
+	return nil, nil
+}
+
+// RateFilter holds posts beyond Limit feedback and replies per Window.
+// Edits add no post and are not counted.
+type RateFilter struct {
+	Limit  int
+	Window time.Duration
+}
+
+func (f RateFilter) Check(db *gorm.DB, post FilterPost) ([]FilterFlag, error) {
+	if post.ContentID != 0 {
+		return nil, nil
+	}
+
+	since := post.PostedAt.Add(-f.Window)
+
+	var count int64
+	if err := db.Raw(`SELECT
+		(SELECT COUNT(*) FROM feedbacks WHERE user_id = ? AND created_at > ? AND deleted_at IS NULL) +
+		(SELECT COUNT(*) FROM feedback_replies WHERE user_id = ? AND created_at > ? AND deleted_at IS NULL)`,
+		post.UserID, since, post.UserID, since).
+		Scan(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check posting rate: %w", err)
+	}
+
+	if count >= int64(f.Limit) {
+		return []FilterFlag{{Filter: "rate", Reason: models.ReasonSpam, Detail: fmt.Sprintf("%d posts in the last %s", count+1, f.Window)}}, nil
+	}
+	return nil, nil
+}
+
+// screenPost runs a citizen post through the filters. Officials and admins
+// post as the barangay and publish directly.
+func screenPost(db *gorm.DB, filters ContentFilters, role string, post FilterPost) ([]FilterFlag, error) {
+	if role == models.RoleOfficial || role == models.RoleAdmin {
+		return nil, nil
+	}
+	return filters.Screen(db, post)
+}
+
+// initialModerationState publishes posts the filters let through, flagged
+// posts wait for a moderator with the first flag as their reason.
+func initialModerationState(flags []FilterFlag) (string, *string) {
+	if len(flags) == 0 {
+		return models.ModerationPublished, nil
+	}
+	reason := flags[0].Reason
+	return models.ModerationPending, &reason
+}
+
+// editedModerationState holds a published post for a moderator again when
+// its new wording is flagged. Posts already pending, hidden or removed keep
+// their state.
+func editedModerationState(current string, currentReason *string, flags []FilterFlag) (string, *string) {
+	if len(flags) == 0 || current != models.ModerationPublished {
+		return current, currentReason
+	}
+	return initialModerationState(flags)
+}
+
+// saveFlags records why a post was held.
+func saveFlags(tx *gorm.DB, contentType string, contentID uint, flags []FilterFlag) error {
+	if len(flags) == 0 {
+		return nil
+	}
+
+	rows := make([]models.ContentFlag, len(flags))
+	for i, flag := range flags {
+		rows[i] = models.ContentFlag{ContentType: contentType, ContentID: contentID, Filter: flag.Filter, Reason: flag.Reason, Detail: flag.Detail}
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to record content flags: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestFilterTokens(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Ang GAGO mo!", []string{"ang", "gago", "mo"}},
+		{"gaaaago", []string{"gago"}},
+		{"sh1t, b!tch", []string{"shit", "b", "tch"}},
+		{"Purok 3 drainage", []string{"purok", "drainage"}},
+		{"Maayong buntag", []string{"maayong", "buntag"}},
+	}
+
+	for _, tt := range tests {
+		if got := filterTokens(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterTokens(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWordFilter(t *testing.T) {
+	filter := NewWordFilter(DEFAULT_FILTER_WORDS)
+
+	tests := []struct {
+		content string
+		reasons []string
+	}{
+		{"The road near the chapel is flooded again.", nil},
+		{"PUTANG INA, kailan matatapos ito?", []string{models.ReasonHarassment}},
+		{"Yawa, wala gihapon tubig sa Purok 2.", []string{models.ReasonHarassment}},
+		{"This is bullsh1t.", []string{models.ReasonHarassment}},
+		// whole words only
+		{"Ang leksyon ay tungkol sa agham.", nil},
+		{"The Scunthorpe shitake mushroom stall", nil},
+	}
+
+	for _, tt := range tests {
+		flags, err := filter.Check(nil, FilterPost{Content: tt.content})
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", tt.content, err)
+		}
+		var reasons []string
+		for _, flag := range flags {
+			reasons = append(reasons, flag.Reason)
+		}
+		if !reflect.DeepEqual(reasons, tt.reasons) {
+			t.Errorf("Check(%q) reasons = %v, want %v", tt.content, reasons, tt.reasons)
+		}
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	filter := LinkFilter{MaxLinks: 2}
+
+	tests := []struct {
+		content string
+		flagged bool
+	}{
+		{"Details are posted at https://batangascity.gov.ph/bids", false},
+		{"Win cash! bit.ly/free-load", true},
+		{"See www.a.com, www.b.com and www.c.com", true},
+		{"The budget is P1,250,000.00 for 2025.", false},
+	}
+
+	for _, tt := range tests {
+		flags, _ := filter.Check(nil, FilterPost{Content: tt.content})
+		if (len(flags) > 0) != tt.flagged {
+			t.Errorf("Check(%q) = %v, want flagged %v", tt.content, flags, tt.flagged)
+		}
+	}
+}
+
+func TestPhoneFilter(t *testing.T) {
+	tests := []struct {
+		content string
+		flagged bool
+	}{
+		{"Text me at 0917 123 4567", true},
+		{"Call +63 917-123-4567 po", true},
+		{"639171234567", true},
+		{"Project 20240917 costs 1234567", false},
+		{"Reference no. 10917123456789", false},
+	}
+
+	for _, tt := range tests {
+		flags, _ := PhoneFilter{}.Check(nil, FilterPost{Content: tt.content})
+		if (len(flags) > 0) != tt.flagged {
+			t.Errorf("Check(%q) = %v, want flagged %v", tt.content, flags, tt.flagged)
+		}
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	if contentHash("Fix  the\nroad") != contentHash("fix the road") {
+		t.Error("contentHash() differs on case and spacing")
+	}
+	if contentHash("fix the road") == contentHash("fix the bridge") {
+		t.Error("contentHash() collides on different text")
+	}
+}
+
+func TestInitialModerationState(t *testing.T) {
+	state, reason := initialModerationState(nil)
+	if state != models.ModerationPublished || reason != nil {
+		t.Errorf("initialModerationState(nil) = %q, %v, want published", state, reason)
+	}
+
+	state, reason = initialModerationState([]FilterFlag{{Filter: "phone", Reason: models.ReasonPersonalInfo}, {Filter: "rate", Reason: models.ReasonSpam}})
+	if state != models.ModerationPending || reason == nil || *reason != models.ReasonPersonalInfo {
+		t.Errorf("initialModerationState(flags) = %q, %v, want pending for personal_info", state, reason)
+	}
+}
+
+func TestScreenPost(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+
+	now := time.Date(2025, time.March, 3, 9, 0, 0, 0, time.UTC)
+	post := FilterPost{ContentType: models.ContentFeedback, UserID: 4, Content: "Kailan po aayusin ang ilaw?", PostedAt: now}
+	filters := DefaultContentFilters(ContentFilterConfig{Words: DEFAULT_FILTER_WORDS, MaxLinks: 2, RateLimit: 3, RateWindow: 10 * time.Minute, DuplicateWindow: 24 * time.Hour})
+
+	// officials post as the barangay and skip the filters
+	if flags, err := screenPost(gormDB, filters, models.RoleOfficial, post); err != nil || flags != nil {
+		t.Errorf("screenPost(official) = %v, %v", flags, err)
+	}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE user_id = \$1 AND content_hash = \$2 AND created_at > \$3 AND deleted_at IS NULL`).
+		WithArgs(4, contentHash(post.Content), now.Add(-24*time.Hour)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT \(SELECT COUNT\(\*\) FROM feedbacks (.+)\) \+ \(SELECT COUNT\(\*\) FROM feedback_replies (.+)\)`).
+		WithArgs(4, now.Add(-10*time.Minute), 4, now.Add(-10*time.Minute)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	flags, err := screenPost(gormDB, filters, models.RoleCitizen, post)
+	if err != nil {
+		t.Fatalf("screenPost() error = %v", err)
+	}
+	if len(flags) != 2 || flags[0].Filter != "duplicate" || flags[1].Filter != "rate" {
+		t.Errorf("screenPost() = %+v, want duplicate and rate flags", flags)
+	}
+
+	// an edit is not a duplicate of itself and adds no post to the rate
+	edit := post
+	edit.ContentID = 9
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE \(user_id = \$1 (.+)\) AND id <> \$4`).
+		WithArgs(4, contentHash(post.Content), now.Add(-24*time.Hour), 9).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	if flags, err := screenPost(gormDB, filters, models.RoleCitizen, edit); err != nil || len(flags) != 0 {
+		t.Errorf("screenPost(edit) = %+v, %v, want no flags", flags, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestReadWordList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	list := "# barangay additions\nkurakot\nspam:Load Promo\n\n"
+	if err := os.WriteFile(path, []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	words, err := readWordList(path)
+	if err != nil {
+		t.Fatalf("readWordList() error = %v", err)
+	}
+	want := map[string]string{"kurakot": models.ReasonHarassment, "load promo": models.ReasonSpam}
+	if !reflect.DeepEqual(words, want) {
+		t.Errorf("readWordList() = %v, want %v", words, want)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrorKind classifies service errors so handlers never pick status codes.
+type ErrorKind string
+
+const (
+	KindValidation   ErrorKind = "validation_error"
+	KindUnauthorized ErrorKind = "unauthorized"
+	KindForbidden    ErrorKind = "forbidden"
+	KindNotFound     ErrorKind = "not_found"
+	KindConflict     ErrorKind = "conflict"
+	KindTooLarge     ErrorKind = "payload_too_large"
+	KindUpstream     ErrorKind = "upstream_error"
+	KindInternal     ErrorKind = "internal_error"
+)
+
+var errorStatus = map[ErrorKind]int{
+	KindValidation:   http.StatusBadRequest,
+	KindUnauthorized: http.StatusUnauthorized,
+	KindForbidden:    http.StatusForbidden,
+	KindNotFound:     http.StatusNotFound,
+	KindConflict:     http.StatusConflict,
+	KindTooLarge:     http.StatusRequestEntityTooLarge,
+	KindUpstream:     http.StatusBadGateway,
+	KindInternal:     http.StatusInternalServerError,
+}
+
+// FieldError names a single request field that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ServiceError is a classified error. Package level sentinels are
+// ServiceErrors, so errors.Is keeps working through fmt.Errorf("%w").
+type ServiceError struct {
+	Kind    ErrorKind
+	Message string
+	Fields  []FieldError
+}
+
+func (e *ServiceError) Error() string {
+	return e.Message
+}
+
+func newKindError(kind ErrorKind, message string) *ServiceError {
+	return &ServiceError{Kind: kind, Message: message}
+}
+
+func ValidationError(message string) *ServiceError   { return newKindError(KindValidation, message) }
+func UnauthorizedError(message string) *ServiceError { return newKindError(KindUnauthorized, message) }
+func ForbiddenError(message string) *ServiceError    { return newKindError(KindForbidden, message) }
+func NotFoundError(message string) *ServiceError     { return newKindError(KindNotFound, message) }
+func ConflictError(message string) *ServiceError     { return newKindError(KindConflict, message) }
+
+// FieldValidationError is a validation error tied to one request field.
+func FieldValidationError(field string, message string) *ServiceError {
+	return &ServiceError{Kind: KindValidation, Message: message, Fields: []FieldError{{Field: field, Message: message}}}
+}
+
+// ErrorResponse is the JSON envelope written for every failed request.
+type ErrorResponse struct {
+	Code      ErrorKind    `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	RequestID string       `json:"request_id"`
+}
+
+// classifyError finds the kind of err, treating unclassified record lookups
+// and malformed numeric parameters as not found and validation errors.
+func classifyError(err error) (ErrorKind, []FieldError) {
+	var serviceErr *ServiceError
+	if errors.As(err, &serviceErr) {
+		return serviceErr.Kind, serviceErr.Fields
+	}
+
+	var numErr *strconv.NumError
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return KindNotFound, nil
+	case errors.As(err, &numErr):
+		return KindValidation, nil
+	}
+
+	return KindInternal, nil
+}
+
+// WriteError aborts the request with the error envelope. Internal errors are
+// logged with the request ID and hidden from the client.
+func WriteError(c *gin.Context, err error) {
+	kind, fields := classifyError(err)
+	requestID := c.GetString(REQUEST_ID_KEY)
+	if requestID == "" {
+		requestID, _ = randomHex(8)
+	}
+
+	message := err.Error()
+	if kind == KindInternal {
+		log.Printf("request %s: %v", requestID, err)
+		message = "Something went wrong, please try again later"
+	}
+
+	c.IndentedJSON(errorStatus[kind], gin.H{"error": ErrorResponse{
+		Code:      kind,
+		Message:   message,
+		Fields:    fields,
+		RequestID: requestID,
+	}})
+	c.Abort()
+}
+
+var (
+	REQUEST_ID_KEY    = "request_id"
+	REQUEST_ID_HEADER = "X-Request-ID"
+	requestIDPattern  = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+)
+
+// RequestID tags every request with an ID, reusing a well-formed one sent by
+// a proxy, and echoes it back in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(REQUEST_ID_HEADER)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID, _ = randomHex(8)
+		}
+
+		c.Set(REQUEST_ID_KEY, requestID)
+		c.Header(REQUEST_ID_HEADER, requestID)
+		c.Next()
+	}
+}
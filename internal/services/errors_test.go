@@ -0,0 +1,134 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func TestClassifyError(t *testing.T) {
+	_, numErr := strconv.Atoi("abc")
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorKind
+	}{
+		{name: "Sentinel", err: ErrUserNotFound, want: KindNotFound},
+		{name: "Wrapped Sentinel", err: fmt.Errorf("login: %w", ErrInvalidCredentials), want: KindUnauthorized},
+		{name: "Field Error", err: ErrInvalidContact, want: KindValidation},
+		{name: "Record Not Found", err: gorm.ErrRecordNotFound, want: KindNotFound},
+		{name: "Bad Number", err: numErr, want: KindValidation},
+		{name: "Unclassified", err: errors.New("connection refused"), want: KindInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := classifyError(tt.err); got != tt.want {
+				t.Errorf("classifyError() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    ErrorKind
+		wantMessage string
+		wantField   string
+	}{
+		{name: "Conflict", err: ErrEmailTaken, wantStatus: http.StatusConflict, wantCode: KindConflict, wantMessage: ErrEmailTaken.Error()},
+		{name: "Field Validation", err: ErrWeakPassword, wantStatus: http.StatusBadRequest, wantCode: KindValidation, wantMessage: ErrWeakPassword.Error(), wantField: "password"},
+		{name: "Too Large", err: ErrImageTooLarge, wantStatus: http.StatusRequestEntityTooLarge, wantCode: KindTooLarge, wantMessage: ErrImageTooLarge.Error()},
+		{name: "Internal Hides Detail", err: errors.New("pq: relation does not exist"), wantStatus: http.StatusInternalServerError, wantCode: KindInternal, wantMessage: "Something went wrong, please try again later"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(RequestID())
+			r.GET("/", func(c *gin.Context) {
+				WriteError(c, tt.err)
+			})
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set(REQUEST_ID_HEADER, "req-123")
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+
+			var body struct {
+				Error ErrorResponse `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode envelope: %v", err)
+			}
+			if body.Error.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, body.Error.Code)
+			}
+			if body.Error.Message != tt.wantMessage {
+				t.Errorf("Expected message %q, got %q", tt.wantMessage, body.Error.Message)
+			}
+			if body.Error.RequestID != "req-123" {
+				t.Errorf("Expected request ID req-123, got %q", body.Error.RequestID)
+			}
+			if tt.wantField != "" && (len(body.Error.Fields) != 1 || body.Error.Fields[0].Field != tt.wantField) {
+				t.Errorf("Expected field %s, got %+v", tt.wantField, body.Error.Fields)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestID())
+	r.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(REQUEST_ID_KEY))
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		wantEcho bool
+	}{
+		{name: "Reuses Incoming", header: "abc-123", wantEcho: true},
+		{name: "Generates When Missing", header: ""},
+		{name: "Replaces Malformed", header: "bad id\nwith newline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set(REQUEST_ID_HEADER, tt.header)
+			}
+			r.ServeHTTP(w, req)
+
+			got := w.Header().Get(REQUEST_ID_HEADER)
+			if got == "" || got != w.Body.String() {
+				t.Fatalf("Expected header and context IDs to match, got %q and %q", got, w.Body.String())
+			}
+			if (got == tt.header) != tt.wantEcho {
+				t.Errorf("Request ID %q, incoming %q, wantEcho %v", got, tt.header, tt.wantEcho)
+			}
+		})
+	}
+}
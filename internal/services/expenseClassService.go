@@ -0,0 +1,259 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExpenseClassNotFound   = NotFoundError("expense class not found")
+	ErrExpenseClassExists     = ConflictError("an expense class with this code already exists")
+	ErrExpenseParentNotFound  = FieldValidationError("parent_code", "parent_code is not in the expense classification")
+	ErrExpenseParentInUse     = ConflictError("parent code is assigned to budget items and cannot take sub-codes")
+	ErrUnknownObjectCode      = FieldValidationError("object_code", "object_code is not in the expense classification")
+	ErrObjectCodeNotLeaf      = FieldValidationError("object_code", "object_code must be an object of expenditure, not a class heading")
+	ErrObjectCodeInactive     = FieldValidationError("object_code", "object_code has been retired")
+	ErrExpenseItemNotFound    = NotFoundError("budget item was not found in this barangay")
+	ErrInvalidExpenseBudgetID = ValidationError("invalid budget item ID format")
+)
+
+// allotment classes are reported in the order of the budget forms, classes
+// an admin adds come after them by code
+var EXPENSE_CLASS_ORDER = map[string]int{"PS": 1, "MOOE": 2, "CO": 3}
+
+type ExpenseClassService struct {
+	db *gorm.DB
+}
+
+func NewExpenseClassService(db *gorm.DB) *ExpenseClassService {
+	return &ExpenseClassService{db: db}
+}
+
+// checkObjectCode makes sure budget items are only assigned active leaves
+// of the classification.
+func checkObjectCode(db *gorm.DB, code string) error {
+	var class models.ExpenseClass
+	if err := db.Where("code = ?", code).First(&class).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUnknownObjectCode
+		}
+		return fmt.Errorf("failed to find object code: %w", err)
+	}
+	if !class.Active {
+		return ErrObjectCodeInactive
+	}
+
+	var children int64
+	if err := db.Model(&models.ExpenseClass{}).Where("parent_code = ?", code).Count(&children).Error; err != nil {
+		return fmt.Errorf("failed to check object code: %w", err)
+	}
+	if children > 0 {
+		return ErrObjectCodeNotLeaf
+	}
+
+	return nil
+}
+
+func (s *ExpenseClassService) AddExpenseClass(class models.NewExpenseClass) error {
+	var existing int64
+	if err := s.db.Model(&models.ExpenseClass{}).Where("code = ?", class.Code).Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check expense class: %w", err)
+	}
+	if existing > 0 {
+		return ErrExpenseClassExists
+	}
+
+	newClass := models.ExpenseClass{Code: class.Code, Name: class.Name}
+	if class.ParentCode != "" {
+		var parents int64
+		if err := s.db.Model(&models.ExpenseClass{}).Where("code = ?", class.ParentCode).Count(&parents).Error; err != nil {
+			return fmt.Errorf("failed to find parent code: %w", err)
+		}
+		if parents == 0 {
+			return ErrExpenseParentNotFound
+		}
+
+		// items sit on leaves only, a used code cannot become a heading
+		var items int64
+		if err := s.db.Model(&models.Budget_Item{}).Where("object_code = ?", class.ParentCode).Count(&items).Error; err != nil {
+			return fmt.Errorf("failed to check parent code: %w", err)
+		}
+		if items > 0 {
+			return ErrExpenseParentInUse
+		}
+
+		newClass.ParentCode = &class.ParentCode
+	}
+
+	if err := s.db.Create(&newClass).Error; err != nil {
+		return fmt.Errorf("failed to create expense class: %w", err)
+	}
+	return nil
+}
+
+// UpdateExpenseClass renames a code or retires it. Retired codes keep their
+// budget items but cannot be assigned again.
+func (s *ExpenseClassService) UpdateExpenseClass(code string, update models.UpdateExpenseClass) error {
+	updates := map[string]interface{}{"name": update.Name}
+	if update.Active != nil {
+		updates["active"] = *update.Active
+	}
+
+	result := s.db.Model(&models.ExpenseClass{}).Where("code = ?", code).Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update expense class: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: %s", ErrExpenseClassNotFound, code)
+	}
+	return nil
+}
+
+// Tree returns the whole classification, children sorted by code.
+func (s *ExpenseClassService) Tree() ([]models.ExpenseClassNode, error) {
+	var classes []models.ExpenseClass
+	if err := s.db.Order("code").Find(&classes).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve expense classes: %w", err)
+	}
+
+	children := map[string][]models.ExpenseClass{}
+	for _, class := range classes {
+		parent := ""
+		if class.ParentCode != nil {
+			parent = *class.ParentCode
+		}
+		children[parent] = append(children[parent], class)
+	}
+
+	var build func(parent string) []models.ExpenseClassNode
+	build = func(parent string) []models.ExpenseClassNode {
+		nodes := []models.ExpenseClassNode{}
+		for _, class := range children[parent] {
+			nodes = append(nodes, models.ExpenseClassNode{
+				Code:     class.Code,
+				Name:     class.Name,
+				Active:   class.Active,
+				Children: build(class.Code),
+			})
+		}
+		return nodes
+	}
+
+	return build(""), nil
+}
+
+// AssignObjectCode classifies a budget item of the official's barangay.
+func (s *ExpenseClassService) AssignObjectCode(barangay_ID uint, budgetItemID string, assign models.AssignObjectCode) error {
+	id, err := strconv.ParseUint(budgetItemID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidExpenseBudgetID, budgetItemID)
+	}
+
+	if err := checkObjectCode(s.db, assign.ObjectCode); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.Budget_Item{}).
+		Where("id = ? AND project_id IN (?)", id, s.db.Model(&models.Project{}).Select("id").Where("barangay_id = ?", barangay_ID)).
+		Update("object_code", assign.ObjectCode)
+	if result.Error != nil {
+		return fmt.Errorf("failed to assign object code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", ErrExpenseItemNotFound, id)
+	}
+	return nil
+}
+
+// ClassReport totals a fiscal year's budget items by allotment class, the
+// root their object code falls under, and by object code within each class.
+func (s *ExpenseClassService) ClassReport(barangay_ID string, fiscalYear string) (models.ExpenseClassReport, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return models.ExpenseClassReport{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+	year, err := parseFiscalYear(fiscalYear)
+	if err != nil {
+		return models.ExpenseClassReport{}, err
+	}
+
+	var totals []struct {
+		ObjectCode *string
+		Amount     float64
+	}
+	if err := s.db.Table("budget_items").
+		Select("budget_items.object_code, SUM(budget_items.amount_allocated) AS amount").
+		Joins("JOIN projects ON projects.id = budget_items.project_id AND projects.deleted_at IS NULL").
+		Where("budget_items.deleted_at IS NULL AND projects.barangay_id = ?", barangay_ID_int).
+		Where(allocatedInFiscalYear, year).
+		Group("budget_items.object_code").
+		Scan(&totals).Error; err != nil {
+		return models.ExpenseClassReport{}, fmt.Errorf("failed to total budget by object code: %w", err)
+	}
+
+	var classes []models.ExpenseClass
+	if err := s.db.Find(&classes).Error; err != nil {
+		return models.ExpenseClassReport{}, fmt.Errorf("failed to retrieve expense classes: %w", err)
+	}
+	byCode := make(map[string]models.ExpenseClass, len(classes))
+	for _, class := range classes {
+		byCode[class.Code] = class
+	}
+	root := func(code string) models.ExpenseClass {
+		class := byCode[code]
+		for class.ParentCode != nil {
+			class = byCode[*class.ParentCode]
+		}
+		return class
+	}
+
+	report := models.ExpenseClassReport{Barangay_ID: uint(barangay_ID_int), FiscalYear: year, Classes: []models.ExpenseClassTotal{}}
+	index := map[string]int{}
+	for _, total := range totals {
+		report.Total += total.Amount
+		if total.ObjectCode == nil {
+			report.Unclassified += total.Amount
+			continue
+		}
+
+		class := root(*total.ObjectCode)
+		i, ok := index[class.Code]
+		if !ok {
+			i = len(report.Classes)
+			index[class.Code] = i
+			report.Classes = append(report.Classes, models.ExpenseClassTotal{Code: class.Code, Name: class.Name})
+		}
+		report.Classes[i].Amount += total.Amount
+		report.Classes[i].Objects = append(report.Classes[i].Objects, models.ObjectCodeTotal{
+			Code:   *total.ObjectCode,
+			Name:   byCode[*total.ObjectCode].Name,
+			Amount: total.Amount,
+		})
+	}
+
+	sort.Slice(report.Classes, func(i, j int) bool {
+		a, b := report.Classes[i], report.Classes[j]
+		orderA, knownA := EXPENSE_CLASS_ORDER[a.Code]
+		orderB, knownB := EXPENSE_CLASS_ORDER[b.Code]
+		if knownA != knownB {
+			return knownA
+		}
+		if orderA != orderB {
+			return orderA < orderB
+		}
+		return a.Code < b.Code
+	})
+	for i := range report.Classes {
+		report.Classes[i].Share = report.Classes[i].Amount / report.Total * 100
+		sort.Slice(report.Classes[i].Objects, func(a, b int) bool {
+			return report.Classes[i].Objects[a].Code < report.Classes[i].Objects[b].Code
+		})
+	}
+
+	return report, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func expenseClassRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"code", "parent_code", "name", "active"}).
+		AddRow("CO", nil, "Capital Outlay", true).
+		AddRow("MOOE", nil, "Maintenance and Other Operating Expenses", true).
+		AddRow("PS", nil, "Personnel Services", true).
+		AddRow("5-01-02-990", "PS", "Other Bonuses and Allowances", true).
+		AddRow("5-02-03-010", "MOOE", "Office Supplies Expenses", true).
+		AddRow("1-07-03-010", "CO", "Road Networks", true)
+}
+
+func TestExpenseClassService_ClassReport(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewExpenseClassService(gormDB)
+
+	mock.ExpectQuery(`SELECT budget_items.object_code, SUM\(budget_items.amount_allocated\) AS amount FROM "budget_items" (.+) GROUP BY "budget_items"."object_code"`).
+		WithArgs(1, 2025).
+		WillReturnRows(sqlmock.NewRows([]string{"object_code", "amount"}).
+			AddRow("1-07-03-010", 500000.0).
+			AddRow("5-02-03-010", 100000.0).
+			AddRow("5-01-02-990", 300000.0).
+			AddRow(nil, 100000.0))
+	mock.ExpectQuery(`SELECT \* FROM "expense_classes"`).
+		WillReturnRows(expenseClassRows())
+
+	report, err := svc.ClassReport("1", "2025")
+	if err != nil {
+		t.Fatalf("ClassReport() error = %v", err)
+	}
+
+	if report.Total != 1000000 || report.Unclassified != 100000 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if len(report.Classes) != 3 || report.Classes[0].Code != "PS" || report.Classes[1].Code != "MOOE" || report.Classes[2].Code != "CO" {
+		t.Fatalf("Expected PS, MOOE, CO, got %+v", report.Classes)
+	}
+	if report.Classes[2].Amount != 500000 || report.Classes[2].Share != 50 || report.Classes[2].Objects[0].Name != "Road Networks" {
+		t.Errorf("Unexpected capital outlay total: %+v", report.Classes[2])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestCheckObjectCode(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT \* FROM "expense_classes" WHERE code = \$1`).
+		WithArgs("9-99", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"code"}))
+	if err := checkObjectCode(gormDB, "9-99"); !errors.Is(err, ErrUnknownObjectCode) {
+		t.Errorf("Expected ErrUnknownObjectCode, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "expense_classes" WHERE code = \$1`).
+		WithArgs("MOOE", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name", "active"}).AddRow("MOOE", "Maintenance and Other Operating Expenses", true))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "expense_classes" WHERE parent_code = \$1`).
+		WithArgs("MOOE").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(8))
+	if err := checkObjectCode(gormDB, "MOOE"); !errors.Is(err, ErrObjectCodeNotLeaf) {
+		t.Errorf("Expected ErrObjectCodeNotLeaf, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "expense_classes" WHERE code = \$1`).
+		WithArgs("5-02-99-990", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name", "active"}).AddRow("5-02-99-990", "Other Maintenance and Operating Expenses", false))
+	if err := checkObjectCode(gormDB, "5-02-99-990"); !errors.Is(err, ErrObjectCodeInactive) {
+		t.Errorf("Expected ErrObjectCodeInactive, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExpenseClassService_Tree(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewExpenseClassService(gormDB)
+
+	mock.ExpectQuery(`SELECT \* FROM "expense_classes" ORDER BY code`).
+		WillReturnRows(expenseClassRows())
+
+	tree, err := svc.Tree()
+	if err != nil {
+		t.Fatalf("Tree() error = %v", err)
+	}
+	if len(tree) != 3 || len(tree[2].Children) != 1 || tree[2].Children[0].Code != "5-01-02-990" || tree[2].Children[0].Children == nil {
+		t.Errorf("Unexpected tree: %+v", tree)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestExpenseClassService_UpdateExpenseClass(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewExpenseClassService(gormDB)
+
+	// a rename without active leaves the code in use
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "expense_classes" SET "name"=\$1,"updated_at"=\$2 WHERE code = \$3`).
+		WithArgs("Office Supplies", sqlmock.AnyArg(), "5-02-03-010").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.UpdateExpenseClass("5-02-03-010", models.UpdateExpenseClass{Name: "Office Supplies"}); err != nil {
+		t.Errorf("UpdateExpenseClass() error = %v", err)
+	}
+
+	retired := false
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "expense_classes" SET "active"=\$1,"name"=\$2,"updated_at"=\$3 WHERE code = \$4`).
+		WithArgs(false, "Office Supplies", sqlmock.AnyArg(), "5-02-03-010").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.UpdateExpenseClass("5-02-03-010", models.UpdateExpenseClass{Name: "Office Supplies", Active: &retired}); err != nil {
+		t.Errorf("UpdateExpenseClass() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,20 +1,59 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
 	"strconv"
+	"time"
 	"wow-bato-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidReplyID      = ValidationError("invalid reply ID format")
+	ErrReplyParentNotFound = NotFoundError("reply being answered not found")
+	ErrReplyTooDeep        = FieldValidationError("parent_ID", "replies nest at most 3 levels deep")
+
+	// a reply, a reply to it and a reply to that
+	REPLY_MAX_DEPTH = 3
+)
+
+// replies read as a conversation, oldest first
+var FEEDBACK_REPLY_LIST_SPEC = ListSpec{
+	IDColumn: "feedback_replies.id",
+	Sorts: map[string]SortField{
+		"created_at": {Column: "feedback_replies.created_at", Field: "CreatedAt"},
+	},
+	DefaultSort: "created_at",
+	TextColumns: []string{"feedback_replies.content"},
+}
+
+// columns of a ReplyResponse, only the author's name is taken from users
+// the author of feedback posted without their name replies under its alias
+const aliasedReply = "feedback_replies.user_id = feedbacks.user_id AND feedbacks.identity <> 'named'"
+
+const replyColumns = `feedback_replies.id, feedback_replies.feedback_id, feedback_replies.parent_id, feedback_replies.depth,
+	feedback_replies.content, feedback_replies.role, feedback_replies.moderation_state AS state,
+	CASE WHEN ` + aliasedReply + ` THEN 0 ELSE users.id END AS author_id,
+	CASE WHEN ` + aliasedReply + ` THEN feedbacks.author_alias ELSE users.first_name END AS author_first_name,
+	CASE WHEN ` + aliasedReply + ` THEN '' ELSE users.last_name END AS author_last_name,
+	feedback_replies.created_at, feedback_replies.edited_at`
+
+const replyJoins = "JOIN users ON users.id = feedback_replies.user_id JOIN feedbacks ON feedbacks.id = feedback_replies.feedback_id"
+
 type FeedbackReplyService struct {
-	db *gorm.DB
+	db      *gorm.DB
+	filters ContentFilters
 }
 
 func NewFeedbackReplyService(db *gorm.DB) *FeedbackReplyService {
-	return &FeedbackReplyService{db: db}
+	return &FeedbackReplyService{db: db, filters: DefaultContentFilters(ContentFilterConfigFromEnv())}
 }
 
+// CreateFeedbackReply saves a reply, holding citizen replies the content
+// filters flag for a moderator.
 func (s *FeedbackReplyService) CreateFeedbackReply(newReply models.NewFeedbackReply) error {
 	
 	feedbackID, err := strconv.Atoi(newReply.FeedbackID)
@@ -22,64 +61,223 @@ func (s *FeedbackReplyService) CreateFeedbackReply(newReply models.NewFeedbackRe
 		return err
 	}
 
+	depth := 0
+	if newReply.ParentID != nil {
+		var parent models.FeedbackReply
+		if err := s.db.Where("id = ? AND feedback_id = ?", *newReply.ParentID, feedbackID).
+			Where(fmt.Sprintf(visibleTo, "feedback_replies"), newReply.UserID).
+			First(&parent).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: ID %d", ErrReplyParentNotFound, *newReply.ParentID)
+			}
+			return err
+		}
+		if depth = parent.Depth + 1; depth >= REPLY_MAX_DEPTH {
+			return ErrReplyTooDeep
+		}
+	}
+
+	flags, err := screenPost(s.db, s.filters, newReply.Role, FilterPost{
+		ContentType: models.ContentReply,
+		UserID:      newReply.UserID,
+		Content:     newReply.Content,
+		PostedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	state, reason := initialModerationState(flags)
+
 	reply := models.FeedbackReply{
 		Content:    newReply.Content,
 		FeedbackID: uint(feedbackID),
+		ParentID:   newReply.ParentID,
+		Depth:      depth,
 		UserID:     newReply.UserID,
+		Role:       newReply.Role,
+
+		ModerationState:  state,
+		ModerationReason: reason,
+		ContentHash:      contentHash(newReply.Content),
 	}
 
-	result := s.db.Create(&reply)
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reply).Error; err != nil {
+			return err
+		}
+		if newReply.Role == models.RoleOfficial {
+			if err := markFirstResponse(tx, reply); err != nil {
+				return err
+			}
+		}
+		return saveFlags(tx, models.ContentReply, reply.ID, flags)
+	})
+}
 
-	return result.Error
+// markFirstResponse stops the response clock of feedback the first time an
+// official of its barangay answers it.
+func markFirstResponse(tx *gorm.DB, reply models.FeedbackReply) error {
+	return tx.Model(&models.Feedback{}).
+		Where("id = ? AND first_response_at IS NULL", reply.FeedbackID).
+		Where("project_id IN (SELECT projects.id FROM projects JOIN users ON users.barangay_id = projects.barangay_id WHERE users.id = ?)", reply.UserID).
+		Update("first_response_at", reply.CreatedAt).Error
 }
 
-func (s *FeedbackReplyService) GetAllReplies(feedbackID string) ([]models.FeedbackReply, error) {
+// GetAllReplies lists the published replies to a feedback and whatever the
+// viewer posted that is still awaiting moderation or hidden. Pages hold
+// replies to the feedback itself, each with its thread nested below it. A
+// reply that is hidden or withdrawn takes the replies to it along.
+func (s *FeedbackReplyService) GetAllReplies(feedbackID string, viewerID uint, params url.Values) ([]models.ReplyResponse, models.PageMeta, error) {
 
 	feedbackID_int, err := strconv.Atoi(feedbackID)
 	if err != nil {
-		return []models.FeedbackReply{}, err
+		return []models.ReplyResponse{}, models.PageMeta{}, err
+	}
+
+	query, err := ParseListQuery(params, FEEDBACK_REPLY_LIST_SPEC)
+	if err != nil {
+		return []models.ReplyResponse{}, models.PageMeta{}, err
+	}
+
+	var replies []models.ReplyResponse
+	base := s.db.Model(&models.FeedbackReply{}).
+		Where("feedback_replies.feedback_id = ? AND feedback_replies.parent_id IS NULL", feedbackID_int).
+		Where(fmt.Sprintf(visibleTo, "feedback_replies"), viewerID)
+	meta, err := ListPage(base, query, &replies, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(replyColumns).Joins(replyJoins)
+	})
+	if err != nil {
+		return []models.ReplyResponse{}, models.PageMeta{}, err
+	}
+	if replies == nil {
+		replies = []models.ReplyResponse{}
 	}
 
-	var replies []models.FeedbackReply
-	if err := s.db.Where("feedback_ID = ?", feedbackID_int).Find(&replies).Error; err != nil {
-		return []models.FeedbackReply{}, err
+	if err := s.loadThreads(replies, viewerID); err != nil {
+		return []models.ReplyResponse{}, models.PageMeta{}, err
 	}
 
-	return replies, nil
+	return replies, meta, nil
 }
 
-func (s *FeedbackReplyService) DeleteFeedbackReply(feedbackID string) error {
+// loadThreads fills in the replies below each reply one level at a time, the
+// depth limit bounds the number of queries.
+func (s *FeedbackReplyService) loadThreads(replies []models.ReplyResponse, viewerID uint) error {
 
-	feedbackID_int, err := strconv.Atoi(feedbackID)
+	level := make([]*models.ReplyResponse, len(replies))
+	for i := range replies {
+		level[i] = &replies[i]
+	}
+
+	for len(level) > 0 {
+		parents := map[uint]*models.ReplyResponse{}
+		ids := make([]uint, len(level))
+		for i, reply := range level {
+			reply.Official = reply.Role == models.RoleOfficial || reply.Role == models.RoleAdmin
+			reply.Replies = []models.ReplyResponse{}
+			parents[reply.ID] = reply
+			ids[i] = reply.ID
+		}
+		if level[0].Depth+1 >= REPLY_MAX_DEPTH {
+			break
+		}
+
+		var children []models.ReplyResponse
+		if err := s.db.Model(&models.FeedbackReply{}).
+			Select(replyColumns).
+			Joins(replyJoins).
+			Where("feedback_replies.parent_id IN ?", ids).
+			Where(fmt.Sprintf(visibleTo, "feedback_replies"), viewerID).
+			Order("feedback_replies.created_at, feedback_replies.id").
+			Scan(&children).Error; err != nil {
+			return fmt.Errorf("failed to retrieve reply threads: %w", err)
+		}
+
+		for _, child := range children {
+			parent := parents[*child.ParentID]
+			parent.Replies = append(parent.Replies, child)
+		}
+
+		// pointers are taken once every slice has stopped growing
+		level = level[:0:0]
+		for _, reply := range parents {
+			for i := range reply.Replies {
+				level = append(level, &reply.Replies[i])
+			}
+		}
+	}
+
+	return nil
+}
+
+// DeleteFeedbackReply withdraws a reply at its author's request.
+func (s *FeedbackReplyService) DeleteFeedbackReply(userID uint, replyID string) error {
+
+	replyID_int, err := strconv.Atoi(replyID)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s", ErrInvalidReplyID, replyID)
 	}
 
 	var reply models.FeedbackReply
-	if err := s.db.Where("id = ?", feedbackID_int).Delete(&reply).Error; err != nil {
+	if err := s.db.Where("id = ?", replyID_int).First(&reply).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: reply %d", ErrModeratedContentNotFound, replyID_int)
+		}
+		return err
+	}
+	if reply.UserID != userID {
+		return ErrNotContentAuthor
+	}
+
+	if err := s.db.Delete(&reply).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *FeedbackReplyService) EditFeedbackReply(replyID string, content string) error {
+// EditFeedbackReply changes the text of a reply at its author's request,
+// screening it again like a new reply.
+func (s *FeedbackReplyService) EditFeedbackReply(userID uint, replyID string, content string) error {
 
 	replyID_int, err := strconv.Atoi(replyID)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %s", ErrInvalidReplyID, replyID)
 	}
 
 	var reply models.FeedbackReply
 	if err := s.db.Where("id = ?", replyID_int).First(&reply).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: reply %d", ErrModeratedContentNotFound, replyID_int)
+		}
 		return err
 	}
-
-	if content != "" {
-		reply.Content = content
+	if reply.UserID != userID {
+		return ErrNotContentAuthor
 	}
 
-	result := s.db.Save(&reply)
+	flags, err := screenPost(s.db, s.filters, reply.Role, FilterPost{
+		ContentType: models.ContentReply,
+		ContentID:   reply.ID,
+		UserID:      reply.UserID,
+		Content:     content,
+		PostedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	state, reason := editedModerationState(reply.ModerationState, reply.ModerationReason, flags)
 
-	return result.Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&reply).Updates(map[string]interface{}{
+			"content":           content,
+			"content_hash":      contentHash(content),
+			"edited_at":         time.Now(),
+			"moderation_state":  state,
+			"moderation_reason": reason,
+		}).Error; err != nil {
+			return err
+		}
+		return saveFlags(tx, models.ContentReply, reply.ID, flags)
+	})
 }
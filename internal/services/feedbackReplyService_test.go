@@ -2,6 +2,8 @@ package services
 
 import (
 	"database/sql"
+	"errors"
+	"net/url"
 	"testing"
 	"wow-bato-backend/internal/models"
 
@@ -36,16 +38,27 @@ func TestFeedbackReplyService_CreateFeedbackReply(t *testing.T) {
 		Content:    "Test reply content",
 		FeedbackID: "1",
 		UserID:     2,
+		Role:       models.RoleCitizen,
 	}
 
 	// Setup expectations
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedback_replies" WHERE user_id = \$1 AND content_hash = \$2`).
+		WithArgs(newReply.UserID, contentHash(newReply.Content), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \(SELECT COUNT\(\*\) FROM feedbacks`).
+		WithArgs(newReply.UserID, sqlmock.AnyArg(), newReply.UserID, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO "feedback_replies"`).
 		WithArgs(
 			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), // Created, updated timestamps
 			newReply.Content,
 			uint(1), // FeedbackID as uint
+			0,       // depth of a reply to the feedback itself
 			newReply.UserID,
+			models.RoleCitizen,
+			models.ModerationPublished, // passed the content filters
+			contentHash(newReply.Content),
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
@@ -60,6 +73,67 @@ func TestFeedbackReplyService_CreateFeedbackReply(t *testing.T) {
 	}
 }
 
+func TestFeedbackReplyService_CreateFeedbackReplyOfficial(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewFeedbackReplyService(gormDB)
+
+	newReply := models.NewFeedbackReply{
+		Content:    "Naipaayos na po ang tubo ngayong umaga.",
+		FeedbackID: "1",
+		UserID:     3,
+		Role:       models.RoleOfficial,
+	}
+
+	// officials skip the content filters, their reply answers the feedback
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "feedback_replies"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`UPDATE "feedbacks" SET "first_response_at"=\$1,"updated_at"=\$2 WHERE \(id = \$3 AND first_response_at IS NULL\) AND project_id IN \(SELECT projects.id FROM projects JOIN users ON users.barangay_id = projects.barangay_id WHERE users.id = \$4\)`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), 1, 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.CreateFeedbackReply(newReply); err != nil {
+		t.Errorf("CreateFeedbackReply() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFeedbackReplyService_CreateFeedbackReplyHeld(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewFeedbackReplyService(gormDB)
+	svc.filters = ContentFilters{PhoneFilter{}}
+
+	newReply := models.NewFeedbackReply{
+		Content:    "Text me at 0917 123 4567 for the permit",
+		FeedbackID: "1",
+		UserID:     2,
+		Role:       models.RoleCitizen,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "feedback_replies" (.+) RETURNING`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, newReply.Content, uint(1), 0, uint(2), models.RoleCitizen, models.ModerationPending, contentHash(newReply.Content), models.ReasonPersonalInfo).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+	mock.ExpectQuery(`INSERT INTO "content_flags"`).
+		WithArgs(models.ContentReply, 8, "phone", models.ReasonPersonalInfo, "contains a mobile number", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := svc.CreateFeedbackReply(newReply); err != nil {
+		t.Errorf("CreateFeedbackReply() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
 func TestFeedbackReplyService_GetAllReplies(t *testing.T) {
 	var db *sql.DB
 	var mock sqlmock.Sqlmock
@@ -85,26 +159,91 @@ func TestFeedbackReplyService_GetAllReplies(t *testing.T) {
 	feedbackID := "1"
 
 	// Setup mock rows
-	rows := sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).
-		AddRow(1, "Reply 1", 1, 2).
-		AddRow(2, "Reply 2", 1, 3)
+	columns := []string{"id", "feedback_id", "parent_id", "depth", "content", "role", "state", "author_id", "author_first_name", "author_last_name"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 1, nil, 0, "Reply 1", "citizen", "published", 2, "Ana", "Santos").
+		AddRow(2, 1, nil, 0, "Reply 2", "official", "published", 3, "Ben", "Cruz")
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedback_replies" WHERE \(feedback_replies.feedback_id = \$1 AND feedback_replies.parent_id IS NULL\)`).
+		WithArgs(1, uint(2)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
 
-	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE feedback_ID = \$1`).
-		WithArgs(1).
+	mock.ExpectQuery(`SELECT feedback_replies.id, (.+) FROM "feedback_replies" JOIN users ON users.id = feedback_replies.user_id JOIN feedbacks ON feedbacks.id = feedback_replies.feedback_id WHERE (.+) ORDER BY feedback_replies.created_at ASC,feedback_replies.id ASC`).
+		WithArgs(1, uint(2), DEFAULT_PAGE_LIMIT+1).
 		WillReturnRows(rows)
 
-	replies, err := svc.GetAllReplies(feedbackID)
+	// the thread below the first reply, one level per query
+	mock.ExpectQuery(`SELECT feedback_replies.id, (.+) WHERE feedback_replies.parent_id IN \(\$1,\$2\) (.+) ORDER BY feedback_replies.created_at, feedback_replies.id`).
+		WithArgs(1, 2, uint(2)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(5, 1, 1, 1, "Reply to reply 1", "citizen", "pending", 2, "Ana", "Santos"))
+	mock.ExpectQuery(`SELECT feedback_replies.id, (.+) WHERE feedback_replies.parent_id IN \(\$1\)`).
+		WithArgs(5, uint(2)).
+		WillReturnRows(sqlmock.NewRows(columns))
+
+	replies, _, err := svc.GetAllReplies(feedbackID, 2, url.Values{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
 	if len(replies) != 2 {
-		t.Errorf("Expected 2 replies, got %d", len(replies))
+		t.Fatalf("Expected 2 replies, got %d", len(replies))
 	}
 
 	if replies[0].Content != "Reply 1" || replies[1].Content != "Reply 2" {
 		t.Errorf("Unexpected reply contents: %s, %s", replies[0].Content, replies[1].Content)
 	}
+	if replies[0].Official || !replies[1].Official {
+		t.Errorf("Expected only the second reply flagged as official")
+	}
+	if replies[1].Author.FirstName != "Ben" {
+		t.Errorf("Expected author Ben, got %+v", replies[1].Author)
+	}
+	if len(replies[0].Replies) != 1 || replies[0].Replies[0].ID != 5 || len(replies[1].Replies) != 0 {
+		t.Errorf("Unexpected threads: %+v", replies)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFeedbackReplyService_CreateNestedReply(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewFeedbackReplyService(gormDB)
+	svc.filters = nil
+
+	parentID := uint(9)
+	newReply := models.NewFeedbackReply{Content: "Salamat po", FeedbackID: "1", ParentID: &parentID, UserID: 2, Role: models.RoleCitizen}
+
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE \(id = \$1 AND feedback_id = \$2\) AND (.+) LIMIT \$4`).
+		WithArgs(9, 1, uint(2), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "feedback_id", "depth", "user_id"}).AddRow(9, 1, 2, 3))
+
+	if err := svc.CreateFeedbackReply(newReply); !errors.Is(err, ErrReplyTooDeep) {
+		t.Errorf("Expected ErrReplyTooDeep, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE \(id = \$1 AND feedback_id = \$2\)`).
+		WithArgs(9, 1, uint(2), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "feedback_id", "depth", "user_id"}))
+
+	if err := svc.CreateFeedbackReply(newReply); !errors.Is(err, ErrReplyParentNotFound) {
+		t.Errorf("Expected ErrReplyParentNotFound, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE \(id = \$1 AND feedback_id = \$2\)`).
+		WithArgs(9, 1, uint(2), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "feedback_id", "depth", "user_id"}).AddRow(9, 1, 0, 3))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "feedback_replies" (.+)"content_hash","parent_id"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Salamat po", uint(1), 1, uint(2), models.RoleCitizen, models.ModerationPublished, contentHash("Salamat po"), &parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectCommit()
+
+	if err := svc.CreateFeedbackReply(newReply); err != nil {
+		t.Errorf("CreateFeedbackReply() error = %v", err)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
@@ -135,18 +274,29 @@ func TestFeedbackReplyService_DeleteFeedbackReply(t *testing.T) {
 
 	replyID := "3"
 
-	// Mock the delete operation
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1`).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).AddRow(3, "Reply", 1, 2))
 	mock.ExpectBegin()
-	mock.ExpectExec(`DELETE FROM "feedback_replies" WHERE id = \$1`).
-		WithArgs(3).
+	mock.ExpectExec(`UPDATE "feedback_replies" SET "deleted_at"=\$1 WHERE "feedback_replies"."id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), 3).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err = svc.DeleteFeedbackReply(replyID)
+	err = svc.DeleteFeedbackReply(2, replyID)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	// only the author may withdraw a reply
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1`).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).AddRow(3, "Reply", 1, 2))
+
+	if err := svc.DeleteFeedbackReply(5, replyID); !errors.Is(err, ErrNotContentAuthor) {
+		t.Errorf("Expected ErrNotContentAuthor, got %v", err)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
@@ -177,25 +327,59 @@ func TestFeedbackReplyService_EditFeedbackReply(t *testing.T) {
 	replyID := "4"
 	newContent := "Updated reply content"
 
+	replyColumns := []string{"id", "content", "feedback_id", "user_id", "role", "moderation_state"}
+
 	// Mock finding the reply
-	findRows := sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).
-		AddRow(4, "Old content", 1, 2)
-	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1 ORDER BY "feedback_replies"."id" LIMIT 1`).
-		WithArgs(4).
+	findRows := sqlmock.NewRows(replyColumns).
+		AddRow(4, "Old content", 1, 2, models.RoleCitizen, models.ModerationPublished)
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1 (.+) ORDER BY "feedback_replies"."id" LIMIT \$2`).
+		WithArgs(4, 1).
 		WillReturnRows(findRows)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedback_replies" WHERE (.+) AND id <> \$4`).
+		WithArgs(2, contentHash(newContent), sqlmock.AnyArg(), 4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 
-	// Mock the update (save) operation
+	// Mock the update operation
 	mock.ExpectBegin()
-	mock.ExpectExec(`UPDATE "feedback_replies" SET (.+) WHERE "id" = \$1`).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), newContent, 4).
+	mock.ExpectExec(`UPDATE "feedback_replies" SET "content"=\$1,"content_hash"=\$2,"edited_at"=\$3,"moderation_reason"=\$4,"moderation_state"=\$5,"updated_at"=\$6 WHERE (.+)"id" = \$7`).
+		WithArgs(newContent, contentHash(newContent), sqlmock.AnyArg(), nil, models.ModerationPublished, sqlmock.AnyArg(), 4).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err = svc.EditFeedbackReply(replyID, newContent)
+	err = svc.EditFeedbackReply(2, replyID, newContent)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	// only the author may edit a reply
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1`).
+		WithArgs(4, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "feedback_id", "user_id"}).AddRow(4, "Old content", 1, 2))
+
+	if err := svc.EditFeedbackReply(7, replyID, newContent); !errors.Is(err, ErrNotContentAuthor) {
+		t.Errorf("Expected ErrNotContentAuthor, got %v", err)
+	}
+
+	// a published reply edited to give out a mobile number waits for a moderator
+	flagged := "Text me at 0917 123 4567"
+	mock.ExpectQuery(`SELECT \* FROM "feedback_replies" WHERE id = \$1`).
+		WithArgs(4, 1).
+		WillReturnRows(sqlmock.NewRows(replyColumns).AddRow(4, "Old content", 1, 2, models.RoleCitizen, models.ModerationPublished))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedback_replies"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "feedback_replies" SET (.+)"moderation_reason"=\$4,"moderation_state"=\$5`).
+		WithArgs(flagged, contentHash(flagged), sqlmock.AnyArg(), models.ReasonPersonalInfo, models.ModerationPending, sqlmock.AnyArg(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "content_flags"`).
+		WithArgs(models.ContentReply, 4, "phone", models.ReasonPersonalInfo, "contains a mobile number", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := svc.EditFeedbackReply(2, replyID, flagged); err != nil {
+		t.Errorf("EditFeedbackReply() error = %v", err)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
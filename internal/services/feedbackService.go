@@ -1,54 +1,198 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 	"wow-bato-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
+var (
+	ErrInvalidFeedbackID      = ValidationError("invalid feedback ID format")
+	ErrFeedbackNotFound       = NotFoundError("feedback not found")
+	ErrInvalidTag             = FieldValidationError("tags", "tags may only contain letters, numbers and dashes")
+	ErrNotAComplaint          = ConflictError("only complaints are tracked as issues")
+	ErrIssueTransition        = ConflictError("complaint cannot move to this status")
+	ErrResolutionNoteRequired = FieldValidationError("note", "a resolution note is required to resolve a complaint")
+	ErrReactOwnFeedback       = ForbiddenError("you cannot react to your own feedback")
+	ErrReactionNotFound       = NotFoundError("you have not reacted to this feedback")
+
+	// reactions that endorse the concern and count towards its support
+	SUPPORT_REACTIONS = []string{models.ReactionUpvote, models.ReactionSameIssue}
+
+	FEEDBACK_TYPES  = []string{models.FeedbackComplaint, models.FeedbackSuggestion, models.FeedbackQuestion, models.FeedbackCommendation}
+	ISSUE_STATUSES  = []string{models.IssueOpen, models.IssueAcknowledged, models.IssueResolved}
+	tagPattern      = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	tagSeparators   = regexp.MustCompile(`[\s_]+`)
+	issueNextStatus = map[string][]string{
+		models.IssueOpen:         {models.IssueAcknowledged, models.IssueResolved},
+		models.IssueAcknowledged: {models.IssueResolved},
+	}
+)
+
+var FEEDBACK_LIST_SPEC = ListSpec{
+	Sorts: map[string]SortField{
+		"created_at": {Column: "created_at", Field: "CreatedAt"},
+		"support":    {Column: "support_count", Field: "SupportCount"},
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]FilterField{
+		"role":       {Column: "role", Kind: FilterEnum, Values: []string{models.RoleCitizen, models.RoleOfficial, models.RoleAdmin}},
+		"type":       {Column: "type", Kind: FilterEnum, Values: FEEDBACK_TYPES},
+		"status":     {Column: "issue_status", Kind: FilterEnum, Values: ISSUE_STATUSES},
+		"tag":        {Column: "id IN (SELECT feedback_id FROM feedback_tags WHERE tag IN ?)", Kind: FilterMatch},
+		"created_at": {Column: "created_at", Kind: FilterDateRange},
+	},
+	TextColumns: []string{"content"},
+}
+
+// normalizeTags lowercases tags and joins words with dashes, so "Street
+// Lights" and "street-lights" are one tag.
+func normalizeTags(tags []string) ([]models.FeedbackTag, error) {
+	var normalized []models.FeedbackTag
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = tagSeparators.ReplaceAllString(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidTag, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, models.FeedbackTag{Tag: tag})
+		}
+	}
+	return normalized, nil
+}
+
 type FeedbackService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	filters  ContentFilters
+	aliasKey []byte
+	lexicon  *Lexicon
 }
 
 func NewFeedbackService(db *gorm.DB) *FeedbackService {
-	return &FeedbackService{db: db}
+	aliasKey, err := AliasKeyFromEnv()
+	if err != nil {
+		aliasKey = randomAliasKey()
+	}
+	return &FeedbackService{db: db, filters: DefaultContentFilters(ContentFilterConfigFromEnv()), aliasKey: aliasKey, lexicon: LexiconFromEnv()}
 }
 
+// CreateFeedback saves feedback, holding citizen feedback the content
+// filters flag for a moderator. Complaints open as issues. Residents may
+// post without their name, the author is still stored. Its sentiment and
+// topics are scored as it is saved.
 func (s *FeedbackService) CreateFeedback(newFeedback models.CreateFeedback) error {
 
-	feedback := models.Feedback{
-		Content:   newFeedback.Content,
-		UserID:    newFeedback.UserID,
-		Role:      newFeedback.Role,
-		ProjectID: newFeedback.ProjectID,
+	identity := newFeedback.Identity
+	if identity == "" {
+		identity = models.IdentityNamed
+	}
+	if identity != models.IdentityNamed && newFeedback.Role != models.RoleCitizen {
+		return ErrOfficialIdentity
+	}
+
+	flags, err := screenPost(s.db, s.filters, newFeedback.Role, FilterPost{
+		ContentType: models.ContentFeedback,
+		UserID:      newFeedback.UserID,
+		Content:     newFeedback.Content,
+		PostedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	state, reason := initialModerationState(flags)
+
+	tags, err := normalizeTags(newFeedback.Tags)
+	if err != nil {
+		return err
+	}
+
+	feedbackType := newFeedback.Type
+	if feedbackType == "" {
+		feedbackType = models.FeedbackSuggestion
+	}
+	var issueStatus *string
+	if feedbackType == models.FeedbackComplaint {
+		open := models.IssueOpen
+		issueStatus = &open
 	}
 
-	result := s.db.Create(&feedback)
+	analysis := s.lexicon.Analyze(newFeedback.Content)
 
-	return result.Error
+	feedback := models.Feedback{
+		Content:     newFeedback.Content,
+		Type:        feedbackType,
+		Tags:        tags,
+		IssueStatus: issueStatus,
+		UserID:      newFeedback.UserID,
+		Role:        newFeedback.Role,
+		ProjectID:   newFeedback.ProjectID,
+		Identity:    identity,
+		AuthorAlias: authorAlias(s.aliasKey, identity, newFeedback.UserID, newFeedback.ProjectID),
+
+		Sentiment:      analysis.Sentiment,
+		SentimentScore: analysis.Score,
+		Topics:         analysis.feedbackTopics(),
+
+		ModerationState:  state,
+		ModerationReason: reason,
+		ContentHash:      contentHash(newFeedback.Content),
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&feedback).Error; err != nil {
+			return err
+		}
+		return saveFlags(tx, models.ContentFeedback, feedback.ID, flags)
+	})
 }
 
-func (s *FeedbackService) GetAllFeedback(projectID string) ([]models.GetAllFeedbacks, error) {
+// GetAllFeedback lists the published feedback of a project and whatever the
+// viewer posted that is still awaiting moderation or hidden.
+func (s *FeedbackService) GetAllFeedback(projectID string, viewerID uint, params url.Values) ([]models.GetAllFeedbacks, models.PageMeta, error) {
 
 	projectid_int, err := strconv.Atoi(projectID)
 	if err != nil {
-		return []models.GetAllFeedbacks{}, err
+		return []models.GetAllFeedbacks{}, models.PageMeta{}, err
+	}
+
+	query, err := ParseListQuery(params, FEEDBACK_LIST_SPEC)
+	if err != nil {
+		return []models.GetAllFeedbacks{}, models.PageMeta{}, err
 	}
 
 	var feedbacks []models.GetAllFeedbacks
-	if err := s.db.Model(&models.Feedback{}).Where("project_id = ?", projectid_int).Select("id, content, role, project_id, user_id").Scan(&feedbacks).Error; err != nil {
-		return []models.GetAllFeedbacks{}, err
+	meta, err := ListPage(s.db.Model(&models.Feedback{}).Where("project_id = ?", projectid_int).Where(fmt.Sprintf(visibleTo, "feedbacks"), viewerID), query, &feedbacks, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id, content, role, project_id, user_id, created_at, type, issue_status, resolution_note, acknowledged_at, resolved_at, support_count, identity, author_alias")
+	})
+	if err != nil {
+		return []models.GetAllFeedbacks{}, models.PageMeta{}, err
 	}
 
+	// authors posting without their name are never looked up
 	var user_id_list []uint
-	for _, feedback := range feedbacks {
+	for i, feedback := range feedbacks {
+		feedbacks[i].Mine = feedback.UserID == viewerID
+		if feedback.Identity != models.IdentityNamed {
+			feedbacks[i].UserID = 0
+			feedbacks[i].FirstName = feedback.AuthorAlias
+			continue
+		}
 		user_id_list = append(user_id_list, feedback.UserID)
 	}
 
 	var users []models.FeedbackUser
 	if err := s.db.Model(&models.User{}).Where("id IN (?)", user_id_list).Select("id, first_name, last_name").Scan(&users).Error; err != nil {
-		return []models.GetAllFeedbacks{}, err
+		return []models.GetAllFeedbacks{}, models.PageMeta{}, err
 	}
 
 	for i, feedback := range feedbacks {
@@ -60,10 +204,224 @@ func (s *FeedbackService) GetAllFeedback(projectID string) ([]models.GetAllFeedb
 		}
 	}
 
-	return feedbacks, nil
+	if err := s.loadTags(feedbacks); err != nil {
+		return []models.GetAllFeedbacks{}, models.PageMeta{}, err
+	}
+	if err := s.loadReactions(feedbacks, viewerID); err != nil {
+		return []models.GetAllFeedbacks{}, models.PageMeta{}, err
+	}
+
+	return feedbacks, meta, nil
+}
+
+func feedbackIDs(feedbacks []models.GetAllFeedbacks) []uint {
+	ids := make([]uint, len(feedbacks))
+	for i, feedback := range feedbacks {
+		ids[i] = feedback.ID
+	}
+	return ids
 }
 
-func (s *FeedbackService) EditFeedback(feedbackID string, editedFeedback models.NewFeedback) error {
+func (s *FeedbackService) loadTags(feedbacks []models.GetAllFeedbacks) error {
+	if len(feedbacks) == 0 {
+		return nil
+	}
+	ids := feedbackIDs(feedbacks)
+
+	var tags []models.FeedbackTag
+	if err := s.db.Where("feedback_id IN ?", ids).Order("tag").Find(&tags).Error; err != nil {
+		return fmt.Errorf("failed to retrieve feedback tags: %w", err)
+	}
+
+	byFeedback := map[uint][]string{}
+	for _, tag := range tags {
+		byFeedback[tag.FeedbackID] = append(byFeedback[tag.FeedbackID], tag.Tag)
+	}
+	for i := range feedbacks {
+		feedbacks[i].Tags = byFeedback[feedbacks[i].ID]
+		if feedbacks[i].Tags == nil {
+			feedbacks[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// loadReactions counts the reactions to each feedback and marks the one
+// the viewer gave.
+func (s *FeedbackService) loadReactions(feedbacks []models.GetAllFeedbacks, viewerID uint) error {
+	if len(feedbacks) == 0 {
+		return nil
+	}
+
+	var counts []struct {
+		FeedbackID uint
+		Reaction   string
+		Count      int64
+		Mine       bool
+	}
+	if err := s.db.Model(&models.FeedbackReaction{}).
+		Select("feedback_id, reaction, COUNT(*) AS count, BOOL_OR(user_id = ?) AS mine", viewerID).
+		Where("feedback_id IN ?", feedbackIDs(feedbacks)).
+		Group("feedback_id, reaction").
+		Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count reactions: %w", err)
+	}
+
+	index := map[uint]int{}
+	for i := range feedbacks {
+		index[feedbacks[i].ID] = i
+		feedbacks[i].Reactions = map[string]int64{}
+	}
+	for _, count := range counts {
+		feedback := &feedbacks[index[count.FeedbackID]]
+		feedback.Reactions[count.Reaction] = count.Count
+		if count.Mine {
+			reaction := count.Reaction
+			feedback.MyReaction = &reaction
+		}
+	}
+	return nil
+}
+
+func supportWeight(reaction string) int {
+	if slices.Contains(SUPPORT_REACTIONS, reaction) {
+		return 1
+	}
+	return 0
+}
+
+// adjustSupport keeps support_count in step with the reactions so feedback
+// can be sorted by it.
+func adjustSupport(tx *gorm.DB, feedbackID uint, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	return tx.Model(&models.Feedback{}).
+		Where("id = ?", feedbackID).
+		UpdateColumn("support_count", gorm.Expr("support_count + ?", delta)).Error
+}
+
+// React records the resident's reaction to published feedback of someone
+// else, replacing the one they gave before.
+func (s *FeedbackService) React(userID uint, feedbackID string, reaction string) error {
+
+	feedbackID_int, err := strconv.ParseUint(feedbackID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFeedbackID, feedbackID)
+	}
+	id := uint(feedbackID_int)
+
+	var feedback models.Feedback
+	if err := s.db.Select("id, user_id").
+		Where("id = ? AND moderation_state = ?", id, models.ModerationPublished).
+		Take(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: ID %d", ErrFeedbackNotFound, id)
+		}
+		return err
+	}
+	if feedback.UserID == userID {
+		return ErrReactOwnFeedback
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.FeedbackReaction
+		err := tx.Where("feedback_id = ? AND user_id = ?", id, userID).Take(&existing).Error
+		delta := supportWeight(reaction)
+
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := tx.Create(&models.FeedbackReaction{FeedbackID: id, UserID: userID, Reaction: reaction}).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case existing.Reaction == reaction:
+			return nil
+		default:
+			delta -= supportWeight(existing.Reaction)
+			if err := tx.Model(&existing).Update("reaction", reaction).Error; err != nil {
+				return err
+			}
+		}
+
+		return adjustSupport(tx, id, delta)
+	})
+}
+
+// RemoveReaction takes back the resident's reaction to feedback.
+func (s *FeedbackService) RemoveReaction(userID uint, feedbackID string) error {
+
+	feedbackID_int, err := strconv.ParseUint(feedbackID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFeedbackID, feedbackID)
+	}
+	id := uint(feedbackID_int)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.FeedbackReaction
+		if err := tx.Where("feedback_id = ? AND user_id = ?", id, userID).Take(&existing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReactionNotFound
+			}
+			return err
+		}
+
+		if err := tx.Where("feedback_id = ? AND user_id = ?", id, userID).Delete(&models.FeedbackReaction{}).Error; err != nil {
+			return err
+		}
+		return adjustSupport(tx, id, -supportWeight(existing.Reaction))
+	})
+}
+
+// UpdateIssue moves a complaint on a project of the official's barangay
+// forward, open to acknowledged to resolved. Resolving takes a note telling
+// the citizen what was done.
+func (s *FeedbackService) UpdateIssue(officialID uint, barangay_ID uint, feedbackID string, update models.UpdateIssue) error {
+
+	feedbackID_int, err := strconv.Atoi(feedbackID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidFeedbackID, feedbackID)
+	}
+
+	var feedback models.Feedback
+	if err := s.db.Joins("JOIN projects ON projects.id = feedbacks.project_id").
+		Where("feedbacks.id = ? AND projects.barangay_id = ?", feedbackID_int, barangay_ID).
+		First(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: ID %d", ErrFeedbackNotFound, feedbackID_int)
+		}
+		return err
+	}
+	if feedback.Type != models.FeedbackComplaint || feedback.IssueStatus == nil {
+		return ErrNotAComplaint
+	}
+	if !slices.Contains(issueNextStatus[*feedback.IssueStatus], update.Status) {
+		return fmt.Errorf("%w: %s to %s", ErrIssueTransition, *feedback.IssueStatus, update.Status)
+	}
+
+	now := time.Now()
+	changes := map[string]interface{}{"issue_status": update.Status}
+	if feedback.AcknowledgedAt == nil {
+		changes["acknowledged_at"] = now
+		changes["acknowledged_by_id"] = officialID
+	}
+	if update.Status == models.IssueResolved {
+		note := strings.TrimSpace(update.Note)
+		if note == "" {
+			return ErrResolutionNoteRequired
+		}
+		changes["resolution_note"] = note
+		changes["resolved_at"] = now
+		changes["resolved_by_id"] = officialID
+	}
+
+	return s.db.Model(&feedback).Updates(changes).Error
+}
+
+// EditFeedback lets the author reword their feedback, screening and scoring
+// it again. Published feedback the filters flag waits for a moderator.
+func (s *FeedbackService) EditFeedback(userID uint, feedbackID string, editedFeedback models.NewFeedback) error {
 
 	feedbackID_int, err := strconv.Atoi(feedbackID)
 	if err != nil {
@@ -72,17 +430,99 @@ func (s *FeedbackService) EditFeedback(feedbackID string, editedFeedback models.
 
 	var feedback models.Feedback
 	if err := s.db.Where("id = ?", feedbackID_int).First(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: feedback %d", ErrModeratedContentNotFound, feedbackID_int)
+		}
 		return err
 	}
+	if feedback.UserID != userID {
+		return ErrNotContentAuthor
+	}
 
-	feedback.Content = editedFeedback.Content
+	flags, err := screenPost(s.db, s.filters, feedback.Role, FilterPost{
+		ContentType: models.ContentFeedback,
+		ContentID:   feedback.ID,
+		UserID:      feedback.UserID,
+		Content:     editedFeedback.Content,
+		PostedAt:    time.Now(),
+	})
+	if err != nil {
+		return err
+	}
 
-	result := s.db.Save(&feedback)
+	state, reason := editedModerationState(feedback.ModerationState, feedback.ModerationReason, flags)
+	analysis := s.lexicon.Analyze(editedFeedback.Content)
 
-	return result.Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&feedback).Updates(map[string]interface{}{
+			"content":           editedFeedback.Content,
+			"content_hash":      contentHash(editedFeedback.Content),
+			"moderation_state":  state,
+			"moderation_reason": reason,
+			"sentiment":         analysis.Sentiment,
+			"sentiment_score":   analysis.Score,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("feedback_id = ?", feedback.ID).Delete(&models.FeedbackTopic{}).Error; err != nil {
+			return err
+		}
+		if topics := analysis.feedbackTopics(); len(topics) > 0 {
+			for i := range topics {
+				topics[i].FeedbackID = feedback.ID
+			}
+			if err := tx.Create(&topics).Error; err != nil {
+				return err
+			}
+		}
+		return saveFlags(tx, models.ContentFeedback, feedback.ID, flags)
+	})
 }
 
-func (s *FeedbackService) DeleteFeedback(feedbackID string) error {
+// Reanalyze scores feedback again with the current lexicon, all of it or
+// only feedback never analyzed, and returns how many it scored.
+func (s *FeedbackService) Reanalyze(all bool) (int, error) {
+	query := s.db.Select("id, content")
+	if !all {
+		query = query.Where("sentiment = ''")
+	}
+
+	var feedbacks []models.Feedback
+	scored := 0
+	result := query.FindInBatches(&feedbacks, 200, func(_ *gorm.DB, _ int) error {
+		return s.db.Transaction(func(tx *gorm.DB) error {
+			for _, feedback := range feedbacks {
+				analysis := s.lexicon.Analyze(feedback.Content)
+				if err := tx.Model(&models.Feedback{}).Where("id = ?", feedback.ID).
+					Updates(map[string]interface{}{"sentiment": analysis.Sentiment, "sentiment_score": analysis.Score}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("feedback_id = ?", feedback.ID).Delete(&models.FeedbackTopic{}).Error; err != nil {
+					return err
+				}
+				if topics := analysis.feedbackTopics(); len(topics) > 0 {
+					for i := range topics {
+						topics[i].FeedbackID = feedback.ID
+					}
+					if err := tx.Create(&topics).Error; err != nil {
+						return err
+					}
+				}
+				scored++
+			}
+			return nil
+		})
+	})
+	if result.Error != nil {
+		return scored, fmt.Errorf("failed to analyze feedback: %w", result.Error)
+	}
+
+	return scored, nil
+}
+
+// DeleteFeedback withdraws feedback at its author's request. Moderators hide
+// or remove feedback instead so the decision stays on record.
+func (s *FeedbackService) DeleteFeedback(userID uint, feedbackID string) error {
 
 	feedbackID_int, err := strconv.Atoi(feedbackID)
 	if err != nil {
@@ -90,7 +530,17 @@ func (s *FeedbackService) DeleteFeedback(feedbackID string) error {
 	}
 
 	var feedback models.Feedback
-	if err := s.db.Where("id = ?", feedbackID_int).Delete(&feedback).Error; err != nil {
+	if err := s.db.Where("id = ?", feedbackID_int).First(&feedback).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: feedback %d", ErrModeratedContentNotFound, feedbackID_int)
+		}
+		return err
+	}
+	if feedback.UserID != userID {
+		return ErrNotContentAuthor
+	}
+
+	if err := s.db.Delete(&feedback).Error; err != nil {
 		return err
 	}
 
@@ -2,7 +2,11 @@ package services
 
 import (
 	"database/sql"
+	"errors"
+	"net/url"
+	"reflect"
 	"testing"
+	"time"
 	"wow-bato-backend/internal/models"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -95,12 +99,16 @@ func TestFeedbackService_GetAllFeedback(t *testing.T) {
 	projectIDInt := 1
 
 	// Mock feedbacks returned by the first query
-	feedbackRows := sqlmock.NewRows([]string{"id", "content", "role", "project_id", "user_id"}).
-		AddRow(1, "Feedback 1", "user", projectIDInt, 10).
-		AddRow(2, "Feedback 2", "admin", projectIDInt, 20)
+	feedbackRows := sqlmock.NewRows([]string{"id", "content", "role", "project_id", "user_id", "created_at", "identity", "author_alias"}).
+		AddRow(1, "Feedback 1", "user", projectIDInt, 10, time.Now(), models.IdentityNamed, "").
+		AddRow(2, "Feedback 2", "admin", projectIDInt, 20, time.Now(), models.IdentityNamed, "")
 
-	mock.ExpectQuery(`SELECT id, content, role, project_id, user_id FROM "feedbacks" WHERE project_id = \$1`).
-		WithArgs(projectIDInt).
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE project_id = \$1`).
+		WithArgs(projectIDInt, uint(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	mock.ExpectQuery(`SELECT id, content, role, project_id, user_id, created_at, (.+) FROM "feedbacks" WHERE project_id = \$1`).
+		WithArgs(projectIDInt, uint(10), DEFAULT_PAGE_LIMIT+1).
 		WillReturnRows(feedbackRows)
 
 	// Mock users returned by the second query
@@ -112,8 +120,17 @@ func TestFeedbackService_GetAllFeedback(t *testing.T) {
 		WithArgs(10, 20).
 		WillReturnRows(userRows)
 
+	mock.ExpectQuery(`SELECT \* FROM "feedback_tags" WHERE feedback_id IN \(\$1,\$2\) ORDER BY tag`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "tag"}).AddRow(1, "drainage").AddRow(1, "purok-3"))
+	mock.ExpectQuery(`SELECT feedback_id, reaction, COUNT\(\*\) AS count, BOOL_OR\(user_id = \$1\) AS mine FROM "feedback_reactions" WHERE feedback_id IN \(\$2,\$3\) GROUP BY feedback_id, reaction`).
+		WithArgs(uint(10), 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "reaction", "count", "mine"}).
+			AddRow(2, models.ReactionUpvote, 4, false).
+			AddRow(2, models.ReactionSameIssue, 2, true))
+
 	// Call the method
-	feedbacks, err := svc.GetAllFeedback(projectID)
+	feedbacks, _, err := svc.GetAllFeedback(projectID, 10, url.Values{})
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
@@ -128,6 +145,15 @@ func TestFeedbackService_GetAllFeedback(t *testing.T) {
 	if feedbacks[1].FirstName != "Jane" || feedbacks[1].LastName != "Smith" {
 		t.Errorf("Expected second feedback user to be Jane Smith, got %s %s", feedbacks[1].FirstName, feedbacks[1].LastName)
 	}
+	if !reflect.DeepEqual(feedbacks[0].Tags, []string{"drainage", "purok-3"}) || len(feedbacks[1].Tags) != 0 {
+		t.Errorf("Expected tags [drainage purok-3] and [], got %v and %v", feedbacks[0].Tags, feedbacks[1].Tags)
+	}
+	if feedbacks[1].Reactions[models.ReactionUpvote] != 4 || feedbacks[1].MyReaction == nil || *feedbacks[1].MyReaction != models.ReactionSameIssue {
+		t.Errorf("Expected 4 upvotes and the viewer's same_issue, got %v and %v", feedbacks[1].Reactions, feedbacks[1].MyReaction)
+	}
+	if len(feedbacks[0].Reactions) != 0 || feedbacks[0].MyReaction != nil {
+		t.Errorf("Expected no reactions, got %v", feedbacks[0].Reactions)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
@@ -159,29 +185,66 @@ func TestFeedbackService_EditFeedback(t *testing.T) {
 	feedbackID := "5"
 	feedbackIDInt := 5
 
-	// Mock finding the feedback
-	findRows := sqlmock.NewRows([]string{"id", "content", "user_id", "role", "project_id"}).
-		AddRow(feedbackIDInt, "Old content", 1, "user", 1)
-	mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1 ORDER BY "feedbacks"."id" LIMIT 1`).
-		WithArgs(feedbackIDInt).
-		WillReturnRows(findRows)
-
-	// Mock the update (save) operation
-	mock.ExpectBegin()
-	mock.ExpectExec(`UPDATE "feedbacks" SET (.+) WHERE "id" = \$1`).
-		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "New content", feedbackIDInt).
-		WillReturnResult(sqlmock.NewResult(0, 1))
-	mock.ExpectCommit()
+	findFeedback := func() {
+		mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1 AND "feedbacks"."deleted_at" IS NULL ORDER BY "feedbacks"."id" LIMIT \$2`).
+			WithArgs(feedbackIDInt, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "content", "user_id", "role", "project_id", "moderation_state"}).
+				AddRow(feedbackIDInt, "Old content", 1, models.RoleCitizen, 1, models.ModerationPublished))
+	}
+	notDuplicate := func(content string) {
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE (.+) AND id <> \$4`).
+			WithArgs(1, contentHash(content), sqlmock.AnyArg(), feedbackIDInt).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	}
 
 	editedFeedback := models.NewFeedback{
 		Content: "New content",
 	}
 
-	err = svc.EditFeedback(feedbackID, editedFeedback)
+	// only the author can reword feedback
+	findFeedback()
+	if err := svc.EditFeedback(2, feedbackID, editedFeedback); !errors.Is(err, ErrNotContentAuthor) {
+		t.Errorf("Expected ErrNotContentAuthor, got %v", err)
+	}
+
+	update := `UPDATE "feedbacks" SET "content"=\$1,"content_hash"=\$2,"moderation_reason"=\$3,"moderation_state"=\$4,"sentiment"=\$5,"sentiment_score"=\$6,"updated_at"=\$7 WHERE "feedbacks"."deleted_at" IS NULL AND "id" = \$8`
+
+	findFeedback()
+	notDuplicate("New content")
+	mock.ExpectBegin()
+	mock.ExpectExec(update).
+		WithArgs("New content", contentHash("New content"), nil, models.ModerationPublished, models.SentimentNeutral, 0.0, sqlmock.AnyArg(), feedbackIDInt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "feedback_topics" WHERE feedback_id = \$1`).
+		WithArgs(feedbackIDInt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err = svc.EditFeedback(1, feedbackID, editedFeedback)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	// published feedback edited to give out a mobile number waits for a moderator
+	flagged := models.NewFeedback{Content: "Text me at 0917 123 4567"}
+	findFeedback()
+	notDuplicate(flagged.Content)
+	mock.ExpectBegin()
+	mock.ExpectExec(update).
+		WithArgs(flagged.Content, contentHash(flagged.Content), models.ReasonPersonalInfo, models.ModerationPending, models.SentimentNeutral, 0.0, sqlmock.AnyArg(), feedbackIDInt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM "feedback_topics" WHERE feedback_id = \$1`).
+		WithArgs(feedbackIDInt).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO "content_flags"`).
+		WithArgs(models.ContentFeedback, feedbackIDInt, "phone", models.ReasonPersonalInfo, "contains a mobile number", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := svc.EditFeedback(1, feedbackID, flagged); err != nil {
+		t.Errorf("EditFeedback() error = %v", err)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
@@ -212,18 +275,199 @@ func TestFeedbackService_DeleteFeedback(t *testing.T) {
 	feedbackID := "7"
 	feedbackIDInt := 7
 
-	// Mock the delete operation
+	mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1`).
+		WithArgs(feedbackIDInt, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "user_id", "role", "project_id"}).AddRow(feedbackIDInt, "Feedback", 1, "citizen", 1))
 	mock.ExpectBegin()
-	mock.ExpectExec(`DELETE FROM "feedbacks" WHERE id = \$1`).
-		WithArgs(feedbackIDInt).
+	mock.ExpectExec(`UPDATE "feedbacks" SET "deleted_at"=\$1 WHERE "feedbacks"."id" = \$2`).
+		WithArgs(sqlmock.AnyArg(), feedbackIDInt).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 	mock.ExpectCommit()
 
-	err = svc.DeleteFeedback(feedbackID)
+	err = svc.DeleteFeedback(1, feedbackID)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	// moderators hide feedback, they do not delete it
+	mock.ExpectQuery(`SELECT \* FROM "feedbacks" WHERE id = \$1`).
+		WithArgs(feedbackIDInt, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "user_id", "role", "project_id"}).AddRow(feedbackIDInt, "Feedback", 1, "citizen", 1))
+
+	if err := svc.DeleteFeedback(2, feedbackID); !errors.Is(err, ErrNotContentAuthor) {
+		t.Errorf("Expected ErrNotContentAuthor, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := normalizeTags([]string{" Street Lights", "street_lights", "Purok-3"})
+	if err != nil {
+		t.Fatalf("normalizeTags() error = %v", err)
+	}
+	if !reflect.DeepEqual(tags, []models.FeedbackTag{{Tag: "street-lights"}, {Tag: "purok-3"}}) {
+		t.Errorf("normalizeTags() = %v", tags)
+	}
+
+	for _, tag := range []string{"", "#baha", "-road"} {
+		if _, err := normalizeTags([]string{tag}); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("normalizeTags(%q) error = %v, want ErrInvalidTag", tag, err)
+		}
+	}
+}
+
+func TestFeedbackService_UpdateIssue(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewFeedbackService(gormDB)
+
+	issueColumns := []string{"id", "type", "issue_status", "acknowledged_at"}
+	expectFeedback := func(feedbackType string, status interface{}, acknowledgedAt interface{}) {
+		mock.ExpectQuery(`SELECT "feedbacks"."id",(.+) FROM "feedbacks" JOIN projects ON projects.id = feedbacks.project_id WHERE \(feedbacks.id = \$1 AND projects.barangay_id = \$2\)`).
+			WithArgs(7, 1, 1).
+			WillReturnRows(sqlmock.NewRows(issueColumns).AddRow(7, feedbackType, status, acknowledgedAt))
+	}
+	resolve := models.UpdateIssue{Status: models.IssueResolved, Note: "Drainage cleared by the barangay tanod."}
+
+	expectFeedback(models.FeedbackSuggestion, nil, nil)
+	if err := svc.UpdateIssue(3, 1, "7", resolve); !errors.Is(err, ErrNotAComplaint) {
+		t.Errorf("Expected ErrNotAComplaint, got %v", err)
+	}
+
+	expectFeedback(models.FeedbackComplaint, models.IssueResolved, time.Now())
+	if err := svc.UpdateIssue(3, 1, "7", models.UpdateIssue{Status: models.IssueAcknowledged}); !errors.Is(err, ErrIssueTransition) {
+		t.Errorf("Expected ErrIssueTransition, got %v", err)
+	}
+
+	expectFeedback(models.FeedbackComplaint, models.IssueOpen, nil)
+	if err := svc.UpdateIssue(3, 1, "7", models.UpdateIssue{Status: models.IssueResolved, Note: "  "}); !errors.Is(err, ErrResolutionNoteRequired) {
+		t.Errorf("Expected ErrResolutionNoteRequired, got %v", err)
+	}
+
+	// resolving an open complaint acknowledges it too
+	expectFeedback(models.FeedbackComplaint, models.IssueOpen, nil)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "feedbacks" SET "acknowledged_at"=\$1,"acknowledged_by_id"=\$2,"issue_status"=\$3,"resolution_note"=\$4,"resolved_at"=\$5,"resolved_by_id"=\$6,"updated_at"=\$7 WHERE "feedbacks"."deleted_at" IS NULL AND "id" = \$8`).
+		WithArgs(sqlmock.AnyArg(), 3, models.IssueResolved, resolve.Note, sqlmock.AnyArg(), 3, sqlmock.AnyArg(), 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.UpdateIssue(3, 1, "7", resolve); err != nil {
+		t.Errorf("UpdateIssue() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFeedbackService_React(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewFeedbackService(gormDB)
+
+	expectFeedback := func(authorID uint) {
+		mock.ExpectQuery(`SELECT id, user_id FROM "feedbacks" WHERE \(id = \$1 AND moderation_state = \$2\) AND "feedbacks"."deleted_at" IS NULL LIMIT \$3`).
+			WithArgs(7, models.ModerationPublished, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id"}).AddRow(7, authorID))
+	}
+	reactionColumns := []string{"feedback_id", "user_id", "reaction"}
+
+	expectFeedback(5)
+	if err := svc.React(5, "7", models.ReactionUpvote); !errors.Is(err, ErrReactOwnFeedback) {
+		t.Errorf("Expected ErrReactOwnFeedback, got %v", err)
+	}
+
+	// a first upvote adds support
+	expectFeedback(2)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "feedback_reactions" WHERE feedback_id = \$1 AND user_id = \$2 LIMIT \$3`).
+		WithArgs(7, 5, 1).
+		WillReturnRows(sqlmock.NewRows(reactionColumns))
+	mock.ExpectExec(`INSERT INTO "feedback_reactions" \("feedback_id","user_id","reaction","created_at","updated_at"\)`).
+		WithArgs(7, 5, models.ReactionUpvote, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "feedbacks" SET "support_count"=support_count \+ \$1 WHERE id = \$2`).
+		WithArgs(1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.React(5, "7", models.ReactionUpvote); err != nil {
+		t.Errorf("React() error = %v", err)
+	}
+
+	// thanks in place of an upvote takes the support back
+	expectFeedback(2)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "feedback_reactions"`).
+		WithArgs(7, 5, 1).
+		WillReturnRows(sqlmock.NewRows(reactionColumns).AddRow(7, 5, models.ReactionUpvote))
+	mock.ExpectExec(`UPDATE "feedback_reactions" SET "reaction"=\$1,"updated_at"=\$2 WHERE "feedback_id" = \$3 AND "user_id" = \$4`).
+		WithArgs(models.ReactionThanks, sqlmock.AnyArg(), 7, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "feedbacks" SET "support_count"=support_count \+ \$1 WHERE id = \$2`).
+		WithArgs(-1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.React(5, "7", models.ReactionThanks); err != nil {
+		t.Errorf("React() error = %v", err)
+	}
+
+	// upvote and same_issue both count as support
+	expectFeedback(2)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "feedback_reactions"`).
+		WithArgs(7, 5, 1).
+		WillReturnRows(sqlmock.NewRows(reactionColumns).AddRow(7, 5, models.ReactionUpvote))
+	mock.ExpectExec(`UPDATE "feedback_reactions" SET "reaction"=\$1`).
+		WithArgs(models.ReactionSameIssue, sqlmock.AnyArg(), 7, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.React(5, "7", models.ReactionSameIssue); err != nil {
+		t.Errorf("React() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFeedbackService_RemoveReaction(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewFeedbackService(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "feedback_reactions" WHERE feedback_id = \$1 AND user_id = \$2`).
+		WithArgs(7, 5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "user_id", "reaction"}))
+	mock.ExpectRollback()
+
+	if err := svc.RemoveReaction(5, "7"); !errors.Is(err, ErrReactionNotFound) {
+		t.Errorf("Expected ErrReactionNotFound, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "feedback_reactions" WHERE feedback_id = \$1 AND user_id = \$2`).
+		WithArgs(7, 5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "user_id", "reaction"}).AddRow(7, 5, models.ReactionSameIssue))
+	mock.ExpectExec(`DELETE FROM "feedback_reactions" WHERE feedback_id = \$1 AND user_id = \$2`).
+		WithArgs(7, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "feedbacks" SET "support_count"=support_count \+ \$1 WHERE id = \$2`).
+		WithArgs(-1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.RemoveReaction(5, "7"); err != nil {
+		t.Errorf("RemoveReaction() error = %v", err)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
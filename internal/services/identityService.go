@@ -0,0 +1,255 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidUserID        = ValidationError("invalid user ID format")
+	ErrCitizenModerator     = ConflictError("only officials and admins can be identity moderators")
+	ErrAlreadyDesignated    = ConflictError("user is already an identity moderator")
+	ErrDesignationNotFound  = NotFoundError("user is not an identity moderator")
+	ErrNotIdentityModerator = ForbiddenError("only designated identity moderators can see who posted anonymous feedback")
+	ErrFeedbackNotAnonymous = ConflictError("feedback was posted under the author's name")
+	ErrOfficialIdentity     = FieldValidationError("identity", "officials post feedback under their own name")
+	ErrNoPseudonymSecret    = errors.New("PSEUDONYM_SECRET or SESSION_SECRET must be set to derive pseudonyms")
+)
+
+var (
+	ANONYMOUS_ALIAS = "Anonymous resident"
+
+	IDENTITY_DISCLOSURE_SPEC = ListSpec{
+		Sorts: map[string]SortField{
+			"created_at": {Column: "identity_disclosures.created_at", Field: "CreatedAt"},
+		},
+		IDColumn:    "identity_disclosures.id",
+		DefaultSort: "-created_at",
+		Filters: map[string]FilterField{
+			"feedback_id":  {Column: "identity_disclosures.feedback_id IN ?", Kind: FilterMatch},
+			"moderator_id": {Column: "identity_disclosures.moderator_id IN ?", Kind: FilterMatch},
+			"created_at":   {Column: "identity_disclosures.created_at", Kind: FilterDateRange},
+		},
+		TextColumns: []string{"identity_disclosures.reason"},
+	}
+)
+
+// AliasKeyFromEnv returns the key pseudonyms are derived from,
+// PSEUDONYM_SECRET or else SESSION_SECRET. Changing it only changes the
+// pseudonyms of feedback posted afterwards. There is no default, anyone
+// knowing the key can match pseudonyms to user IDs.
+func AliasKeyFromEnv() ([]byte, error) {
+	if secret := os.Getenv("PSEUDONYM_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	if secret := os.Getenv("SESSION_SECRET"); secret != "" {
+		return []byte(secret), nil
+	}
+	return nil, ErrNoPseudonymSecret
+}
+
+// randomAliasKey stands in for a missing secret outside the app, which
+// refuses to start without one. Pseudonyms stay unguessable but change
+// with every restart.
+func randomAliasKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("identity: failed to generate pseudonym key: %v", err))
+	}
+	return key
+}
+
+// authorAlias is the name shown for feedback posted without the author's
+// name. A pseudonym stays the same for a resident within one project so a
+// conversation can be followed, but differs between projects.
+func authorAlias(key []byte, identity string, userID uint, projectID uint) string {
+	switch identity {
+	case models.IdentityPseudonymous:
+		mac := hmac.New(sha256.New, key)
+		fmt.Fprintf(mac, "%d:%d", projectID, userID)
+		return "Resident " + strings.ToUpper(hex.EncodeToString(mac.Sum(nil))[:6])
+	case models.IdentityAnonymous:
+		return ANONYMOUS_ALIAS
+	}
+	return ""
+}
+
+// author name columns of a query joining users to feedbacks, with the alias
+// in place of the name of authors who posted without it
+const publicAuthorColumns = `CASE WHEN feedbacks.identity = 'named' THEN users.first_name ELSE feedbacks.author_alias END AS first_name,
+	CASE WHEN feedbacks.identity = 'named' THEN users.last_name ELSE '' END AS last_name`
+
+// maskModerationAuthors keeps moderators from seeing who posted anonymous or
+// pseudonymous feedback, only the disclosure log does that.
+func maskModerationAuthors(items []models.ModerationItem) {
+	for i := range items {
+		if items[i].Identity != "" && items[i].Identity != models.IdentityNamed {
+			items[i].UserID = 0
+			items[i].FirstName = items[i].AuthorAlias
+			items[i].LastName = ""
+		}
+	}
+}
+
+type IdentityService struct {
+	db *gorm.DB
+}
+
+func NewIdentityService(db *gorm.DB) *IdentityService {
+	return &IdentityService{db: db}
+}
+
+func parseUserID(userID string) (uint, error) {
+	id, err := strconv.ParseUint(userID, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidUserID, userID)
+	}
+	return uint(id), nil
+}
+
+// Moderators lists the active identity moderators.
+func (s *IdentityService) Moderators() ([]models.IdentityModeratorResponse, error) {
+	var moderators []models.IdentityModeratorResponse
+	if err := s.db.Table("identity_moderators").
+		Select("identity_moderators.id, identity_moderators.user_id, users.first_name, users.last_name, users.role, users.barangay_id, identity_moderators.designated_by_id, identity_moderators.created_at").
+		Joins("JOIN users ON users.id = identity_moderators.user_id").
+		Where("identity_moderators.revoked_at IS NULL").
+		Order("identity_moderators.created_at").
+		Scan(&moderators).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve identity moderators: %w", err)
+	}
+
+	if moderators == nil {
+		moderators = []models.IdentityModeratorResponse{}
+	}
+	return moderators, nil
+}
+
+// Designate makes an official or admin an identity moderator. Officials
+// only see authors of feedback on projects of their own barangay.
+func (s *IdentityService) Designate(adminID uint, userID string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	var user models.User
+	if err := s.db.Select("id, role").Where("id = ?", id).Take(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: ID %d", ErrUserNotFound, id)
+		}
+		return err
+	}
+	if user.Role != models.RoleOfficial && user.Role != models.RoleAdmin {
+		return ErrCitizenModerator
+	}
+
+	var active int64
+	if err := s.db.Model(&models.IdentityModerator{}).Where("user_id = ? AND revoked_at IS NULL", id).Count(&active).Error; err != nil {
+		return err
+	}
+	if active > 0 {
+		return ErrAlreadyDesignated
+	}
+
+	return s.db.Create(&models.IdentityModerator{UserID: id, DesignatedByID: adminID}).Error
+}
+
+// Revoke ends a designation, the record of it stays.
+func (s *IdentityService) Revoke(adminID uint, userID string) error {
+	id, err := parseUserID(userID)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.IdentityModerator{}).
+		Where("user_id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "revoked_by_id": adminID})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke identity moderator: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", ErrDesignationNotFound, id)
+	}
+
+	return nil
+}
+
+// Disclose shows a designated moderator who posted anonymous or
+// pseudonymous feedback. The disclosure is recorded with its reason before
+// anything is returned. barangay_ID is 0 for admins.
+func (s *IdentityService) Disclose(moderatorID uint, barangay_ID uint, feedbackID string, reason string) (models.DisclosedAuthor, error) {
+	id, err := strconv.ParseUint(feedbackID, 10, 32)
+	if err != nil {
+		return models.DisclosedAuthor{}, fmt.Errorf("%w: %s", ErrInvalidFeedbackID, feedbackID)
+	}
+
+	var designated int64
+	if err := s.db.Model(&models.IdentityModerator{}).Where("user_id = ? AND revoked_at IS NULL", moderatorID).Count(&designated).Error; err != nil {
+		return models.DisclosedAuthor{}, err
+	}
+	if designated == 0 {
+		return models.DisclosedAuthor{}, ErrNotIdentityModerator
+	}
+
+	query := s.db.Table("feedbacks").
+		Select("feedbacks.id AS feedback_id, users.id AS user_id, users.first_name, users.last_name, users.email, users.contact, feedbacks.identity, feedbacks.author_alias AS alias").
+		Joins("JOIN projects ON projects.id = feedbacks.project_id").
+		Joins("JOIN users ON users.id = feedbacks.user_id").
+		Where("feedbacks.id = ?", id)
+	if barangay_ID != 0 {
+		query = query.Where("projects.barangay_id = ?", barangay_ID)
+	}
+
+	var authors []models.DisclosedAuthor
+	if err := query.Limit(1).Scan(&authors).Error; err != nil {
+		return models.DisclosedAuthor{}, fmt.Errorf("failed to find feedback: %w", err)
+	}
+	if len(authors) == 0 {
+		return models.DisclosedAuthor{}, fmt.Errorf("%w: ID %d", ErrFeedbackNotFound, id)
+	}
+	if authors[0].Identity == models.IdentityNamed {
+		return models.DisclosedAuthor{}, ErrFeedbackNotAnonymous
+	}
+
+	if err := s.db.Create(&models.IdentityDisclosure{
+		FeedbackID:  uint(id),
+		ModeratorID: moderatorID,
+		Reason:      strings.TrimSpace(reason),
+	}).Error; err != nil {
+		return models.DisclosedAuthor{}, fmt.Errorf("failed to record disclosure: %w", err)
+	}
+
+	return authors[0], nil
+}
+
+// Disclosures lists the disclosure log for auditors, newest first.
+func (s *IdentityService) Disclosures(params url.Values) ([]models.IdentityDisclosureResponse, models.PageMeta, error) {
+	query, err := ParseListQuery(params, IDENTITY_DISCLOSURE_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var disclosures []models.IdentityDisclosureResponse
+	meta, err := ListPage(s.db.Table("identity_disclosures"), query, &disclosures, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("identity_disclosures.id, identity_disclosures.feedback_id, identity_disclosures.moderator_id, users.first_name, users.last_name, identity_disclosures.reason, identity_disclosures.created_at").
+			Joins("JOIN users ON users.id = identity_disclosures.moderator_id")
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return disclosures, meta, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestAuthorAlias(t *testing.T) {
+	key := []byte("test-secret")
+
+	first := authorAlias(key, models.IdentityPseudonymous, 5, 2)
+	if first != authorAlias(key, models.IdentityPseudonymous, 5, 2) {
+		t.Error("authorAlias() differs for the same resident and project")
+	}
+	if len(first) != len("Resident ")+6 {
+		t.Errorf("authorAlias() = %q, want Resident and six characters", first)
+	}
+	if first == authorAlias(key, models.IdentityPseudonymous, 5, 3) {
+		t.Error("authorAlias() is the same across projects")
+	}
+	if first == authorAlias(key, models.IdentityPseudonymous, 6, 2) {
+		t.Error("authorAlias() is the same for different residents")
+	}
+
+	if alias := authorAlias(key, models.IdentityAnonymous, 5, 2); alias != ANONYMOUS_ALIAS {
+		t.Errorf("authorAlias(anonymous) = %q, want %q", alias, ANONYMOUS_ALIAS)
+	}
+	if alias := authorAlias(key, models.IdentityNamed, 5, 2); alias != "" {
+		t.Errorf("authorAlias(named) = %q, want none", alias)
+	}
+}
+
+func TestAliasKeyFromEnv(t *testing.T) {
+	t.Setenv("PSEUDONYM_SECRET", "")
+	t.Setenv("SESSION_SECRET", "")
+	if _, err := AliasKeyFromEnv(); !errors.Is(err, ErrNoPseudonymSecret) {
+		t.Errorf("Expected ErrNoPseudonymSecret without a secret, got %v", err)
+	}
+
+	t.Setenv("SESSION_SECRET", "session")
+	if key, err := AliasKeyFromEnv(); err != nil || string(key) != "session" {
+		t.Errorf("AliasKeyFromEnv() = %q, %v, want the session secret", key, err)
+	}
+
+	t.Setenv("PSEUDONYM_SECRET", "pseudonym")
+	if key, err := AliasKeyFromEnv(); err != nil || string(key) != "pseudonym" {
+		t.Errorf("AliasKeyFromEnv() = %q, %v, want the pseudonym secret", key, err)
+	}
+}
+
+func TestIdentityService_Designate(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewIdentityService(gormDB)
+
+	if err := svc.Designate(1, "abc"); !errors.Is(err, ErrInvalidUserID) {
+		t.Errorf("Expected ErrInvalidUserID, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT id, role FROM "users" WHERE id = \$1`).
+		WithArgs(4, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(4, models.RoleCitizen))
+	if err := svc.Designate(1, "4"); !errors.Is(err, ErrCitizenModerator) {
+		t.Errorf("Expected ErrCitizenModerator, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT id, role FROM "users" WHERE id = \$1`).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role"}).AddRow(3, models.RoleOfficial))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "identity_moderators" WHERE user_id = \$1 AND revoked_at IS NULL`).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "identity_moderators"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := svc.Designate(1, "3"); err != nil {
+		t.Errorf("Designate() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestIdentityService_Disclose(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewIdentityService(gormDB)
+
+	reason := "Threat of violence against a barangay worker."
+	authorColumns := []string{"feedback_id", "user_id", "first_name", "last_name", "email", "contact", "identity", "alias"}
+	designated := func(count int) {
+		mock.ExpectQuery(`SELECT count\(\*\) FROM "identity_moderators" WHERE user_id = \$1 AND revoked_at IS NULL`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(count))
+	}
+
+	designated(0)
+	if _, err := svc.Disclose(3, 1, "7", reason); !errors.Is(err, ErrNotIdentityModerator) {
+		t.Errorf("Expected ErrNotIdentityModerator, got %v", err)
+	}
+
+	designated(1)
+	mock.ExpectQuery(`SELECT (.+) FROM "feedbacks" JOIN projects ON projects.id = feedbacks.project_id JOIN users ON users.id = feedbacks.user_id WHERE feedbacks.id = \$1 AND projects.barangay_id = \$2`).
+		WithArgs(7, 1, 1).
+		WillReturnRows(sqlmock.NewRows(authorColumns).AddRow(7, 5, "Ana", "Santos", "ana@example.com", "09171234567", models.IdentityNamed, ""))
+	if _, err := svc.Disclose(3, 1, "7", reason); !errors.Is(err, ErrFeedbackNotAnonymous) {
+		t.Errorf("Expected ErrFeedbackNotAnonymous, got %v", err)
+	}
+
+	// the disclosure is logged before the author is returned
+	designated(1)
+	mock.ExpectQuery(`SELECT (.+) FROM "feedbacks" (.+) WHERE feedbacks.id = \$1 AND projects.barangay_id = \$2`).
+		WithArgs(7, 1, 1).
+		WillReturnRows(sqlmock.NewRows(authorColumns).AddRow(7, 5, "Ana", "Santos", "ana@example.com", "09171234567", models.IdentityAnonymous, ANONYMOUS_ALIAS))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "identity_disclosures"`).
+		WithArgs(7, 3, reason, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	author, err := svc.Disclose(3, 1, "7", reason)
+	if err != nil {
+		t.Fatalf("Disclose() error = %v", err)
+	}
+	if author.UserID != 5 || author.FirstName != "Ana" {
+		t.Errorf("Disclose() = %+v, want Ana Santos", author)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestFeedbackService_GetAllFeedbackMasksAuthors(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := &FeedbackService{db: gormDB, aliasKey: []byte("test-secret")}
+
+	now := time.Now()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" WHERE project_id = \$1`).
+		WithArgs(1, uint(10)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT id, content, role, project_id, user_id, created_at, (.+) FROM "feedbacks" WHERE project_id = \$1`).
+		WithArgs(1, uint(10), DEFAULT_PAGE_LIMIT+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "role", "project_id", "user_id", "created_at", "identity", "author_alias"}).
+			AddRow(1, "Feedback 1", "citizen", 1, 10, now, models.IdentityPseudonymous, "Resident 1A2B3C").
+			AddRow(2, "Feedback 2", "citizen", 1, 20, now, models.IdentityNamed, ""))
+	// only the author of named feedback is looked up
+	mock.ExpectQuery(`SELECT id, first_name, last_name FROM "users" WHERE id IN \(\$1\)`).
+		WithArgs(20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name"}).AddRow(20, "Jane", "Smith"))
+	mock.ExpectQuery(`SELECT \* FROM "feedback_tags"`).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "tag"}))
+	mock.ExpectQuery(`SELECT (.+) FROM "feedback_reactions"`).
+		WithArgs(uint(10), 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "reaction", "count", "mine"}))
+
+	feedbacks, _, err := svc.GetAllFeedback("1", 10, url.Values{})
+	if err != nil {
+		t.Fatalf("GetAllFeedback() error = %v", err)
+	}
+	if len(feedbacks) != 2 {
+		t.Fatalf("Expected 2 feedbacks, got %d", len(feedbacks))
+	}
+	if feedbacks[0].UserID != 0 || feedbacks[0].FirstName != "Resident 1A2B3C" || !feedbacks[0].Mine {
+		t.Errorf("Expected the viewer's own pseudonymous feedback under its alias, got %+v", feedbacks[0])
+	}
+	if feedbacks[1].UserID != 20 || feedbacks[1].FirstName != "Jane" || feedbacks[1].Mine {
+		t.Errorf("Expected named feedback by Jane Smith, got %+v", feedbacks[1])
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
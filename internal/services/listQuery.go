@@ -0,0 +1,346 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	DEFAULT_PAGE_LIMIT = 10
+	MAX_PAGE_LIMIT     = 100
+
+	ErrInvalidPage   = FieldValidationError("page", "page must be a positive number")
+	ErrInvalidLimit  = FieldValidationError("limit", fmt.Sprintf("limit must be between 1 and %d", MAX_PAGE_LIMIT))
+	ErrInvalidCursor = FieldValidationError("cursor", "cursor is not valid for this list")
+)
+
+// FilterKind decides which query parameters a filter field accepts.
+type FilterKind int
+
+const (
+	// FilterEnum matches name=a,b against the allowed values.
+	FilterEnum FilterKind = iota
+	// FilterDateRange accepts name_from and name_to as YYYY-MM-DD, both inclusive.
+	FilterDateRange
+	// FilterNumberRange accepts name_min and name_max, both inclusive.
+	FilterNumberRange
+	// FilterMatch accepts name=a,b with values not known in advance. Column
+	// is then a condition with one placeholder for the list.
+	FilterMatch
+)
+
+// SortField maps a public sort name to its column and the result struct
+// field the next cursor is read from.
+type SortField struct {
+	Column string
+	Field  string
+}
+
+type FilterField struct {
+	Column string
+	Kind   FilterKind
+	Values []string
+}
+
+// ListSpec whitelists what a list endpoint may be sorted, filtered and
+// searched by. Columns are trusted SQL, query values never are.
+type ListSpec struct {
+	IDColumn    string
+	Sorts       map[string]SortField
+	DefaultSort string
+	Filters     map[string]FilterField
+	TextColumns []string
+}
+
+type listCondition struct {
+	sql  string
+	args []interface{}
+}
+
+// ListQuery is a parsed and validated list request.
+type ListQuery struct {
+	Page   int
+	Limit  int
+	Sort   string
+	Text   string
+	spec   ListSpec
+	sort   SortField
+	desc   bool
+	cursor *listCursor
+	where  []listCondition
+}
+
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// ParseListQuery reads page, limit, cursor, sort, q and the spec's filters
+// from the query string. Missing values fall back to the first page sorted
+// by the spec's default.
+func ParseListQuery(params url.Values, spec ListSpec) (ListQuery, error) {
+	page, limit, err := parsePageParams(params)
+	if err != nil {
+		return ListQuery{}, err
+	}
+	query := ListQuery{Page: page, Limit: limit, spec: spec}
+
+	query.Sort = params.Get("sort")
+	if query.Sort == "" {
+		query.Sort = spec.DefaultSort
+	}
+	name := strings.TrimPrefix(query.Sort, "-")
+	sortField, ok := spec.Sorts[name]
+	if !ok {
+		return ListQuery{}, FieldValidationError("sort", "sort must be one of: "+strings.Join(sortedKeys(spec.Sorts), ", "))
+	}
+	query.sort = sortField
+	query.desc = strings.HasPrefix(query.Sort, "-")
+
+	if cursor := params.Get("cursor"); cursor != "" {
+		decoded, err := decodeCursor(cursor)
+		if err != nil || decoded.Sort != query.Sort {
+			return ListQuery{}, ErrInvalidCursor
+		}
+		query.cursor = &decoded
+		query.Page = 0
+	}
+
+	// sorted so the generated SQL is stable
+	for _, name := range sortedKeys(spec.Filters) {
+		if err := query.addFilter(params, name, spec.Filters[name]); err != nil {
+			return ListQuery{}, err
+		}
+	}
+
+	query.Text = strings.TrimSpace(params.Get("q"))
+	if query.Text != "" && len(spec.TextColumns) > 0 {
+		pattern := "%" + escapeLike(query.Text) + "%"
+		clauses := make([]string, len(spec.TextColumns))
+		args := make([]interface{}, len(spec.TextColumns))
+		for i, column := range spec.TextColumns {
+			clauses[i] = column + " ILIKE ?"
+			args[i] = pattern
+		}
+		query.where = append(query.where, listCondition{"(" + strings.Join(clauses, " OR ") + ")", args})
+	}
+
+	return query, nil
+}
+
+// parsePageParams reads page and limit, defaulting to the first page.
+func parsePageParams(params url.Values) (int, int, error) {
+	page, limit := 1, DEFAULT_PAGE_LIMIT
+
+	if raw := params.Get("page"); raw != "" {
+		pageInt, err := strconv.Atoi(raw)
+		if err != nil || pageInt < 1 {
+			return 0, 0, ErrInvalidPage
+		}
+		page = pageInt
+	}
+
+	if raw := params.Get("limit"); raw != "" {
+		limitInt, err := strconv.Atoi(raw)
+		if err != nil || limitInt < 1 || limitInt > MAX_PAGE_LIMIT {
+			return 0, 0, ErrInvalidLimit
+		}
+		limit = limitInt
+	}
+
+	return page, limit, nil
+}
+
+func (q *ListQuery) addFilter(params url.Values, name string, filter FilterField) error {
+	switch filter.Kind {
+	case FilterEnum:
+		raw := params.Get(name)
+		if raw == "" {
+			return nil
+		}
+		values := strings.Split(raw, ",")
+		for _, value := range values {
+			if !containsString(filter.Values, value) {
+				return FieldValidationError(name, name+" must be one of: "+strings.Join(filter.Values, ", "))
+			}
+		}
+		q.where = append(q.where, listCondition{filter.Column + " IN ?", []interface{}{values}})
+
+	case FilterMatch:
+		raw := params.Get(name)
+		if raw == "" {
+			return nil
+		}
+		q.where = append(q.where, listCondition{filter.Column, []interface{}{strings.Split(raw, ",")}})
+
+	case FilterDateRange:
+		if from := params.Get(name + "_from"); from != "" {
+			date, err := time.Parse(GO_DATE_FORMAT, from)
+			if err != nil {
+				return FieldValidationError(name+"_from", name+"_from must be a date in YYYY-MM-DD format")
+			}
+			q.where = append(q.where, listCondition{filter.Column + " >= ?", []interface{}{date}})
+		}
+		if to := params.Get(name + "_to"); to != "" {
+			date, err := time.Parse(GO_DATE_FORMAT, to)
+			if err != nil {
+				return FieldValidationError(name+"_to", name+"_to must be a date in YYYY-MM-DD format")
+			}
+			q.where = append(q.where, listCondition{filter.Column + " < ?", []interface{}{date.AddDate(0, 0, 1)}})
+		}
+
+	case FilterNumberRange:
+		if min := params.Get(name + "_min"); min != "" {
+			value, err := strconv.ParseFloat(min, 64)
+			if err != nil {
+				return FieldValidationError(name+"_min", name+"_min must be a number")
+			}
+			q.where = append(q.where, listCondition{filter.Column + " >= ?", []interface{}{value}})
+		}
+		if max := params.Get(name + "_max"); max != "" {
+			value, err := strconv.ParseFloat(max, 64)
+			if err != nil {
+				return FieldValidationError(name+"_max", name+"_max must be a number")
+			}
+			q.where = append(q.where, listCondition{filter.Column + " <= ?", []interface{}{value}})
+		}
+	}
+
+	return nil
+}
+
+// filter applies the filter and search conditions to tx.
+func (q ListQuery) filter(tx *gorm.DB) *gorm.DB {
+	for _, condition := range q.where {
+		tx = tx.Where(condition.sql, condition.args...)
+	}
+	return tx
+}
+
+func (q ListQuery) idColumn() string {
+	if q.spec.IDColumn == "" {
+		return "id"
+	}
+	return q.spec.IDColumn
+}
+
+// ListPage counts the filtered rows of base and loads one page into out.
+// shape adds the select, joins and grouping that the count must not see.
+// The id column breaks ties so pages and cursors never skip or repeat rows.
+func ListPage[T any](base *gorm.DB, query ListQuery, out *[]T, shape func(*gorm.DB) *gorm.DB) (models.PageMeta, error) {
+	shared := base.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.filter(shared).Count(&total).Error; err != nil {
+		return models.PageMeta{}, fmt.Errorf("failed to count results: %w", err)
+	}
+
+	direction := "ASC"
+	if query.desc {
+		direction = "DESC"
+	}
+
+	list := query.filter(shared)
+	if shape != nil {
+		list = shape(list)
+	}
+
+	if query.cursor != nil {
+		operator := ">"
+		if query.desc {
+			operator = "<"
+		}
+		list = list.Where(fmt.Sprintf("(%s %s ? OR (%s = ? AND %s %s ?))", query.sort.Column, operator, query.sort.Column, query.idColumn(), operator),
+			query.cursor.Value, query.cursor.Value, query.cursor.ID)
+	} else {
+		list = list.Offset((query.Page - 1) * query.Limit)
+	}
+
+	var rows []T
+	if err := list.Order(query.sort.Column + " " + direction).
+		Order(query.idColumn() + " " + direction).
+		Limit(query.Limit + 1).
+		Scan(&rows).Error; err != nil {
+		return models.PageMeta{}, fmt.Errorf("failed to retrieve results: %w", err)
+	}
+
+	meta := models.PageMeta{
+		Page:       query.Page,
+		Limit:      query.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(query.Limit) - 1) / int64(query.Limit)),
+		HasMore:    len(rows) > query.Limit,
+		Sort:       query.Sort,
+	}
+
+	if meta.HasMore {
+		rows = rows[:query.Limit]
+		meta.NextCursor = encodeCursor(query.Sort, rows[len(rows)-1], query.sort.Field)
+	}
+
+	if rows == nil {
+		rows = []T{}
+	}
+	*out = rows
+
+	return meta, nil
+}
+
+func encodeCursor(sort string, row interface{}, field string) string {
+	value := reflect.Indirect(reflect.ValueOf(row))
+	cursor := listCursor{Sort: sort, Value: cursorValue(value.FieldByName(field).Interface())}
+	if id := value.FieldByName("ID"); id.IsValid() {
+		cursor.ID = uint(id.Uint())
+	}
+
+	encoded, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(raw string) (listCursor, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return listCursor{}, err
+	}
+
+	var cursor listCursor
+	err = json.Unmarshal(decoded, &cursor)
+	return cursor, err
+}
+
+func cursorValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// escapeLike keeps user input from acting as LIKE wildcards.
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
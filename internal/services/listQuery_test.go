@@ -0,0 +1,110 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestParseListQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    url.Values
+		wantField string
+	}{
+		{name: "Defaults", params: url.Values{}},
+		{name: "Full Query", params: url.Values{"page": {"2"}, "limit": {"25"}, "sort": {"-amount"}, "status": {"pending"}, "amount_max": {"500"}, "created_at_from": {"2024-01-01"}, "q": {"cement"}}},
+		{name: "Zero Page", params: url.Values{"page": {"0"}}, wantField: "page"},
+		{name: "Limit Too Large", params: url.Values{"limit": {"1000"}}, wantField: "limit"},
+		{name: "Unknown Sort", params: url.Values{"sort": {"password"}}, wantField: "sort"},
+		{name: "Unknown Status", params: url.Values{"status": {"pending,deleted"}}, wantField: "status"},
+		{name: "Bad Amount", params: url.Values{"amount_min": {"ten"}}, wantField: "amount_min"},
+		{name: "Bad Date", params: url.Values{"created_at_to": {"01/31/2024"}}, wantField: "created_at_to"},
+		{name: "Garbage Cursor", params: url.Values{"cursor": {"not-a-cursor"}}, wantField: "cursor"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseListQuery(tt.params, BUDGET_ITEM_LIST_SPEC)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("ParseListQuery() error = %v", err)
+				}
+				return
+			}
+
+			var serviceErr *ServiceError
+			if !errors.As(err, &serviceErr) || len(serviceErr.Fields) != 1 || serviceErr.Fields[0].Field != tt.wantField {
+				t.Errorf("ParseListQuery() error = %v, want field error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestParseListQuery_CursorBoundToSort(t *testing.T) {
+	cursor := encodeCursor("-created_at", models.Budget_Item{Name: "Cement"}, "CreatedAt")
+
+	if _, err := ParseListQuery(url.Values{"cursor": {cursor}}, BUDGET_ITEM_LIST_SPEC); err != nil {
+		t.Errorf("Expected cursor to match the default sort, got %v", err)
+	}
+
+	if _, err := ParseListQuery(url.Values{"cursor": {cursor}, "sort": {"name"}}, BUDGET_ITEM_LIST_SPEC); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor for a different sort, got %v", err)
+	}
+}
+
+func TestListPage_Cursor(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+
+	query, err := ParseListQuery(url.Values{"limit": {"1"}}, BARANGAY_LIST_SPEC)
+	if err != nil {
+		t.Fatalf("ParseListQuery() error = %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "barangays"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT (.+) FROM "barangays" (.+) ORDER BY name ASC,id ASC LIMIT \$1`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "city", "region"}).
+			AddRow(4, "Bagong Silang", "Caloocan", "NCR").
+			AddRow(9, "Commonwealth", "Quezon City", "NCR"))
+
+	var first []models.AllBarangayResponse
+	meta, err := ListPage(gormDB.Model(&models.Barangay{}), query, &first, nil)
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(first) != 1 || !meta.HasMore || meta.NextCursor == "" {
+		t.Fatalf("Expected one row and a next cursor, got %d rows and %+v", len(first), meta)
+	}
+
+	query, err = ParseListQuery(url.Values{"limit": {"1"}, "cursor": {meta.NextCursor}}, BARANGAY_LIST_SPEC)
+	if err != nil {
+		t.Fatalf("ParseListQuery() error = %v", err)
+	}
+
+	// the second page continues after the last row instead of using an offset
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "barangays"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery(`SELECT (.+) FROM "barangays" WHERE \(\(name > \$1 OR \(name = \$2 AND id > \$3\)\)\) (.+) ORDER BY name ASC,id ASC LIMIT \$4`).
+		WithArgs("Bagong Silang", "Bagong Silang", 4, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "city", "region"}).
+			AddRow(9, "Commonwealth", "Quezon City", "NCR"))
+
+	var second []models.AllBarangayResponse
+	meta, err = ListPage(gormDB.Model(&models.Barangay{}), query, &second, nil)
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(second) != 1 || second[0].ID != 9 || meta.HasMore || meta.NextCursor != "" {
+		t.Errorf("Expected the last row and no cursor, got %+v and %+v", second, meta)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"log"
+	"os"
+)
+
+// Mailer delivers transactional mail such as email change confirmations.
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// LogMailer records outgoing mail in the server log, used until an SMTP
+// provider is configured. Bodies carry confirmation tokens, so only the
+// recipient and subject are logged.
+type LogMailer struct{}
+
+func (LogMailer) Send(to string, subject string, body string) error {
+	log.Printf("mail to %s: %s (body withheld)", to, subject)
+	return nil
+}
+
+// AppURL is the frontend base URL used when building links sent by mail.
+func AppURL() string {
+	if url := os.Getenv("APP_URL"); url != "" {
+		return url
+	}
+
+	return "http://localhost:3000"
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidContentType       = FieldValidationError("type", "type must be one of: feedback, reply")
+	ErrInvalidContentID         = ValidationError("invalid content ID format")
+	ErrModeratedContentNotFound = NotFoundError("feedback or reply not found")
+	ErrModerationReasonRequired = FieldValidationError("reason", "reason is required to hide or remove content")
+	ErrModerationUnchanged      = ConflictError("content is already in this state")
+	ErrAppealNotAuthor          = ForbiddenError("only the author can appeal a moderation decision")
+	ErrAppealNotAllowed         = ConflictError("only hidden or removed content can be appealed")
+	ErrAppealExists             = ConflictError("an appeal of this decision is already open")
+	ErrAppealNotFound           = NotFoundError("appeal not found")
+	ErrInvalidAppealID          = ValidationError("invalid appeal ID format")
+	ErrAppealClosed             = ConflictError("appeal has already been decided")
+	ErrNotContentAuthor         = ForbiddenError("only the author can change or withdraw this content")
+)
+
+var (
+	MODERATION_CONTENT_TYPES = []string{models.ContentFeedback, models.ContentReply}
+
+	MODERATION_REASONS = []string{models.ReasonSpam, models.ReasonHarassment, models.ReasonHateSpeech, models.ReasonPersonalInfo, models.ReasonOffTopic, models.ReasonMisinformation, models.ReasonOther}
+
+	// states a moderator works through, removed content is left to auditors
+	MODERATION_QUEUE_STATES = []string{models.ModerationPending, models.ModerationHidden, models.ModerationPublished}
+
+	APPEAL_LIST_SPEC = ListSpec{
+		Sorts: map[string]SortField{
+			"created_at": {Column: "created_at", Field: "CreatedAt"},
+		},
+		DefaultSort: "created_at",
+		Filters: map[string]FilterField{
+			"status":       {Column: "status", Kind: FilterEnum, Values: []string{models.AppealOpen, models.AppealUpheld, models.AppealOverturned}},
+			"content_type": {Column: "content_type", Kind: FilterEnum, Values: MODERATION_CONTENT_TYPES},
+			"created_at":   {Column: "created_at", Kind: FilterDateRange},
+		},
+		TextColumns: []string{"statement"},
+	}
+)
+
+// visibleTo keeps content from the public until it is published, authors
+// still see what they posted unless it was removed.
+const visibleTo = "(%[1]s.moderation_state = 'published' OR (%[1]s.user_id = ? AND %[1]s.moderation_state <> 'removed'))"
+
+type ModerationService struct {
+	db *gorm.DB
+}
+
+func NewModerationService(db *gorm.DB) *ModerationService {
+	return &ModerationService{db: db}
+}
+
+func moderatedTable(contentType string) (string, error) {
+	switch contentType {
+	case models.ContentFeedback:
+		return "feedbacks", nil
+	case models.ContentReply:
+		return "feedback_replies", nil
+	}
+	return "", ErrInvalidContentType
+}
+
+// contentQuery selects feedback or replies as ModerationItem rows joined to
+// the project they were posted on.
+func (s *ModerationService) contentQuery(contentType string) (*gorm.DB, string, error) {
+	table, err := moderatedTable(contentType)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := s.db.Table(table).Where(table + ".deleted_at IS NULL")
+	if contentType == models.ContentReply {
+		query = query.Joins("JOIN feedbacks ON feedbacks.id = feedback_replies.feedback_id")
+	}
+	query = query.Joins("JOIN projects ON projects.id = feedbacks.project_id").
+		Joins("JOIN users ON users.id = " + table + ".user_id")
+
+	return query, table, nil
+}
+
+func contentColumns(contentType string, table string) string {
+	feedbackID, identity := "NULL", "feedbacks.identity, feedbacks.author_alias"
+	if contentType == models.ContentReply {
+		feedbackID, identity = "feedback_replies.feedback_id", "'named' AS identity, '' AS author_alias"
+	}
+	return fmt.Sprintf(`%[1]s.id, '%[2]s' AS content_type, %[1]s.content, %[1]s.moderation_state, %[1]s.moderation_reason,
+		%[1]s.moderated_at, %[1]s.user_id, users.first_name, users.last_name, %[4]s, projects.id AS project_id,
+		projects.name AS project_name, %[3]s AS feedback_id, %[1]s.created_at`, table, contentType, feedbackID, identity)
+}
+
+type moderatedContent struct {
+	models.ModerationItem
+	Barangay_ID uint
+}
+
+func (s *ModerationService) findContent(contentType string, contentID string) (moderatedContent, error) {
+	id, err := strconv.ParseUint(contentID, 10, 32)
+	if err != nil {
+		return moderatedContent{}, fmt.Errorf("%w: %s", ErrInvalidContentID, contentID)
+	}
+
+	query, table, err := s.contentQuery(contentType)
+	if err != nil {
+		return moderatedContent{}, err
+	}
+
+	var content []moderatedContent
+	if err := query.Select(contentColumns(contentType, table)+", projects.barangay_id").
+		Where(table+".id = ?", id).
+		Limit(1).
+		Scan(&content).Error; err != nil {
+		return moderatedContent{}, fmt.Errorf("failed to find content: %w", err)
+	}
+	if len(content) == 0 {
+		return moderatedContent{}, fmt.Errorf("%w: %s %d", ErrModeratedContentNotFound, contentType, id)
+	}
+
+	return content[0], nil
+}
+
+// setState moves content to a new state and logs the decision.
+func setState(tx *gorm.DB, content moderatedContent, moderatorID uint, state string, reason string, note string) error {
+	table, err := moderatedTable(content.ContentType)
+	if err != nil {
+		return err
+	}
+
+	var reasonValue *string
+	if reason != "" && state != models.ModerationPublished {
+		reasonValue = &reason
+	}
+
+	now := time.Now()
+	if err := tx.Table(table).Where("id = ?", content.ID).Updates(map[string]interface{}{
+		"moderation_state":  state,
+		"moderation_reason": reasonValue,
+		"moderated_at":      now,
+		"moderated_by_id":   moderatorID,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to moderate content: %w", err)
+	}
+
+	action := models.ModerationAction{
+		ContentType: content.ContentType,
+		ContentID:   content.ID,
+		ModeratorID: moderatorID,
+		FromState:   content.ModerationState,
+		ToState:     state,
+		Reason:      reasonValue,
+		Note:        note,
+	}
+	if err := tx.Create(&action).Error; err != nil {
+		return fmt.Errorf("failed to log moderation: %w", err)
+	}
+
+	return nil
+}
+
+// Moderate publishes, hides or removes feedback or a reply. Officials
+// moderate their own barangay, a barangay_ID of 0 lets admins moderate any.
+func (s *ModerationService) Moderate(moderatorID uint, barangay_ID uint, contentType string, contentID string, decision models.ModerateContent) error {
+	if decision.State != models.ModerationPublished && decision.Reason == "" {
+		return ErrModerationReasonRequired
+	}
+
+	content, err := s.findContent(contentType, contentID)
+	if err != nil {
+		return err
+	}
+	if barangay_ID != 0 && content.Barangay_ID != barangay_ID {
+		return fmt.Errorf("%w: %s %d", ErrModeratedContentNotFound, contentType, content.ID)
+	}
+	if content.ModerationState == decision.State {
+		return ErrModerationUnchanged
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return setState(tx, content, moderatorID, decision.State, decision.Reason, decision.Note)
+	})
+}
+
+func (s *ModerationService) listContent(barangay_ID string, params url.Values, states []string) ([]models.ModerationItem, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	contentType := params.Get("type")
+	if contentType == "" {
+		contentType = models.ContentFeedback
+	}
+	query, table, err := s.contentQuery(contentType)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	// a queue with no state asked for shows what waits on a moderator
+	if params.Get("state") == "" {
+		defaulted := url.Values{}
+		for key, values := range params {
+			defaulted[key] = values
+		}
+		defaulted.Set("state", states[0])
+		params = defaulted
+	}
+
+	spec := ListSpec{
+		IDColumn: table + ".id",
+		Sorts: map[string]SortField{
+			"created_at": {Column: table + ".created_at", Field: "CreatedAt"},
+		},
+		// oldest first so nothing waits forever
+		DefaultSort: "created_at",
+		Filters: map[string]FilterField{
+			"state":      {Column: table + ".moderation_state", Kind: FilterEnum, Values: states},
+			"created_at": {Column: table + ".created_at", Kind: FilterDateRange},
+		},
+		TextColumns: []string{table + ".content"},
+	}
+	listQuery, err := ParseListQuery(params, spec)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var items []models.ModerationItem
+	meta, err := ListPage(query.Where("projects.barangay_id = ?", barangay_ID_int), listQuery, &items, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(contentColumns(contentType, table))
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+	maskModerationAuthors(items)
+	if err := s.attachFlags(contentType, items); err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return items, meta, nil
+}
+
+// attachFlags adds what the content filters found to each item.
+func (s *ModerationService) attachFlags(contentType string, items []models.ModerationItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	var flags []models.ContentFlagResponse
+	if err := s.db.Model(&models.ContentFlag{}).
+		Select("content_id, filter, reason, detail").
+		Where("content_type = ? AND content_id IN ?", contentType, ids).
+		Order("id").
+		Scan(&flags).Error; err != nil {
+		return fmt.Errorf("failed to retrieve content flags: %w", err)
+	}
+
+	byContent := map[uint][]models.ContentFlagResponse{}
+	for _, flag := range flags {
+		byContent[flag.ContentID] = append(byContent[flag.ContentID], flag)
+	}
+	for i := range items {
+		items[i].Flags = byContent[items[i].ID]
+		if items[i].Flags == nil {
+			items[i].Flags = []models.ContentFlagResponse{}
+		}
+	}
+	return nil
+}
+
+// Queue lists the feedback, or with type=reply the replies, of a barangay
+// awaiting moderation. state=hidden or published lists earlier decisions.
+func (s *ModerationService) Queue(barangay_ID string, params url.Values) ([]models.ModerationItem, models.PageMeta, error) {
+	return s.listContent(barangay_ID, params, MODERATION_QUEUE_STATES)
+}
+
+// Removed lists the removed content of a barangay for auditors.
+func (s *ModerationService) Removed(barangay_ID string, params url.Values) ([]models.ModerationItem, models.PageMeta, error) {
+	return s.listContent(barangay_ID, params, []string{models.ModerationRemoved})
+}
+
+// History returns a feedback or reply whatever its state, with every
+// moderation decision and appeal on it.
+func (s *ModerationService) History(contentType string, contentID string) (models.ModerationHistory, error) {
+	content, err := s.findContent(contentType, contentID)
+	if err != nil {
+		return models.ModerationHistory{}, err
+	}
+
+	history := models.ModerationHistory{Item: content.ModerationItem}
+	items := []models.ModerationItem{history.Item}
+	maskModerationAuthors(items)
+	if err := s.attachFlags(contentType, items); err != nil {
+		return models.ModerationHistory{}, err
+	}
+	history.Item = items[0]
+	if err := s.db.Table("moderation_actions").
+		Select("moderation_actions.id, moderation_actions.moderator_id, users.first_name, users.last_name, moderation_actions.from_state, moderation_actions.to_state, moderation_actions.reason, moderation_actions.note, moderation_actions.created_at").
+		Joins("JOIN users ON users.id = moderation_actions.moderator_id").
+		Where("moderation_actions.content_type = ? AND moderation_actions.content_id = ?", contentType, content.ID).
+		Order("moderation_actions.created_at, moderation_actions.id").
+		Scan(&history.Actions).Error; err != nil {
+		return models.ModerationHistory{}, fmt.Errorf("failed to retrieve moderation actions: %w", err)
+	}
+	if err := s.db.Model(&models.ModerationAppeal{}).
+		Where("content_type = ? AND content_id = ?", contentType, content.ID).
+		Order("created_at, id").
+		Scan(&history.Appeals).Error; err != nil {
+		return models.ModerationHistory{}, fmt.Errorf("failed to retrieve appeals: %w", err)
+	}
+
+	if history.Item.Identity != models.IdentityNamed {
+		for i := range history.Appeals {
+			history.Appeals[i].UserID = 0
+		}
+	}
+
+	if history.Actions == nil {
+		history.Actions = []models.ModerationActionResponse{}
+	}
+	if history.Appeals == nil {
+		history.Appeals = []models.AppealResponse{}
+	}
+	return history, nil
+}
+
+// Appeal lets the author of hidden or removed content ask for it back, one
+// open appeal at a time.
+func (s *ModerationService) Appeal(userID uint, contentType string, contentID string, appeal models.NewAppeal) (uint, error) {
+	content, err := s.findContent(contentType, contentID)
+	if err != nil {
+		return 0, err
+	}
+	if content.UserID != userID {
+		return 0, ErrAppealNotAuthor
+	}
+	if content.ModerationState != models.ModerationHidden && content.ModerationState != models.ModerationRemoved {
+		return 0, ErrAppealNotAllowed
+	}
+
+	var open int64
+	if err := s.db.Model(&models.ModerationAppeal{}).
+		Where("content_type = ? AND content_id = ? AND status = ?", contentType, content.ID, models.AppealOpen).
+		Count(&open).Error; err != nil {
+		return 0, fmt.Errorf("failed to check appeals: %w", err)
+	}
+	if open > 0 {
+		return 0, ErrAppealExists
+	}
+
+	newAppeal := models.ModerationAppeal{
+		ContentType: contentType,
+		ContentID:   content.ID,
+		Barangay_ID: content.Barangay_ID,
+		UserID:      userID,
+		Statement:   appeal.Statement,
+		Status:      models.AppealOpen,
+	}
+	if err := s.db.Create(&newAppeal).Error; err != nil {
+		return 0, fmt.Errorf("failed to file appeal: %w", err)
+	}
+
+	return newAppeal.ID, nil
+}
+
+func (s *ModerationService) ListAppeals(barangay_ID string, params url.Values) ([]models.AppealResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, APPEAL_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var appeals []models.AppealResponse
+	meta, err := ListPage(s.db.Model(&models.ModerationAppeal{}).Where("barangay_id = ?", barangay_ID_int), query, &appeals, nil)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+	if err := s.maskAppellants(appeals); err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return appeals, meta, nil
+}
+
+// maskAppellants hides who appealed for feedback posted without the
+// author's name.
+func (s *ModerationService) maskAppellants(appeals []models.AppealResponse) error {
+	var feedbackIDs []uint
+	for _, appeal := range appeals {
+		if appeal.ContentType == models.ContentFeedback {
+			feedbackIDs = append(feedbackIDs, appeal.ContentID)
+		}
+	}
+	if len(feedbackIDs) == 0 {
+		return nil
+	}
+
+	var unnamed []uint
+	if err := s.db.Model(&models.Feedback{}).
+		Where("id IN ? AND identity <> ?", feedbackIDs, models.IdentityNamed).
+		Pluck("id", &unnamed).Error; err != nil {
+		return fmt.Errorf("failed to check appellants: %w", err)
+	}
+
+	for i := range appeals {
+		if appeals[i].ContentType == models.ContentFeedback && slices.Contains(unnamed, appeals[i].ContentID) {
+			appeals[i].UserID = 0
+		}
+	}
+	return nil
+}
+
+// ResolveAppeal decides an open appeal, overturning it publishes the content
+// again. Officials decide appeals of their own barangay, a barangay_ID of 0
+// lets admins decide any.
+func (s *ModerationService) ResolveAppeal(reviewerID uint, barangay_ID uint, appealID string, resolve models.ResolveAppeal) error {
+	id, err := strconv.ParseUint(appealID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidAppealID, appealID)
+	}
+
+	query := s.db.Where("id = ?", id)
+	if barangay_ID != 0 {
+		query = query.Where("barangay_id = ?", barangay_ID)
+	}
+	var appeal models.ModerationAppeal
+	if err := query.First(&appeal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: ID %d", ErrAppealNotFound, id)
+		}
+		return fmt.Errorf("failed to find appeal: %w", err)
+	}
+	if appeal.Status != models.AppealOpen {
+		return ErrAppealClosed
+	}
+
+	content, err := s.findContent(appeal.ContentType, strconv.FormatUint(uint64(appeal.ContentID), 10))
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if resolve.Decision == models.AppealOverturned && content.ModerationState != models.ModerationPublished {
+			note := fmt.Sprintf("appeal %d overturned", appeal.ID)
+			if resolve.Note != "" {
+				note += ": " + resolve.Note
+			}
+			if err := setState(tx, content, reviewerID, models.ModerationPublished, "", note); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&appeal).Updates(map[string]interface{}{
+			"status":         resolve.Decision,
+			"reviewed_by_id": reviewerID,
+			"decision_note":  resolve.Note,
+			"reviewed_at":    time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to resolve appeal: %w", err)
+		}
+		return nil
+	})
+}
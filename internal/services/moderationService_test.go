@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var moderatedColumns = []string{"id", "content_type", "content", "moderation_state", "user_id", "project_id", "barangay_id", "created_at"}
+
+func TestModerationService_Moderate(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewModerationService(gormDB)
+
+	if err := svc.Moderate(3, 1, models.ContentFeedback, "7", models.ModerateContent{State: models.ModerationHidden}); !errors.Is(err, ErrModerationReasonRequired) {
+		t.Errorf("Expected ErrModerationReasonRequired, got %v", err)
+	}
+	if err := svc.Moderate(3, 1, "project", "7", models.ModerateContent{State: models.ModerationPublished}); !errors.Is(err, ErrInvalidContentType) {
+		t.Errorf("Expected ErrInvalidContentType, got %v", err)
+	}
+
+	// officials cannot reach feedback of another barangay
+	mock.ExpectQuery(`SELECT (.+) FROM "feedbacks" JOIN projects ON projects.id = feedbacks.project_id JOIN users ON users.id = feedbacks.user_id WHERE feedbacks.deleted_at IS NULL AND feedbacks.id = \$1`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(moderatedColumns).AddRow(7, "feedback", "Spam link", "pending", 5, 2, 2, time.Now()))
+
+	decision := models.ModerateContent{State: models.ModerationRemoved, Reason: models.ReasonSpam, Note: "link farm"}
+	if err := svc.Moderate(3, 1, models.ContentFeedback, "7", decision); !errors.Is(err, ErrModeratedContentNotFound) {
+		t.Errorf("Expected ErrModeratedContentNotFound, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "feedbacks" (.+) WHERE feedbacks.deleted_at IS NULL AND feedbacks.id = \$1`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(moderatedColumns).AddRow(7, "feedback", "Spam link", "pending", 5, 2, 2, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "feedbacks" SET "moderated_at"=\$1,"moderated_by_id"=\$2,"moderation_reason"=\$3,"moderation_state"=\$4 WHERE id = \$5`).
+		WithArgs(sqlmock.AnyArg(), 3, models.ReasonSpam, models.ModerationRemoved, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "moderation_actions"`).
+		WithArgs(models.ContentFeedback, 7, 3, models.ModerationPending, models.ModerationRemoved, "link farm", sqlmock.AnyArg(), models.ReasonSpam).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	// admins moderate any barangay
+	if err := svc.Moderate(3, 0, models.ContentFeedback, "7", decision); err != nil {
+		t.Errorf("Moderate() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestModerationService_Appeal(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewModerationService(gormDB)
+
+	appeal := models.NewAppeal{Statement: "The photo was of my own house, not someone else's."}
+	content := func(state string) *sqlmock.Rows {
+		return sqlmock.NewRows(moderatedColumns).AddRow(4, "reply", "See photo", state, 5, 2, 1, time.Now())
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "feedback_replies" JOIN feedbacks ON feedbacks.id = feedback_replies.feedback_id`).
+		WithArgs(4, 1).
+		WillReturnRows(content(models.ModerationHidden))
+	if _, err := svc.Appeal(6, models.ContentReply, "4", appeal); !errors.Is(err, ErrAppealNotAuthor) {
+		t.Errorf("Expected ErrAppealNotAuthor, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "feedback_replies"`).
+		WithArgs(4, 1).
+		WillReturnRows(content(models.ModerationPending))
+	if _, err := svc.Appeal(5, models.ContentReply, "4", appeal); !errors.Is(err, ErrAppealNotAllowed) {
+		t.Errorf("Expected ErrAppealNotAllowed, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "feedback_replies"`).
+		WithArgs(4, 1).
+		WillReturnRows(content(models.ModerationHidden))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "moderation_appeals" WHERE content_type = \$1 AND content_id = \$2 AND status = \$3`).
+		WithArgs(models.ContentReply, 4, models.AppealOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	if _, err := svc.Appeal(5, models.ContentReply, "4", appeal); !errors.Is(err, ErrAppealExists) {
+		t.Errorf("Expected ErrAppealExists, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "feedback_replies"`).
+		WithArgs(4, 1).
+		WillReturnRows(content(models.ModerationHidden))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "moderation_appeals"`).
+		WithArgs(models.ContentReply, 4, models.AppealOpen).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "moderation_appeals"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectCommit()
+
+	appealID, err := svc.Appeal(5, models.ContentReply, "4", appeal)
+	if err != nil {
+		t.Fatalf("Appeal() error = %v", err)
+	}
+	if appealID != 2 {
+		t.Errorf("Expected appeal 2, got %d", appealID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestModerationService_ResolveAppeal(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewModerationService(gormDB)
+
+	appealColumns := []string{"id", "content_type", "content_id", "barangay_id", "user_id", "status"}
+
+	mock.ExpectQuery(`SELECT \* FROM "moderation_appeals" WHERE id = \$1 AND barangay_id = \$2`).
+		WithArgs(2, 1, 1).
+		WillReturnRows(sqlmock.NewRows(appealColumns).AddRow(2, "feedback", 7, 1, 5, models.AppealUpheld))
+	if err := svc.ResolveAppeal(3, 1, "2", models.ResolveAppeal{Decision: models.AppealOverturned}); !errors.Is(err, ErrAppealClosed) {
+		t.Errorf("Expected ErrAppealClosed, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "moderation_appeals" WHERE id = \$1 AND barangay_id = \$2`).
+		WithArgs(2, 1, 1).
+		WillReturnRows(sqlmock.NewRows(appealColumns).AddRow(2, "feedback", 7, 1, 5, models.AppealOpen))
+	mock.ExpectQuery(`SELECT (.+) FROM "feedbacks"`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(moderatedColumns).AddRow(7, "feedback", "Road is flooded", "hidden", 5, 2, 1, time.Now()))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "feedbacks" SET (.+) WHERE id = \$5`).
+		WithArgs(sqlmock.AnyArg(), 3, nil, models.ModerationPublished, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "moderation_actions"`).
+		WithArgs(models.ContentFeedback, 7, 3, models.ModerationHidden, models.ModerationPublished, "appeal 2 overturned: photo shows a public road", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`UPDATE "moderation_appeals" SET (.+) WHERE "id" = \$6`).
+		WithArgs("photo shows a public road", sqlmock.AnyArg(), 3, models.AppealOverturned, sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.ResolveAppeal(3, 1, "2", models.ResolveAppeal{Decision: models.AppealOverturned, Note: "photo shows a public road"}); err != nil {
+		t.Errorf("ResolveAppeal() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,457 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrOfficialNotFound      = NotFoundError("official not found")
+	ErrInvalidOfficialID     = ValidationError("invalid official ID format")
+	ErrOfficialTermOrder     = FieldValidationError("termEnd", "termEnd must be after termStart")
+	ErrOfficialEndedAtRange  = FieldValidationError("endedAt", "endedAt must fall within the term")
+	ErrOfficialTermEnded     = ConflictError("official's term has already ended")
+	ErrOfficialSeatTaken     = ConflictError("every seat for this position is filled during that term")
+	ErrOfficialUserNotFound  = FieldValidationError("user_id", "user_id does not belong to a user of this barangay")
+	ErrOfficialOtherBarangay = ForbiddenError("official belongs to another barangay")
+	ErrOfficialNotInOffice   = ForbiddenError("only an official in office in this barangay can do this")
+)
+
+var (
+	// seats per barangay, from the Local Government Code
+	OFFICIAL_SEATS = map[string]int64{
+		models.PositionPunongBarangay: 1,
+		models.PositionKagawad:        7,
+		models.PositionSKChairperson:  1,
+		models.PositionSecretary:      1,
+		models.PositionTreasurer:      1,
+	}
+
+	OFFICIAL_PHOTO_SIZE           = 512
+	OFFICIAL_PHOTO_THUMBNAIL_SIZE = 128
+
+	// directory order, punong barangay first
+	officialPositionOrder = `CASE position
+		WHEN 'punong_barangay' THEN 1
+		WHEN 'kagawad' THEN 2
+		WHEN 'sk_chairperson' THEN 3
+		WHEN 'secretary' THEN 4
+		WHEN 'treasurer' THEN 5
+	END`
+
+	OFFICIAL_HISTORY_LIST_SPEC = ListSpec{
+		Sorts: map[string]SortField{
+			"term_start": {Column: "term_start", Field: "TermStart"},
+			"last_name":  {Column: "last_name", Field: "LastName"},
+		},
+		DefaultSort: "-term_start",
+		Filters: map[string]FilterField{
+			"position":   {Column: "position", Kind: FilterEnum, Values: []string{models.PositionPunongBarangay, models.PositionKagawad, models.PositionSKChairperson, models.PositionSecretary, models.PositionTreasurer}},
+			"term_start": {Column: "term_start", Kind: FilterDateRange},
+		},
+		TextColumns: []string{"first_name", "last_name"},
+	}
+)
+
+// a term is in office from term_start until it ended early or ran out
+const officialInOffice = "term_start <= ? AND COALESCE(ended_at, term_end) > ?"
+
+type OfficialService struct {
+	db        *gorm.DB
+	uploadDir string
+}
+
+func NewOfficialService(db *gorm.DB) *OfficialService {
+	return &OfficialService{db: db, uploadDir: UploadDirectory()}
+}
+
+func parseOfficialID(officialID string) (uint, error) {
+	id, err := strconv.ParseUint(officialID, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidOfficialID, officialID)
+	}
+	return uint(id), nil
+}
+
+func committeeRows(names []string) []models.OfficialCommittee {
+	committees := make([]models.OfficialCommittee, len(names))
+	for i, name := range names {
+		committees[i] = models.OfficialCommittee{Name: name}
+	}
+	return committees
+}
+
+func (s *OfficialService) checkOfficialUser(barangay_ID uint, userID *uint) error {
+	if userID == nil {
+		return nil
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("id = ? AND barangay_id = ?", *userID, barangay_ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check user: %w", err)
+	}
+	if count == 0 {
+		return ErrOfficialUserNotFound
+	}
+	return nil
+}
+
+// seatTerm is the time a term of office holds a seat
+type seatTerm struct {
+	TermStart time.Time
+	Until     time.Time
+}
+
+// mostSeatsFilled returns the most terms holding a seat at the same moment.
+// Terms that follow one another share a seat, a term ending the day the next
+// one starts does not overlap it.
+func mostSeatsFilled(terms []seatTerm) int64 {
+	type change struct {
+		at    time.Time
+		delta int64
+	}
+
+	changes := make([]change, 0, 2*len(terms))
+	for _, term := range terms {
+		changes = append(changes, change{term.TermStart, 1}, change{term.Until, -1})
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].at.Equal(changes[j].at) {
+			return changes[i].delta < changes[j].delta
+		}
+		return changes[i].at.Before(changes[j].at)
+	})
+
+	var filled, most int64
+	for _, c := range changes {
+		filled += c.delta
+		if filled > most {
+			most = filled
+		}
+	}
+	return most
+}
+
+// AddOfficial records a term of office. At no moment of the term may more
+// terms of the same position be in office than the position has seats; the
+// barangay row is locked while seats are counted so concurrent adds cannot
+// both take the last one.
+func (s *OfficialService) AddOfficial(barangay_ID uint, newOfficial models.NewOfficial) (uint, error) {
+	termStart, err := time.Parse(GO_DATE_FORMAT, newOfficial.TermStart)
+	if err != nil {
+		return 0, FieldValidationError("termStart", "termStart must be a date in YYYY-MM-DD format")
+	}
+	termEnd, err := time.Parse(GO_DATE_FORMAT, newOfficial.TermEnd)
+	if err != nil {
+		return 0, FieldValidationError("termEnd", "termEnd must be a date in YYYY-MM-DD format")
+	}
+	if !termEnd.After(termStart) {
+		return 0, ErrOfficialTermOrder
+	}
+
+	if err := s.checkOfficialUser(barangay_ID, newOfficial.UserID); err != nil {
+		return 0, err
+	}
+
+	official := models.Official{
+		Barangay_ID: barangay_ID,
+		UserID:      newOfficial.UserID,
+		FirstName:   newOfficial.FirstName,
+		LastName:    newOfficial.LastName,
+		Position:    newOfficial.Position,
+		TermStart:   termStart,
+		TermEnd:     termEnd,
+		Committees:  committeeRows(newOfficial.Committees),
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var barangay models.Barangay
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("id = ?", barangay_ID).Take(&barangay).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: ID %d", ErrBarangayNotFound, barangay_ID)
+			}
+			return fmt.Errorf("failed to lock barangay: %w", err)
+		}
+
+		// every term found reaches into the new one, so the most filled
+		// moment among them falls within the new term
+		var overlapping []seatTerm
+		if err := tx.Model(&models.Official{}).
+			Select("term_start, COALESCE(ended_at, term_end) AS until").
+			Where("barangay_id = ? AND position = ?", barangay_ID, newOfficial.Position).
+			Where("term_start < ? AND COALESCE(ended_at, term_end) > ?", termEnd, termStart).
+			Scan(&overlapping).Error; err != nil {
+			return fmt.Errorf("failed to check seats: %w", err)
+		}
+		if mostSeatsFilled(overlapping) >= OFFICIAL_SEATS[newOfficial.Position] {
+			return ErrOfficialSeatTaken
+		}
+
+		if err := tx.Create(&official).Error; err != nil {
+			return fmt.Errorf("failed to create official: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return official.ID, nil
+}
+
+func (s *OfficialService) findOfficial(barangay_ID uint, officialID string) (models.Official, error) {
+	id, err := parseOfficialID(officialID)
+	if err != nil {
+		return models.Official{}, err
+	}
+
+	var official models.Official
+	if err := s.db.Where("id = ?", id).First(&official).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Official{}, fmt.Errorf("%w: ID %d", ErrOfficialNotFound, id)
+		}
+		return models.Official{}, fmt.Errorf("failed to find official: %w", err)
+	}
+	if official.Barangay_ID != barangay_ID {
+		return models.Official{}, ErrOfficialOtherBarangay
+	}
+
+	return official, nil
+}
+
+// UpdateOfficial corrects names, committees and the linked account. Terms
+// are fixed once recorded, an early end goes through EndTerm.
+func (s *OfficialService) UpdateOfficial(barangay_ID uint, officialID string, update models.UpdateOfficial) error {
+	official, err := s.findOfficial(barangay_ID, officialID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkOfficialUser(barangay_ID, update.UserID); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&official).Updates(map[string]interface{}{
+			"first_name": update.FirstName,
+			"last_name":  update.LastName,
+			"user_id":    update.UserID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update official: %w", err)
+		}
+
+		if err := tx.Where("official_id = ?", official.ID).Delete(&models.OfficialCommittee{}).Error; err != nil {
+			return fmt.Errorf("failed to clear committees: %w", err)
+		}
+
+		committees := committeeRows(update.Committees)
+		for i := range committees {
+			committees[i].OfficialID = official.ID
+		}
+		if len(committees) > 0 {
+			if err := tx.Create(&committees).Error; err != nil {
+				return fmt.Errorf("failed to save committees: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// EndTerm closes a term before its scheduled end. The row stays so the
+// official remains on record for the decisions made while in office.
+func (s *OfficialService) EndTerm(barangay_ID uint, officialID string, end models.EndOfficialTerm) error {
+	official, err := s.findOfficial(barangay_ID, officialID)
+	if err != nil {
+		return err
+	}
+	if official.EndedAt != nil {
+		return ErrOfficialTermEnded
+	}
+
+	endedAt, err := time.Parse(GO_DATE_FORMAT, end.EndedAt)
+	if err != nil {
+		return FieldValidationError("endedAt", "endedAt must be a date in YYYY-MM-DD format")
+	}
+	if endedAt.Before(official.TermStart) || endedAt.After(official.TermEnd) {
+		return ErrOfficialEndedAtRange
+	}
+
+	if err := s.db.Model(&official).Updates(map[string]interface{}{
+		"ended_at":   endedAt,
+		"end_reason": end.EndReason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to end term: %w", err)
+	}
+
+	return nil
+}
+
+func (s *OfficialService) UpdateOfficialPhoto(barangay_ID uint, officialID string, file io.Reader) (models.OfficialPhotoResponse, error) {
+	official, err := s.findOfficial(barangay_ID, officialID)
+	if err != nil {
+		return models.OfficialPhotoResponse{}, err
+	}
+
+	img, err := decodeImage(file)
+	if err != nil {
+		return models.OfficialPhotoResponse{}, err
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return models.OfficialPhotoResponse{}, fmt.Errorf("failed to name photo: %w", err)
+	}
+
+	base := fmt.Sprintf("official-%d-%s", official.ID, suffix)
+
+	photo, err := savePNG(s.uploadDir, "officials", fmt.Sprintf("%s-%d.png", base, OFFICIAL_PHOTO_SIZE), squareThumbnail(img, OFFICIAL_PHOTO_SIZE))
+	if err != nil {
+		return models.OfficialPhotoResponse{}, err
+	}
+
+	thumbnail, err := savePNG(s.uploadDir, "officials", fmt.Sprintf("%s-%d.png", base, OFFICIAL_PHOTO_THUMBNAIL_SIZE), squareThumbnail(img, OFFICIAL_PHOTO_THUMBNAIL_SIZE))
+	if err != nil {
+		return models.OfficialPhotoResponse{}, err
+	}
+
+	if err := s.db.Model(&official).Updates(map[string]interface{}{
+		"photo_url":       photo,
+		"photo_thumbnail": thumbnail,
+	}).Error; err != nil {
+		return models.OfficialPhotoResponse{}, fmt.Errorf("failed to save official photo: %w", err)
+	}
+
+	return models.OfficialPhotoResponse{PhotoURL: photo, PhotoThumbnail: thumbnail}, nil
+}
+
+// attachCommittees fills the committees of each official in one query.
+func (s *OfficialService) attachCommittees(officials []models.OfficialResponse) error {
+	if len(officials) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, len(officials))
+	for i, official := range officials {
+		ids[i] = official.ID
+		officials[i].Committees = []string{}
+	}
+
+	var committees []models.OfficialCommittee
+	if err := s.db.Where("official_id IN ?", ids).Order("id").Find(&committees).Error; err != nil {
+		return fmt.Errorf("failed to retrieve committees: %w", err)
+	}
+
+	byOfficial := make(map[uint][]string)
+	for _, committee := range committees {
+		byOfficial[committee.OfficialID] = append(byOfficial[committee.OfficialID], committee.Name)
+	}
+	for i := range officials {
+		if names, ok := byOfficial[officials[i].ID]; ok {
+			officials[i].Committees = names
+		}
+	}
+
+	return nil
+}
+
+// CurrentOfficials lists who held office on as_of, today when empty.
+func (s *OfficialService) CurrentOfficials(barangay_ID string, asOf string) ([]models.OfficialResponse, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	date := time.Now()
+	if asOf != "" {
+		date, err = time.Parse(GO_DATE_FORMAT, asOf)
+		if err != nil {
+			return nil, FieldValidationError("as_of", "as_of must be a date in YYYY-MM-DD format")
+		}
+	}
+
+	var officials []models.OfficialResponse
+	if err := s.db.Model(&models.Official{}).
+		Where("barangay_id = ?", barangay_ID_int).
+		Where(officialInOffice, date, date).
+		Order(officialPositionOrder).
+		Order("last_name").
+		Scan(&officials).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve officials: %w", err)
+	}
+
+	if officials == nil {
+		officials = []models.OfficialResponse{}
+	}
+	return officials, s.attachCommittees(officials)
+}
+
+// OfficialHistory lists every term recorded for the barangay, past ones included.
+func (s *OfficialService) OfficialHistory(barangay_ID string, params url.Values) ([]models.OfficialResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, OFFICIAL_HISTORY_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var officials []models.OfficialResponse
+	meta, err := ListPage(s.db.Model(&models.Official{}).Where("barangay_id = ?", barangay_ID_int), query, &officials, nil)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return officials, meta, s.attachCommittees(officials)
+}
+
+func (s *OfficialService) GetOfficial(officialID string) (models.OfficialResponse, error) {
+	id, err := parseOfficialID(officialID)
+	if err != nil {
+		return models.OfficialResponse{}, err
+	}
+
+	var official models.OfficialResponse
+	if err := s.db.Model(&models.Official{}).Where("id = ?", id).First(&official).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.OfficialResponse{}, fmt.Errorf("%w: ID %d", ErrOfficialNotFound, id)
+		}
+		return models.OfficialResponse{}, fmt.Errorf("failed to find official: %w", err)
+	}
+
+	officials := []models.OfficialResponse{official}
+	if err := s.attachCommittees(officials); err != nil {
+		return models.OfficialResponse{}, err
+	}
+	return officials[0], nil
+}
+
+// officialTermOf returns the term userID holds in barangay_ID at the given
+// time, or ErrOfficialNotInOffice when the user is not an official there then.
+func officialTermOf(tx *gorm.DB, userID uint, barangay_ID uint, at time.Time) (uint, error) {
+	var ids []uint
+	if err := tx.Model(&models.Official{}).
+		Where("user_id = ? AND barangay_id = ?", userID, barangay_ID).
+		Where(officialInOffice, at, at).
+		Order("term_start DESC").
+		Limit(1).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to find official term: %w", err)
+	}
+
+	if len(ids) == 0 {
+		return 0, ErrOfficialNotInOffice
+	}
+	return ids[0], nil
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var fullTerm = [2]string{"2023-11-30", "2026-11-30"}
+
+func seatRows(terms ...[2]string) *sqlmock.Rows {
+	rows := sqlmock.NewRows([]string{"term_start", "until"})
+	for _, term := range terms {
+		start, _ := time.Parse(GO_DATE_FORMAT, term[0])
+		until, _ := time.Parse(GO_DATE_FORMAT, term[1])
+		rows.AddRow(start, until)
+	}
+	return rows
+}
+
+func TestMostSeatsFilled(t *testing.T) {
+	date := func(value string) time.Time {
+		parsed, _ := time.Parse(GO_DATE_FORMAT, value)
+		return parsed
+	}
+
+	tests := []struct {
+		name  string
+		terms []seatTerm
+		want  int64
+	}{
+		{name: "None", terms: nil, want: 0},
+		{name: "Back to back share a seat", terms: []seatTerm{
+			{date("2023-11-30"), date("2025-01-01")},
+			{date("2025-01-01"), date("2026-11-30")},
+		}, want: 1},
+		{name: "Overlapping", terms: []seatTerm{
+			{date("2023-11-30"), date("2026-11-30")},
+			{date("2024-06-01"), date("2025-06-01")},
+			{date("2025-01-01"), date("2026-01-01")},
+		}, want: 3},
+		{name: "Apart", terms: []seatTerm{
+			{date("2023-11-30"), date("2026-11-30")},
+			{date("2023-11-30"), date("2024-06-01")},
+			{date("2025-01-01"), date("2026-11-30")},
+		}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mostSeatsFilled(tt.terms); got != tt.want {
+				t.Errorf("mostSeatsFilled() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfficialService_AddOfficial(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewOfficialService(gormDB)
+
+	kagawad := models.NewOfficial{
+		FirstName:  "Maria",
+		LastName:   "Santos",
+		Position:   models.PositionKagawad,
+		Committees: []string{"Infrastructure", "Health"},
+		TermStart:  "2023-11-30",
+		TermEnd:    "2026-11-30",
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "barangays" WHERE id = \$1 AND "barangays"."deleted_at" IS NULL LIMIT \$2 FOR UPDATE`).
+		WithArgs(uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	// seven terms overlap the new one, but never more than six at once
+	mock.ExpectQuery(`SELECT term_start, COALESCE\(ended_at, term_end\) AS until FROM "officials" WHERE \(barangay_id = \$1 AND position = \$2\) AND \(term_start < \$3 AND COALESCE\(ended_at, term_end\) > \$4\)`).
+		WithArgs(uint(1), models.PositionKagawad, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(seatRows(
+			fullTerm, fullTerm, fullTerm, fullTerm, fullTerm,
+			[2]string{"2023-11-30", "2024-11-30"},
+			[2]string{"2025-01-15", "2026-11-30"},
+		))
+	mock.ExpectQuery(`INSERT INTO "officials"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectQuery(`INSERT INTO "official_committees" \("official_id","name"\) VALUES \(\$1,\$2\),\(\$3,\$4\)`).
+		WithArgs(12, "Infrastructure", 12, "Health").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	id, err := svc.AddOfficial(1, kagawad)
+	if err != nil || id != 12 {
+		t.Fatalf("AddOfficial() = %d, %v", id, err)
+	}
+
+	// the seventh kagawad seat is taken
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "barangays" WHERE id = \$1 AND "barangays"."deleted_at" IS NULL LIMIT \$2 FOR UPDATE`).
+		WithArgs(uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT term_start, COALESCE\(ended_at, term_end\) AS until FROM "officials"`).
+		WillReturnRows(seatRows(fullTerm, fullTerm, fullTerm, fullTerm, fullTerm, fullTerm, fullTerm))
+	mock.ExpectRollback()
+
+	if _, err := svc.AddOfficial(1, kagawad); !errors.Is(err, ErrOfficialSeatTaken) {
+		t.Errorf("Expected ErrOfficialSeatTaken, got %v", err)
+	}
+
+	kagawad.TermEnd = "2023-01-01"
+	if _, err := svc.AddOfficial(1, kagawad); !errors.Is(err, ErrOfficialTermOrder) {
+		t.Errorf("Expected ErrOfficialTermOrder, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOfficialService_CurrentOfficials(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewOfficialService(gormDB)
+
+	asOf := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT \* FROM "officials" WHERE barangay_id = \$1 AND \(term_start <= \$2 AND COALESCE\(ended_at, term_end\) > \$3\) AND "officials"."deleted_at" IS NULL ORDER BY CASE position (.+) END,last_name`).
+		WithArgs(1, asOf, asOf).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "first_name", "last_name", "position"}).
+			AddRow(3, "Jose", "Reyes", models.PositionPunongBarangay).
+			AddRow(4, "Ana", "Cruz", models.PositionKagawad))
+	mock.ExpectQuery(`SELECT \* FROM "official_committees" WHERE official_id IN \(\$1,\$2\) ORDER BY id`).
+		WithArgs(3, 4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "official_id", "name"}).AddRow(1, 4, "Peace and Order"))
+
+	officials, err := svc.CurrentOfficials("1", "2020-06-01")
+	if err != nil {
+		t.Fatalf("CurrentOfficials() error = %v", err)
+	}
+	if len(officials) != 2 || len(officials[0].Committees) != 0 || officials[1].Committees[0] != "Peace and Order" {
+		t.Errorf("Unexpected officials: %+v", officials)
+	}
+
+	if _, err := svc.CurrentOfficials("1", "June 2020"); err == nil {
+		t.Error("Expected an error for a malformed as_of date")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOfficialService_EndTerm(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewOfficialService(gormDB)
+
+	termRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "barangay_id", "position", "term_start", "term_end"}).
+			AddRow(3, 1, models.PositionPunongBarangay, time.Date(2023, 11, 30, 0, 0, 0, 0, time.UTC), time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC))
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "officials" WHERE id = \$1`).WillReturnRows(termRows())
+	if err := svc.EndTerm(2, "3", models.EndOfficialTerm{EndedAt: "2024-05-01", EndReason: "Resigned"}); !errors.Is(err, ErrOfficialOtherBarangay) {
+		t.Errorf("Expected ErrOfficialOtherBarangay, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "officials" WHERE id = \$1`).WillReturnRows(termRows())
+	if err := svc.EndTerm(1, "3", models.EndOfficialTerm{EndedAt: "2027-01-01", EndReason: "Resigned"}); !errors.Is(err, ErrOfficialEndedAtRange) {
+		t.Errorf("Expected ErrOfficialEndedAtRange, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "officials" WHERE id = \$1`).WillReturnRows(termRows())
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "officials" SET "end_reason"=\$1,"ended_at"=\$2,"updated_at"=\$3 WHERE "officials"."deleted_at" IS NULL AND "id" = \$4`).
+		WithArgs("Resigned", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), sqlmock.AnyArg(), 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := svc.EndTerm(1, "3", models.EndOfficialTerm{EndedAt: "2024-05-01", EndReason: "Resigned"}); err != nil {
+		t.Errorf("EndTerm() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
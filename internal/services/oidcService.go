@@ -0,0 +1,496 @@
+package services
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCNotConfigured = NotFoundError("single sign-on is not configured")
+	ErrOIDCDiscovery     = newKindError(KindUpstream, "failed to load identity provider configuration")
+	ErrOIDCTokenExchange = UnauthorizedError("identity provider rejected the authorization code")
+	ErrInvalidIDToken    = UnauthorizedError("identity provider returned an invalid ID token")
+	ErrOIDCInvalidState  = ValidationError("single sign-on state mismatch, please try logging in again")
+	OIDC_CLOCK_SKEW      = time.Minute
+	OIDC_DEFAULT_SCOPES  = []string{"openid", "email", "profile"}
+	OIDC_DEFAULT_GROUPS  = "groups"
+	OIDC_HTTP_TIMEOUT    = 10 * time.Second
+	oidcRolePrecedence   = map[string]int{models.RoleCitizen: 0, models.RoleOfficial: 1, models.RoleAdmin: 2}
+)
+
+// OIDCConfig describes the city IdP this backend acts as a relying party for.
+type OIDCConfig struct {
+	Issuer        string
+	ClientID      string
+	ClientSecret  string
+	RedirectURL   string
+	Scopes        []string
+	GroupsClaim   string
+	BarangayClaim string            // optional claim holding the user's barangay name
+	RoleMapping   map[string]string // IdP group -> models.Role*
+}
+
+// OIDCConfigFromEnv reads OIDC_* variables. OIDC_ROLE_MAP is a comma
+// separated list of group=role pairs, e.g. "brgy-staff=official,city-it=admin".
+func OIDCConfigFromEnv() OIDCConfig {
+	config := OIDCConfig{
+		Issuer:        os.Getenv("OIDC_ISSUER"),
+		ClientID:      os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:  os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:   os.Getenv("OIDC_REDIRECT_URL"),
+		GroupsClaim:   os.Getenv("OIDC_GROUPS_CLAIM"),
+		BarangayClaim: os.Getenv("OIDC_BARANGAY_CLAIM"),
+		RoleMapping:   map[string]string{},
+	}
+
+	if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+		config.Scopes = strings.Fields(scopes)
+	}
+
+	for _, pair := range strings.Split(os.Getenv("OIDC_ROLE_MAP"), ",") {
+		group, role, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && group != "" {
+			config.RoleMapping[group] = role
+		}
+	}
+
+	return config
+}
+
+// OIDCAuthRequest carries the per-login secrets the handler keeps in the
+// session until the IdP redirects back.
+type OIDCAuthRequest struct {
+	URL          string
+	State        string
+	Nonce        string
+	CodeVerifier string
+}
+
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type OIDCService struct {
+	db     *gorm.DB
+	config OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	provider *oidcProviderMetadata
+	keys     map[string]*rsa.PublicKey
+}
+
+func NewOIDCService(db *gorm.DB, config OIDCConfig) *OIDCService {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	if len(config.Scopes) == 0 {
+		config.Scopes = OIDC_DEFAULT_SCOPES
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = OIDC_DEFAULT_GROUPS
+	}
+
+	return &OIDCService{db: db, config: config, client: &http.Client{Timeout: OIDC_HTTP_TIMEOUT}}
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s.config.Issuer != "" && s.config.ClientID != "" && s.config.RedirectURL != ""
+}
+
+func (s *OIDCService) discover() (*oidcProviderMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+
+	resp, err := s.client.Get(s.config.Issuer + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", ErrOIDCDiscovery, resp.StatusCode)
+	}
+
+	var provider oidcProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&provider); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+
+	if strings.TrimSuffix(provider.Issuer, "/") != s.config.Issuer {
+		return nil, fmt.Errorf("%w: issuer %q does not match %q", ErrOIDCDiscovery, provider.Issuer, s.config.Issuer)
+	}
+
+	s.provider = &provider
+	return s.provider, nil
+}
+
+// NewAuthRequest builds the authorization code + PKCE (S256) redirect.
+func (s *OIDCService) NewAuthRequest() (OIDCAuthRequest, error) {
+	if !s.Enabled() {
+		return OIDCAuthRequest{}, ErrOIDCNotConfigured
+	}
+
+	provider, err := s.discover()
+	if err != nil {
+		return OIDCAuthRequest{}, err
+	}
+
+	var request OIDCAuthRequest
+	for _, value := range []*string{&request.State, &request.Nonce, &request.CodeVerifier} {
+		if *value, err = randomHex(32); err != nil {
+			return OIDCAuthRequest{}, fmt.Errorf("failed to generate login secrets: %w", err)
+		}
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.config.ClientID},
+		"redirect_uri":          {s.config.RedirectURL},
+		"scope":                 {strings.Join(s.config.Scopes, " ")},
+		"state":                 {request.State},
+		"nonce":                 {request.Nonce},
+		"code_challenge":        {pkceChallenge(request.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	request.URL = provider.AuthorizationEndpoint + separator + query.Encode()
+
+	return request, nil
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Exchange redeems the authorization code and returns the verified claims
+// of the ID token issued with it.
+func (s *OIDCService) Exchange(code string, codeVerifier string, nonce string) (models.OIDCClaims, error) {
+	if !s.Enabled() {
+		return models.OIDCClaims{}, ErrOIDCNotConfigured
+	}
+
+	provider, err := s.discover()
+	if err != nil {
+		return models.OIDCClaims{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.config.RedirectURL},
+		"client_id":     {s.config.ClientID},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return models.OIDCClaims{}, fmt.Errorf("%w: %v", ErrOIDCTokenExchange, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return models.OIDCClaims{}, fmt.Errorf("%w: %v", ErrOIDCTokenExchange, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.OIDCClaims{}, fmt.Errorf("%w: status %d", ErrOIDCTokenExchange, resp.StatusCode)
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.IDToken == "" {
+		return models.OIDCClaims{}, fmt.Errorf("%w: missing id_token", ErrOIDCTokenExchange)
+	}
+
+	return s.verifyIDToken(token.IDToken, nonce)
+}
+
+// verifyIDToken checks an RS256 signed ID token against the IdP's JWKS
+// and validates issuer, audience, expiry and nonce.
+func (s *OIDCService) verifyIDToken(raw string, nonce string) (models.OIDCClaims, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return models.OIDCClaims{}, fmt.Errorf("%w: malformed token", ErrInvalidIDToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return models.OIDCClaims{}, err
+	}
+	if header.Alg != "RS256" {
+		return models.OIDCClaims{}, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidIDToken, header.Alg)
+	}
+
+	key, err := s.signingKey(header.Kid)
+	if err != nil {
+		return models.OIDCClaims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return models.OIDCClaims{}, fmt.Errorf("%w: malformed signature", ErrInvalidIDToken)
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return models.OIDCClaims{}, fmt.Errorf("%w: bad signature", ErrInvalidIDToken)
+	}
+
+	var payload map[string]interface{}
+	if err := decodeJWTSegment(parts[1], &payload); err != nil {
+		return models.OIDCClaims{}, err
+	}
+
+	if iss, _ := payload["iss"].(string); strings.TrimSuffix(iss, "/") != s.config.Issuer {
+		return models.OIDCClaims{}, fmt.Errorf("%w: unexpected issuer", ErrInvalidIDToken)
+	}
+
+	if !containsString(stringList(payload["aud"]), s.config.ClientID) {
+		return models.OIDCClaims{}, fmt.Errorf("%w: unexpected audience", ErrInvalidIDToken)
+	}
+
+	exp, _ := payload["exp"].(float64)
+	if time.Now().Add(-OIDC_CLOCK_SKEW).After(time.Unix(int64(exp), 0)) {
+		return models.OIDCClaims{}, fmt.Errorf("%w: token expired", ErrInvalidIDToken)
+	}
+
+	if tokenNonce, _ := payload["nonce"].(string); tokenNonce == "" || tokenNonce != nonce {
+		return models.OIDCClaims{}, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+
+	claims := models.OIDCClaims{Issuer: s.config.Issuer, Groups: stringList(payload[s.config.GroupsClaim])}
+	claims.Subject, _ = payload["sub"].(string)
+	claims.Email, _ = payload["email"].(string)
+	claims.EmailVerified, _ = payload["email_verified"].(bool)
+	claims.FirstName, _ = payload["given_name"].(string)
+	claims.LastName, _ = payload["family_name"].(string)
+	if s.config.BarangayClaim != "" {
+		claims.Barangay, _ = payload[s.config.BarangayClaim].(string)
+	}
+
+	if claims.Subject == "" {
+		return models.OIDCClaims{}, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+func decodeJWTSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidIDToken)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidIDToken)
+	}
+
+	return nil
+}
+
+// stringList reads a claim that may be a single string or a list of strings.
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if str, ok := item.(string); ok {
+				list = append(list, str)
+			}
+		}
+		return list
+	}
+
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// signingKey returns the JWKS key for kid, refetching the key set once when
+// the IdP has rotated to a key we have not seen yet.
+func (s *OIDCService) signingKey(kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	key, ok := s.keys[kid]
+	s.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	if err := s.fetchKeys(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("%w: unknown signing key %q", ErrInvalidIDToken, kid)
+}
+
+func (s *OIDCService) fetchKeys() error {
+	provider, err := s.discover()
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Get(provider.JWKSURI)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+	defer resp.Body.Close()
+
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("%w: %v", ErrOIDCDiscovery, err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+
+		n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+		e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+		if errN != nil || errE != nil {
+			continue
+		}
+
+		keys[jwk.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+
+	return nil
+}
+
+// MapRole picks the most privileged role granted by the user's IdP groups,
+// falling back to citizen.
+func (s *OIDCService) MapRole(groups []string) string {
+	role := models.RoleCitizen
+	for _, group := range groups {
+		mapped, ok := s.config.RoleMapping[group]
+		if ok && oidcRolePrecedence[mapped] > oidcRolePrecedence[role] {
+			role = mapped
+		}
+	}
+
+	return role
+}
+
+// LinkOrProvisionUser finds the account linked to the IdP subject, links an
+// existing account with the same verified email, or creates a new one. New
+// accounts take the role of their IdP groups. Groups only ever raise the role
+// of existing accounts, so officials and admins without a mapped group keep
+// theirs.
+func (s *OIDCService) LinkOrProvisionUser(claims models.OIDCClaims) (models.UserStruct, error) {
+
+	var user models.User
+	err := s.db.Where("oidc_issuer = ? AND oidc_subject = ?", claims.Issuer, claims.Subject).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.UserStruct{}, fmt.Errorf("failed to look up linked account: %w", err)
+	}
+
+	email, emailErr := NormalizeEmail(claims.Email)
+
+	if errors.Is(err, gorm.ErrRecordNotFound) && claims.EmailVerified && emailErr == nil {
+		err = s.db.Where("LOWER(email) = ?", email).First(&user).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.UserStruct{}, fmt.Errorf("failed to look up account by email: %w", err)
+		}
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if emailErr != nil {
+			return models.UserStruct{}, fmt.Errorf("%w: email claim is required to provision an account", ErrInvalidIDToken)
+		}
+
+		user = models.User{Email: email, FirstName: claims.FirstName, LastName: claims.LastName, Role: models.RoleCitizen}
+	}
+
+	user.OIDCIssuer = claims.Issuer
+	user.OIDCSubject = claims.Subject
+	if role := s.MapRole(claims.Groups); oidcRolePrecedence[role] > oidcRolePrecedence[user.Role] {
+		user.Role = role
+	}
+
+	if claims.Barangay != "" {
+		var barangay models.Barangay
+		if err := s.db.Select("id").Where("LOWER(name) = LOWER(?)", claims.Barangay).First(&barangay).Error; err == nil {
+			user.Barangay_ID = &barangay.ID
+		}
+	}
+
+	if err := s.db.Save(&user).Error; err != nil {
+		return models.UserStruct{}, fmt.Errorf("failed to save linked account: %w", err)
+	}
+
+	session := models.UserStruct{ID: user.ID, Role: user.Role, SessionVersion: user.SessionVersion}
+
+	if user.Barangay_ID != nil {
+		var barangay models.Barangay
+		if err := s.db.Select("name").Where("id = ?", *user.Barangay_ID).First(&barangay).Error; err == nil {
+			session.Barangay_ID = *user.Barangay_ID
+			session.Barangay_Name = barangay.Name
+		}
+	}
+
+	return session, nil
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakeIdP is a minimal OpenID provider: discovery, JWKS and a token
+// endpoint that enforces PKCE for codes registered with authorize.
+type fakeIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]interface{}
+
+	mu    sync.Mutex
+	codes map[string]fakeAuthorization
+}
+
+type fakeAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	idp := &fakeIdP{t: t, key: key, codes: map[string]fakeAuthorization{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test-key",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+
+		idp.mu.Lock()
+		auth, ok := idp.codes[r.Form.Get("code")]
+		delete(idp.codes, r.Form.Get("code"))
+		idp.mu.Unlock()
+
+		if !ok || pkceChallenge(r.Form.Get("code_verifier")) != auth.challenge || r.Form.Get("client_id") != "wow-bato" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		claims := idp.baseClaims()
+		claims["nonce"] = auth.nonce
+		json.NewEncoder(w).Encode(map[string]string{"id_token": idp.sign(claims), "token_type": "Bearer"})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+
+	return idp
+}
+
+func (idp *fakeIdP) baseClaims() map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":            idp.server.URL,
+		"aud":            "wow-bato",
+		"sub":            "staff-001",
+		"email":          "Maria.Santos@Cebu.gov.ph",
+		"email_verified": true,
+		"given_name":     "Maria",
+		"family_name":    "Santos",
+		"groups":         []string{"all-staff", "brgy-officials"},
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+
+	for key, value := range idp.claims {
+		claims[key] = value
+	}
+
+	return claims
+}
+
+// authorize stands in for the browser leg: it records the code the IdP
+// would issue for the given authorization URL.
+func (idp *fakeIdP) authorize(authURL string, code string) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		idp.t.Fatalf("Invalid authorization URL: %v", err)
+	}
+
+	query := parsed.Query()
+	idp.mu.Lock()
+	idp.codes[code] = fakeAuthorization{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.mu.Unlock()
+}
+
+func (idp *fakeIdP) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test-key", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	if err != nil {
+		idp.t.Fatalf("Failed to sign token: %v", err)
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func newTestOIDCService(idp *fakeIdP, db *gorm.DB) *OIDCService {
+	return NewOIDCService(db, OIDCConfig{
+		Issuer:      idp.server.URL,
+		ClientID:    "wow-bato",
+		RedirectURL: "http://localhost:8080/api/v1/user/oidc/callback",
+		RoleMapping: map[string]string{"brgy-officials": models.RoleOfficial, "city-it": models.RoleAdmin},
+	})
+}
+
+func TestOIDCService_AuthorizationCodeFlow(t *testing.T) {
+	idp := newFakeIdP(t)
+	svc := newTestOIDCService(idp, nil)
+
+	request, err := svc.NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest() error = %v", err)
+	}
+
+	query, _ := url.ParseQuery(request.URL[len(idp.server.URL+"/authorize?"):])
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") != pkceChallenge(request.CodeVerifier) {
+		t.Errorf("Expected S256 PKCE challenge in %s", request.URL)
+	}
+	if query.Get("state") != request.State || query.Get("nonce") != request.Nonce {
+		t.Errorf("Expected state and nonce in %s", request.URL)
+	}
+
+	idp.authorize(request.URL, "code-123")
+
+	claims, err := svc.Exchange("code-123", request.CodeVerifier, request.Nonce)
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	if claims.Subject != "staff-001" || claims.Email != "Maria.Santos@Cebu.gov.ph" || !claims.EmailVerified {
+		t.Errorf("Unexpected claims %+v", claims)
+	}
+
+	if role := svc.MapRole(claims.Groups); role != models.RoleOfficial {
+		t.Errorf("MapRole() = %s, want %s", role, models.RoleOfficial)
+	}
+}
+
+func TestOIDCService_Exchange_WrongVerifier(t *testing.T) {
+	idp := newFakeIdP(t)
+	svc := newTestOIDCService(idp, nil)
+
+	request, err := svc.NewAuthRequest()
+	if err != nil {
+		t.Fatalf("NewAuthRequest() error = %v", err)
+	}
+	idp.authorize(request.URL, "code-123")
+
+	_, err = svc.Exchange("code-123", "stolen-code-without-verifier", request.Nonce)
+	if !errors.Is(err, ErrOIDCTokenExchange) {
+		t.Errorf("Expected ErrOIDCTokenExchange, got %v", err)
+	}
+}
+
+func TestOIDCService_VerifyIDToken(t *testing.T) {
+	idp := newFakeIdP(t)
+	svc := newTestOIDCService(idp, nil)
+
+	tests := []struct {
+		name    string
+		modify  func(claims map[string]interface{})
+		tamper  bool
+		wantErr bool
+	}{
+		{name: "Valid", modify: func(claims map[string]interface{}) {}},
+		{name: "Audience List", modify: func(claims map[string]interface{}) { claims["aud"] = []string{"other", "wow-bato"} }},
+		{name: "Wrong Nonce", modify: func(claims map[string]interface{}) { claims["nonce"] = "replayed" }, wantErr: true},
+		{name: "Expired", modify: func(claims map[string]interface{}) { claims["exp"] = time.Now().Add(-time.Hour).Unix() }, wantErr: true},
+		{name: "Wrong Audience", modify: func(claims map[string]interface{}) { claims["aud"] = "another-app" }, wantErr: true},
+		{name: "Wrong Issuer", modify: func(claims map[string]interface{}) { claims["iss"] = "https://evil.example" }, wantErr: true},
+		{name: "Tampered Signature", modify: func(claims map[string]interface{}) {}, tamper: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := idp.baseClaims()
+			claims["nonce"] = "nonce-1"
+			tt.modify(claims)
+
+			token := idp.sign(claims)
+			if tt.tamper {
+				token = token[:len(token)-4] + "AAAA"
+			}
+
+			_, err := svc.verifyIDToken(token, "nonce-1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyIDToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidIDToken) {
+				t.Errorf("verifyIDToken() error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestOIDCService_MapRole(t *testing.T) {
+	svc := NewOIDCService(nil, OIDCConfig{
+		RoleMapping: map[string]string{"brgy-officials": models.RoleOfficial, "city-it": models.RoleAdmin},
+	})
+
+	tests := []struct {
+		name   string
+		groups []string
+		want   string
+	}{
+		{name: "No Groups", groups: nil, want: models.RoleCitizen},
+		{name: "Unmapped Group", groups: []string{"all-staff"}, want: models.RoleCitizen},
+		{name: "Official", groups: []string{"all-staff", "brgy-officials"}, want: models.RoleOfficial},
+		{name: "Highest Wins", groups: []string{"city-it", "brgy-officials"}, want: models.RoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := svc.MapRole(tt.groups); got != tt.want {
+				t.Errorf("MapRole() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOIDCService_LinkOrProvisionUser_Provisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+
+	svc := NewOIDCService(gormDB, OIDCConfig{
+		Issuer:      "https://sso.cebucity.gov.ph",
+		RoleMapping: map[string]string{"brgy-officials": models.RoleOfficial},
+	})
+
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE \(oidc_issuer = \$1 AND oidc_subject = \$2\)`).
+		WithArgs("https://sso.cebucity.gov.ph", "staff-001", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE LOWER\(email\) = \$1`).
+		WithArgs("maria.santos@cebu.gov.ph", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	user, err := svc.LinkOrProvisionUser(models.OIDCClaims{
+		Issuer:        "https://sso.cebucity.gov.ph",
+		Subject:       "staff-001",
+		Email:         "Maria.Santos@Cebu.gov.ph",
+		EmailVerified: true,
+		FirstName:     "Maria",
+		LastName:      "Santos",
+		Groups:        []string{"brgy-officials"},
+	})
+	if err != nil {
+		t.Fatalf("LinkOrProvisionUser() error = %v", err)
+	}
+
+	if user.ID != 7 || user.Role != models.RoleOfficial {
+		t.Errorf("Unexpected session user %+v", user)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOIDCService_LinkOrProvisionUser_KeepsRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+
+	svc := NewOIDCService(gormDB, OIDCConfig{
+		Issuer:      "https://sso.cebucity.gov.ph",
+		RoleMapping: map[string]string{"brgy-officials": models.RoleOfficial},
+	})
+
+	// a local official account without a mapped group is linked, not demoted
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE \(oidc_issuer = \$1 AND oidc_subject = \$2\)`).
+		WithArgs("https://sso.cebucity.gov.ph", "staff-002", 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE LOWER\(email\) = \$1`).
+		WithArgs("jose.reyes@cebu.gov.ph", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role"}).AddRow(4, "jose.reyes@cebu.gov.ph", models.RoleOfficial))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET (.+) WHERE "users"."deleted_at" IS NULL AND "id" = \$\d+`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	user, err := svc.LinkOrProvisionUser(models.OIDCClaims{
+		Issuer:        "https://sso.cebucity.gov.ph",
+		Subject:       "staff-002",
+		Email:         "jose.reyes@cebu.gov.ph",
+		EmailVerified: true,
+		Groups:        []string{"all-staff"},
+	})
+	if err != nil {
+		t.Fatalf("LinkOrProvisionUser() error = %v", err)
+	}
+
+	if user.ID != 4 || user.Role != models.RoleOfficial {
+		t.Errorf("Unexpected session user %+v", user)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestOIDCService_NotConfigured(t *testing.T) {
+	svc := NewOIDCService(nil, OIDCConfig{})
+
+	if _, err := svc.NewAuthRequest(); !errors.Is(err, ErrOIDCNotConfigured) {
+		t.Errorf("Expected ErrOIDCNotConfigured, got %v", err)
+	}
+}
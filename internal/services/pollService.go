@@ -0,0 +1,408 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPollID       = ValidationError("invalid poll ID format")
+	ErrPollNotFound        = NotFoundError("poll not found")
+	ErrPollCandidates      = FieldValidationError("project_IDs", "candidates must be planned projects of the barangay")
+	ErrPollClosesInPast    = FieldValidationError("closes_at", "a poll must close in the future")
+	ErrPollStarted         = ConflictError("poll has opened and can no longer be withdrawn")
+	ErrPollNotOpen         = ConflictError("poll is not open for voting")
+	ErrNotEligibleVoter    = ForbiddenError("only verified residents of the barangay can vote in its polls")
+	ErrAlreadyVoted        = ConflictError("you have already voted in this poll")
+	ErrSingleChoice        = FieldValidationError("choices", "this poll takes a single choice")
+	ErrInvalidBallot       = FieldValidationError("choices", "choices must be distinct candidates of the poll")
+	ErrResultsNotPublished = ConflictError("results are published once the poll closes")
+)
+
+var POLL_LIST_SPEC = ListSpec{
+	Sorts: map[string]SortField{
+		"opens_at":  {Column: "opens_at", Field: "OpensAt"},
+		"closes_at": {Column: "closes_at", Field: "ClosesAt"},
+	},
+	DefaultSort: "-opens_at",
+	Filters: map[string]FilterField{
+		"fiscal_year": {Column: "fiscal_year IN ?", Kind: FilterMatch},
+		"method":      {Column: "method", Kind: FilterEnum, Values: []string{models.PollSingle, models.PollRanked}},
+	},
+	TextColumns: []string{"title", "description"},
+}
+
+const pollColumns = "id, barangay_id, fiscal_year, title, description, method, opens_at, closes_at"
+
+func pollStatus(opensAt time.Time, closesAt time.Time, now time.Time) string {
+	switch {
+	case now.Before(opensAt):
+		return models.PollUpcoming
+	case now.Before(closesAt):
+		return models.PollOpen
+	}
+	return models.PollClosed
+}
+
+type PollService struct {
+	db *gorm.DB
+}
+
+func NewPollService(db *gorm.DB) *PollService {
+	return &PollService{db: db}
+}
+
+func parsePollID(pollID string) (uint, error) {
+	id, err := strconv.ParseUint(pollID, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidPollID, pollID)
+	}
+	return uint(id), nil
+}
+
+func (s *PollService) findPoll(pollID string) (models.Poll, error) {
+	id, err := parsePollID(pollID)
+	if err != nil {
+		return models.Poll{}, err
+	}
+
+	var poll models.Poll
+	if err := s.db.Where("id = ?", id).Take(&poll).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Poll{}, fmt.Errorf("%w: ID %d", ErrPollNotFound, id)
+		}
+		return models.Poll{}, err
+	}
+	return poll, nil
+}
+
+// CreatePoll opens a poll of the official's barangay on planned projects of
+// the barangay. Residents can vote between opens_at and closes_at.
+func (s *PollService) CreatePoll(officialID uint, barangay_ID uint, newPoll models.NewPoll) (uint, error) {
+	if !newPoll.ClosesAt.After(time.Now()) {
+		return 0, ErrPollClosesInPast
+	}
+
+	projectIDs := slices.Clone(newPoll.ProjectIDs)
+	slices.Sort(projectIDs)
+	projectIDs = slices.Compact(projectIDs)
+
+	var planned int64
+	if err := s.db.Model(&models.Project{}).
+		Where("id IN ? AND barangay_id = ? AND status = ?", projectIDs, barangay_ID, "planned").
+		Count(&planned).Error; err != nil {
+		return 0, err
+	}
+	if int(planned) != len(projectIDs) || len(projectIDs) < 2 {
+		return 0, ErrPollCandidates
+	}
+
+	poll := models.Poll{
+		Barangay_ID: barangay_ID,
+		FiscalYear:  newPoll.FiscalYear,
+		Title:       newPoll.Title,
+		Description: newPoll.Description,
+		Method:      newPoll.Method,
+		OpensAt:     newPoll.OpensAt,
+		ClosesAt:    newPoll.ClosesAt,
+		CreatedByID: officialID,
+	}
+	for _, projectID := range projectIDs {
+		poll.Candidates = append(poll.Candidates, models.PollCandidate{ProjectID: projectID})
+	}
+
+	if err := s.db.Create(&poll).Error; err != nil {
+		return 0, fmt.Errorf("failed to create poll: %w", err)
+	}
+	return poll.ID, nil
+}
+
+// DeletePoll withdraws a poll of the official's barangay that has not opened.
+func (s *PollService) DeletePoll(barangay_ID uint, pollID string) error {
+	poll, err := s.findPoll(pollID)
+	if err != nil {
+		return err
+	}
+	if poll.Barangay_ID != barangay_ID {
+		return fmt.Errorf("%w: ID %d", ErrPollNotFound, poll.ID)
+	}
+	if !time.Now().Before(poll.OpensAt) {
+		return ErrPollStarted
+	}
+
+	return s.db.Delete(&poll).Error
+}
+
+// ListPolls lists the polls of a barangay.
+func (s *PollService) ListPolls(barangay_ID string, params url.Values) ([]models.PollResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, POLL_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var polls []models.PollResponse
+	meta, err := ListPage(s.db.Model(&models.Poll{}).Where("barangay_id = ?", barangay_ID_int), query, &polls, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(pollColumns)
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	now := time.Now()
+	for i := range polls {
+		polls[i].Status = pollStatus(polls[i].OpensAt, polls[i].ClosesAt, now)
+	}
+	return polls, meta, nil
+}
+
+// GetPoll returns a poll with its candidates and whether the viewer, 0 when
+// not logged in, has voted.
+func (s *PollService) GetPoll(pollID string, viewerID uint) (models.PollResponse, error) {
+	poll, err := s.findPoll(pollID)
+	if err != nil {
+		return models.PollResponse{}, err
+	}
+
+	response := models.PollResponse{
+		ID:          poll.ID,
+		Barangay_ID: poll.Barangay_ID,
+		FiscalYear:  poll.FiscalYear,
+		Title:       poll.Title,
+		Description: poll.Description,
+		Method:      poll.Method,
+		OpensAt:     poll.OpensAt,
+		ClosesAt:    poll.ClosesAt,
+		Status:      pollStatus(poll.OpensAt, poll.ClosesAt, time.Now()),
+	}
+
+	if err := s.db.Table("poll_candidates").
+		Select("poll_candidates.id, poll_candidates.project_id, projects.name AS project_name, projects.description").
+		Joins("JOIN projects ON projects.id = poll_candidates.project_id").
+		Where("poll_candidates.poll_id = ?", poll.ID).
+		Order("projects.name").
+		Scan(&response.Candidates).Error; err != nil {
+		return models.PollResponse{}, fmt.Errorf("failed to retrieve candidates: %w", err)
+	}
+
+	if viewerID != 0 {
+		var voted int64
+		if err := s.db.Model(&models.PollVoter{}).Where("poll_id = ? AND user_id = ?", poll.ID, viewerID).Count(&voted).Error; err != nil {
+			return models.PollResponse{}, err
+		}
+		response.Voted = voted > 0
+	}
+
+	return response, nil
+}
+
+// Vote casts a verified resident's ballot in an open poll of their barangay.
+// Who voted is recorded apart from the ballot and no endpoint returns a
+// voter's ballot. Both rows are written in one transaction, so anyone reading
+// the database directly can still pair them, the ballot is secret only
+// through the API.
+func (s *PollService) Vote(userID uint, pollID string, ballot models.CastBallot) error {
+	poll, err := s.findPoll(pollID)
+	if err != nil {
+		return err
+	}
+	if pollStatus(poll.OpensAt, poll.ClosesAt, time.Now()) != models.PollOpen {
+		return ErrPollNotOpen
+	}
+
+	var user models.User
+	if err := s.db.Select("id, role, barangay_id, residency_verified").Where("id = ?", userID).Take(&user).Error; err != nil {
+		return err
+	}
+	if user.Role != models.RoleCitizen || !user.ResidencyVerified || user.Barangay_ID == nil || *user.Barangay_ID != poll.Barangay_ID {
+		return ErrNotEligibleVoter
+	}
+
+	if poll.Method == models.PollSingle && len(ballot.Choices) != 1 {
+		return ErrSingleChoice
+	}
+	var candidates []uint
+	if err := s.db.Model(&models.PollCandidate{}).Where("poll_id = ?", poll.ID).Pluck("id", &candidates).Error; err != nil {
+		return err
+	}
+	choices := make([]models.PollChoice, len(ballot.Choices))
+	for i, candidateID := range ballot.Choices {
+		if !slices.Contains(candidates, candidateID) || slices.Contains(ballot.Choices[:i], candidateID) {
+			return ErrInvalidBallot
+		}
+		choices[i] = models.PollChoice{Rank: i + 1, CandidateID: candidateID}
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var voted int64
+		if err := tx.Model(&models.PollVoter{}).Where("poll_id = ? AND user_id = ?", poll.ID, userID).Count(&voted).Error; err != nil {
+			return err
+		}
+		if voted > 0 {
+			return ErrAlreadyVoted
+		}
+
+		// the voter's primary key still stops a second ballot cast at the same time
+		if err := tx.Create(&models.PollVoter{PollID: poll.ID, UserID: userID}).Error; err != nil {
+			return fmt.Errorf("failed to record voter: %w", err)
+		}
+		if err := tx.Create(&models.PollBallot{PollID: poll.ID, Choices: choices}).Error; err != nil {
+			return fmt.Errorf("failed to cast ballot: %w", err)
+		}
+		return nil
+	})
+}
+
+// Results counts the ballots of a closed poll. Single choice polls rank
+// candidates by votes and have no winner on a tie for first. Ranked polls
+// are counted by instant runoff.
+func (s *PollService) Results(pollID string) (models.PollResults, error) {
+	poll, err := s.findPoll(pollID)
+	if err != nil {
+		return models.PollResults{}, err
+	}
+	if pollStatus(poll.OpensAt, poll.ClosesAt, time.Now()) != models.PollClosed {
+		return models.PollResults{}, ErrResultsNotPublished
+	}
+
+	var candidates []models.CandidateTally
+	if err := s.db.Table("poll_candidates").
+		Select("poll_candidates.id AS candidate_id, poll_candidates.project_id, projects.name AS project_name").
+		Joins("JOIN projects ON projects.id = poll_candidates.project_id").
+		Where("poll_candidates.poll_id = ?", poll.ID).
+		Order("poll_candidates.id").
+		Scan(&candidates).Error; err != nil {
+		return models.PollResults{}, fmt.Errorf("failed to retrieve candidates: %w", err)
+	}
+
+	var choices []models.PollChoice
+	if err := s.db.Table("poll_choices").
+		Select("poll_choices.ballot_id, poll_choices.rank, poll_choices.candidate_id").
+		Joins("JOIN poll_ballots ON poll_ballots.id = poll_choices.ballot_id").
+		Where("poll_ballots.poll_id = ?", poll.ID).
+		Order("poll_choices.ballot_id, poll_choices.rank").
+		Scan(&choices).Error; err != nil {
+		return models.PollResults{}, fmt.Errorf("failed to retrieve ballots: %w", err)
+	}
+
+	var ballots [][]uint
+	for i, choice := range choices {
+		if i == 0 || choice.BallotID != choices[i-1].BallotID {
+			ballots = append(ballots, nil)
+		}
+		ballots[len(ballots)-1] = append(ballots[len(ballots)-1], choice.CandidateID)
+	}
+
+	results := models.PollResults{PollID: poll.ID, Method: poll.Method, Ballots: len(ballots)}
+	if err := s.db.Model(&models.User{}).
+		Where("role = ? AND barangay_id = ? AND residency_verified", models.RoleCitizen, poll.Barangay_ID).
+		Count(&results.Eligible).Error; err != nil {
+		return models.PollResults{}, err
+	}
+
+	if poll.Method == models.PollRanked && len(ballots) > 0 {
+		results.Rounds, results.Ranking = instantRunoff(candidates, ballots)
+		results.Winner = &results.Ranking[0]
+	} else {
+		results.Ranking = countFirstChoices(candidates, ballots)
+		if len(ballots) > 0 && (len(results.Ranking) < 2 || results.Ranking[0].Votes > results.Ranking[1].Votes) {
+			results.Winner = &results.Ranking[0]
+		}
+	}
+
+	return results, nil
+}
+
+// countFirstChoices tallies the first choice of every ballot, most votes
+// first.
+func countFirstChoices(candidates []models.CandidateTally, ballots [][]uint) []models.CandidateTally {
+	counts := map[uint]int{}
+	for _, ballot := range ballots {
+		counts[ballot[0]]++
+	}
+
+	tallies := slices.Clone(candidates)
+	for i := range tallies {
+		tallies[i].Votes = counts[tallies[i].CandidateID]
+	}
+	sort.SliceStable(tallies, func(i, j int) bool { return tallies[i].Votes > tallies[j].Votes })
+	return tallies
+}
+
+// instantRunoff counts each ballot for its highest ranked candidate still
+// running. A candidate with a majority of the ballots still counting wins;
+// otherwise the last is eliminated and the count repeats. A tie for last
+// eliminates the candidate with fewer first choices, then the one added
+// later. The ranking lists the winner, the rest of the final round, then the
+// eliminated candidates from last eliminated to first.
+func instantRunoff(candidates []models.CandidateTally, ballots [][]uint) ([]models.RunoffRound, []models.CandidateTally) {
+	running := map[uint]bool{}
+	for _, candidate := range candidates {
+		running[candidate.CandidateID] = true
+	}
+
+	var rounds []models.RunoffRound
+	var eliminated []models.CandidateTally
+	firstChoices := map[uint]int{}
+
+	for round := 1; ; round++ {
+		counts := map[uint]int{}
+		exhausted := 0
+		for _, ballot := range ballots {
+			i := slices.IndexFunc(ballot, func(candidateID uint) bool { return running[candidateID] })
+			if i < 0 {
+				exhausted++
+				continue
+			}
+			counts[ballot[i]]++
+		}
+		if round == 1 {
+			firstChoices = counts
+		}
+
+		var tallies []models.CandidateTally
+		for _, candidate := range candidates {
+			if running[candidate.CandidateID] {
+				candidate.Votes = counts[candidate.CandidateID]
+				tallies = append(tallies, candidate)
+			}
+		}
+		sort.SliceStable(tallies, func(i, j int) bool {
+			if tallies[i].Votes != tallies[j].Votes {
+				return tallies[i].Votes > tallies[j].Votes
+			}
+			if firstChoices[tallies[i].CandidateID] != firstChoices[tallies[j].CandidateID] {
+				return firstChoices[tallies[i].CandidateID] > firstChoices[tallies[j].CandidateID]
+			}
+			return tallies[i].CandidateID < tallies[j].CandidateID
+		})
+
+		current := models.RunoffRound{Round: round, Tallies: tallies, Exhausted: exhausted}
+		if len(tallies) == 1 || tallies[0].Votes*2 > len(ballots)-exhausted {
+			rounds = append(rounds, current)
+			ranking := slices.Clone(tallies)
+			for i := len(eliminated) - 1; i >= 0; i-- {
+				ranking = append(ranking, eliminated[i])
+			}
+			return rounds, ranking
+		}
+
+		last := tallies[len(tallies)-1]
+		current.Eliminated = &last.CandidateID
+		running[last.CandidateID] = false
+		eliminated = append(eliminated, last)
+		rounds = append(rounds, current)
+	}
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestInstantRunoff(t *testing.T) {
+	candidates := []models.CandidateTally{
+		{CandidateID: 1, ProjectName: "Drainage upgrade"},
+		{CandidateID: 2, ProjectName: "Covered court"},
+		{CandidateID: 3, ProjectName: "Street lights"},
+	}
+	// no first round majority; street lights is eliminated and its voters
+	// carry the covered court past drainage
+	ballots := [][]uint{
+		{1}, {1}, {1, 3}, {1},
+		{2, 1}, {2}, {2, 3},
+		{3, 2}, {3, 2},
+	}
+
+	rounds, ranking := instantRunoff(candidates, ballots)
+	if len(rounds) != 2 {
+		t.Fatalf("instantRunoff() took %d rounds, want 2", len(rounds))
+	}
+	if rounds[0].Eliminated == nil || *rounds[0].Eliminated != 3 {
+		t.Errorf("round 1 eliminated %v, want street lights", rounds[0].Eliminated)
+	}
+	if rounds[1].Tallies[0].CandidateID != 2 || rounds[1].Tallies[0].Votes != 5 {
+		t.Errorf("round 2 = %+v, want covered court with 5 votes", rounds[1].Tallies)
+	}
+
+	var order []uint
+	for _, tally := range ranking {
+		order = append(order, tally.CandidateID)
+	}
+	if len(order) != 3 || order[0] != 2 || order[1] != 1 || order[2] != 3 {
+		t.Errorf("instantRunoff() ranking = %v, want [2 1 3]", order)
+	}
+
+	// ballots that run out of choices stop counting toward the majority
+	rounds, ranking = instantRunoff(candidates, [][]uint{{1}, {1}, {2}, {3}})
+	if ranking[0].CandidateID != 1 || len(rounds) != 2 || rounds[1].Exhausted != 1 {
+		t.Errorf("instantRunoff() = %+v, %+v, want drainage after one elimination", rounds, ranking)
+	}
+}
+
+func TestPollService_Vote(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPollService(gormDB)
+
+	now := time.Now()
+	pollColumns := []string{"id", "barangay_id", "method", "opens_at", "closes_at"}
+	openPoll := func(method string) {
+		mock.ExpectQuery(`SELECT \* FROM "polls" WHERE id = \$1`).
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows(pollColumns).AddRow(7, 1, method, now.Add(-time.Hour), now.Add(time.Hour)))
+	}
+	voter := func(barangay_ID int, verified bool) {
+		mock.ExpectQuery(`SELECT id, role, barangay_id, residency_verified FROM "users" WHERE id = \$1`).
+			WithArgs(5, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "role", "barangay_id", "residency_verified"}).AddRow(5, models.RoleCitizen, barangay_ID, verified))
+	}
+	candidates := func() {
+		mock.ExpectQuery(`SELECT "id" FROM "poll_candidates" WHERE poll_id = \$1`).
+			WithArgs(7).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(11).AddRow(12).AddRow(13))
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "polls" WHERE id = \$1`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(pollColumns).AddRow(7, 1, models.PollSingle, now.Add(-2*time.Hour), now.Add(-time.Hour)))
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{11}}); !errors.Is(err, ErrPollNotOpen) {
+		t.Errorf("Expected ErrPollNotOpen, got %v", err)
+	}
+
+	// residents of other barangays and unverified residents cannot vote
+	openPoll(models.PollSingle)
+	voter(2, true)
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{11}}); !errors.Is(err, ErrNotEligibleVoter) {
+		t.Errorf("Expected ErrNotEligibleVoter, got %v", err)
+	}
+	openPoll(models.PollSingle)
+	voter(1, false)
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{11}}); !errors.Is(err, ErrNotEligibleVoter) {
+		t.Errorf("Expected ErrNotEligibleVoter, got %v", err)
+	}
+
+	openPoll(models.PollSingle)
+	voter(1, true)
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{11, 12}}); !errors.Is(err, ErrSingleChoice) {
+		t.Errorf("Expected ErrSingleChoice, got %v", err)
+	}
+
+	openPoll(models.PollRanked)
+	voter(1, true)
+	candidates()
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{12, 12}}); !errors.Is(err, ErrInvalidBallot) {
+		t.Errorf("Expected ErrInvalidBallot, got %v", err)
+	}
+
+	openPoll(models.PollRanked)
+	voter(1, true)
+	candidates()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "poll_voters" WHERE poll_id = \$1 AND user_id = \$2`).
+		WithArgs(7, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{12, 11}}); !errors.Is(err, ErrAlreadyVoted) {
+		t.Errorf("Expected ErrAlreadyVoted, got %v", err)
+	}
+
+	// the ballot carries no trace of its voter
+	openPoll(models.PollRanked)
+	voter(1, true)
+	candidates()
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "poll_voters"`).
+		WithArgs(7, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(`INSERT INTO "poll_voters" \("poll_id","user_id"\) VALUES \(\$1,\$2\)`).
+		WithArgs(7, 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`INSERT INTO "poll_ballots" \("poll_id"\) VALUES \(\$1\) RETURNING "id"`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(40))
+	mock.ExpectExec(`INSERT INTO "poll_choices" \("ballot_id","rank","candidate_id"\) VALUES \(\$1,\$2,\$3\),\(\$4,\$5,\$6\)`).
+		WithArgs(40, 1, 12, 40, 2, 11).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	if err := svc.Vote(5, "7", models.CastBallot{Choices: []uint{12, 11}}); err != nil {
+		t.Errorf("Vote() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPollService_Results(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPollService(gormDB)
+
+	now := time.Now()
+	pollColumns := []string{"id", "barangay_id", "method", "opens_at", "closes_at"}
+
+	mock.ExpectQuery(`SELECT \* FROM "polls" WHERE id = \$1`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(pollColumns).AddRow(7, 1, models.PollSingle, now.Add(-time.Hour), now.Add(time.Hour)))
+	if _, err := svc.Results("7"); !errors.Is(err, ErrResultsNotPublished) {
+		t.Errorf("Expected ErrResultsNotPublished, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "polls" WHERE id = \$1`).
+		WithArgs(7, 1).
+		WillReturnRows(sqlmock.NewRows(pollColumns).AddRow(7, 1, models.PollSingle, now.Add(-48*time.Hour), now.Add(-time.Hour)))
+	mock.ExpectQuery(`SELECT poll_candidates.id AS candidate_id, (.+) FROM "poll_candidates" JOIN projects (.+) WHERE poll_candidates.poll_id = \$1 ORDER BY poll_candidates.id`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"candidate_id", "project_id", "project_name"}).
+			AddRow(11, 2, "Drainage upgrade").
+			AddRow(12, 3, "Covered court"))
+	mock.ExpectQuery(`SELECT poll_choices.ballot_id, (.+) FROM "poll_choices" JOIN poll_ballots (.+) WHERE poll_ballots.poll_id = \$1 ORDER BY poll_choices.ballot_id, poll_choices.rank`).
+		WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"ballot_id", "rank", "candidate_id"}).
+			AddRow(1, 1, 12).AddRow(2, 1, 11).AddRow(3, 1, 12))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "users" WHERE \(role = \$1 AND barangay_id = \$2 AND residency_verified\)`).
+		WithArgs(models.RoleCitizen, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(10))
+
+	results, err := svc.Results("7")
+	if err != nil {
+		t.Fatalf("Results() error = %v", err)
+	}
+	if results.Ballots != 3 || results.Eligible != 10 {
+		t.Errorf("Results() = %+v, want 3 of 10 eligible residents voting", results)
+	}
+	if results.Winner == nil || results.Winner.CandidateID != 12 || results.Winner.Votes != 2 {
+		t.Errorf("Results() winner = %+v, want covered court with 2 votes", results.Winner)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
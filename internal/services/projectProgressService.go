@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrProgressProjectNotFound  = NotFoundError("project was not found in this barangay")
+	ErrInvalidProgressProjectID = ValidationError("invalid project ID format")
+	ErrMilestoneNotFound        = NotFoundError("milestone not found")
+	ErrInvalidMilestoneID       = ValidationError("invalid milestone ID format")
+	ErrPhotoNotFound            = NotFoundError("project photo not found")
+	ErrInvalidPhotoID           = ValidationError("invalid photo ID format")
+	ErrCaptionTooLong           = FieldValidationError("caption", "caption must be at most 200 characters")
+)
+
+// site photos are kept at a size that prints well on a status sheet
+var (
+	PROJECT_PHOTO_MAX_SIDE    = 1280
+	PROJECT_PHOTO_MAX_CAPTION = 200
+)
+
+type ProjectProgressService struct {
+	db        *gorm.DB
+	uploadDir string
+}
+
+func NewProjectProgressService(db *gorm.DB) *ProjectProgressService {
+	return &ProjectProgressService{db: db, uploadDir: UploadDirectory()}
+}
+
+func (s *ProjectProgressService) findProject(barangay_ID uint, projectID string) (models.Project, error) {
+	id, err := strconv.ParseUint(projectID, 10, 32)
+	if err != nil {
+		return models.Project{}, fmt.Errorf("%w: %s", ErrInvalidProgressProjectID, projectID)
+	}
+
+	var project models.Project
+	if err := s.db.Where("id = ? AND barangay_id = ?", id, barangay_ID).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Project{}, fmt.Errorf("%w: ID %d", ErrProgressProjectNotFound, id)
+		}
+		return models.Project{}, fmt.Errorf("failed to find project: %w", err)
+	}
+
+	return project, nil
+}
+
+func (s *ProjectProgressService) AddMilestone(barangay_ID uint, projectID string, milestone models.NewMilestone) (uint, error) {
+	project, err := s.findProject(barangay_ID, projectID)
+	if err != nil {
+		return 0, err
+	}
+
+	due, err := time.Parse(GO_DATE_FORMAT, milestone.DueDate)
+	if err != nil {
+		return 0, FieldValidationError("dueDate", "dueDate must be a date in YYYY-MM-DD format")
+	}
+
+	newMilestone := models.ProjectMilestone{ProjectID: project.ID, Title: milestone.Title, DueDate: due}
+	if err := s.db.Create(&newMilestone).Error; err != nil {
+		return 0, fmt.Errorf("failed to create milestone: %w", err)
+	}
+
+	return newMilestone.ID, nil
+}
+
+// CompleteMilestone marks a milestone of the official's barangay as reached.
+func (s *ProjectProgressService) CompleteMilestone(barangay_ID uint, milestoneID string, complete models.CompleteMilestone) error {
+	id, err := strconv.ParseUint(milestoneID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidMilestoneID, milestoneID)
+	}
+
+	completed, err := time.Parse(GO_DATE_FORMAT, complete.CompletedAt)
+	if err != nil {
+		return FieldValidationError("completedAt", "completedAt must be a date in YYYY-MM-DD format")
+	}
+
+	result := s.db.Model(&models.ProjectMilestone{}).
+		Where("id = ? AND project_id IN (?)", id, s.db.Model(&models.Project{}).Select("id").Where("barangay_id = ?", barangay_ID)).
+		Update("completed_at", completed)
+	if result.Error != nil {
+		return fmt.Errorf("failed to complete milestone: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", ErrMilestoneNotFound, id)
+	}
+	return nil
+}
+
+func (s *ProjectProgressService) DeleteMilestone(barangay_ID uint, milestoneID string) error {
+	id, err := strconv.ParseUint(milestoneID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidMilestoneID, milestoneID)
+	}
+
+	result := s.db.Where("id = ? AND project_id IN (?)", id, s.db.Model(&models.Project{}).Select("id").Where("barangay_id = ?", barangay_ID)).
+		Delete(&models.ProjectMilestone{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete milestone: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", ErrMilestoneNotFound, id)
+	}
+	return nil
+}
+
+// AddPhoto stores a site photo of the project, scaled down so its longer
+// side is at most PROJECT_PHOTO_MAX_SIDE.
+func (s *ProjectProgressService) AddPhoto(barangay_ID uint, projectID string, caption string, file io.Reader) (models.ProjectPhotoResponse, error) {
+	if len([]rune(caption)) > PROJECT_PHOTO_MAX_CAPTION {
+		return models.ProjectPhotoResponse{}, ErrCaptionTooLong
+	}
+
+	project, err := s.findProject(barangay_ID, projectID)
+	if err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+
+	img, err := decodeImage(file)
+	if err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return models.ProjectPhotoResponse{}, fmt.Errorf("failed to name photo: %w", err)
+	}
+
+	url, err := savePNG(s.uploadDir, "projects", fmt.Sprintf("project-%d-%s.png", project.ID, suffix), fitImage(img, PROJECT_PHOTO_MAX_SIDE))
+	if err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+
+	photo := models.ProjectPhoto{ProjectID: project.ID, URL: url, Caption: caption}
+	if err := s.db.Create(&photo).Error; err != nil {
+		return models.ProjectPhotoResponse{}, fmt.Errorf("failed to save project photo: %w", err)
+	}
+
+	return models.ProjectPhotoResponse{ID: photo.ID, URL: photo.URL, Caption: photo.Caption, CreatedAt: photo.CreatedAt}, nil
+}
+
+func (s *ProjectProgressService) DeletePhoto(barangay_ID uint, photoID string) error {
+	id, err := strconv.ParseUint(photoID, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidPhotoID, photoID)
+	}
+
+	result := s.db.Where("id = ? AND project_id IN (?)", id, s.db.Model(&models.Project{}).Select("id").Where("barangay_id = ?", barangay_ID)).
+		Delete(&models.ProjectPhoto{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete project photo: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: ID %d", ErrPhotoNotFound, id)
+	}
+	return nil
+}
+
+// Progress lists the milestones of a project by due date and its photos
+// oldest first.
+func (s *ProjectProgressService) Progress(projectID string) (models.ProjectProgress, error) {
+	id, err := strconv.ParseUint(projectID, 10, 32)
+	if err != nil {
+		return models.ProjectProgress{}, fmt.Errorf("%w: %s", ErrInvalidProgressProjectID, projectID)
+	}
+
+	progress := models.ProjectProgress{ProjectID: uint(id)}
+	if err := s.db.Model(&models.ProjectMilestone{}).
+		Select("id, title, due_date, completed_at").
+		Where("project_id = ?", id).
+		Order("due_date, id").
+		Scan(&progress.Milestones).Error; err != nil {
+		return models.ProjectProgress{}, fmt.Errorf("failed to retrieve milestones: %w", err)
+	}
+	if err := s.db.Model(&models.ProjectPhoto{}).
+		Select("id, url, caption, created_at").
+		Where("project_id = ?", id).
+		Order("created_at, id").
+		Scan(&progress.Photos).Error; err != nil {
+		return models.ProjectProgress{}, fmt.Errorf("failed to retrieve project photos: %w", err)
+	}
+
+	if progress.Milestones == nil {
+		progress.Milestones = []models.MilestoneResponse{}
+	}
+	if progress.Photos == nil {
+		progress.Photos = []models.ProjectPhotoResponse{}
+	}
+	return progress, nil
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newProjectProgressServiceMock(t *testing.T) (*ProjectProgressService, sqlmock.Sqlmock, func() error) {
+	gormDB, mock, db := newMockDB(t)
+	svc := NewProjectProgressService(gormDB)
+	svc.uploadDir = t.TempDir()
+	return svc, mock, db.Close
+}
+
+// the project is looked up within the official's barangay, so projects of
+// other barangays are not found
+func expectProgressProject(mock sqlmock.Sqlmock, barangay_ID uint, found bool) {
+	rows := sqlmock.NewRows([]string{"id", "name", "barangay_id"})
+	if found {
+		rows.AddRow(3, "Drainage upgrade", barangay_ID)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "projects" WHERE \(id = \$1 AND barangay_id = \$2\) AND "projects"."deleted_at" IS NULL ORDER BY "projects"."id" LIMIT \$3`).
+		WithArgs(3, barangay_ID, 1).
+		WillReturnRows(rows)
+}
+
+func TestProjectProgressService_AddMilestone(t *testing.T) {
+	svc, mock, closeDB := newProjectProgressServiceMock(t)
+	defer closeDB()
+
+	milestone := models.NewMilestone{Title: "Excavation done", DueDate: "2025-03-31"}
+
+	expectProgressProject(mock, 2, false)
+	if _, err := svc.AddMilestone(2, "3", milestone); !errors.Is(err, ErrProgressProjectNotFound) {
+		t.Errorf("Expected ErrProgressProjectNotFound for another barangay's project, got %v", err)
+	}
+
+	expectProgressProject(mock, 1, true)
+	if _, err := svc.AddMilestone(1, "3", models.NewMilestone{Title: "Excavation done", DueDate: "03/31/2025"}); err == nil {
+		t.Error("Expected an error for a malformed due date")
+	}
+
+	expectProgressProject(mock, 1, true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "project_milestones" \("project_id","title","due_date","completed_at","created_at"\)`).
+		WithArgs(3, "Excavation done", time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), nil, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+	mock.ExpectCommit()
+
+	id, err := svc.AddMilestone(1, "3", milestone)
+	if err != nil || id != 8 {
+		t.Fatalf("AddMilestone() = %d, %v", id, err)
+	}
+
+	if _, err := svc.AddMilestone(1, "abc", milestone); !errors.Is(err, ErrInvalidProgressProjectID) {
+		t.Errorf("Expected ErrInvalidProgressProjectID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProjectProgressService_CompleteMilestone(t *testing.T) {
+	svc, mock, closeDB := newProjectProgressServiceMock(t)
+	defer closeDB()
+
+	complete := models.CompleteMilestone{CompletedAt: "2025-03-28"}
+	update := `UPDATE "project_milestones" SET "completed_at"=\$1 WHERE id = \$2 AND project_id IN \(SELECT "id" FROM "projects" WHERE barangay_id = \$3 AND "projects"."deleted_at" IS NULL\)`
+
+	// milestones of another barangay's project are not touched
+	mock.ExpectBegin()
+	mock.ExpectExec(update).
+		WithArgs(time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC), 8, uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := svc.CompleteMilestone(2, "8", complete); !errors.Is(err, ErrMilestoneNotFound) {
+		t.Errorf("Expected ErrMilestoneNotFound, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(update).
+		WithArgs(time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC), 8, uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := svc.CompleteMilestone(1, "8", complete); err != nil {
+		t.Errorf("CompleteMilestone() error = %v", err)
+	}
+
+	if err := svc.CompleteMilestone(1, "8", models.CompleteMilestone{CompletedAt: "yesterday"}); err == nil {
+		t.Error("Expected an error for a malformed completion date")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProjectProgressService_AddPhoto(t *testing.T) {
+	svc, mock, closeDB := newProjectProgressServiceMock(t)
+	defer closeDB()
+
+	var upload bytes.Buffer
+	if err := png.Encode(&upload, image.NewRGBA(image.Rect(0, 0, 2560, 1440))); err != nil {
+		t.Fatalf("Failed to encode upload: %v", err)
+	}
+
+	if _, err := svc.AddPhoto(1, "3", strings.Repeat("a", PROJECT_PHOTO_MAX_CAPTION+1), bytes.NewReader(upload.Bytes())); !errors.Is(err, ErrCaptionTooLong) {
+		t.Errorf("Expected ErrCaptionTooLong, got %v", err)
+	}
+
+	expectProgressProject(mock, 2, false)
+	if _, err := svc.AddPhoto(2, "3", "Culvert laid", bytes.NewReader(upload.Bytes())); !errors.Is(err, ErrProgressProjectNotFound) {
+		t.Errorf("Expected ErrProgressProjectNotFound for another barangay's project, got %v", err)
+	}
+
+	expectProgressProject(mock, 1, true)
+	if _, err := svc.AddPhoto(1, "3", "Culvert laid", strings.NewReader("not an image")); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Expected ErrInvalidImage, got %v", err)
+	}
+
+	expectProgressProject(mock, 1, true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "project_photos" \("project_id","url","caption","created_at"\)`).
+		WithArgs(3, sqlmock.AnyArg(), "Culvert laid", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectCommit()
+
+	photo, err := svc.AddPhoto(1, "3", "Culvert laid", bytes.NewReader(upload.Bytes()))
+	if err != nil {
+		t.Fatalf("AddPhoto() error = %v", err)
+	}
+	if photo.ID != 5 || !strings.HasPrefix(photo.URL, UPLOAD_URL_PREFIX+"/projects/project-3-") {
+		t.Errorf("AddPhoto() = %+v", photo)
+	}
+
+	// the stored photo is scaled down to fit, keeping its aspect ratio
+	saved, err := os.Open(filepath.Join(svc.uploadDir, "projects", path.Base(photo.URL)))
+	if err != nil {
+		t.Fatalf("Failed to open stored photo: %v", err)
+	}
+	defer saved.Close()
+	config, err := png.DecodeConfig(saved)
+	if err != nil {
+		t.Fatalf("Failed to decode stored photo: %v", err)
+	}
+	if config.Width != PROJECT_PHOTO_MAX_SIDE || config.Height != 720 {
+		t.Errorf("Stored photo is %dx%d, want %dx720", config.Width, config.Height, PROJECT_PHOTO_MAX_SIDE)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProjectProgressService_DeletePhoto(t *testing.T) {
+	svc, mock, closeDB := newProjectProgressServiceMock(t)
+	defer closeDB()
+
+	query := `DELETE FROM "project_photos" WHERE id = \$1 AND project_id IN \(SELECT "id" FROM "projects" WHERE barangay_id = \$2 AND "projects"."deleted_at" IS NULL\)`
+
+	// photos of another barangay's project are not deleted
+	mock.ExpectBegin()
+	mock.ExpectExec(query).
+		WithArgs(5, uint(2)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := svc.DeletePhoto(2, "5"); !errors.Is(err, ErrPhotoNotFound) {
+		t.Errorf("Expected ErrPhotoNotFound, got %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(query).
+		WithArgs(5, uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := svc.DeletePhoto(1, "5"); err != nil {
+		t.Errorf("DeletePhoto() error = %v", err)
+	}
+
+	if err := svc.DeletePhoto(1, "five"); !errors.Is(err, ErrInvalidPhotoID) {
+		t.Errorf("Expected ErrInvalidPhotoID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
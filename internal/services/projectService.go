@@ -1,8 +1,8 @@
 package services
 
 import (
-	"errors"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
 	"wow-bato-backend/internal/models"
@@ -12,12 +12,27 @@ import (
 
 var (
 	GO_DATE_FORMAT = "2006-01-02"
-	ErrParseStartDate = errors.New("something went wrong while parsing start date")  
-	ErrParseEndDate = errors.New("something went wrong while parsing end date") 
-	ErrProjectUpdate = errors.New("project to update not found")	
+	ErrParseStartDate = FieldValidationError("startDate", "something went wrong while parsing start date")  
+	ErrParseEndDate = FieldValidationError("endDate", "something went wrong while parsing end date") 
+	ErrProjectUpdate = NotFoundError("project to update not found")	
 )
 
 
+var PROJECT_LIST_SPEC = ListSpec{
+	Sorts: map[string]SortField{
+		"name":       {Column: "name", Field: "Name"},
+		"start_date": {Column: "start_date", Field: "StartDate"},
+		"end_date":   {Column: "end_date", Field: "EndDate"},
+	},
+	DefaultSort: "-start_date",
+	Filters: map[string]FilterField{
+		"status":     {Column: "status", Kind: FilterEnum, Values: []string{"planned", "ongoing", "completed"}},
+		"start_date": {Column: "start_date", Kind: FilterDateRange},
+		"end_date":   {Column: "end_date", Kind: FilterDateRange},
+	},
+	TextColumns: []string{"name", "description"},
+}
+
 type ProjectService struct {
 	db *gorm.DB
 }
@@ -92,36 +107,29 @@ func (s *ProjectService) UpdateProject(barangay_ID uint, projectID string, updat
 	return result.Error
 }
 
-func (s *ProjectService) GetAllProjects(barangay_ID uint, categoryID string, limit string, page string) ([]models.ProjectList, error) {
+func (s *ProjectService) GetAllProjects(barangay_ID uint, categoryID string, params url.Values) ([]models.ProjectList, models.PageMeta, error) {
 
-	limit_int, err := strconv.Atoi(limit)
+	categoryID_int, err := strconv.Atoi(categoryID)
 	if err != nil {
-		return []models.ProjectList{}, err
+		return []models.ProjectList{}, models.PageMeta{}, err
 	}
 
-	page_int, err := strconv.Atoi(page)
+	query, err := ParseListQuery(params, PROJECT_LIST_SPEC)
 	if err != nil {
-		return []models.ProjectList{}, err
+		return []models.ProjectList{}, models.PageMeta{}, err
 	}
 
-	offset := (page_int - 1) * limit_int
-
-	categoryID_int, err := strconv.Atoi(categoryID)
-	if err != nil {
-		return []models.ProjectList{}, err
-	}
+	base := s.db.Model(&models.Project{}).Where("barangay_id = ? AND category_id = ?", barangay_ID, categoryID_int)
 
 	var projects []models.ProjectList
-	if err := s.db.Model(&models.Project{}).
-		Where("barangay_id = ? AND category_id = ?", barangay_ID, categoryID_int).
-		Select("id, name, status, start_date, end_date").
-		Limit(limit_int).
-		Offset(offset).
-		Scan(&projects).Error; err != nil {
-		return []models.ProjectList{}, fmt.Errorf("failed to retrieve all projects: %w", err)
+	meta, err := ListPage(base, query, &projects, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id, name, status, start_date, end_date")
+	})
+	if err != nil {
+		return []models.ProjectList{}, models.PageMeta{}, fmt.Errorf("failed to retrieve all projects: %w", err)
 	}
 
-	return projects, nil
+	return projects, meta, nil
 }
 
 func (s *ProjectService) UpdateProjectStatus(projectID string, barangay_ID uint, newStatus models.NewProjectStatus) error {
@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"net/url"
 	"testing"
 	"time"
 	"wow-bato-backend/internal/models"
@@ -221,8 +222,7 @@ func TestProjectService_GetAllProjects(t *testing.T) {
 	// Test parameters
 	barangayID := uint(1)
 	categoryID := "2"
-	limit := "10"
-	page := "1"
+	params := url.Values{}
 
 	// Dates for test data - these should be formatted as strings as per the model
 	startDate1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
@@ -241,17 +241,25 @@ func TestProjectService_GetAllProjects(t *testing.T) {
 		AddRow(1, "Project 1", "ongoing", startDate1Str, endDate1Str).
 		AddRow(2, "Project 2", "planned", startDate2Str, endDate2Str)
 
-	// Expect query with pagination
-	mock.ExpectQuery(`SELECT id, name, status, start_date, end_date FROM "projects" WHERE barangay_id = \$1 AND category_id = \$2 LIMIT \$3 OFFSET \$4`).
-		WithArgs(barangayID, 2, 10, 0). // barangay_id, category_id, limit, offset
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "projects" WHERE \(barangay_id = \$1 AND category_id = \$2\)`).
+		WithArgs(barangayID, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	// Missing page and limit fall back to the first page, newest start first
+	mock.ExpectQuery(`SELECT id, name, status, start_date, end_date FROM "projects" WHERE (.+) ORDER BY start_date DESC,id DESC LIMIT \$3`).
+		WithArgs(barangayID, 2, DEFAULT_PAGE_LIMIT+1). // barangay_id, category_id, limit plus one
 		WillReturnRows(rows)
 
 	// Call the method
-	results, err := svc.GetAllProjects(barangayID, categoryID, limit, page)
+	results, meta, err := svc.GetAllProjects(barangayID, categoryID, params)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 
+	if meta.Page != 1 || meta.Limit != DEFAULT_PAGE_LIMIT || meta.Total != 2 {
+		t.Errorf("Unexpected page meta %+v", meta)
+	}
+
 	// Check results length
 	if len(results) != 2 {
 		t.Errorf("Expected 2 results, got %d", len(results))
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidProposalID      = ValidationError("invalid proposal ID format")
+	ErrProposalNotFound       = NotFoundError("proposal not found")
+	ErrNotVerifiedResident    = ForbiddenError("only verified residents can propose and endorse projects of their barangay")
+	ErrProposalClosed         = ConflictError("proposal has already been reviewed")
+	ErrProposalNotAccepted    = ConflictError("only accepted proposals can be converted into a project")
+	ErrProposalConverted      = ConflictError("proposal has already been converted into a project")
+	ErrProposalNoteRequired   = FieldValidationError("note", "a reason is required when declining a proposal")
+	ErrTooManyProposalPhotos  = ConflictError("a proposal can have at most 5 photos")
+	ErrEndorseOwnProposal     = ConflictError("you cannot endorse your own proposal")
+	ErrAlreadyEndorsed        = ConflictError("you already endorse this proposal")
+	ErrEndorsementNotFound    = NotFoundError("you do not endorse this proposal")
+	ErrProposalEndBeforeStart = FieldValidationError("endDate", "end date must not be before the start date")
+)
+
+var (
+	PROPOSAL_MAX_PHOTOS = 5
+
+	PROPOSAL_LIST_SPEC = ListSpec{
+		IDColumn: "proposals.id",
+		Sorts: map[string]SortField{
+			"created_at":     {Column: "proposals.created_at", Field: "CreatedAt"},
+			"endorsements":   {Column: "proposals.endorsement_count", Field: "Endorsements"},
+			"estimated_cost": {Column: "proposals.estimated_cost", Field: "EstimatedCost"},
+		},
+		DefaultSort: "-created_at",
+		Filters: map[string]FilterField{
+			"status":         {Column: "proposals.status", Kind: FilterEnum, Values: []string{models.ProposalSubmitted, models.ProposalAccepted, models.ProposalDeclined}},
+			"created_at":     {Column: "proposals.created_at", Kind: FilterDateRange},
+			"estimated_cost": {Column: "proposals.estimated_cost", Kind: FilterNumberRange},
+		},
+		TextColumns: []string{"proposals.title", "proposals.description", "proposals.location"},
+	}
+)
+
+const proposalColumns = `proposals.id, proposals.barangay_id, proposals.user_id, users.first_name, users.last_name,
+	proposals.title, proposals.description, proposals.location, proposals.estimated_cost, proposals.status,
+	proposals.review_note, proposals.reviewed_at, proposals.project_id, proposals.endorsement_count AS endorsements, proposals.created_at`
+
+type ProposalService struct {
+	db        *gorm.DB
+	uploadDir string
+}
+
+func NewProposalService(db *gorm.DB) *ProposalService {
+	return &ProposalService{db: db, uploadDir: UploadDirectory()}
+}
+
+// resident returns the barangay of a citizen whose residency was verified.
+func (s *ProposalService) resident(userID uint) (uint, error) {
+	var user models.User
+	if err := s.db.Select("id, role, barangay_id, residency_verified").Where("id = ?", userID).Take(&user).Error; err != nil {
+		return 0, err
+	}
+	if user.Role != models.RoleCitizen || !user.ResidencyVerified || user.Barangay_ID == nil {
+		return 0, ErrNotVerifiedResident
+	}
+	return *user.Barangay_ID, nil
+}
+
+func (s *ProposalService) findProposal(proposalID string) (models.Proposal, error) {
+	id, err := strconv.ParseUint(proposalID, 10, 32)
+	if err != nil {
+		return models.Proposal{}, fmt.Errorf("%w: %s", ErrInvalidProposalID, proposalID)
+	}
+
+	var proposal models.Proposal
+	if err := s.db.Where("id = ?", id).Take(&proposal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Proposal{}, fmt.Errorf("%w: ID %d", ErrProposalNotFound, id)
+		}
+		return models.Proposal{}, err
+	}
+	return proposal, nil
+}
+
+// officialProposal finds a proposal put to the official's barangay.
+func (s *ProposalService) officialProposal(barangay_ID uint, proposalID string) (models.Proposal, error) {
+	proposal, err := s.findProposal(proposalID)
+	if err != nil {
+		return models.Proposal{}, err
+	}
+	if proposal.Barangay_ID != barangay_ID {
+		return models.Proposal{}, fmt.Errorf("%w: ID %d", ErrProposalNotFound, proposal.ID)
+	}
+	return proposal, nil
+}
+
+// Submit puts a verified resident's project idea to their barangay.
+func (s *ProposalService) Submit(userID uint, newProposal models.NewProposal) (uint, error) {
+	barangay_ID, err := s.resident(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	proposal := models.Proposal{
+		Barangay_ID:   barangay_ID,
+		UserID:        userID,
+		Title:         strings.TrimSpace(newProposal.Title),
+		Description:   strings.TrimSpace(newProposal.Description),
+		Location:      strings.TrimSpace(newProposal.Location),
+		EstimatedCost: newProposal.EstimatedCost,
+		Status:        models.ProposalSubmitted,
+	}
+	if err := s.db.Create(&proposal).Error; err != nil {
+		return 0, fmt.Errorf("failed to submit proposal: %w", err)
+	}
+	return proposal.ID, nil
+}
+
+// AddPhoto attaches a photo to the author's proposal while it awaits
+// review, scaled down like project site photos.
+func (s *ProposalService) AddPhoto(userID uint, proposalID string, caption string, file io.Reader) (models.ProjectPhotoResponse, error) {
+	if len([]rune(caption)) > PROJECT_PHOTO_MAX_CAPTION {
+		return models.ProjectPhotoResponse{}, ErrCaptionTooLong
+	}
+
+	proposal, err := s.findProposal(proposalID)
+	if err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+	if proposal.UserID != userID {
+		return models.ProjectPhotoResponse{}, ErrNotContentAuthor
+	}
+	if proposal.Status != models.ProposalSubmitted {
+		return models.ProjectPhotoResponse{}, ErrProposalClosed
+	}
+
+	var photos int64
+	if err := s.db.Model(&models.ProposalPhoto{}).Where("proposal_id = ?", proposal.ID).Count(&photos).Error; err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+	if photos >= int64(PROPOSAL_MAX_PHOTOS) {
+		return models.ProjectPhotoResponse{}, ErrTooManyProposalPhotos
+	}
+
+	img, err := decodeImage(file)
+	if err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return models.ProjectPhotoResponse{}, fmt.Errorf("failed to name photo: %w", err)
+	}
+
+	url, err := savePNG(s.uploadDir, "proposals", fmt.Sprintf("proposal-%d-%s.png", proposal.ID, suffix), fitImage(img, PROJECT_PHOTO_MAX_SIDE))
+	if err != nil {
+		return models.ProjectPhotoResponse{}, err
+	}
+
+	photo := models.ProposalPhoto{ProposalID: proposal.ID, URL: url, Caption: caption}
+	if err := s.db.Create(&photo).Error; err != nil {
+		return models.ProjectPhotoResponse{}, fmt.Errorf("failed to save proposal photo: %w", err)
+	}
+
+	return models.ProjectPhotoResponse{ID: photo.ID, URL: photo.URL, Caption: photo.Caption, CreatedAt: photo.CreatedAt}, nil
+}
+
+// Withdraw removes the author's proposal while it awaits review.
+func (s *ProposalService) Withdraw(userID uint, proposalID string) error {
+	proposal, err := s.findProposal(proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.UserID != userID {
+		return ErrNotContentAuthor
+	}
+	if proposal.Status != models.ProposalSubmitted {
+		return ErrProposalClosed
+	}
+
+	return s.db.Delete(&proposal).Error
+}
+
+// Endorse backs a proposal awaiting review on behalf of a verified resident
+// of its barangay.
+func (s *ProposalService) Endorse(userID uint, proposalID string) error {
+	proposal, err := s.findProposal(proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.UserID == userID {
+		return ErrEndorseOwnProposal
+	}
+	if proposal.Status != models.ProposalSubmitted {
+		return ErrProposalClosed
+	}
+
+	barangay_ID, err := s.resident(userID)
+	if err != nil {
+		return err
+	}
+	if barangay_ID != proposal.Barangay_ID {
+		return ErrNotVerifiedResident
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var endorsed int64
+		if err := tx.Model(&models.ProposalEndorsement{}).Where("proposal_id = ? AND user_id = ?", proposal.ID, userID).Count(&endorsed).Error; err != nil {
+			return err
+		}
+		if endorsed > 0 {
+			return ErrAlreadyEndorsed
+		}
+
+		if err := tx.Create(&models.ProposalEndorsement{ProposalID: proposal.ID, UserID: userID}).Error; err != nil {
+			return fmt.Errorf("failed to endorse proposal: %w", err)
+		}
+		return tx.Model(&models.Proposal{}).Where("id = ?", proposal.ID).
+			UpdateColumn("endorsement_count", gorm.Expr("endorsement_count + 1")).Error
+	})
+}
+
+// RemoveEndorsement takes back a resident's endorsement.
+func (s *ProposalService) RemoveEndorsement(userID uint, proposalID string) error {
+	proposal, err := s.findProposal(proposalID)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("proposal_id = ? AND user_id = ?", proposal.ID, userID).Delete(&models.ProposalEndorsement{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrEndorsementNotFound
+		}
+		return tx.Model(&models.Proposal{}).Where("id = ?", proposal.ID).
+			UpdateColumn("endorsement_count", gorm.Expr("endorsement_count - 1")).Error
+	})
+}
+
+// ListProposals lists the proposals put to a barangay.
+func (s *ProposalService) ListProposals(barangay_ID string, params url.Values) ([]models.ProposalResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, PROPOSAL_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	base := s.db.Table("proposals").Where("proposals.barangay_id = ? AND proposals.deleted_at IS NULL", barangay_ID_int)
+
+	var proposals []models.ProposalResponse
+	meta, err := ListPage(base, query, &proposals, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(proposalColumns).Joins("JOIN users ON users.id = proposals.user_id")
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return proposals, meta, nil
+}
+
+// GetProposal returns a proposal with its photos and whether the viewer, 0
+// when not logged in, endorses it.
+func (s *ProposalService) GetProposal(proposalID string, viewerID uint) (models.ProposalResponse, error) {
+	id, err := strconv.ParseUint(proposalID, 10, 32)
+	if err != nil {
+		return models.ProposalResponse{}, fmt.Errorf("%w: %s", ErrInvalidProposalID, proposalID)
+	}
+
+	var proposals []models.ProposalResponse
+	if err := s.db.Table("proposals").
+		Select(proposalColumns).
+		Joins("JOIN users ON users.id = proposals.user_id").
+		Where("proposals.id = ? AND proposals.deleted_at IS NULL", id).
+		Limit(1).
+		Scan(&proposals).Error; err != nil {
+		return models.ProposalResponse{}, fmt.Errorf("failed to retrieve proposal: %w", err)
+	}
+	if len(proposals) == 0 {
+		return models.ProposalResponse{}, fmt.Errorf("%w: ID %d", ErrProposalNotFound, id)
+	}
+	proposal := proposals[0]
+
+	if err := s.db.Model(&models.ProposalPhoto{}).
+		Where("proposal_id = ?", proposal.ID).
+		Order("created_at").
+		Find(&proposal.Photos).Error; err != nil {
+		return models.ProposalResponse{}, fmt.Errorf("failed to retrieve proposal photos: %w", err)
+	}
+
+	if viewerID != 0 {
+		var endorsed int64
+		if err := s.db.Model(&models.ProposalEndorsement{}).Where("proposal_id = ? AND user_id = ?", proposal.ID, viewerID).Count(&endorsed).Error; err != nil {
+			return models.ProposalResponse{}, err
+		}
+		proposal.Endorsed = endorsed > 0
+	}
+
+	return proposal, nil
+}
+
+// Review accepts or declines a proposal put to the official's barangay.
+// Declining needs a reason the author can read.
+func (s *ProposalService) Review(officialID uint, barangay_ID uint, proposalID string, review models.ReviewProposal) error {
+	note := strings.TrimSpace(review.Note)
+	if review.Decision == models.ProposalDeclined && note == "" {
+		return ErrProposalNoteRequired
+	}
+
+	proposal, err := s.officialProposal(barangay_ID, proposalID)
+	if err != nil {
+		return err
+	}
+	if proposal.Status != models.ProposalSubmitted {
+		return ErrProposalClosed
+	}
+
+	// the status check guards against two officials deciding at once
+	result := s.db.Model(&models.Proposal{}).Where("id = ? AND status = ?", proposal.ID, models.ProposalSubmitted).Updates(map[string]interface{}{
+		"status":         review.Decision,
+		"review_note":    note,
+		"reviewed_by_id": officialID,
+		"reviewed_at":    time.Now(),
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to review proposal: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrProposalClosed
+	}
+
+	return nil
+}
+
+// Convert turns an accepted proposal into a planned project under a budget
+// category of the barangay. The proposal keeps a link to the project.
+func (s *ProposalService) Convert(barangay_ID uint, proposalID string, convert models.ConvertProposal) (uint, error) {
+	startDate, err := time.Parse(GO_DATE_FORMAT, convert.StartDate)
+	if err != nil {
+		return 0, ErrParseStartDate
+	}
+	endDate, err := time.Parse(GO_DATE_FORMAT, convert.EndDate)
+	if err != nil {
+		return 0, ErrParseEndDate
+	}
+	if endDate.Before(startDate) {
+		return 0, ErrProposalEndBeforeStart
+	}
+
+	proposal, err := s.officialProposal(barangay_ID, proposalID)
+	if err != nil {
+		return 0, err
+	}
+	if proposal.ProjectID != nil {
+		return 0, ErrProposalConverted
+	}
+	if proposal.Status != models.ProposalAccepted {
+		return 0, ErrProposalNotAccepted
+	}
+
+	var categories int64
+	if err := s.db.Model(&models.Budget_Category{}).Where("id = ? AND barangay_id = ?", convert.CategoryID, barangay_ID).Count(&categories).Error; err != nil {
+		return 0, err
+	}
+	if categories == 0 {
+		return 0, fmt.Errorf("%w: ID %d", ErrBudgetCategoryNotFound, convert.CategoryID)
+	}
+
+	name := strings.TrimSpace(convert.Name)
+	if name == "" {
+		name = proposal.Title
+	}
+	description := proposal.Description
+	if proposal.Location != "" {
+		description += "\n\nLocation: " + proposal.Location
+	}
+
+	project := models.Project{
+		Barangay_ID: barangay_ID,
+		CategoryID:  convert.CategoryID,
+		Name:        name,
+		Description: description,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		Status:      "planned",
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return fmt.Errorf("failed to create project: %w", err)
+		}
+		// the check guards against converting the same proposal twice at once
+		result := tx.Model(&models.Proposal{}).Where("id = ? AND project_id IS NULL", proposal.ID).Update("project_id", project.ID)
+		if result.Error != nil {
+			return fmt.Errorf("failed to link proposal: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrProposalConverted
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return project.ID, nil
+}
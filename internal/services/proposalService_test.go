@@ -0,0 +1,202 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var proposalRows = []string{"id", "barangay_id", "user_id", "title", "description", "location", "status", "project_id"}
+
+func expectProposal(mock sqlmock.Sqlmock, status string, projectID interface{}) {
+	mock.ExpectQuery(`SELECT \* FROM "proposals" WHERE id = \$1 AND "proposals"."deleted_at" IS NULL LIMIT \$2`).
+		WithArgs(4, 1).
+		WillReturnRows(sqlmock.NewRows(proposalRows).
+			AddRow(4, 1, 5, "Covered court for Purok 3", "Our purok has no place for events and sports.", "Purok 3", status, projectID))
+}
+
+func expectResident(mock sqlmock.Sqlmock, userID int, barangay_ID int, verified bool) {
+	mock.ExpectQuery(`SELECT id, role, barangay_id, residency_verified FROM "users" WHERE id = \$1`).
+		WithArgs(userID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "role", "barangay_id", "residency_verified"}).AddRow(userID, models.RoleCitizen, barangay_ID, verified))
+}
+
+func TestProposalService_Submit(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewProposalService(gormDB)
+
+	proposal := models.NewProposal{Title: " Covered court ", Description: "Our purok has no place for events and sports.", EstimatedCost: 1500000}
+
+	expectResident(mock, 5, 1, false)
+	if _, err := svc.Submit(5, proposal); !errors.Is(err, ErrNotVerifiedResident) {
+		t.Errorf("Expected ErrNotVerifiedResident, got %v", err)
+	}
+
+	expectResident(mock, 5, 1, true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "proposals"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 1, 5, "Covered court", proposal.Description, "", 1500000.0, models.ProposalSubmitted, "", 0).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectCommit()
+
+	proposalID, err := svc.Submit(5, proposal)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if proposalID != 4 {
+		t.Errorf("Expected proposal 4, got %d", proposalID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProposalService_Endorse(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewProposalService(gormDB)
+
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	if err := svc.Endorse(5, "4"); !errors.Is(err, ErrEndorseOwnProposal) {
+		t.Errorf("Expected ErrEndorseOwnProposal, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalDeclined, nil)
+	if err := svc.Endorse(6, "4"); !errors.Is(err, ErrProposalClosed) {
+		t.Errorf("Expected ErrProposalClosed, got %v", err)
+	}
+
+	// residents of other barangays cannot endorse
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	expectResident(mock, 6, 2, true)
+	if err := svc.Endorse(6, "4"); !errors.Is(err, ErrNotVerifiedResident) {
+		t.Errorf("Expected ErrNotVerifiedResident, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	expectResident(mock, 6, 1, true)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "proposal_endorsements" WHERE proposal_id = \$1 AND user_id = \$2`).
+		WithArgs(4, 6).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectExec(`INSERT INTO "proposal_endorsements" \("proposal_id","user_id","created_at"\)`).
+		WithArgs(4, 6, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "proposals" SET "endorsement_count"=endorsement_count \+ 1 WHERE id = \$1`).
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.Endorse(6, "4"); err != nil {
+		t.Errorf("Endorse() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProposalService_Review(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewProposalService(gormDB)
+
+	if err := svc.Review(3, 1, "4", models.ReviewProposal{Decision: models.ProposalDeclined}); !errors.Is(err, ErrProposalNoteRequired) {
+		t.Errorf("Expected ErrProposalNoteRequired, got %v", err)
+	}
+
+	// officials only review proposals put to their barangay
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	if err := svc.Review(3, 2, "4", models.ReviewProposal{Decision: models.ProposalAccepted}); !errors.Is(err, ErrProposalNotFound) {
+		t.Errorf("Expected ErrProposalNotFound, got %v", err)
+	}
+
+	review := `UPDATE "proposals" SET "review_note"=\$1,"reviewed_at"=\$2,"reviewed_by_id"=\$3,"status"=\$4,"updated_at"=\$5 WHERE \(id = \$6 AND status = \$7\) AND "proposals"."deleted_at" IS NULL`
+
+	// another official decided between the lookup and the update
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	mock.ExpectBegin()
+	mock.ExpectExec(review).
+		WithArgs("", sqlmock.AnyArg(), 3, models.ProposalAccepted, sqlmock.AnyArg(), 4, models.ProposalSubmitted).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+	if err := svc.Review(3, 1, "4", models.ReviewProposal{Decision: models.ProposalAccepted}); !errors.Is(err, ErrProposalClosed) {
+		t.Errorf("Expected ErrProposalClosed, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	mock.ExpectBegin()
+	mock.ExpectExec(review).
+		WithArgs("The lot is privately owned.", sqlmock.AnyArg(), 3, models.ProposalDeclined, sqlmock.AnyArg(), 4, models.ProposalSubmitted).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := svc.Review(3, 1, "4", models.ReviewProposal{Decision: models.ProposalDeclined, Note: "The lot is privately owned."}); err != nil {
+		t.Errorf("Review() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestProposalService_Convert(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewProposalService(gormDB)
+
+	convert := models.ConvertProposal{CategoryID: 9, StartDate: "2026-01-15", EndDate: "2026-06-30"}
+
+	if _, err := svc.Convert(1, "4", models.ConvertProposal{CategoryID: 9, StartDate: "2026-06-30", EndDate: "2026-01-15"}); !errors.Is(err, ErrProposalEndBeforeStart) {
+		t.Errorf("Expected ErrProposalEndBeforeStart, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalSubmitted, nil)
+	if _, err := svc.Convert(1, "4", convert); !errors.Is(err, ErrProposalNotAccepted) {
+		t.Errorf("Expected ErrProposalNotAccepted, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalAccepted, 12)
+	if _, err := svc.Convert(1, "4", convert); !errors.Is(err, ErrProposalConverted) {
+		t.Errorf("Expected ErrProposalConverted, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalAccepted, nil)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "budget_categories" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+		WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	if _, err := svc.Convert(1, "4", convert); !errors.Is(err, ErrBudgetCategoryNotFound) {
+		t.Errorf("Expected ErrBudgetCategoryNotFound, got %v", err)
+	}
+
+	expectProposal(mock, models.ProposalAccepted, nil)
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "budget_categories"`).
+		WithArgs(9, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "projects"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, "Covered court for Purok 3", "Our purok has no place for events and sports.\n\nLocation: Purok 3",
+			time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC), "planned", 1, 9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(12))
+	mock.ExpectExec(`UPDATE "proposals" SET "project_id"=\$1,"updated_at"=\$2 WHERE \(id = \$3 AND project_id IS NULL\)`).
+		WithArgs(12, sqlmock.AnyArg(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	projectID, err := svc.Convert(1, "4", convert)
+	if err != nil {
+		t.Fatalf("Convert() error = %v", err)
+	}
+	if projectID != 12 {
+		t.Errorf("Expected project 12, got %d", projectID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
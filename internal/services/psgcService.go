@@ -0,0 +1,351 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidPSGCFile = ValidationError("PSGC file must have the 10-digit PSGC, Name and Geographic Level columns")
+	ErrInvalidPSGCCode = FieldValidationError("psgc_code", "psgc_code must be a 10 digit barangay code")
+	ErrUnknownPSGCCode = FieldValidationError("psgc_code", "psgc_code does not belong to an imported city or municipality")
+)
+
+var (
+	PSGC_IMPORT_BATCH = 500
+
+	psgcParenthetical = regexp.MustCompile(`\([^)]*\)`)
+	psgcSpaces        = regexp.MustCompile(`\s+`)
+)
+
+type psgcEntry struct {
+	Code  string
+	Name  string
+	Level string
+}
+
+// psgcDataset is the PSGC publication split by level. Sub-municipalities
+// (the districts of Manila) are not kept, their barangays roll up to the city.
+type psgcDataset struct {
+	Regions   []models.Region
+	Provinces []models.Province
+	Cities    []models.CityMunicipality
+	Barangays []psgcEntry
+}
+
+type PSGCService struct {
+	db *gorm.DB
+}
+
+func NewPSGCService(db *gorm.DB) *PSGCService {
+	return &PSGCService{db: db}
+}
+
+// psgcRegionCode, psgcProvinceCode and psgcCityCode return the code of the
+// area containing code, read from its digits.
+func psgcRegionCode(code string) string   { return code[:2] + "00000000" }
+func psgcProvinceCode(code string) string { return code[:5] + "00000" }
+func psgcCityCode(code string) string     { return code[:7] + "000" }
+
+// normalizePSGCCode restores the leading zero spreadsheets drop from codes
+// like 0102800000.
+func normalizePSGCCode(raw string) (string, bool) {
+	code := strings.TrimSpace(raw)
+	if len(code) == 9 {
+		code = "0" + code
+	}
+	if len(code) != 10 {
+		return "", false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// parsePSGC reads the PSGC sheet of the PSA publication saved as CSV. Rows of
+// other levels and rows without a valid code are skipped.
+func parsePSGC(r io.Reader) (psgcDataset, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return psgcDataset{}, ErrInvalidPSGCFile
+	}
+
+	codeCol, nameCol, levelCol := -1, -1, -1
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch {
+		case strings.Contains(column, "psgc") && codeCol == -1:
+			codeCol = i
+		case column == "name":
+			nameCol = i
+		case strings.Contains(column, "level"):
+			levelCol = i
+		}
+	}
+	if codeCol == -1 || nameCol == -1 || levelCol == -1 {
+		return psgcDataset{}, ErrInvalidPSGCFile
+	}
+
+	var entries []psgcEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return psgcDataset{}, fmt.Errorf("failed to read PSGC file: %w", err)
+		}
+		if len(record) <= codeCol || len(record) <= nameCol || len(record) <= levelCol {
+			continue
+		}
+
+		code, ok := normalizePSGCCode(record[codeCol])
+		if !ok {
+			continue
+		}
+		entries = append(entries, psgcEntry{
+			Code:  code,
+			Name:  strings.TrimSpace(record[nameCol]),
+			Level: strings.TrimSpace(record[levelCol]),
+		})
+	}
+
+	var data psgcDataset
+	provinces := map[string]bool{}
+	for _, entry := range entries {
+		switch entry.Level {
+		case "Reg":
+			data.Regions = append(data.Regions, models.Region{Code: entry.Code, Name: entry.Name})
+		case "Prov":
+			data.Provinces = append(data.Provinces, models.Province{Code: entry.Code, Name: entry.Name, RegionCode: psgcRegionCode(entry.Code)})
+			provinces[entry.Code] = true
+		}
+	}
+
+	for _, entry := range entries {
+		switch entry.Level {
+		case "City", "Mun", "SGU":
+			city := models.CityMunicipality{Code: entry.Code, Name: entry.Name, Level: entry.Level, RegionCode: psgcRegionCode(entry.Code)}
+			// independent and NCR cities carry their own province digits
+			if province := psgcProvinceCode(entry.Code); provinces[province] {
+				city.ProvinceCode = &province
+			}
+			data.Cities = append(data.Cities, city)
+		case "Bgy":
+			data.Barangays = append(data.Barangays, entry)
+		}
+	}
+
+	return data, nil
+}
+
+// normalizePlaceName lets "Cebu City", "City of Cebu (Capital)" and
+// "cebu city" compare equal when matching existing barangays.
+func normalizePlaceName(name string) string {
+	name = strings.ToLower(psgcParenthetical.ReplaceAllString(name, " "))
+	name = strings.NewReplacer("ñ", "n", ".", " ").Replace(name)
+	name = psgcSpaces.ReplaceAllString(strings.TrimSpace(name), " ")
+
+	for _, prefix := range []string{"city of ", "barangay ", "brgy "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return strings.TrimSuffix(name, " city")
+}
+
+// Import loads regions, provinces and cities/municipalities from a PSGC file
+// and links existing barangays to their PSGC code by name and city. Running it
+// again with a newer publication updates names in place.
+func (s *PSGCService) Import(r io.Reader) (models.PSGCImportSummary, error) {
+	data, err := parsePSGC(r)
+	if err != nil {
+		return models.PSGCImportSummary{}, err
+	}
+	if len(data.Regions) == 0 {
+		return models.PSGCImportSummary{}, ErrInvalidPSGCFile
+	}
+
+	summary := models.PSGCImportSummary{
+		Regions:            len(data.Regions),
+		Provinces:          len(data.Provinces),
+		CityMunicipalities: len(data.Cities),
+		BarangaysUnmatched: []string{},
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		upsert := func(rows interface{}, columns ...string) error {
+			return tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "code"}},
+				DoUpdates: clause.AssignmentColumns(append(columns, "updated_at")),
+			}).CreateInBatches(rows, PSGC_IMPORT_BATCH).Error
+		}
+
+		if err := upsert(&data.Regions, "name"); err != nil {
+			return fmt.Errorf("failed to import regions: %w", err)
+		}
+		if len(data.Provinces) > 0 {
+			if err := upsert(&data.Provinces, "name", "region_code"); err != nil {
+				return fmt.Errorf("failed to import provinces: %w", err)
+			}
+		}
+		if len(data.Cities) > 0 {
+			if err := upsert(&data.Cities, "name", "level", "region_code", "province_code"); err != nil {
+				return fmt.Errorf("failed to import cities and municipalities: %w", err)
+			}
+		}
+
+		linked, unmatched, err := linkBarangays(tx, data)
+		if err != nil {
+			return err
+		}
+		summary.BarangaysLinked = linked
+		summary.BarangaysUnmatched = append(summary.BarangaysUnmatched, unmatched...)
+
+		return syncBarangayPlaceNames(tx)
+	})
+	if err != nil {
+		return models.PSGCImportSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// linkBarangays sets the PSGC code of barangays that do not have one yet.
+// Names shared by two barangays of the same city are left for an official to
+// pick by hand.
+func linkBarangays(tx *gorm.DB, data psgcDataset) (int, []string, error) {
+	cityNames := make(map[string]string, len(data.Cities))
+	for _, city := range data.Cities {
+		cityNames[city.Code] = city.Name
+	}
+
+	codes := map[string]string{}
+	for _, entry := range data.Barangays {
+		cityCode := psgcCityCode(entry.Code)
+		if _, ok := cityNames[cityCode]; !ok {
+			cityCode = psgcProvinceCode(entry.Code)
+		}
+		key := normalizePlaceName(entry.Name) + "|" + normalizePlaceName(cityNames[cityCode])
+		if _, seen := codes[key]; seen {
+			codes[key] = ""
+			continue
+		}
+		codes[key] = entry.Code
+	}
+
+	var barangays []models.Barangay
+	if err := tx.Where("psgc_code IS NULL").Find(&barangays).Error; err != nil {
+		return 0, nil, fmt.Errorf("failed to load barangays: %w", err)
+	}
+
+	linked := 0
+	var unmatched []string
+	for _, barangay := range barangays {
+		code := codes[normalizePlaceName(barangay.Name)+"|"+normalizePlaceName(barangay.City)]
+		if code == "" {
+			unmatched = append(unmatched, barangay.Name)
+			continue
+		}
+		if err := applyPSGCCode(tx, &barangay, code); err != nil {
+			return 0, nil, err
+		}
+		if err := tx.Save(&barangay).Error; err != nil {
+			return 0, nil, fmt.Errorf("failed to link barangay %s: %w", barangay.Name, err)
+		}
+		linked++
+	}
+
+	sort.Strings(unmatched)
+	return linked, unmatched, nil
+}
+
+// syncBarangayPlaceNames rewrites the free text city and region of linked
+// barangays so older screens show the PSGC names.
+func syncBarangayPlaceNames(tx *gorm.DB) error {
+	err := tx.Exec(`UPDATE barangays SET city = city_municipalities.name, region = regions.name
+		FROM city_municipalities
+		JOIN regions ON regions.code = city_municipalities.region_code
+		WHERE barangays.city_municipality_code = city_municipalities.code`).Error
+	if err != nil {
+		return fmt.Errorf("failed to sync barangay place names: %w", err)
+	}
+	return nil
+}
+
+// applyPSGCCode links barangay to the city/municipality its code belongs to
+// and copies the PSGC city and region names over the free text ones.
+func applyPSGCCode(tx *gorm.DB, barangay *models.Barangay, code string) error {
+	code, ok := normalizePSGCCode(code)
+	if !ok || strings.HasSuffix(code, "000") {
+		return ErrInvalidPSGCCode
+	}
+
+	var city models.CityMunicipality
+	err := tx.Preload("Region").Where("code IN ?", []string{psgcCityCode(code), psgcProvinceCode(code)}).
+		Order("code DESC").
+		First(&city).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUnknownPSGCCode
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find city for PSGC code %s: %w", code, err)
+	}
+
+	barangay.PSGCCode = &code
+	barangay.CityMunicipalityCode = &city.Code
+	barangay.City = city.Name
+	barangay.Region = city.Region.Name
+
+	return nil
+}
+
+func (s *PSGCService) Regions() ([]models.GeoArea, error) {
+	var regions []models.GeoArea
+	if err := s.db.Model(&models.Region{}).Select("code, name").Order("code").Scan(&regions).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve regions: %w", err)
+	}
+	return regions, nil
+}
+
+func (s *PSGCService) Provinces(regionCode string) ([]models.GeoArea, error) {
+	query := s.db.Model(&models.Province{}).Select("code, name").Order("name")
+	if regionCode != "" {
+		query = query.Where("region_code = ?", regionCode)
+	}
+
+	var provinces []models.GeoArea
+	if err := query.Scan(&provinces).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve provinces: %w", err)
+	}
+	return provinces, nil
+}
+
+func (s *PSGCService) CityMunicipalities(regionCode, provinceCode string) ([]models.GeoArea, error) {
+	query := s.db.Model(&models.CityMunicipality{}).Select("code, name, level").Order("name")
+	if regionCode != "" {
+		query = query.Where("region_code = ?", regionCode)
+	}
+	if provinceCode != "" {
+		query = query.Where("province_code = ?", provinceCode)
+	}
+
+	var cities []models.GeoArea
+	if err := query.Scan(&cities).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve cities and municipalities: %w", err)
+	}
+	return cities, nil
+}
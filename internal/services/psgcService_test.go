@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const psgcSample = "\ufeff10-digit PSGC,Name,Correspondence Code,Geographic Level\n" +
+	"0700000000,Region VII (Central Visayas),070000000,Reg\n" +
+	"0702200000,Cebu,072200000,Prov\n" +
+	"0702217000,City of Talisay,072250000,City\n" +
+	"0702217001,Biasong,072250001,Bgy\n" +
+	"0730600000,City of Cebu (Capital),073306000,City\n" +
+	"0730600011,Lahug,073306011,Bgy\n" +
+	"1300000000,National Capital Region (NCR),130000000,Reg\n" +
+	"1380600000,City of Manila,133900000,City\n" +
+	"1380601000,Tondo I/II,133901000,SubMun\n" +
+	"1380601001,Barangay 1,133901001,Bgy\n" +
+	"102800000,Ilocos Norte,012800000,Prov\n" +
+	"not-a-code,Footnote,,\n"
+
+func TestParsePSGC(t *testing.T) {
+	data, err := parsePSGC(strings.NewReader(psgcSample))
+	if err != nil {
+		t.Fatalf("parsePSGC() error = %v", err)
+	}
+
+	if len(data.Regions) != 2 || len(data.Provinces) != 2 || len(data.Cities) != 3 || len(data.Barangays) != 3 {
+		t.Fatalf("Unexpected counts: %d regions, %d provinces, %d cities, %d barangays",
+			len(data.Regions), len(data.Provinces), len(data.Cities), len(data.Barangays))
+	}
+
+	// the leading zero excel drops is restored
+	if data.Provinces[1].Code != "0102800000" || data.Provinces[1].RegionCode != "0100000000" {
+		t.Errorf("Expected padded province code, got %+v", data.Provinces[1])
+	}
+
+	cities := map[string]models.CityMunicipality{}
+	for _, city := range data.Cities {
+		cities[city.Name] = city
+	}
+	if talisay := cities["City of Talisay"]; talisay.ProvinceCode == nil || *talisay.ProvinceCode != "0702200000" {
+		t.Errorf("Expected Talisay under Cebu province, got %+v", talisay)
+	}
+	if cebu := cities["City of Cebu (Capital)"]; cebu.ProvinceCode != nil || cebu.RegionCode != "0700000000" {
+		t.Errorf("Expected independent Cebu City without a province, got %+v", cebu)
+	}
+	if manila := cities["City of Manila"]; manila.ProvinceCode != nil || manila.RegionCode != "1300000000" {
+		t.Errorf("Expected Manila directly under NCR, got %+v", manila)
+	}
+}
+
+func TestParsePSGC_MissingColumns(t *testing.T) {
+	_, err := parsePSGC(strings.NewReader("Code,Name\n0700000000,Region VII\n"))
+	if !errors.Is(err, ErrInvalidPSGCFile) {
+		t.Errorf("Expected ErrInvalidPSGCFile, got %v", err)
+	}
+}
+
+func TestNormalizePlaceName(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"Cebu City", "City of Cebu (Capital)"},
+		{"cebu city", "CEBU CITY"},
+		{"Parañaque City", "City of Paranaque"},
+		{"Brgy. Lahug", "Lahug"},
+		{"Sto.  Niño", "Sto Nino"},
+	}
+
+	for _, tt := range tests {
+		if normalizePlaceName(tt.a) != normalizePlaceName(tt.b) {
+			t.Errorf("Expected %q and %q to match, got %q and %q", tt.a, tt.b, normalizePlaceName(tt.a), normalizePlaceName(tt.b))
+		}
+	}
+
+	if normalizePlaceName("Talisay City") == normalizePlaceName("Cebu City") {
+		t.Error("Expected different cities not to match")
+	}
+}
+
+func TestApplyPSGCCode(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+
+	if err := applyPSGCCode(gormDB, &models.Barangay{}, "0730600000"); !errors.Is(err, ErrInvalidPSGCCode) {
+		t.Errorf("Expected ErrInvalidPSGCCode for a city code, got %v", err)
+	}
+
+	// Manila district barangays fall back to the city
+	mock.ExpectQuery(`SELECT \* FROM "city_municipalities" WHERE code IN \(\$1,\$2\) ORDER BY code DESC`).
+		WithArgs("1380601000", "1380600000", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name", "level", "region_code"}).
+			AddRow("1380600000", "City of Manila", "City", "1300000000"))
+	mock.ExpectQuery(`SELECT \* FROM "regions" WHERE "regions"."code" = \$1`).
+		WithArgs("1300000000").
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name"}).AddRow("1300000000", "National Capital Region (NCR)"))
+
+	barangay := models.Barangay{Name: "Barangay 1", City: "manila", Region: "ncr"}
+	if err := applyPSGCCode(gormDB, &barangay, "1380601001"); err != nil {
+		t.Fatalf("applyPSGCCode() error = %v", err)
+	}
+	if *barangay.PSGCCode != "1380601001" || *barangay.CityMunicipalityCode != "1380600000" ||
+		barangay.City != "City of Manila" || barangay.Region != "National Capital Region (NCR)" {
+		t.Errorf("Unexpected barangay after linking: %+v", barangay)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "city_municipalities"`).
+		WillReturnRows(sqlmock.NewRows([]string{"code"}))
+	if err := applyPSGCCode(gormDB, &barangay, "0999901001"); !errors.Is(err, ErrUnknownPSGCCode) {
+		t.Errorf("Expected ErrUnknownPSGCCode, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -1,6 +1,9 @@
 package services
 
 import (
+	"fmt"
+	"net/url"
+	"sort"
 	"time"
 	"wow-bato-backend/internal/models"
 
@@ -8,11 +11,12 @@ import (
 )
 
 type PublicDashboardService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	response ResponseConfig
 }
 
 func NewPublicDashboardService(db *gorm.DB) *PublicDashboardService {
-	return &PublicDashboardService{db: db}
+	return &PublicDashboardService{db: db, response: ResponseConfigFromEnv()}
 }
 
 type CompleteStats struct {
@@ -236,7 +240,7 @@ func (s *PublicDashboardService) FeedbacksPerProject() ([]ProjectFeedbackStats,
 	var results []ProjectFeedbackStats
 	err := s.db.Table("projects").
 		Select("projects.name as project_name, COUNT(feedbacks.id) as feedback_count").
-		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id").
+		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id AND feedbacks.moderation_state = 'published'").
 		Group("projects.id").
 		Scan(&results).Error
 	return results, err
@@ -296,7 +300,7 @@ func (s *PublicDashboardService) ProjectsWithoutFeedback() ([]ProjectNoFeedback,
 	var results []ProjectNoFeedback
 	err := s.db.Table("projects").
 		Select("projects.name as project_name").
-		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id").
+		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id AND feedbacks.moderation_state = 'published'").
 		Group("projects.id").
 		Having("COUNT(feedbacks.id) = 0").
 		Scan(&results).Error
@@ -313,7 +317,7 @@ func (s *PublicDashboardService) TopUsersByFeedback(limit int) ([]TopUserFeedbac
 	var results []TopUserFeedback
 	err := s.db.Table("users").
 		Select("users.id as user_id, users.first_name || ' ' || users.last_name as user_name, COUNT(feedbacks.id) as feedbacks").
-		Joins("LEFT JOIN feedbacks ON feedbacks.user_id = users.id").
+		Joins("LEFT JOIN feedbacks ON feedbacks.user_id = users.id AND feedbacks.moderation_state = 'published' AND feedbacks.identity = 'named'").
 		Group("users.id").
 		Order("feedbacks DESC").
 		Limit(limit).
@@ -378,7 +382,7 @@ func (s *PublicDashboardService) ProjectsWithMostFeedback(limit int) ([]ProjectF
 	var results []ProjectFeedbackCount
 	err := s.db.Table("projects").
 		Select("projects.name as project_name, COUNT(feedbacks.id) as feedback_count").
-		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id").
+		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id AND feedbacks.moderation_state = 'published'").
 		Group("projects.id").
 		Order("feedback_count DESC").
 		Limit(limit).
@@ -435,7 +439,7 @@ func (s *PublicDashboardService) MostActiveBarangaysByFeedback(limit int) ([]Bar
 	err := s.db.Table("barangays").
 		Select("barangays.name as barangay_name, COUNT(feedbacks.id) as feedback_count").
 		Joins("LEFT JOIN projects ON projects.barangay_id = barangays.id").
-		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id").
+		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id AND feedbacks.moderation_state = 'published'").
 		Group("barangays.id").
 		Order("feedback_count DESC").
 		Limit(limit).
@@ -472,3 +476,328 @@ func (s *PublicDashboardService) AverageProjectDurationByBarangay() ([]BarangayD
 		Scan(&results).Error
 	return results, err
 }
+
+var (
+	ErrInvalidRollupLevel        = FieldValidationError("level", "level must be one of: region, province, city, barangay")
+	ErrInvalidDashboardProjectID = ValidationError("invalid project ID format")
+	ErrInvalidSentimentLevel     = FieldValidationError("level", "level must be one of: project, barangay")
+)
+
+// group columns of each rollup level, barangays that are not linked to a
+// PSGC city/municipality are left out of every level
+var ROLLUP_LEVELS = map[string][2]string{
+	"region":   {"regions.code", "regions.name"},
+	"province": {"provinces.code", "provinces.name"},
+	"city":     {"city_municipalities.code", "city_municipalities.name"},
+	"barangay": {"barangays.psgc_code", "barangays.name"},
+}
+
+// GeoRollup totals projects, budget items and feedback per region, province,
+// city/municipality or barangay. region, province and city narrow the rows
+// to one parent area. Provinces leave out independent and NCR cities.
+func (s *PublicDashboardService) GeoRollup(level string, params url.Values) ([]models.GeoRollup, error) {
+	columns, ok := ROLLUP_LEVELS[level]
+	if !ok {
+		return nil, ErrInvalidRollupLevel
+	}
+
+	projectStats := s.db.Table("projects").
+		Select("barangay_id, COUNT(*) AS projects, COUNT(*) FILTER (WHERE status = 'completed') AS completed_projects").
+		Where("deleted_at IS NULL").
+		Group("barangay_id")
+	budgetStats := s.db.Table("budget_items").
+		Select("projects.barangay_id, SUM(budget_items.amount_allocated) AS budget_allocated").
+		Joins("JOIN projects ON projects.id = budget_items.project_id AND projects.deleted_at IS NULL").
+		Where("budget_items.deleted_at IS NULL").
+		Group("projects.barangay_id")
+	feedbackStats := s.db.Table("feedbacks").
+		Select("projects.barangay_id, COUNT(*) AS feedbacks").
+		Joins("JOIN projects ON projects.id = feedbacks.project_id AND projects.deleted_at IS NULL").
+		Where("feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published'").
+		Group("projects.barangay_id")
+
+	query := s.db.Table("barangays").
+		Select(columns[0]+" AS code, "+columns[1]+` AS name,
+			COUNT(barangays.id) AS barangays,
+			COALESCE(SUM(project_stats.projects), 0) AS projects,
+			COALESCE(SUM(project_stats.completed_projects), 0) AS completed_projects,
+			COALESCE(SUM(budget_stats.budget_allocated), 0) AS budget_allocated,
+			COALESCE(SUM(feedback_stats.feedbacks), 0) AS feedbacks`).
+		Joins("JOIN city_municipalities ON city_municipalities.code = barangays.city_municipality_code").
+		Joins("JOIN regions ON regions.code = city_municipalities.region_code").
+		Joins("LEFT JOIN provinces ON provinces.code = city_municipalities.province_code").
+		Joins("LEFT JOIN (?) AS project_stats ON project_stats.barangay_id = barangays.id", projectStats).
+		Joins("LEFT JOIN (?) AS budget_stats ON budget_stats.barangay_id = barangays.id", budgetStats).
+		Joins("LEFT JOIN (?) AS feedback_stats ON feedback_stats.barangay_id = barangays.id", feedbackStats).
+		Where("barangays.deleted_at IS NULL")
+
+	if level == "province" {
+		query = query.Where("provinces.code IS NOT NULL")
+	}
+	if region := params.Get("region"); region != "" {
+		query = query.Where("regions.code = ?", region)
+	}
+	if province := params.Get("province"); province != "" {
+		query = query.Where("provinces.code = ?", province)
+	}
+	if city := params.Get("city"); city != "" {
+		query = query.Where("city_municipalities.code = ?", city)
+	}
+
+	var results []models.GeoRollup
+	if err := query.Group(columns[0] + ", " + columns[1]).
+		Order("projects DESC").
+		Order("name").
+		Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to roll up %s totals: %w", level, err)
+	}
+
+	for i := range results {
+		if results[i].Projects > 0 {
+			results[i].CompletionRate = float64(results[i].CompletedProjects) / float64(results[i].Projects) * 100
+		}
+	}
+
+	if results == nil {
+		results = []models.GeoRollup{}
+	}
+	return results, nil
+}
+
+// StatutoryCompliance lists the compliance report of every barangay that
+// recorded its income for fiscalYear, largest shortfall first.
+func (s *PublicDashboardService) StatutoryCompliance(fiscalYear string) ([]models.ComplianceReport, error) {
+	year, err := parseFiscalYear(fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := complianceReports(s.db, year, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool { return reports[i].Shortfall > reports[j].Shortfall })
+	return reports, nil
+}
+
+// RevenueBySource totals recorded revenue per fiscal year and source for the
+// public charts. barangay_ID narrows it to one barangay, from and to to a
+// range of fiscal years.
+func (s *PublicDashboardService) RevenueBySource(params url.Values) ([]models.RevenueChartPoint, error) {
+	query := s.db.Model(&models.Revenue{}).Select("fiscal_year, source, SUM(amount) AS amount").Where(countedRevenue)
+
+	if barangay_ID := params.Get("barangay_ID"); barangay_ID != "" {
+		barangay_ID_int, err := ConvertToInt(barangay_ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+		}
+		query = query.Where("barangay_id = ?", barangay_ID_int)
+	}
+	for _, bound := range [][2]string{{"from", "fiscal_year >= ?"}, {"to", "fiscal_year <= ?"}} {
+		if value := params.Get(bound[0]); value != "" {
+			year, err := parseFiscalYear(value)
+			if err != nil {
+				return nil, FieldValidationError(bound[0], bound[0]+" must be a year between 1991 and 2100")
+			}
+			query = query.Where(bound[1], year)
+		}
+	}
+
+	var points []models.RevenueChartPoint
+	if err := query.Group("fiscal_year, source").Order("fiscal_year").Order("source").Scan(&points).Error; err != nil {
+		return nil, fmt.Errorf("failed to total revenue by source: %w", err)
+	}
+
+	if points == nil {
+		points = []models.RevenueChartPoint{}
+	}
+	return points, nil
+}
+
+// Responsiveness measures per barangay how soon officials answer citizen
+// feedback against the barangay's response target, best on-time rate first.
+// barangay_ID narrows it to one barangay.
+func (s *PublicDashboardService) Responsiveness(params url.Values) ([]models.BarangayResponsiveness, error) {
+	deadline := s.response.responseDeadline()
+
+	query := s.db.Table("barangays").
+		Select(fmt.Sprintf(`barangays.id AS barangay_id, barangays.name AS barangay_name,
+			COALESCE(response_targets.response_hours, %d) AS response_hours,
+			COUNT(feedbacks.id) AS feedbacks,
+			COUNT(feedbacks.first_response_at) AS answered,
+			COUNT(*) FILTER (WHERE feedbacks.first_response_at <= %[2]s) AS answered_on_time,
+			COUNT(*) FILTER (WHERE feedbacks.first_response_at IS NULL AND %[2]s < ?) AS overdue,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM feedbacks.first_response_at - feedbacks.created_at) / 3600) AS median_response_hours`,
+			s.response.ResponseHours, deadline), time.Now()).
+		Joins("LEFT JOIN response_targets ON response_targets.barangay_id = barangays.id").
+		Joins("LEFT JOIN projects ON projects.barangay_id = barangays.id AND projects.deleted_at IS NULL").
+		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id AND feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published' AND feedbacks.role = 'citizen'").
+		Where("barangays.deleted_at IS NULL")
+
+	if barangay_ID := params.Get("barangay_ID"); barangay_ID != "" {
+		barangay_ID_int, err := ConvertToInt(barangay_ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+		}
+		query = query.Where("barangays.id = ?", barangay_ID_int)
+	}
+
+	var results []models.BarangayResponsiveness
+	if err := query.Group("barangays.id, barangays.name, response_targets.response_hours").
+		Order("barangays.name").
+		Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to measure responsiveness: %w", err)
+	}
+
+	for i := range results {
+		// feedback counts once it is answered or past due
+		if due := results[i].Answered + results[i].Overdue; due > 0 {
+			results[i].OnTimeRate = float64(results[i].AnsweredOnTime) / float64(due) * 100
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].OnTimeRate > results[j].OnTimeRate })
+
+	if results == nil {
+		results = []models.BarangayResponsiveness{}
+	}
+	return results, nil
+}
+
+// MostSupportedConcerns lists the published feedback residents back the
+// most, leaving out resolved complaints. project_ID or barangay_ID narrow it
+// to one project or barangay, limit caps the rows.
+func (s *PublicDashboardService) MostSupportedConcerns(params url.Values) ([]models.SupportedConcern, error) {
+	_, limit, err := parsePageParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.db.Table("feedbacks").
+		Select(`feedbacks.id AS feedback_id, feedbacks.content, feedbacks.type, feedbacks.issue_status,
+			feedbacks.support_count AS support, projects.id AS project_id, projects.name AS project_name,
+			barangays.id AS barangay_id, barangays.name AS barangay_name, feedbacks.created_at`).
+		Joins("JOIN projects ON projects.id = feedbacks.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN barangays ON barangays.id = projects.barangay_id").
+		Where("feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published' AND feedbacks.support_count > 0").
+		Where("feedbacks.issue_status IS NULL OR feedbacks.issue_status <> ?", models.IssueResolved)
+
+	query, err = feedbackScope(query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var concerns []models.SupportedConcern
+	if err := query.Order("feedbacks.support_count DESC").
+		Order("feedbacks.created_at").
+		Limit(limit).
+		Scan(&concerns).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank supported concerns: %w", err)
+	}
+
+	if concerns == nil {
+		concerns = []models.SupportedConcern{}
+	}
+	return concerns, nil
+}
+
+// feedbackScope narrows a query joining projects and barangays to the
+// project_ID or barangay_ID of params.
+func feedbackScope(query *gorm.DB, params url.Values) (*gorm.DB, error) {
+	if project_ID := params.Get("project_ID"); project_ID != "" {
+		project_ID_int, err := ConvertToInt(project_ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDashboardProjectID, project_ID)
+		}
+		query = query.Where("projects.id = ?", project_ID_int)
+	}
+	if barangay_ID := params.Get("barangay_ID"); barangay_ID != "" {
+		barangay_ID_int, err := ConvertToInt(barangay_ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+		}
+		query = query.Where("barangays.id = ?", barangay_ID_int)
+	}
+	return query, nil
+}
+
+// group columns of each sentiment level
+var SENTIMENT_LEVELS = map[string][2]string{
+	"project":  {"projects.id", "projects.name"},
+	"barangay": {"barangays.id", "barangays.name"},
+}
+
+// published citizen feedback the sentiment lexicon has scored
+func (s *PublicDashboardService) analyzedFeedback() *gorm.DB {
+	return s.db.Table("feedbacks").
+		Joins("JOIN projects ON projects.id = feedbacks.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN barangays ON barangays.id = projects.barangay_id").
+		Where("feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published' AND feedbacks.role = 'citizen' AND feedbacks.sentiment <> ''")
+}
+
+// Sentiment sums up the tone of citizen feedback per project or barangay,
+// the largest share of negative feedback first. project_ID or barangay_ID
+// narrow it, limit caps the rows.
+func (s *PublicDashboardService) Sentiment(level string, params url.Values) ([]models.SentimentSummary, error) {
+	columns, ok := SENTIMENT_LEVELS[level]
+	if !ok {
+		return nil, ErrInvalidSentimentLevel
+	}
+
+	_, limit, err := parsePageParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := feedbackScope(s.analyzedFeedback(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var summaries []models.SentimentSummary
+	if err := query.Select(fmt.Sprintf(`%s AS id, %s AS name, COUNT(*) AS feedbacks,
+			COUNT(*) FILTER (WHERE feedbacks.sentiment = 'positive') AS positive,
+			COUNT(*) FILTER (WHERE feedbacks.sentiment = 'neutral') AS neutral,
+			COUNT(*) FILTER (WHERE feedbacks.sentiment = 'negative') AS negative,
+			AVG(feedbacks.sentiment_score) AS average_score`, columns[0], columns[1])).
+		Group(columns[0] + ", " + columns[1]).
+		Order("COUNT(*) FILTER (WHERE feedbacks.sentiment = 'negative')::float / COUNT(*) DESC, COUNT(*) DESC, " + columns[1]).
+		Limit(limit).
+		Scan(&summaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum up sentiment: %w", err)
+	}
+
+	for i := range summaries {
+		summaries[i].NegativeShare = float64(summaries[i].Negative) / float64(summaries[i].Feedbacks) * 100
+	}
+
+	if summaries == nil {
+		summaries = []models.SentimentSummary{}
+	}
+	return summaries, nil
+}
+
+// Topics counts the topics citizen feedback mentions, most mentioned first.
+// project_ID or barangay_ID narrow it.
+func (s *PublicDashboardService) Topics(params url.Values) ([]models.TopicSummary, error) {
+	query, err := feedbackScope(s.analyzedFeedback(), params)
+	if err != nil {
+		return nil, err
+	}
+
+	var topics []models.TopicSummary
+	if err := query.Select(`feedback_topics.topic, COUNT(*) AS feedbacks,
+			COUNT(*) FILTER (WHERE feedbacks.sentiment = 'negative') AS negative,
+			AVG(feedbacks.sentiment_score) AS average_score`).
+		Joins("JOIN feedback_topics ON feedback_topics.feedback_id = feedbacks.id").
+		Group("feedback_topics.topic").
+		Order("COUNT(*) DESC, feedback_topics.topic").
+		Scan(&topics).Error; err != nil {
+		return nil, fmt.Errorf("failed to count feedback topics: %w", err)
+	}
+
+	if topics == nil {
+		topics = []models.TopicSummary{}
+	}
+	return topics, nil
+}
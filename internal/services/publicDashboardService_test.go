@@ -0,0 +1,183 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestPublicDashboardService_GeoRollup(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPublicDashboardService(gormDB)
+
+	if _, err := svc.GeoRollup("country", url.Values{}); !errors.Is(err, ErrInvalidRollupLevel) {
+		t.Errorf("Expected ErrInvalidRollupLevel, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT city_municipalities.code AS code, city_municipalities.name AS name, (.+) FROM "barangays" JOIN city_municipalities (.+) WHERE barangays.deleted_at IS NULL AND regions.code = \$1 GROUP BY city_municipalities.code, city_municipalities.name ORDER BY projects DESC,name`).
+		WithArgs("0700000000").
+		WillReturnRows(sqlmock.NewRows([]string{"code", "name", "barangays", "projects", "completed_projects", "budget_allocated", "feedbacks"}).
+			AddRow("0730600000", "City of Cebu (Capital)", 3, 8, 2, 1250000.0, 14).
+			AddRow("0702217000", "City of Talisay", 1, 0, 0, 0.0, 0))
+
+	rollup, err := svc.GeoRollup("city", url.Values{"region": {"0700000000"}})
+	if err != nil {
+		t.Fatalf("GeoRollup() error = %v", err)
+	}
+	if len(rollup) != 2 || rollup[0].CompletionRate != 25 || rollup[1].CompletionRate != 0 {
+		t.Errorf("Unexpected rollup: %+v", rollup)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublicDashboardService_RevenueBySource(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPublicDashboardService(gormDB)
+
+	mock.ExpectQuery(`SELECT fiscal_year, source, SUM\(amount\) AS amount FROM "revenues" WHERE \(`+regexp.QuoteMeta(countedRevenue)+`\) AND barangay_id = \$1 AND fiscal_year >= \$2 AND "revenues"."deleted_at" IS NULL GROUP BY fiscal_year, source ORDER BY fiscal_year,source`).
+		WithArgs(1, 2024).
+		WillReturnRows(sqlmock.NewRows([]string{"fiscal_year", "source", "amount"}).
+			AddRow(2024, "nta", 2800000.0).
+			AddRow(2025, "nta", 3000000.0))
+
+	points, err := svc.RevenueBySource(url.Values{"barangay_ID": {"1"}, "from": {"2024"}})
+	if err != nil {
+		t.Fatalf("RevenueBySource() error = %v", err)
+	}
+	if len(points) != 2 || points[1].FiscalYear != 2025 {
+		t.Errorf("Unexpected points: %+v", points)
+	}
+
+	if _, err := svc.RevenueBySource(url.Values{"to": {"soon"}}); err == nil {
+		t.Error("Expected an error for an invalid to year")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublicDashboardService_Responsiveness(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := &PublicDashboardService{db: gormDB, response: ResponseConfig{ResponseHours: 72, EscalationHours: 120}}
+
+	median := 30.5
+	mock.ExpectQuery(`SELECT barangays.id AS barangay_id, (.+) COALESCE\(response_targets.response_hours, 72\) AS response_hours, (.+) FROM "barangays" LEFT JOIN response_targets (.+) WHERE barangays.deleted_at IS NULL GROUP BY barangays.id, barangays.name, response_targets.response_hours ORDER BY barangays.name`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id", "barangay_name", "response_hours", "feedbacks", "answered", "answered_on_time", "overdue", "median_response_hours"}).
+			AddRow(1, "Bato", 72, 10, 6, 3, 2, median).
+			AddRow(2, "Calero", 48, 0, 0, 0, 0, nil).
+			AddRow(3, "Poblacion", 24, 4, 4, 4, 0, 12.0))
+
+	results, err := svc.Responsiveness(url.Values{})
+	if err != nil {
+		t.Fatalf("Responsiveness() error = %v", err)
+	}
+	if len(results) != 3 || results[0].BarangayName != "Poblacion" || results[0].OnTimeRate != 100 {
+		t.Fatalf("Unexpected ranking: %+v", results)
+	}
+	if results[1].OnTimeRate != 37.5 || results[1].MedianResponseHours == nil || *results[1].MedianResponseHours != median {
+		t.Errorf("Unexpected Bato metrics: %+v", results[1])
+	}
+	if results[2].MedianResponseHours != nil {
+		t.Errorf("Expected no median without answers, got %v", *results[2].MedianResponseHours)
+	}
+
+	if _, err := svc.Responsiveness(url.Values{"barangay_ID": {"x"}}); !errors.Is(err, ErrInvalidBarangayID) {
+		t.Errorf("Expected ErrInvalidBarangayID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublicDashboardService_MostSupportedConcerns(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPublicDashboardService(gormDB)
+
+	mock.ExpectQuery(`SELECT feedbacks.id AS feedback_id, (.+) FROM "feedbacks" JOIN projects (.+) JOIN barangays (.+) WHERE \(feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published' AND feedbacks.support_count > 0\) AND \(feedbacks.issue_status IS NULL OR feedbacks.issue_status <> \$1\) AND barangays.id = \$2 ORDER BY feedbacks.support_count DESC,feedbacks.created_at LIMIT \$3`).
+		WithArgs(models.IssueResolved, 1, 5).
+		WillReturnRows(sqlmock.NewRows([]string{"feedback_id", "content", "type", "support", "project_id", "project_name", "barangay_id", "barangay_name"}).
+			AddRow(9, "Baha sa kanto ng Rizal St.", models.FeedbackComplaint, 31, 2, "Drainage upgrade", 1, "Bato").
+			AddRow(4, "Add more street lights", models.FeedbackSuggestion, 12, 3, "Street lights", 1, "Bato"))
+
+	concerns, err := svc.MostSupportedConcerns(url.Values{"barangay_ID": {"1"}, "limit": {"5"}})
+	if err != nil {
+		t.Fatalf("MostSupportedConcerns() error = %v", err)
+	}
+	if len(concerns) != 2 || concerns[0].FeedbackID != 9 || concerns[0].Support != 31 {
+		t.Errorf("Unexpected concerns: %+v", concerns)
+	}
+
+	if _, err := svc.MostSupportedConcerns(url.Values{"project_ID": {"x"}}); !errors.Is(err, ErrInvalidDashboardProjectID) {
+		t.Errorf("Expected ErrInvalidDashboardProjectID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublicDashboardService_Sentiment(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPublicDashboardService(gormDB)
+
+	if _, err := svc.Sentiment("region", url.Values{}); !errors.Is(err, ErrInvalidSentimentLevel) {
+		t.Errorf("Expected ErrInvalidSentimentLevel, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT projects.id AS id, projects.name AS name, COUNT\(\*\) AS feedbacks, (.+) FROM "feedbacks" JOIN projects (.+) JOIN barangays (.+) WHERE \(feedbacks.deleted_at IS NULL (.+) AND feedbacks.sentiment <> ''\) AND barangays.id = \$1 GROUP BY projects.id, projects.name ORDER BY (.+) LIMIT \$2`).
+		WithArgs(1, DEFAULT_PAGE_LIMIT).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "feedbacks", "positive", "neutral", "negative", "average_score"}).
+			AddRow(2, "Drainage upgrade", 8, 1, 1, 6, -0.42).
+			AddRow(3, "Street lights", 4, 3, 0, 1, 0.31))
+
+	summaries, err := svc.Sentiment("project", url.Values{"barangay_ID": {"1"}})
+	if err != nil {
+		t.Fatalf("Sentiment() error = %v", err)
+	}
+	if len(summaries) != 2 || summaries[0].ID != 2 || summaries[0].NegativeShare != 75 || summaries[1].NegativeShare != 25 {
+		t.Errorf("Unexpected sentiment: %+v", summaries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestPublicDashboardService_Topics(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewPublicDashboardService(gormDB)
+
+	mock.ExpectQuery(`SELECT feedback_topics.topic, COUNT\(\*\) AS feedbacks, (.+) FROM "feedbacks" (.+) JOIN feedback_topics ON feedback_topics.feedback_id = feedbacks.id WHERE (.+) AND projects.id = \$1 GROUP BY "feedback_topics"."topic" ORDER BY COUNT\(\*\) DESC, feedback_topics.topic`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"topic", "feedbacks", "negative", "average_score"}).
+			AddRow("drainage", 6, 5, -0.5).
+			AddRow("delays", 3, 3, -0.6))
+
+	topics, err := svc.Topics(url.Values{"project_ID": {"2"}})
+	if err != nil {
+		t.Fatalf("Topics() error = %v", err)
+	}
+	if len(topics) != 2 || topics[0].Topic != "drainage" || topics[0].Negative != 5 {
+		t.Errorf("Unexpected topics: %+v", topics)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/pdf"
+)
+
+const (
+	reportMargin = 50.0
+	reportWidth  = pdf.PageWidth - 2*reportMargin
+	reportBottom = pdf.PageHeight - 60
+	reportRow    = 16.0
+)
+
+type reportColumn struct {
+	Title string
+	Width float64
+	Right bool
+}
+
+// reportWriter lays out a report top to bottom, starting a new page with the
+// letterhead repeated in short form whenever the next block does not fit.
+type reportWriter struct {
+	doc        *pdf.Document
+	y          float64
+	letterhead models.Letterhead
+	seal       image.Image
+	title      string
+	subtitle   string
+	columns    []reportColumn //header reprinted when a table breaks across pages
+}
+
+func newReportWriter(letterhead models.Letterhead, seal image.Image, title string, subtitle string) *reportWriter {
+	r := &reportWriter{doc: pdf.New(), letterhead: letterhead, seal: seal, title: title, subtitle: subtitle}
+	r.firstPage()
+	return r
+}
+
+func (r *reportWriter) centered(y float64, font pdf.Font, size float64, text string) {
+	text = pdf.Truncate(font, size, text, reportWidth)
+	r.doc.Text((pdf.PageWidth-pdf.Width(font, size, text))/2, y, font, size, text, 0)
+}
+
+func (r *reportWriter) firstPage() {
+	r.doc.AddPage()
+
+	if r.seal != nil {
+		// the seal only decorates the letterhead, a broken image is left out
+		_ = r.doc.Image(r.seal, reportMargin, 40, 64, 64)
+	}
+
+	r.centered(52, pdf.Regular, 10, "Republic of the Philippines")
+	y := 66.0
+	if r.letterhead.Region != "" {
+		r.centered(y, pdf.Regular, 10, r.letterhead.Region)
+		y += 14
+	}
+	if r.letterhead.City != "" {
+		r.centered(y, pdf.Regular, 10, r.letterhead.City)
+		y += 14
+	}
+	r.centered(y+4, pdf.Bold, 14, "BARANGAY "+strings.ToUpper(r.letterhead.Barangay))
+
+	r.doc.Line(reportMargin, 116, pdf.PageWidth-reportMargin, 116, 1.5)
+	r.centered(140, pdf.Bold, 13, r.title)
+	r.y = 144
+	if r.subtitle != "" {
+		r.centered(156, pdf.Regular, 10, r.subtitle)
+		r.y = 160
+	}
+	r.y += 16
+}
+
+func (r *reportWriter) nextPage() {
+	r.doc.AddPage()
+	r.doc.Text(reportMargin, 40, pdf.Bold, 9, pdf.Truncate(pdf.Bold, 9, "Barangay "+r.letterhead.Barangay+" - "+r.title, reportWidth), 0.3)
+	r.doc.Line(reportMargin, 46, pdf.PageWidth-reportMargin, 46, 0.5)
+	r.y = 66
+	if r.columns != nil {
+		r.tableHeader(r.columns)
+	}
+}
+
+// ensure starts a new page unless height more points fit on this one.
+func (r *reportWriter) ensure(height float64) {
+	if r.y+height > reportBottom {
+		r.nextPage()
+	}
+}
+
+func (r *reportWriter) heading(text string) {
+	r.columns = nil
+	r.ensure(40)
+	r.y += 8
+	r.doc.Text(reportMargin, r.y, pdf.Bold, 11, text, 0)
+	r.y += 14
+}
+
+func (r *reportWriter) paragraph(text string) {
+	for _, line := range pdf.Wrap(pdf.Regular, 10, text, reportWidth) {
+		r.ensure(14)
+		r.doc.Text(reportMargin, r.y, pdf.Regular, 10, line, 0)
+		r.y += 14
+	}
+}
+
+// field prints a label and its value on one line.
+func (r *reportWriter) field(label string, value string) {
+	r.ensure(14)
+	r.doc.Text(reportMargin, r.y, pdf.Bold, 10, label, 0)
+	r.doc.Text(reportMargin+110, r.y, pdf.Regular, 10, pdf.Truncate(pdf.Regular, 10, value, reportWidth-110), 0)
+	r.y += 14
+}
+
+func (r *reportWriter) tableHeader(columns []reportColumn) {
+	r.columns = nil
+	r.ensure(reportRow * 2)
+	r.columns = columns
+	r.doc.FillRect(reportMargin, r.y, reportWidth, reportRow, 0.85)
+	r.cells(columns, columnTitles(columns), pdf.Bold)
+}
+
+func columnTitles(columns []reportColumn) []string {
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+	}
+	return titles
+}
+
+func (r *reportWriter) tableRow(cells []string, bold bool) {
+	r.ensure(reportRow)
+	font := pdf.Regular
+	if bold {
+		font = pdf.Bold
+	}
+	r.cells(r.columns, cells, font)
+}
+
+func (r *reportWriter) cells(columns []reportColumn, cells []string, font pdf.Font) {
+	x := reportMargin
+	for i, column := range columns {
+		if cells[i] != "" {
+			text := pdf.Truncate(font, 9, cells[i], column.Width-8)
+			left := x + 4
+			if column.Right {
+				left = x + column.Width - 4 - pdf.Width(font, 9, text)
+			}
+			r.doc.Text(left, r.y+11.5, font, 9, text, 0)
+		}
+		x += column.Width
+	}
+	r.y += reportRow
+	r.doc.Line(reportMargin, r.y, reportMargin+reportWidth, r.y, 0.25)
+}
+
+func (r *reportWriter) endTable() {
+	r.columns = nil
+	r.y += 8
+}
+
+// finish numbers the pages and renders the document.
+func (r *reportWriter) finish(generated time.Time) []byte {
+	pages := r.doc.PageCount()
+	for page := 1; page <= pages; page++ {
+		r.doc.SetPage(page)
+		r.doc.Line(reportMargin, pdf.PageHeight-44, pdf.PageWidth-reportMargin, pdf.PageHeight-44, 0.5)
+		r.doc.Text(reportMargin, pdf.PageHeight-32, pdf.Regular, 8, "Generated "+generated.Format("January 2, 2006"), 0.3)
+		number := fmt.Sprintf("Page %d of %d", page, pages)
+		r.doc.Text(pdf.PageWidth-reportMargin-pdf.Width(pdf.Regular, 8, number), pdf.PageHeight-32, pdf.Regular, 8, number, 0.3)
+	}
+	return r.doc.Bytes()
+}
+
+// formatPeso writes an amount with thousands separators. The peso sign is not
+// in the standard PDF fonts so the currency code is used.
+func formatPeso(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	whole := strconv.FormatFloat(amount, 'f', 2, 64)
+	integer, cents := whole[:len(whole)-3], whole[len(whole)-2:]
+
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+	return sign + "PHP " + grouped.String() + "." + cents
+}
+
+func formatReportDate(date time.Time) string {
+	return date.Format("Jan 2, 2006")
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+)
+
+func TestReportWriterTableBreaksAcrossPages(t *testing.T) {
+	r := newReportWriter(models.Letterhead{Barangay: "Lahug", City: "Cebu City"}, nil, "Project Status Sheet", "")
+
+	columns := []reportColumn{{Title: "Milestone", Width: 300}, {Title: "Due", Width: reportWidth - 300, Right: true}}
+	r.heading("Milestones")
+	r.tableHeader(columns)
+	for i := 1; i <= 60; i++ {
+		r.tableRow([]string{fmt.Sprintf("Milestone %d", i), "Mar 31, 2025"}, false)
+	}
+	r.endTable()
+
+	pages := r.doc.PageCount()
+	if pages < 2 {
+		t.Fatalf("60 table rows fit on %d page, want a page break", pages)
+	}
+	if r.columns != nil {
+		t.Error("endTable() left the header to be reprinted")
+	}
+
+	out := r.finish(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC))
+
+	// the column header is reprinted on every page the table spans
+	if got := bytes.Count(out, []byte("(Milestone)")); got != pages {
+		t.Errorf("header printed %d times, want once per page (%d)", got, pages)
+	}
+	if !bytes.Contains(out, []byte(fmt.Sprintf("(Page %d of %d)", pages, pages))) {
+		t.Errorf("finish() did not number the last page")
+	}
+	if !bytes.Contains(out, []byte("(Barangay Lahug - Project Status Sheet)")) {
+		t.Error("continuation pages are missing the short letterhead")
+	}
+}
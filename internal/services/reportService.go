@@ -0,0 +1,392 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+	"wow-bato-backend/internal/pdf"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrReportProjectNotFound  = NotFoundError("project not found")
+	ErrInvalidReportProjectID = ValidationError("invalid project ID format")
+)
+
+var (
+	REPORT_MAX_PHOTOS      = 4
+	REPORT_RECENT_FEEDBACK = 10
+	REPORT_EXCERPT_LINES   = 6
+)
+
+// reportPhoto is a site photo read back from the upload directory.
+type reportPhoto struct {
+	Image   image.Image
+	Caption string
+	Date    time.Time
+}
+
+type ReportService struct {
+	db        *gorm.DB
+	uploadDir string
+	now       func() time.Time
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db, uploadDir: UploadDirectory(), now: time.Now}
+}
+
+func (s *ReportService) letterhead(barangay_ID uint) (models.Letterhead, error) {
+	var barangay models.Barangay
+	if err := s.db.Where("id = ?", barangay_ID).First(&barangay).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Letterhead{}, fmt.Errorf("%w: ID %d", ErrBarangayNotFound, barangay_ID)
+		}
+		return models.Letterhead{}, fmt.Errorf("failed to find barangay: %w", err)
+	}
+
+	return models.Letterhead{Barangay: barangay.Name, City: barangay.City, Region: barangay.Region, SealURL: barangay.ImageURL}, nil
+}
+
+// loadUpload reads back an image this server stored, other URLs and files
+// that are missing or unreadable give nil.
+func (s *ReportService) loadUpload(url string) image.Image {
+	if !strings.HasPrefix(url, UPLOAD_URL_PREFIX+"/") {
+		return nil
+	}
+
+	name := path.Clean("/" + strings.TrimPrefix(url, UPLOAD_URL_PREFIX+"/"))
+	file, err := os.Open(filepath.Join(s.uploadDir, filepath.FromSlash(name)))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	img, err := decodeImage(file)
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+// BudgetReport prints the annual budget of a fiscal year by category, with
+// the amounts as enacted and after amendments.
+func (s *ReportService) BudgetReport(barangay_ID string, fiscalYear string) ([]byte, error) {
+	budget, err := (&AmendmentService{db: s.db}).AmendedBudget(barangay_ID, fiscalYear)
+	if err != nil {
+		return nil, err
+	}
+
+	letterhead, err := s.letterhead(budget.Barangay_ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderBudgetReport(letterhead, s.loadUpload(letterhead.SealURL), budget, s.now()), nil
+}
+
+// ProjectReport prints the status sheet of a project with its budget items,
+// milestones and latest site photos.
+func (s *ReportService) ProjectReport(projectID string) ([]byte, error) {
+	id, err := strconv.ParseUint(projectID, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidReportProjectID, projectID)
+	}
+
+	var project models.Project
+	if err := s.db.Preload("Category").Where("id = ?", id).First(&project).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: ID %d", ErrReportProjectNotFound, id)
+		}
+		return nil, fmt.Errorf("failed to find project: %w", err)
+	}
+
+	letterhead, err := s.letterhead(project.Barangay_ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := models.ProjectStatusSheet{
+		Letterhead:  letterhead,
+		Name:        project.Name,
+		Category:    project.Category.Name,
+		Status:      project.Status,
+		StartDate:   project.StartDate,
+		EndDate:     project.EndDate,
+		Description: project.Description,
+	}
+
+	if err := s.db.Model(&models.Budget_Item{}).
+		Select("name, status, amount_allocated AS amount").
+		Where("project_id = ?", project.ID).
+		Order("name, id").
+		Scan(&sheet.Items).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve budget items: %w", err)
+	}
+
+	progress, err := (&ProjectProgressService{db: s.db}).Progress(projectID)
+	if err != nil {
+		return nil, err
+	}
+	sheet.Milestones = progress.Milestones
+	sheet.Photos = progress.Photos
+	if len(sheet.Photos) > REPORT_MAX_PHOTOS {
+		sheet.Photos = sheet.Photos[len(sheet.Photos)-REPORT_MAX_PHOTOS:]
+	}
+
+	var photos []reportPhoto
+	for _, photo := range sheet.Photos {
+		if img := s.loadUpload(photo.URL); img != nil {
+			photos = append(photos, reportPhoto{Image: img, Caption: photo.Caption, Date: photo.CreatedAt})
+		}
+	}
+
+	return renderProjectSheet(sheet, s.loadUpload(letterhead.SealURL), photos, s.now()), nil
+}
+
+// FeedbackReport prints how much feedback each project of the barangay drew
+// and the latest comments.
+func (s *ReportService) FeedbackReport(barangay_ID string) ([]byte, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	letterhead, err := s.letterhead(uint(barangay_ID_int))
+	if err != nil {
+		return nil, err
+	}
+
+	summary := models.FeedbackSummary{Letterhead: letterhead}
+	if err := s.db.Table("projects").
+		Select(`projects.id AS project_id, projects.name, projects.status,
+			COUNT(DISTINCT feedbacks.id) AS feedback, COUNT(feedback_replies.id) AS replies,
+			MAX(feedbacks.created_at) AS latest_at`).
+		Joins("LEFT JOIN feedbacks ON feedbacks.project_id = projects.id AND feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published'").
+		Joins("LEFT JOIN feedback_replies ON feedback_replies.feedback_id = feedbacks.id AND feedback_replies.deleted_at IS NULL AND feedback_replies.moderation_state = 'published'").
+		Where("projects.deleted_at IS NULL AND projects.barangay_id = ?", barangay_ID_int).
+		Group("projects.id, projects.name, projects.status").
+		Order("feedback DESC").
+		Order("projects.name").
+		Scan(&summary.Projects).Error; err != nil {
+		return nil, fmt.Errorf("failed to total feedback: %w", err)
+	}
+
+	if err := s.db.Table("feedbacks").
+		Select("projects.name AS project_name, "+publicAuthorColumns+", feedbacks.role, feedbacks.content, feedbacks.created_at").
+		Joins("JOIN projects ON projects.id = feedbacks.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN users ON users.id = feedbacks.user_id").
+		Where("feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published' AND projects.barangay_id = ?", barangay_ID_int).
+		Order("feedbacks.created_at DESC").
+		Limit(REPORT_RECENT_FEEDBACK).
+		Scan(&summary.Recent).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve recent feedback: %w", err)
+	}
+
+	for _, project := range summary.Projects {
+		summary.Feedback += project.Feedback
+		summary.Replies += project.Replies
+	}
+
+	return renderFeedbackSummary(summary, s.loadUpload(letterhead.SealURL), s.now()), nil
+}
+
+func renderBudgetReport(letterhead models.Letterhead, seal image.Image, budget models.AmendedBudget, generated time.Time) []byte {
+	r := newReportWriter(letterhead, seal, "ANNUAL BUDGET", fmt.Sprintf("Fiscal Year %d", budget.FiscalYear))
+
+	if len(budget.Categories) == 0 {
+		r.paragraph(fmt.Sprintf("No budget items are allocated for fiscal year %d.", budget.FiscalYear))
+		return r.finish(generated)
+	}
+
+	columns := []reportColumn{
+		{Title: "Budget item", Width: 200},
+		{Title: "Project", Width: 135},
+		{Title: "Original", Width: 80, Right: true},
+		{Title: "Amended", Width: 80, Right: true},
+	}
+
+	for _, category := range budget.Categories {
+		r.heading(category.Name)
+		r.tableHeader(columns)
+		for _, item := range category.Items {
+			r.tableRow([]string{item.Name, item.ProjectName, formatPeso(item.Original), formatPeso(item.Amended)}, false)
+		}
+		r.tableRow([]string{"Subtotal", "", formatPeso(category.Original), formatPeso(category.Amended)}, true)
+		r.endTable()
+	}
+
+	r.heading("Summary")
+	r.tableHeader([]reportColumn{
+		{Title: "Category", Width: 335},
+		{Title: "Original", Width: 80, Right: true},
+		{Title: "Amended", Width: 80, Right: true},
+	})
+	for _, category := range budget.Categories {
+		r.tableRow([]string{category.Name, formatPeso(category.Original), formatPeso(category.Amended)}, false)
+	}
+	r.tableRow([]string{"Total", formatPeso(budget.Original), formatPeso(budget.Amended)}, true)
+	r.endTable()
+
+	return r.finish(generated)
+}
+
+func milestoneState(milestone models.MilestoneResponse, asOf time.Time) string {
+	switch {
+	case milestone.CompletedAt != nil:
+		return "Done"
+	case milestone.DueDate.Before(asOf.Truncate(24 * time.Hour)):
+		return "Overdue"
+	default:
+		return "Pending"
+	}
+}
+
+func renderProjectSheet(sheet models.ProjectStatusSheet, seal image.Image, photos []reportPhoto, generated time.Time) []byte {
+	r := newReportWriter(sheet.Letterhead, seal, "PROJECT STATUS SHEET", sheet.Name)
+
+	r.field("Project", sheet.Name)
+	r.field("Category", sheet.Category)
+	r.field("Status", sheet.Status)
+	r.field("Schedule", formatReportDate(sheet.StartDate)+" to "+formatReportDate(sheet.EndDate))
+	if sheet.Description != "" {
+		r.y += 4
+		r.paragraph(sheet.Description)
+	}
+
+	r.heading("Budget items")
+	if len(sheet.Items) == 0 {
+		r.paragraph("No budget items have been filed for this project.")
+	} else {
+		r.tableHeader([]reportColumn{
+			{Title: "Item", Width: 255},
+			{Title: "Status", Width: 100},
+			{Title: "Amount", Width: 140, Right: true},
+		})
+		var total float64
+		for _, item := range sheet.Items {
+			r.tableRow([]string{item.Name, item.Status, formatPeso(item.Amount)}, false)
+			if !strings.EqualFold(item.Status, "rejected") {
+				total += item.Amount
+			}
+		}
+		r.tableRow([]string{"Total, excluding rejected items", "", formatPeso(total)}, true)
+		r.endTable()
+	}
+
+	r.heading("Milestones")
+	if len(sheet.Milestones) == 0 {
+		r.paragraph("No milestones have been set for this project.")
+	} else {
+		done := 0
+		r.tableHeader([]reportColumn{
+			{Title: "Milestone", Width: 235},
+			{Title: "Due", Width: 90},
+			{Title: "Completed", Width: 90},
+			{Title: "Status", Width: 80},
+		})
+		for _, milestone := range sheet.Milestones {
+			completed := ""
+			if milestone.CompletedAt != nil {
+				completed = formatReportDate(*milestone.CompletedAt)
+				done++
+			}
+			r.tableRow([]string{milestone.Title, formatReportDate(milestone.DueDate), completed, milestoneState(milestone, generated)}, false)
+		}
+		r.endTable()
+		r.paragraph(fmt.Sprintf("%d of %d milestones completed.", done, len(sheet.Milestones)))
+	}
+
+	if len(photos) > 0 {
+		r.heading("Site photos")
+		const gap = 15.0
+		const boxHeight = 170.0
+		boxWidth := (reportWidth - gap) / 2
+
+		for i := 0; i < len(photos); i += 2 {
+			r.ensure(boxHeight + 30)
+			for j := i; j < i+2 && j < len(photos); j++ {
+				photo := photos[j]
+				bounds := photo.Image.Bounds()
+				w, h := boxWidth, boxWidth*float64(bounds.Dy())/float64(bounds.Dx())
+				if h > boxHeight {
+					w, h = boxHeight*float64(bounds.Dx())/float64(bounds.Dy()), boxHeight
+				}
+
+				x := reportMargin + float64(j-i)*(boxWidth+gap)
+				_ = r.doc.Image(photo.Image, x+(boxWidth-w)/2, r.y, w, h)
+
+				caption := formatReportDate(photo.Date)
+				if photo.Caption != "" {
+					caption = photo.Caption + " (" + caption + ")"
+				}
+				r.doc.Text(x, r.y+boxHeight+12, pdf.Regular, 8, pdf.Truncate(pdf.Regular, 8, caption, boxWidth), 0.3)
+			}
+			r.y += boxHeight + 26
+		}
+	}
+
+	return r.finish(generated)
+}
+
+func renderFeedbackSummary(summary models.FeedbackSummary, seal image.Image, generated time.Time) []byte {
+	r := newReportWriter(summary.Letterhead, seal, "FEEDBACK SUMMARY", "As of "+generated.Format("January 2, 2006"))
+
+	r.field("Projects", strconv.Itoa(len(summary.Projects)))
+	r.field("Feedback", strconv.Itoa(summary.Feedback))
+	r.field("Replies", strconv.Itoa(summary.Replies))
+
+	r.heading("Feedback by project")
+	if len(summary.Projects) == 0 {
+		r.paragraph("The barangay has no projects yet.")
+	} else {
+		r.tableHeader([]reportColumn{
+			{Title: "Project", Width: 235},
+			{Title: "Status", Width: 70},
+			{Title: "Feedback", Width: 60, Right: true},
+			{Title: "Replies", Width: 60, Right: true},
+			{Title: "Latest", Width: 70},
+		})
+		for _, project := range summary.Projects {
+			latest := ""
+			if project.LatestAt != nil {
+				latest = formatReportDate(*project.LatestAt)
+			}
+			r.tableRow([]string{project.Name, project.Status, strconv.Itoa(project.Feedback), strconv.Itoa(project.Replies), latest}, false)
+		}
+		r.endTable()
+	}
+
+	r.heading("Recent feedback")
+	if len(summary.Recent) == 0 {
+		r.paragraph("No feedback has been posted yet.")
+	}
+	for _, excerpt := range summary.Recent {
+		lines := pdf.Wrap(pdf.Regular, 10, excerpt.Content, reportWidth-12)
+		if len(lines) > REPORT_EXCERPT_LINES {
+			lines = lines[:REPORT_EXCERPT_LINES]
+			lines[len(lines)-1] = pdf.Truncate(pdf.Regular, 10, lines[len(lines)-1]+"...", reportWidth-12)
+		}
+
+		r.ensure(float64(len(lines))*13 + 24)
+		author := strings.TrimSpace(excerpt.FirstName + " " + excerpt.LastName)
+		byline := fmt.Sprintf("%s (%s) on %s, %s", author, excerpt.Role, excerpt.ProjectName, formatReportDate(excerpt.CreatedAt))
+		r.doc.Text(reportMargin, r.y, pdf.Bold, 9, pdf.Truncate(pdf.Bold, 9, byline, reportWidth), 0)
+		r.y += 13
+		for _, line := range lines {
+			r.doc.Text(reportMargin+12, r.y, pdf.Regular, 10, line, 0.2)
+			r.y += 13
+		}
+		r.y += 6
+	}
+
+	return r.finish(generated)
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+)
+
+// go test ./internal/services -run TestRender -update rewrites the golden files
+var updateGolden = flag.Bool("update", false, "rewrite the golden files of the PDF reports")
+
+var reportGenerated = time.Date(2024, time.September, 30, 9, 0, 0, 0, time.UTC)
+
+var reportLetterhead = models.Letterhead{Barangay: "San Isidro", City: "City of Batangas", Region: "Region IV-A (CALABARZON)"}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name+".golden.pdf")
+	if *updateGolden {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("failed to read %s, run with -update to create it: %v", golden, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s does not match the rendered report, run with -update if the change is intended", golden)
+	}
+}
+
+// sealImage is a two colour disc so images are exercised without a fixture.
+func sealImage() image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			dx, dy := x-8, y-8
+			switch {
+			case dx*dx+dy*dy < 25:
+				img.Set(x, y, color.NRGBA{R: 200, G: 30, B: 30, A: 255})
+			case dx*dx+dy*dy < 64:
+				img.Set(x, y, color.NRGBA{B: 160, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestRenderBudgetReport(t *testing.T) {
+	budget := models.AmendedBudget{
+		Barangay_ID: 1,
+		FiscalYear:  2024,
+		Original:    1350000,
+		Amended:     1350000,
+		Categories: []models.AmendedBudgetCategory{
+			{ID: 2, Name: "Health Services", Original: 350000, Amended: 400000, Items: []models.AmendedBudgetItem{
+				{ID: 4, Name: "Medicines", ProjectName: "Barangay Health Station", Original: 150000, Amended: 200000},
+				{ID: 5, Name: "Nutrition program", ProjectName: "Operation Timbang", Original: 200000, Amended: 200000},
+			}},
+			{ID: 1, Name: "Infrastructure", Original: 1000000, Amended: 950000, Items: []models.AmendedBudgetItem{
+				{ID: 1, Name: "Concrete road, Purok 3", ProjectName: "Farm-to-market road", Original: 1000000, Amended: 950000},
+			}},
+		},
+	}
+
+	first := renderBudgetReport(reportLetterhead, sealImage(), budget, reportGenerated)
+	if second := renderBudgetReport(reportLetterhead, sealImage(), budget, reportGenerated); !bytes.Equal(first, second) {
+		t.Fatal("renderBudgetReport() is not deterministic")
+	}
+	checkGolden(t, "budget", first)
+}
+
+func TestRenderProjectSheet(t *testing.T) {
+	completed := time.Date(2024, time.June, 28, 0, 0, 0, 0, time.UTC)
+	sheet := models.ProjectStatusSheet{
+		Letterhead:  reportLetterhead,
+		Name:        "Farm-to-market road",
+		Category:    "Infrastructure",
+		Status:      "ongoing",
+		StartDate:   time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2024, time.December, 15, 0, 0, 0, 0, time.UTC),
+		Description: "Concreting of the 1.2 km road from Purok 3 to the national highway so farmers can bring produce to the public market in all weather.",
+		Items: []models.ReportBudgetItem{
+			{Name: "Cement and aggregates", Status: "approved", Amount: 650000},
+			{Name: "Equipment rental", Status: "rejected", Amount: 120000},
+			{Name: "Labor", Status: "pending", Amount: 300000},
+		},
+		Milestones: []models.MilestoneResponse{
+			{ID: 1, Title: "Clearing and grading", DueDate: time.Date(2024, time.June, 30, 0, 0, 0, 0, time.UTC), CompletedAt: &completed},
+			{ID: 2, Title: "Pouring, first 600 m", DueDate: time.Date(2024, time.September, 15, 0, 0, 0, 0, time.UTC)},
+			{ID: 3, Title: "Pouring, remaining 600 m", DueDate: time.Date(2024, time.November, 30, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+	photos := []reportPhoto{{Image: sealImage(), Caption: "Grading near the chapel", Date: completed}}
+
+	checkGolden(t, "project", renderProjectSheet(sheet, nil, photos, reportGenerated))
+}
+
+func TestRenderFeedbackSummary(t *testing.T) {
+	latest := time.Date(2024, time.September, 12, 0, 0, 0, 0, time.UTC)
+	summary := models.FeedbackSummary{
+		Letterhead: reportLetterhead,
+		Feedback:   3,
+		Replies:    1,
+		Projects: []models.ReportProjectFeedback{
+			{ProjectID: 1, Name: "Farm-to-market road", Status: "ongoing", Feedback: 3, Replies: 1, LatestAt: &latest},
+			{ProjectID: 2, Name: "Operation Timbang", Status: "planned"},
+		},
+		Recent: []models.ReportFeedbackExcerpt{
+			{ProjectName: "Farm-to-market road", FirstName: "Maria", LastName: "Reyes", Role: "resident", Content: strings.Repeat("The grading left the drainage blocked near the chapel. ", 20), CreatedAt: latest},
+			{ProjectName: "Farm-to-market road", FirstName: "Jose", LastName: "Cruz", Role: "resident", Content: "Salamat po, mas mabilis na ang biyahe papunta sa palengke.", CreatedAt: latest.AddDate(0, 0, -3)},
+		},
+	}
+
+	checkGolden(t, "feedback", renderFeedbackSummary(summary, nil, reportGenerated))
+}
+
+func TestRenderBudgetReportPages(t *testing.T) {
+	// enough items to spill over so the header and page numbers repeat
+	category := models.AmendedBudgetCategory{ID: 1, Name: "Infrastructure"}
+	for i := 0; i < 60; i++ {
+		category.Items = append(category.Items, models.AmendedBudgetItem{ID: uint(i + 1), Name: "Item", ProjectName: "Road", Original: 1000, Amended: 1000})
+	}
+	budget := models.AmendedBudget{FiscalYear: 2024, Categories: []models.AmendedBudgetCategory{category}}
+
+	got := renderBudgetReport(reportLetterhead, nil, budget, reportGenerated)
+	if !bytes.Contains(got, []byte("(Page 2 of 2)")) {
+		t.Errorf("renderBudgetReport() does not number a second page")
+	}
+	if bytes.Contains(got, []byte("(Page 3 of")) {
+		t.Errorf("renderBudgetReport() rendered more than two pages")
+	}
+}
+
+func TestFormatPeso(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{0, "PHP 0.00"},
+		{999.5, "PHP 999.50"},
+		{1000, "PHP 1,000.00"},
+		{1234567.891, "PHP 1,234,567.89"},
+		{-50000, "-PHP 50,000.00"},
+	}
+
+	for _, tt := range tests {
+		if got := formatPeso(tt.amount); got != tt.want {
+			t.Errorf("formatPeso(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrResidencyClaimNotFound  = NotFoundError("residency claim not found")
+	ErrInvalidResidencyClaimID = ValidationError("invalid residency claim ID format")
+	ErrClaimAlreadyReviewed    = ConflictError("residency claim has already been reviewed")
+	ErrClaimPending            = ConflictError("a residency claim is already pending review")
+	ErrResidencyNoteRequired   = FieldValidationError("note", "a note is required when rejecting a residency claim")
+)
+
+var (
+	MY_CLAIMS_LIST_SPEC = ListSpec{
+		Sorts: map[string]SortField{
+			"created_at": {Column: "created_at", Field: "CreatedAt"},
+		},
+		DefaultSort: "-created_at",
+		Filters: map[string]FilterField{
+			"status": {Column: "status", Kind: FilterEnum, Values: []string{models.ResidencyPending, models.ResidencyApproved, models.ResidencyRejected}},
+		},
+	}
+
+	// oldest first so officials review in filing order
+	PENDING_CLAIMS_LIST_SPEC = ListSpec{
+		IDColumn: "residency_claims.id",
+		Sorts: map[string]SortField{
+			"created_at": {Column: "residency_claims.created_at", Field: "CreatedAt"},
+		},
+		DefaultSort: "created_at",
+		Filters: map[string]FilterField{
+			"created_at": {Column: "residency_claims.created_at", Kind: FilterDateRange},
+		},
+		TextColumns: []string{"users.first_name", "users.last_name", "users.email"},
+	}
+)
+
+type ResidencyService struct {
+	db *gorm.DB
+}
+
+func NewResidencyService(db *gorm.DB) *ResidencyService {
+	return &ResidencyService{db: db}
+}
+
+// FileClaim records a citizen's claim to live in a barangay. The user's
+// barangay moves to the claimed one but stays unverified until approved.
+func (s *ResidencyService) FileClaim(userID uint, claim models.NewResidencyClaim) error {
+	if claim.Barangay_ID == 0 {
+		return ErrInvalidBarangayID
+	}
+
+	var barangayCount int64
+	if err := s.db.Model(&models.Barangay{}).Where("id = ?", claim.Barangay_ID).Count(&barangayCount).Error; err != nil {
+		return fmt.Errorf("failed to check barangay: %w", err)
+	}
+	if barangayCount == 0 {
+		return fmt.Errorf("%w: ID %d", ErrBarangayNotFound, claim.Barangay_ID)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// the user row is locked so two claims filed at once are counted one
+		// after the other, the pending claim index backs this up
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").Where("id = ?", userID).Take(&models.User{}).Error; err != nil {
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
+
+		var pendingCount int64
+		if err := tx.Model(&models.ResidencyClaim{}).
+			Where("user_id = ? AND status = ?", userID, models.ResidencyPending).
+			Count(&pendingCount).Error; err != nil {
+			return fmt.Errorf("failed to check pending claims: %w", err)
+		}
+		if pendingCount > 0 {
+			return ErrClaimPending
+		}
+
+		newClaim := models.ResidencyClaim{
+			UserID:      userID,
+			Barangay_ID: claim.Barangay_ID,
+			Status:      models.ResidencyPending,
+		}
+
+		if err := tx.Create(&newClaim).Error; err != nil {
+			return fmt.Errorf("failed to file residency claim: %w", err)
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"barangay_id":        claim.Barangay_ID,
+			"residency_verified": false,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update user barangay: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// GetMyClaims lists a citizen's claims, newest first.
+func (s *ResidencyService) GetMyClaims(userID uint, params url.Values) ([]models.ResidencyClaimResponse, models.PageMeta, error) {
+
+	query, err := ParseListQuery(params, MY_CLAIMS_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var claims []models.ResidencyClaimResponse
+	meta, err := ListPage(s.db.Model(&models.ResidencyClaim{}).Where("user_id = ?", userID), query, &claims, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("id, user_id, barangay_id, status, note, created_at")
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("failed to retrieve residency claims: %w", err)
+	}
+
+	return claims, meta, nil
+}
+
+// GetPendingClaims lists claims awaiting review in the official's barangay.
+func (s *ResidencyService) GetPendingClaims(barangay_ID uint, params url.Values) ([]models.ResidencyClaimResponse, models.PageMeta, error) {
+
+	query, err := ParseListQuery(params, PENDING_CLAIMS_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	base := s.db.Table("residency_claims").
+		Joins("JOIN users ON users.id = residency_claims.user_id").
+		Where("residency_claims.barangay_id = ? AND residency_claims.status = ? AND residency_claims.deleted_at IS NULL", barangay_ID, models.ResidencyPending)
+
+	var claims []models.ResidencyClaimResponse
+	meta, err := ListPage(base, query, &claims, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select("residency_claims.id, residency_claims.user_id, users.first_name, users.last_name, users.email, users.contact, residency_claims.barangay_id, residency_claims.status, residency_claims.note, residency_claims.created_at")
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("failed to retrieve pending claims: %w", err)
+	}
+
+	return claims, meta, nil
+}
+
+// ReviewClaim approves or rejects a pending claim filed in the reviewer's
+// barangay. Approval marks the citizen as a verified resident.
+func (s *ResidencyService) ReviewClaim(reviewerID uint, barangay_ID uint, claimID string, review models.ReviewResidencyClaim) error {
+
+	claimID_int, err := strconv.Atoi(claimID)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidResidencyClaimID, claimID)
+	}
+
+	if !review.Approve && review.Note == "" {
+		return ErrResidencyNoteRequired
+	}
+
+	var claim models.ResidencyClaim
+	if err := s.db.Where("id = ? AND barangay_id = ?", claimID_int, barangay_ID).First(&claim).Error; err != nil {
+		return fmt.Errorf("%w: ID %d", ErrResidencyClaimNotFound, claimID_int)
+	}
+
+	if claim.Status != models.ResidencyPending {
+		return ErrClaimAlreadyReviewed
+	}
+
+	status := models.ResidencyRejected
+	if review.Approve {
+		status = models.ResidencyApproved
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// the status check keeps two reviewers from both deciding the claim
+		result := tx.Model(&models.ResidencyClaim{}).
+			Where("id = ? AND status = ?", claim.ID, models.ResidencyPending).
+			Updates(map[string]interface{}{
+				"status":         status,
+				"note":           review.Note,
+				"reviewed_by_id": reviewerID,
+				"reviewed_at":    time.Now(),
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to save residency review: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrClaimAlreadyReviewed
+		}
+
+		if !review.Approve {
+			return nil
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND barangay_id = ?", claim.UserID, claim.Barangay_ID).
+			Update("residency_verified", true).Error; err != nil {
+			return fmt.Errorf("failed to verify resident: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newResidencyServiceMock(t *testing.T) (*ResidencyService, sqlmock.Sqlmock, func()) {
+	gormDB, mock, db := newMockDB(t)
+	return NewResidencyService(gormDB), mock, func() { db.Close() }
+}
+
+func TestResidencyService_ReviewClaim_Approve(t *testing.T) {
+	svc, mock, closeDB := newResidencyServiceMock(t)
+	defer closeDB()
+
+	mock.ExpectQuery(`SELECT \* FROM "residency_claims" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+		WithArgs(5, uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "barangay_id", "status"}).
+			AddRow(5, 10, 1, models.ResidencyPending))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "residency_claims" SET "note"=\$1,"reviewed_at"=\$2,"reviewed_by_id"=\$3,"status"=\$4,"updated_at"=\$5 WHERE \(id = \$6 AND status = \$7\) AND "residency_claims"."deleted_at" IS NULL`).
+		WithArgs("", sqlmock.AnyArg(), uint(2), models.ResidencyApproved, sqlmock.AnyArg(), uint(5), models.ResidencyPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "users" SET "residency_verified"=\$1,"updated_at"=\$2 WHERE \(id = \$3 AND barangay_id = \$4\)`).
+		WithArgs(true, sqlmock.AnyArg(), uint(10), uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := svc.ReviewClaim(2, 1, "5", models.ReviewResidencyClaim{Approve: true})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResidencyService_ReviewClaim_AlreadyReviewed(t *testing.T) {
+	svc, mock, closeDB := newResidencyServiceMock(t)
+	defer closeDB()
+
+	mock.ExpectQuery(`SELECT \* FROM "residency_claims" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+		WithArgs(5, uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "barangay_id", "status"}).
+			AddRow(5, 10, 1, models.ResidencyApproved))
+
+	err := svc.ReviewClaim(2, 1, "5", models.ReviewResidencyClaim{Approve: true})
+	if !errors.Is(err, ErrClaimAlreadyReviewed) {
+		t.Errorf("Expected ErrClaimAlreadyReviewed, got %v", err)
+	}
+}
+
+func TestResidencyService_ReviewClaim_ConcurrentReview(t *testing.T) {
+	svc, mock, closeDB := newResidencyServiceMock(t)
+	defer closeDB()
+
+	// another official decided the claim between the lookup and the update
+	mock.ExpectQuery(`SELECT \* FROM "residency_claims" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+		WithArgs(5, uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "barangay_id", "status"}).
+			AddRow(5, 10, 1, models.ResidencyPending))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "residency_claims" SET (.+) WHERE \(id = \$6 AND status = \$7\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := svc.ReviewClaim(2, 1, "5", models.ReviewResidencyClaim{Approve: true})
+	if !errors.Is(err, ErrClaimAlreadyReviewed) {
+		t.Errorf("Expected ErrClaimAlreadyReviewed, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResidencyService_ReviewClaim_RejectRequiresNote(t *testing.T) {
+	svc, _, closeDB := newResidencyServiceMock(t)
+	defer closeDB()
+
+	err := svc.ReviewClaim(2, 1, "5", models.ReviewResidencyClaim{Approve: false})
+	if !errors.Is(err, ErrResidencyNoteRequired) {
+		t.Errorf("Expected ErrResidencyNoteRequired, got %v", err)
+	}
+}
+
+func TestResidencyService_FileClaim_PendingExists(t *testing.T) {
+	svc, mock, closeDB := newResidencyServiceMock(t)
+	defer closeDB()
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "barangays" WHERE id = \$1`).
+		WithArgs(uint(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT "id" FROM "users" WHERE id = \$1 AND "users"."deleted_at" IS NULL LIMIT \$2 FOR UPDATE`).
+		WithArgs(uint(10), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(10))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "residency_claims" WHERE \(user_id = \$1 AND status = \$2\)`).
+		WithArgs(uint(10), models.ResidencyPending).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := svc.FileClaim(10, models.NewResidencyClaim{Barangay_ID: 3})
+	if !errors.Is(err, ErrClaimPending) {
+		t.Errorf("Expected ErrClaimPending, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrResolutionNotFound    = NotFoundError("resolution not found")
+	ErrInvalidResolutionID   = ValidationError("invalid resolution ID format")
+	ErrResolutionNumberTaken = ConflictError("a measure of this kind already has that number")
+	ErrRollCallMismatch      = FieldValidationError("roll_call", "roll_call must agree with votes_for, votes_against and abstentions")
+	ErrRollCallMember        = FieldValidationError("roll_call", "roll_call may only list council members in office on dateEnacted")
+	ErrRollCallDuplicate     = FieldValidationError("roll_call", "roll_call lists a member more than once")
+	ErrLinkTargetNotFound    = NotFoundError("budget record to link was not found in this barangay")
+	ErrInvalidMeasureKind    = FieldValidationError("kind", "kind must be one of: resolution, ordinance")
+)
+
+var (
+	// positions with a vote in the Sangguniang Barangay, the punong barangay
+	// presides and votes to break ties
+	COUNCIL_POSITIONS = []string{models.PositionPunongBarangay, models.PositionKagawad, models.PositionSKChairperson}
+
+	RESOLUTION_LIST_SPEC = ListSpec{
+		Sorts: map[string]SortField{
+			"date_enacted": {Column: "date_enacted", Field: "DateEnacted"},
+			"number":       {Column: "number", Field: "Number"},
+		},
+		DefaultSort: "-date_enacted",
+		Filters: map[string]FilterField{
+			"kind":         {Column: "kind", Kind: FilterEnum, Values: []string{models.MeasureResolution, models.MeasureOrdinance}},
+			"date_enacted": {Column: "date_enacted", Kind: FilterDateRange},
+		},
+		TextColumns: []string{"number", "title", "summary"},
+	}
+
+	// tables a measure can authorize, and how each reaches its barangay
+	resolutionLinkTargets = map[string]struct {
+		model    interface{}
+		barangay string
+	}{
+		"budget_category": {&models.Budget_Category{}, "SELECT barangay_id FROM budget_categories WHERE id = ? AND deleted_at IS NULL"},
+		"project":         {&models.Project{}, "SELECT barangay_id FROM projects WHERE id = ? AND deleted_at IS NULL"},
+		"budget_item":     {&models.Budget_Item{}, "SELECT projects.barangay_id FROM budget_items JOIN projects ON projects.id = budget_items.project_id WHERE budget_items.id = ? AND budget_items.deleted_at IS NULL"},
+	}
+)
+
+type ResolutionService struct {
+	db        *gorm.DB
+	uploadDir string
+}
+
+func NewResolutionService(db *gorm.DB) *ResolutionService {
+	return &ResolutionService{db: db, uploadDir: UploadDirectory()}
+}
+
+func parseResolutionID(resolutionID string) (uint, error) {
+	id, err := strconv.ParseUint(resolutionID, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidResolutionID, resolutionID)
+	}
+	return uint(id), nil
+}
+
+// checkRollCall makes sure the roll call matches the tallies and only lists
+// council members who held office when the measure was enacted.
+func (s *ResolutionService) checkRollCall(barangay_ID uint, enacted time.Time, resolution models.NewResolution) error {
+	if len(resolution.RollCall) == 0 {
+		return nil
+	}
+
+	tally := map[string]int{}
+	seen := map[uint]bool{}
+	ids := make([]uint, 0, len(resolution.RollCall))
+	for _, vote := range resolution.RollCall {
+		if seen[vote.OfficialID] {
+			return ErrRollCallDuplicate
+		}
+		seen[vote.OfficialID] = true
+		tally[vote.Vote]++
+		ids = append(ids, vote.OfficialID)
+	}
+
+	if tally[models.VoteYes] != resolution.VotesFor || tally[models.VoteNo] != resolution.VotesAgainst || tally[models.VoteAbstain] != resolution.Abstentions {
+		return ErrRollCallMismatch
+	}
+
+	var members int64
+	if err := s.db.Model(&models.Official{}).
+		Where("id IN ? AND barangay_id = ? AND position IN ?", ids, barangay_ID, COUNCIL_POSITIONS).
+		Where(officialInOffice, enacted, enacted).
+		Count(&members).Error; err != nil {
+		return fmt.Errorf("failed to check roll call: %w", err)
+	}
+	if members != int64(len(ids)) {
+		return ErrRollCallMember
+	}
+
+	return nil
+}
+
+func (s *ResolutionService) AddResolution(barangay_ID uint, resolution models.NewResolution) (uint, error) {
+	enacted, err := time.Parse(GO_DATE_FORMAT, resolution.DateEnacted)
+	if err != nil {
+		return 0, FieldValidationError("dateEnacted", "dateEnacted must be a date in YYYY-MM-DD format")
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.Resolution{}).
+		Where("barangay_id = ? AND kind = ? AND number = ?", barangay_ID, resolution.Kind, resolution.Number).
+		Count(&existing).Error; err != nil {
+		return 0, fmt.Errorf("failed to check resolution number: %w", err)
+	}
+	if existing > 0 {
+		return 0, ErrResolutionNumberTaken
+	}
+
+	if err := s.checkRollCall(barangay_ID, enacted, resolution); err != nil {
+		return 0, err
+	}
+
+	newResolution := models.Resolution{
+		Barangay_ID:  barangay_ID,
+		Kind:         resolution.Kind,
+		Number:       resolution.Number,
+		Title:        resolution.Title,
+		Summary:      resolution.Summary,
+		DateEnacted:  enacted,
+		VotesFor:     resolution.VotesFor,
+		VotesAgainst: resolution.VotesAgainst,
+		Abstentions:  resolution.Abstentions,
+	}
+	for _, vote := range resolution.RollCall {
+		newResolution.Votes = append(newResolution.Votes, models.ResolutionVote{OfficialID: vote.OfficialID, Vote: vote.Vote})
+	}
+
+	if err := s.db.Create(&newResolution).Error; err != nil {
+		return 0, fmt.Errorf("failed to create resolution: %w", err)
+	}
+
+	return newResolution.ID, nil
+}
+
+func (s *ResolutionService) findResolution(barangay_ID uint, resolutionID string) (models.Resolution, error) {
+	id, err := parseResolutionID(resolutionID)
+	if err != nil {
+		return models.Resolution{}, err
+	}
+
+	var resolution models.Resolution
+	if err := s.db.Where("id = ? AND barangay_id = ?", id, barangay_ID).First(&resolution).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Resolution{}, fmt.Errorf("%w: ID %d", ErrResolutionNotFound, id)
+		}
+		return models.Resolution{}, fmt.Errorf("failed to find resolution: %w", err)
+	}
+
+	return resolution, nil
+}
+
+// UploadDocument attaches the signed PDF copy, replacing any earlier upload.
+func (s *ResolutionService) UploadDocument(barangay_ID uint, resolutionID string, file io.Reader) (string, error) {
+	resolution, err := s.findResolution(barangay_ID, resolutionID)
+	if err != nil {
+		return "", err
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return "", fmt.Errorf("failed to name document: %w", err)
+	}
+
+	document, err := savePDF(s.uploadDir, "resolutions", fmt.Sprintf("%s-%d-%s.pdf", resolution.Kind, resolution.ID, suffix), file)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.db.Model(&resolution).Update("document_url", document).Error; err != nil {
+		return "", fmt.Errorf("failed to save resolution document: %w", err)
+	}
+
+	return document, nil
+}
+
+// LinkResolution records that the measure authorized a budget category,
+// project or budget item of the same barangay.
+func (s *ResolutionService) LinkResolution(barangay_ID uint, resolutionID string, link models.LinkResolution) error {
+	resolution, err := s.findResolution(barangay_ID, resolutionID)
+	if err != nil {
+		return err
+	}
+
+	target, ok := resolutionLinkTargets[link.Type]
+	if !ok {
+		return FieldValidationError("type", "type must be one of: budget_category, project, budget_item")
+	}
+
+	var owners []uint
+	if err := s.db.Raw(target.barangay, link.ID).Scan(&owners).Error; err != nil {
+		return fmt.Errorf("failed to find %s: %w", link.Type, err)
+	}
+	if len(owners) == 0 || owners[0] != barangay_ID {
+		return ErrLinkTargetNotFound
+	}
+
+	if err := s.db.Model(target.model).Where("id = ?", link.ID).Update("resolution_id", resolution.ID).Error; err != nil {
+		return fmt.Errorf("failed to link %s: %w", link.Type, err)
+	}
+
+	return nil
+}
+
+func (s *ResolutionService) ListResolutions(barangay_ID string, params url.Values) ([]models.ResolutionResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, RESOLUTION_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var resolutions []models.ResolutionResponse
+	meta, err := ListPage(s.db.Model(&models.Resolution{}).Where("barangay_id = ?", barangay_ID_int), query, &resolutions, nil)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return resolutions, meta, nil
+}
+
+// GetByNumber looks a measure up the way it is cited, by kind and number,
+// with its roll call and everything it authorized.
+func (s *ResolutionService) GetByNumber(barangay_ID string, kind string, number string) (models.ResolutionDetail, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return models.ResolutionDetail{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+	if kind != models.MeasureResolution && kind != models.MeasureOrdinance {
+		return models.ResolutionDetail{}, ErrInvalidMeasureKind
+	}
+
+	var detail models.ResolutionDetail
+	if err := s.db.Model(&models.Resolution{}).
+		Where("barangay_id = ? AND kind = ? AND number = ?", barangay_ID_int, kind, number).
+		First(&detail.ResolutionResponse).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.ResolutionDetail{}, fmt.Errorf("%w: %s %s", ErrResolutionNotFound, kind, number)
+		}
+		return models.ResolutionDetail{}, fmt.Errorf("failed to find resolution: %w", err)
+	}
+
+	if err := s.db.Table("resolution_votes").
+		Select("resolution_votes.official_id, officials.first_name, officials.last_name, resolution_votes.vote").
+		Joins("JOIN officials ON officials.id = resolution_votes.official_id").
+		Where("resolution_votes.resolution_id = ?", detail.ID).
+		Order("officials.last_name").
+		Scan(&detail.RollCall).Error; err != nil {
+		return models.ResolutionDetail{}, fmt.Errorf("failed to retrieve roll call: %w", err)
+	}
+
+	if err := s.db.Raw(`SELECT 'budget_category' AS type, id, name FROM budget_categories WHERE resolution_id = @id AND deleted_at IS NULL
+		UNION ALL SELECT 'project', id, name FROM projects WHERE resolution_id = @id AND deleted_at IS NULL
+		UNION ALL SELECT 'budget_item', id, name FROM budget_items WHERE resolution_id = @id AND deleted_at IS NULL
+		ORDER BY type, id`, map[string]interface{}{"id": detail.ID}).
+		Scan(&detail.Authorizes).Error; err != nil {
+		return models.ResolutionDetail{}, fmt.Errorf("failed to retrieve authorized records: %w", err)
+	}
+
+	if detail.RollCall == nil {
+		detail.RollCall = []models.ResolutionVoteResponse{}
+	}
+	if detail.Authorizes == nil {
+		detail.Authorizes = []models.ResolutionLink{}
+	}
+	return detail, nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newResolutionServiceMock(t *testing.T) (*ResolutionService, sqlmock.Sqlmock, func() error) {
+	gormDB, mock, db := newMockDB(t)
+	svc := NewResolutionService(gormDB)
+	svc.uploadDir = t.TempDir()
+	return svc, mock, db.Close
+}
+
+func TestResolutionService_AddResolution(t *testing.T) {
+	svc, mock, closeDB := newResolutionServiceMock(t)
+	defer closeDB()
+
+	resolution := models.NewResolution{
+		Kind:        models.MeasureOrdinance,
+		Number:      "2024-003",
+		Title:       "Annual Budget for Fiscal Year 2025",
+		DateEnacted: "2024-10-15",
+		VotesFor:    2,
+		Abstentions: 1,
+		RollCall: []models.ResolutionVoteInput{
+			{OfficialID: 3, Vote: models.VoteYes},
+			{OfficialID: 4, Vote: models.VoteYes},
+			{OfficialID: 5, Vote: models.VoteAbstain},
+		},
+	}
+
+	numberQuery := `SELECT count\(\*\) FROM "resolutions" WHERE \(barangay_id = \$1 AND kind = \$2 AND number = \$3\)`
+
+	mock.ExpectQuery(numberQuery).
+		WithArgs(uint(1), models.MeasureOrdinance, "2024-003").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	if _, err := svc.AddResolution(1, resolution); !errors.Is(err, ErrResolutionNumberTaken) {
+		t.Errorf("Expected ErrResolutionNumberTaken, got %v", err)
+	}
+
+	// the roll call has two yes votes but the tally says three
+	mismatched := resolution
+	mismatched.VotesFor = 3
+	mock.ExpectQuery(numberQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	if _, err := svc.AddResolution(1, mismatched); !errors.Is(err, ErrRollCallMismatch) {
+		t.Errorf("Expected ErrRollCallMismatch, got %v", err)
+	}
+
+	enacted := time.Date(2024, 10, 15, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(numberQuery).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "officials" WHERE \(id IN \(\$1,\$2,\$3\) AND barangay_id = \$4 AND position IN \(\$5,\$6,\$7\)\) AND \(term_start <= \$8 AND COALESCE\(ended_at, term_end\) > \$9\)`).
+		WithArgs(uint(3), uint(4), uint(5), uint(1), models.PositionPunongBarangay, models.PositionKagawad, models.PositionSKChairperson, enacted, enacted).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "resolutions"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectQuery(`INSERT INTO "resolution_votes" \("resolution_id","official_id","vote"\) VALUES (.+) ON CONFLICT`).
+		WithArgs(9, uint(3), models.VoteYes, 9, uint(4), models.VoteYes, 9, uint(5), models.VoteAbstain).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	mock.ExpectCommit()
+
+	id, err := svc.AddResolution(1, resolution)
+	if err != nil || id != 9 {
+		t.Fatalf("AddResolution() = %d, %v", id, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResolutionService_LinkResolution(t *testing.T) {
+	svc, mock, closeDB := newResolutionServiceMock(t)
+	defer closeDB()
+
+	findResolution := func() {
+		mock.ExpectQuery(`SELECT \* FROM "resolutions" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+			WithArgs(uint(9), uint(1), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "barangay_id", "kind", "number"}).AddRow(9, 1, models.MeasureOrdinance, "2024-003"))
+	}
+
+	// a project of another barangay cannot be tied to this ordinance
+	findResolution()
+	mock.ExpectQuery(`SELECT barangay_id FROM projects WHERE id = \$1`).
+		WithArgs(uint(40)).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id"}).AddRow(2))
+	if err := svc.LinkResolution(1, "9", models.LinkResolution{Type: "project", ID: 40}); !errors.Is(err, ErrLinkTargetNotFound) {
+		t.Errorf("Expected ErrLinkTargetNotFound, got %v", err)
+	}
+
+	findResolution()
+	mock.ExpectQuery(`SELECT projects.barangay_id FROM budget_items JOIN projects`).
+		WithArgs(uint(77)).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "budget_items" SET "resolution_id"=\$1,"updated_at"=\$2 WHERE id = \$3`).
+		WithArgs(uint(9), sqlmock.AnyArg(), uint(77)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	if err := svc.LinkResolution(1, "9", models.LinkResolution{Type: "budget_item", ID: 77}); err != nil {
+		t.Errorf("LinkResolution() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResolutionService_UploadDocument(t *testing.T) {
+	svc, mock, closeDB := newResolutionServiceMock(t)
+	defer closeDB()
+
+	findResolution := func() {
+		mock.ExpectQuery(`SELECT \* FROM "resolutions" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "barangay_id", "kind", "number"}).AddRow(9, 1, models.MeasureOrdinance, "2024-003"))
+	}
+
+	findResolution()
+	if _, err := svc.UploadDocument(1, "9", strings.NewReader("<html>not a pdf</html>")); !errors.Is(err, ErrInvalidDocument) {
+		t.Errorf("Expected ErrInvalidDocument, got %v", err)
+	}
+
+	findResolution()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "resolutions" SET "document_url"=\$1`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	document, err := svc.UploadDocument(1, "9", strings.NewReader("%PDF-1.7\n%%EOF\n"))
+	if err != nil {
+		t.Fatalf("UploadDocument() error = %v", err)
+	}
+	if !strings.HasPrefix(document, "/uploads/resolutions/ordinance-9-") || !strings.HasSuffix(document, ".pdf") {
+		t.Errorf("Unexpected document URL %s", document)
+	}
+	if _, err := os.Stat(filepath.Join(svc.uploadDir, "resolutions", path.Base(document))); err != nil {
+		t.Errorf("Expected document file to be written: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResolutionService_GetByNumber(t *testing.T) {
+	svc, mock, closeDB := newResolutionServiceMock(t)
+	defer closeDB()
+
+	if _, err := svc.GetByNumber("1", "memo", "2024-003"); !errors.Is(err, ErrInvalidMeasureKind) {
+		t.Errorf("Expected ErrInvalidMeasureKind, got %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT (.+) FROM "resolutions" WHERE \(barangay_id = \$1 AND kind = \$2 AND number = \$3\)`).
+		WithArgs(1, models.MeasureOrdinance, "2024-003", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "barangay_id", "kind", "number", "title"}).
+			AddRow(9, 1, models.MeasureOrdinance, "2024-003", "Annual Budget for Fiscal Year 2025"))
+	mock.ExpectQuery(`SELECT resolution_votes.official_id, (.+) FROM "resolution_votes" JOIN officials`).
+		WithArgs(uint(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"official_id", "first_name", "last_name", "vote"}).AddRow(3, "Jose", "Reyes", models.VoteYes))
+	mock.ExpectQuery(`SELECT 'budget_category' AS type, id, name FROM budget_categories WHERE resolution_id = \$1 (.+) UNION ALL (.+) ORDER BY type, id`).
+		WithArgs(uint(9), uint(9), uint(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "name"}).AddRow("project", 40, "Drainage Canal"))
+
+	detail, err := svc.GetByNumber("1", models.MeasureOrdinance, "2024-003")
+	if err != nil {
+		t.Fatalf("GetByNumber() error = %v", err)
+	}
+	if detail.ID != 9 || len(detail.RollCall) != 1 || len(detail.Authorizes) != 1 || detail.Authorizes[0].Name != "Drainage Canal" {
+		t.Errorf("Unexpected detail: %+v", detail)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// response targets of barangays that have not set their own, in calendar
+// hours. Weekends and holidays count, so these are platform defaults rather
+// than the working-day periods of the Ease of Doing Business Act.
+var (
+	RESPONSE_HOURS   = 72
+	ESCALATION_HOURS = 120
+)
+
+var OVERDUE_LIST_SPEC = ListSpec{
+	IDColumn: "feedbacks.id",
+	Sorts: map[string]SortField{
+		"created_at": {Column: "feedbacks.created_at", Field: "CreatedAt"},
+	},
+	DefaultSort: "created_at",
+	Filters: map[string]FilterField{
+		"type": {Column: "feedbacks.type", Kind: FilterEnum, Values: FEEDBACK_TYPES},
+	},
+	TextColumns: []string{"feedbacks.content"},
+}
+
+// citizen feedback the public can see that no official has answered
+const awaitingResponse = "feedbacks.deleted_at IS NULL AND feedbacks.moderation_state = 'published' AND feedbacks.role = 'citizen' AND feedbacks.first_response_at IS NULL"
+
+const overdueColumns = "feedbacks.id, feedbacks.content, feedbacks.type, feedbacks.project_id, projects.name AS project_name, projects.barangay_id, feedbacks.created_at, feedbacks.escalated_at"
+
+// ResponseConfig holds the targets used where a barangay has set none.
+type ResponseConfig struct {
+	ResponseHours   int
+	EscalationHours int
+}
+
+// ResponseConfigFromEnv starts from the defaults and applies
+// FEEDBACK_RESPONSE_HOURS and FEEDBACK_ESCALATION_HOURS.
+func ResponseConfigFromEnv() ResponseConfig {
+	config := ResponseConfig{ResponseHours: RESPONSE_HOURS, EscalationHours: ESCALATION_HOURS}
+
+	for name, target := range map[string]*int{
+		"FEEDBACK_RESPONSE_HOURS":   &config.ResponseHours,
+		"FEEDBACK_ESCALATION_HOURS": &config.EscalationHours,
+	} {
+		if value := os.Getenv(name); value != "" {
+			if hours, err := strconv.Atoi(value); err == nil && hours > 0 {
+				*target = hours
+			} else {
+				log.Printf("response targets: invalid %s %q", name, value)
+			}
+		}
+	}
+
+	if config.EscalationHours < config.ResponseHours {
+		log.Printf("response targets: escalation before the response target, escalating at %d hours", config.ResponseHours)
+		config.EscalationHours = config.ResponseHours
+	}
+
+	return config
+}
+
+// responseDeadline is when feedback is due an answer under the targets of
+// its barangay. It needs response_targets joined.
+func (c ResponseConfig) responseDeadline() string {
+	return fmt.Sprintf("feedbacks.created_at + make_interval(hours => COALESCE(response_targets.response_hours, %d))", c.ResponseHours)
+}
+
+func (c ResponseConfig) escalationDeadline() string {
+	return fmt.Sprintf("feedbacks.created_at + make_interval(hours => COALESCE(response_targets.escalation_hours, %d))", c.EscalationHours)
+}
+
+type ResponseService struct {
+	db     *gorm.DB
+	config ResponseConfig
+	mailer Mailer
+}
+
+func NewResponseService(db *gorm.DB) *ResponseService {
+	return &ResponseService{db: db, config: ResponseConfigFromEnv(), mailer: LogMailer{}}
+}
+
+// GetTarget returns the response targets of a barangay, the defaults when it
+// has not set its own.
+func (s *ResponseService) GetTarget(barangay_ID string) (models.ResponseTargetResponse, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return models.ResponseTargetResponse{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	target := models.ResponseTargetResponse{
+		Barangay_ID:     uint(barangay_ID_int),
+		ResponseHours:   s.config.ResponseHours,
+		EscalationHours: s.config.EscalationHours,
+		Default:         true,
+	}
+
+	var saved models.ResponseTarget
+	if err := s.db.Where("barangay_id = ?", barangay_ID_int).Take(&saved).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return target, nil
+		}
+		return models.ResponseTargetResponse{}, fmt.Errorf("failed to retrieve response targets: %w", err)
+	}
+
+	target.ResponseHours = saved.ResponseHours
+	target.EscalationHours = saved.EscalationHours
+	target.Default = false
+	return target, nil
+}
+
+// SetTarget saves the response targets of the official's barangay. They
+// apply to feedback already waiting as well.
+func (s *ResponseService) SetTarget(officialID uint, barangay_ID uint, target models.SetResponseTarget) error {
+	record := models.ResponseTarget{
+		Barangay_ID:     barangay_ID,
+		ResponseHours:   target.ResponseHours,
+		EscalationHours: target.EscalationHours,
+		UpdatedByID:     officialID,
+	}
+
+	if err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "barangay_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"response_hours", "escalation_hours", "updated_by_id", "updated_at"}),
+	}).Create(&record).Error; err != nil {
+		return fmt.Errorf("failed to save response targets: %w", err)
+	}
+
+	return nil
+}
+
+func (s *ResponseService) awaiting() *gorm.DB {
+	return s.db.Table("feedbacks").
+		Joins("JOIN projects ON projects.id = feedbacks.project_id").
+		Joins("LEFT JOIN response_targets ON response_targets.barangay_id = projects.barangay_id").
+		Where(awaitingResponse)
+}
+
+// Overdue lists citizen feedback of a barangay still unanswered past its
+// response target, oldest first.
+func (s *ResponseService) Overdue(barangay_ID string, params url.Values) ([]models.OverdueFeedback, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, OVERDUE_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	now := time.Now()
+	base := s.awaiting().
+		Where("projects.barangay_id = ?", barangay_ID_int).
+		Where(s.config.responseDeadline()+" < ?", now)
+
+	var overdue []models.OverdueFeedback
+	meta, err := ListPage(base, query, &overdue, func(tx *gorm.DB) *gorm.DB {
+		return tx.Select(overdueColumns + ", " + s.config.responseDeadline() + " AS due_at")
+	})
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	for i := range overdue {
+		overdue[i].HoursOverdue = now.Sub(overdue[i].DueAt).Hours()
+	}
+	return overdue, meta, nil
+}
+
+// EscalateOverdue marks citizen feedback unanswered past the escalation
+// target of its barangay and mails the barangay's officials and the admins
+// about it. Each feedback is escalated once. It returns how many were.
+func (s *ResponseService) EscalateOverdue(now time.Time) (int, error) {
+	var due []models.OverdueFeedback
+	if err := s.awaiting().
+		Select(overdueColumns+", "+s.config.escalationDeadline()+" AS due_at").
+		Where("feedbacks.escalated_at IS NULL").
+		Where(s.config.escalationDeadline()+" < ?", now).
+		Order("projects.barangay_id").
+		Order("feedbacks.created_at").
+		Scan(&due).Error; err != nil {
+		return 0, fmt.Errorf("failed to find overdue feedback: %w", err)
+	}
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, len(due))
+	byBarangay := map[uint][]models.OverdueFeedback{}
+	var barangays []uint
+	for i, feedback := range due {
+		ids[i] = feedback.ID
+		if _, ok := byBarangay[feedback.Barangay_ID]; !ok {
+			barangays = append(barangays, feedback.Barangay_ID)
+		}
+		byBarangay[feedback.Barangay_ID] = append(byBarangay[feedback.Barangay_ID], feedback)
+	}
+
+	if err := s.db.Model(&models.Feedback{}).Where("id IN ?", ids).Update("escalated_at", now).Error; err != nil {
+		return 0, fmt.Errorf("failed to escalate overdue feedback: %w", err)
+	}
+
+	var mailErrs []error
+	for _, barangay_ID := range barangays {
+		if err := s.notifyEscalation(barangay_ID, byBarangay[barangay_ID]); err != nil {
+			mailErrs = append(mailErrs, err)
+		}
+	}
+
+	return len(due), errors.Join(mailErrs...)
+}
+
+func (s *ResponseService) notifyEscalation(barangay_ID uint, feedbacks []models.OverdueFeedback) error {
+	var recipients []string
+	if err := s.db.Model(&models.User{}).
+		Where("(role = ? AND barangay_id = ?) OR role = ?", models.RoleOfficial, barangay_ID, models.RoleAdmin).
+		Pluck("email", &recipients).Error; err != nil {
+		return fmt.Errorf("failed to find officials of barangay %d: %w", barangay_ID, err)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "%d citizen feedback posted in barangay %d are still waiting for an answer from an official:\n\n", len(feedbacks), barangay_ID)
+	for _, feedback := range feedbacks {
+		fmt.Fprintf(&body, "- #%d on %s, posted %s\n  %s/projects/%d\n", feedback.ID, feedback.ProjectName,
+			feedback.CreatedAt.Format("Jan 2, 2006 3:04 PM"), AppURL(), feedback.ProjectID)
+	}
+
+	var errs []error
+	for _, to := range recipients {
+		if err := s.mailer.Send(to, "Unanswered citizen feedback", body.String()); err != nil {
+			errs = append(errs, fmt.Errorf("failed to mail %s: %w", to, err))
+		}
+	}
+	return errors.Join(errs...)
+}
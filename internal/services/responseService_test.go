@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type recordedMail struct {
+	to      string
+	subject string
+	body    string
+}
+
+type recordingMailer struct {
+	sent []recordedMail
+	err  error
+}
+
+func (m *recordingMailer) Send(to string, subject string, body string) error {
+	m.sent = append(m.sent, recordedMail{to, subject, body})
+	return m.err
+}
+
+func TestResponseConfigFromEnv(t *testing.T) {
+	t.Setenv("FEEDBACK_RESPONSE_HOURS", "48")
+	t.Setenv("FEEDBACK_ESCALATION_HOURS", "24")
+
+	config := ResponseConfigFromEnv()
+	if config.ResponseHours != 48 || config.EscalationHours != 48 {
+		t.Errorf("ResponseConfigFromEnv() = %+v, want escalation raised to 48 hours", config)
+	}
+
+	t.Setenv("FEEDBACK_RESPONSE_HOURS", "soon")
+	if config := ResponseConfigFromEnv(); config.ResponseHours != RESPONSE_HOURS {
+		t.Errorf("ResponseConfigFromEnv() = %+v, want the default response target", config)
+	}
+}
+
+func TestResponseService_GetTarget(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := &ResponseService{db: gormDB, config: ResponseConfig{ResponseHours: 72, EscalationHours: 120}}
+
+	mock.ExpectQuery(`SELECT \* FROM "response_targets" WHERE barangay_id = \$1 LIMIT \$2`).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id", "response_hours", "escalation_hours"}))
+
+	target, err := svc.GetTarget("1")
+	if err != nil {
+		t.Fatalf("GetTarget() error = %v", err)
+	}
+	if !target.Default || target.ResponseHours != 72 || target.EscalationHours != 120 {
+		t.Errorf("GetTarget() = %+v, want the defaults", target)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "response_targets" WHERE barangay_id = \$1`).
+		WithArgs(2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id", "response_hours", "escalation_hours"}).AddRow(2, 24, 48))
+
+	target, err = svc.GetTarget("2")
+	if err != nil {
+		t.Fatalf("GetTarget() error = %v", err)
+	}
+	if target.Default || target.ResponseHours != 24 || target.EscalationHours != 48 {
+		t.Errorf("GetTarget() = %+v, want the barangay's own targets", target)
+	}
+
+	if _, err := svc.GetTarget("abc"); !errors.Is(err, ErrInvalidBarangayID) {
+		t.Errorf("Expected ErrInvalidBarangayID, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResponseService_SetTarget(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewResponseService(gormDB)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "response_targets" (.+) ON CONFLICT \("barangay_id"\) DO UPDATE SET "response_hours"="excluded"."response_hours","escalation_hours"="excluded"."escalation_hours","updated_by_id"="excluded"."updated_by_id","updated_at"="excluded"."updated_at"`).
+		WithArgs(24, 48, 3, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"barangay_id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	if err := svc.SetTarget(3, 1, models.SetResponseTarget{ResponseHours: 24, EscalationHours: 48}); err != nil {
+		t.Errorf("SetTarget() error = %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResponseService_Overdue(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := &ResponseService{db: gormDB, config: ResponseConfig{ResponseHours: 72, EscalationHours: 120}}
+
+	due := time.Now().Add(-6 * time.Hour)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "feedbacks" JOIN projects (.+) LEFT JOIN response_targets (.+) WHERE (.+) AND feedbacks.first_response_at IS NULL\) AND projects.barangay_id = \$1 AND feedbacks.created_at \+ make_interval\(hours => COALESCE\(response_targets.response_hours, 72\)\) < \$2`).
+		WithArgs(1, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`SELECT feedbacks.id, (.+) AS due_at FROM "feedbacks" (.+) ORDER BY feedbacks.created_at ASC,feedbacks.id ASC`).
+		WithArgs(1, sqlmock.AnyArg(), DEFAULT_PAGE_LIMIT+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "type", "project_id", "project_name", "barangay_id", "created_at", "escalated_at", "due_at"}).
+			AddRow(9, "Walang tubig sa Purok 4", models.FeedbackComplaint, 2, "Water line", 1, due.Add(-72*time.Hour), nil, due))
+
+	overdue, meta, err := svc.Overdue("1", url.Values{})
+	if err != nil {
+		t.Fatalf("Overdue() error = %v", err)
+	}
+	if len(overdue) != 1 || meta.Total != 1 || overdue[0].HoursOverdue < 6 || overdue[0].HoursOverdue > 7 {
+		t.Errorf("Overdue() = %+v, want feedback 9 about 6 hours overdue", overdue)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestResponseService_EscalateOverdue(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	mailer := &recordingMailer{}
+	svc := &ResponseService{db: gormDB, config: ResponseConfig{ResponseHours: 72, EscalationHours: 120}, mailer: mailer}
+
+	now := time.Date(2025, time.March, 10, 8, 0, 0, 0, time.UTC)
+	overdueRows := sqlmock.NewRows([]string{"id", "content", "type", "project_id", "project_name", "barangay_id", "created_at", "escalated_at", "due_at"}).
+		AddRow(9, "Walang tubig", models.FeedbackComplaint, 2, "Water line", 1, now.Add(-130*time.Hour), nil, now.Add(-10*time.Hour)).
+		AddRow(11, "Sirang ilaw", models.FeedbackComplaint, 3, "Street lights", 1, now.Add(-125*time.Hour), nil, now.Add(-5*time.Hour))
+
+	mock.ExpectQuery(`SELECT feedbacks.id, (.+) FROM "feedbacks" (.+) AND feedbacks.escalated_at IS NULL AND feedbacks.created_at \+ make_interval\(hours => COALESCE\(response_targets.escalation_hours, 120\)\) < \$1 ORDER BY projects.barangay_id,feedbacks.created_at`).
+		WithArgs(now).
+		WillReturnRows(overdueRows)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "feedbacks" SET "escalated_at"=\$1,"updated_at"=\$2 WHERE id IN \(\$3,\$4\)`).
+		WithArgs(now, sqlmock.AnyArg(), 9, 11).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT "email" FROM "users" WHERE \(\(role = \$1 AND barangay_id = \$2\) OR role = \$3\)`).
+		WithArgs(models.RoleOfficial, 1, models.RoleAdmin).
+		WillReturnRows(sqlmock.NewRows([]string{"email"}).AddRow("kapitan@example.com").AddRow("admin@example.com"))
+
+	escalated, err := svc.EscalateOverdue(now)
+	if err != nil {
+		t.Fatalf("EscalateOverdue() error = %v", err)
+	}
+	if escalated != 2 {
+		t.Errorf("Expected 2 escalated, got %d", escalated)
+	}
+	if len(mailer.sent) != 2 || mailer.sent[0].to != "kapitan@example.com" {
+		t.Errorf("Expected one mail to each official and admin, got %+v", mailer.sent)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
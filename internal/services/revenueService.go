@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRevenueNotFound  = NotFoundError("revenue not found")
+	ErrInvalidRevenueID = ValidationError("invalid revenue ID format")
+)
+
+var (
+	REVENUE_SOURCES = []string{models.RevenueNTA, models.RevenueRPTShare, models.RevenueFees, models.RevenueGrants, models.RevenueDonations, models.RevenueOther}
+
+	REVENUE_LIST_SPEC = ListSpec{
+		Sorts: map[string]SortField{
+			"fiscal_year": {Column: "fiscal_year", Field: "FiscalYear"},
+			"amount":      {Column: "amount", Field: "Amount"},
+		},
+		DefaultSort: "-fiscal_year",
+		Filters: map[string]FilterField{
+			"source":        {Column: "source", Kind: FilterEnum, Values: REVENUE_SOURCES},
+			"fiscal_year":   {Column: "fiscal_year", Kind: FilterNumberRange},
+			"amount":        {Column: "amount", Kind: FilterNumberRange},
+			"date_received": {Column: "date_received", Kind: FilterDateRange},
+		},
+		TextColumns: []string{"description"},
+	}
+)
+
+type RevenueService struct {
+	db *gorm.DB
+}
+
+func NewRevenueService(db *gorm.DB) *RevenueService {
+	return &RevenueService{db: db}
+}
+
+func parseDateReceived(date string) (*time.Time, error) {
+	if date == "" {
+		return nil, nil
+	}
+	received, err := time.Parse(GO_DATE_FORMAT, date)
+	if err != nil {
+		return nil, FieldValidationError("dateReceived", "dateReceived must be a date in YYYY-MM-DD format")
+	}
+	return &received, nil
+}
+
+func (s *RevenueService) findRevenue(barangay_ID uint, revenueID string) (models.Revenue, error) {
+	id, err := strconv.ParseUint(revenueID, 10, 32)
+	if err != nil {
+		return models.Revenue{}, fmt.Errorf("%w: %s", ErrInvalidRevenueID, revenueID)
+	}
+
+	var revenue models.Revenue
+	if err := s.db.Where("id = ? AND barangay_id = ?", id, barangay_ID).First(&revenue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Revenue{}, fmt.Errorf("%w: ID %d", ErrRevenueNotFound, id)
+		}
+		return models.Revenue{}, fmt.Errorf("failed to find revenue: %w", err)
+	}
+
+	return revenue, nil
+}
+
+func (s *RevenueService) AddRevenue(barangay_ID uint, revenue models.NewRevenue) (uint, error) {
+	received, err := parseDateReceived(revenue.DateReceived)
+	if err != nil {
+		return 0, err
+	}
+
+	newRevenue := models.Revenue{
+		Barangay_ID:  barangay_ID,
+		FiscalYear:   revenue.FiscalYear,
+		Source:       revenue.Source,
+		Description:  revenue.Description,
+		Amount:       revenue.Amount,
+		DateReceived: received,
+	}
+
+	if err := s.db.Create(&newRevenue).Error; err != nil {
+		return 0, fmt.Errorf("failed to record revenue: %w", err)
+	}
+
+	return newRevenue.ID, nil
+}
+
+func (s *RevenueService) UpdateRevenue(barangay_ID uint, revenueID string, update models.NewRevenue) error {
+	revenue, err := s.findRevenue(barangay_ID, revenueID)
+	if err != nil {
+		return err
+	}
+
+	received, err := parseDateReceived(update.DateReceived)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(&revenue).Updates(map[string]interface{}{
+		"fiscal_year":   update.FiscalYear,
+		"source":        update.Source,
+		"description":   update.Description,
+		"amount":        update.Amount,
+		"date_received": received,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update revenue: %w", err)
+	}
+
+	return nil
+}
+
+func (s *RevenueService) DeleteRevenue(barangay_ID uint, revenueID string) error {
+	revenue, err := s.findRevenue(barangay_ID, revenueID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(&revenue).Error; err != nil {
+		return fmt.Errorf("failed to delete revenue: %w", err)
+	}
+
+	return nil
+}
+
+func (s *RevenueService) ListRevenue(barangay_ID string, params url.Values) ([]models.RevenueResponse, models.PageMeta, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+
+	query, err := ParseListQuery(params, REVENUE_LIST_SPEC)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var revenue []models.RevenueResponse
+	meta, err := ListPage(s.db.Model(&models.Revenue{}).Where("barangay_id = ?", barangay_ID_int), query, &revenue, nil)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	return revenue, meta, nil
+}
+
+// Balance sets the revenue of a fiscal year by source against the budget
+// items it allocates by category.
+func (s *RevenueService) Balance(barangay_ID string, fiscalYear string) (models.SourcesUsesReport, error) {
+	barangay_ID_int, err := ConvertToInt(barangay_ID)
+	if err != nil {
+		return models.SourcesUsesReport{}, fmt.Errorf("%w: %s", ErrInvalidBarangayID, barangay_ID)
+	}
+	year, err := parseFiscalYear(fiscalYear)
+	if err != nil {
+		return models.SourcesUsesReport{}, err
+	}
+
+	report := models.SourcesUsesReport{Barangay_ID: uint(barangay_ID_int), FiscalYear: year}
+
+	if err := s.db.Model(&models.Revenue{}).
+		Select("source, SUM(amount) AS amount").
+		Where("barangay_id = ? AND fiscal_year = ?", barangay_ID_int, year).
+		Where(countedRevenue).
+		Group("source").
+		Order("amount DESC").
+		Scan(&report.Sources).Error; err != nil {
+		return models.SourcesUsesReport{}, fmt.Errorf("failed to total revenue: %w", err)
+	}
+
+	if err := s.db.Table("budget_items").
+		Select("budget_categories.id AS category_id, budget_categories.name, SUM(budget_items.amount_allocated) AS amount").
+		Joins("JOIN projects ON projects.id = budget_items.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN budget_categories ON budget_categories.id = projects.category_id AND budget_categories.deleted_at IS NULL").
+		Where("budget_items.deleted_at IS NULL AND projects.barangay_id = ?", barangay_ID_int).
+		Where(allocatedInFiscalYear, year).
+		Group("budget_categories.id, budget_categories.name").
+		Order("amount DESC").
+		Scan(&report.Uses).Error; err != nil {
+		return models.SourcesUsesReport{}, fmt.Errorf("failed to total budget uses: %w", err)
+	}
+
+	for _, source := range report.Sources {
+		report.TotalSources += source.Amount
+	}
+	for i := range report.Sources {
+		report.Sources[i].Share = report.Sources[i].Amount / report.TotalSources * 100
+	}
+	for _, use := range report.Uses {
+		report.TotalUses += use.Amount
+	}
+	report.Balance = report.TotalSources - report.TotalUses
+
+	if report.Sources == nil {
+		report.Sources = []models.RevenueSourceTotal{}
+	}
+	if report.Uses == nil {
+		report.Uses = []models.RevenueUseTotal{}
+	}
+	return report, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestRevenueService_Balance(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewRevenueService(gormDB)
+
+	mock.ExpectQuery(`SELECT source, SUM\(amount\) AS amount FROM "revenues" WHERE \(barangay_id = \$1 AND fiscal_year = \$2\) AND \(`+regexp.QuoteMeta(countedRevenue)+`\) AND "revenues"."deleted_at" IS NULL GROUP BY "source" ORDER BY amount DESC`).
+		WithArgs(1, 2025).
+		WillReturnRows(sqlmock.NewRows([]string{"source", "amount"}).
+			AddRow(models.RevenueNTA, 3000000.0).
+			AddRow(models.RevenueFees, 1000000.0))
+	mock.ExpectQuery(`SELECT budget_categories.id AS category_id, (.+) FROM "budget_items" (.+)EXTRACT\(YEAR FROM projects.start_date\) = \$2 (.+) GROUP BY budget_categories.id, budget_categories.name`).
+		WithArgs(1, 2025).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id", "name", "amount"}).
+			AddRow(2, "Infrastructure", 3500000.0).
+			AddRow(3, "Health", 800000.0))
+
+	report, err := svc.Balance("1", "2025")
+	if err != nil {
+		t.Fatalf("Balance() error = %v", err)
+	}
+	if report.TotalSources != 4000000 || report.TotalUses != 4300000 || report.Balance != -300000 {
+		t.Errorf("Unexpected totals: %+v", report)
+	}
+	if report.Sources[0].Share != 75 || report.Sources[1].Share != 25 {
+		t.Errorf("Unexpected source shares: %+v", report.Sources)
+	}
+
+	if _, err := svc.Balance("1", "next"); !errors.Is(err, ErrInvalidFiscalYear) {
+		t.Errorf("Expected ErrInvalidFiscalYear, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestRevenueService_UpdateRevenueOtherBarangay(t *testing.T) {
+	gormDB, mock, db := newMockDB(t)
+	defer db.Close()
+	svc := NewRevenueService(gormDB)
+
+	mock.ExpectQuery(`SELECT \* FROM "revenues" WHERE \(id = \$1 AND barangay_id = \$2\)`).
+		WithArgs(7, 1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	err := svc.UpdateRevenue(1, "7", models.NewRevenue{FiscalYear: 2025, Source: models.RevenueGrants, Amount: 50000})
+	if !errors.Is(err, ErrRevenueNotFound) {
+		t.Errorf("Expected ErrRevenueNotFound, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
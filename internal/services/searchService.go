@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"wow-bato-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	MAX_SEARCH_LENGTH = 200
+	SEARCH_TYPES      = []string{"project", "budget_item", "budget_category", "feedback"}
+
+	ErrEmptySearch   = FieldValidationError("q", "search text cannot be empty")
+	ErrSearchTooLong = FieldValidationError("q", fmt.Sprintf("search text must be at most %d characters", MAX_SEARCH_LENGTH))
+)
+
+// the document is escaped before ts_headline adds the <mark> tags, feedback
+// is user input and the snippet is rendered as HTML
+const searchHeadline = `ts_headline('english',
+	replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+	search.query, 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2')`
+
+// search_query and search_vector are created by migrateSearch. A row matches
+// on full text or, for misspelled words, on trigram similarity to its title.
+// Fuzzy matches count for half so exact words rank first.
+var searchUnion = `WITH search AS (SELECT search_query(@text) AS query, CAST(@text AS text) AS text)
+SELECT 'project' AS type, p.id, p.name AS title,
+	` + fmt.Sprintf(searchHeadline, "coalesce(nullif(p.description, ''), p.name)") + ` AS snippet,
+	GREATEST(ts_rank_cd(p.search_vector, search.query), word_similarity(search.text, p.name) * 0.5) AS rank,
+	p.barangay_id, b.name AS barangay_name, p.id AS project_id
+FROM projects p
+JOIN barangays b ON b.id = p.barangay_id
+CROSS JOIN search
+WHERE p.deleted_at IS NULL AND (p.search_vector @@ search.query OR search.text <% p.name)
+UNION ALL
+SELECT 'budget_item', i.id, i.name,
+	` + fmt.Sprintf(searchHeadline, "coalesce(nullif(i.description, ''), i.name)") + `,
+	GREATEST(ts_rank_cd(i.search_vector, search.query), word_similarity(search.text, i.name) * 0.5),
+	p.barangay_id, b.name, p.id
+FROM budget_items i
+JOIN projects p ON p.id = i.project_id AND p.deleted_at IS NULL
+JOIN barangays b ON b.id = p.barangay_id
+CROSS JOIN search
+WHERE i.deleted_at IS NULL AND (i.search_vector @@ search.query OR search.text <% i.name)
+UNION ALL
+SELECT 'budget_category', c.id, c.name,
+	` + fmt.Sprintf(searchHeadline, "coalesce(nullif(c.description, ''), c.name)") + `,
+	GREATEST(ts_rank_cd(c.search_vector, search.query), word_similarity(search.text, c.name) * 0.5),
+	c.barangay_id, b.name, NULL
+FROM budget_categories c
+JOIN barangays b ON b.id = c.barangay_id
+CROSS JOIN search
+WHERE c.deleted_at IS NULL AND (c.search_vector @@ search.query OR search.text <% c.name)
+UNION ALL
+SELECT 'feedback', f.id, p.name,
+	` + fmt.Sprintf(searchHeadline, "f.content") + `,
+	ts_rank_cd(f.search_vector, search.query),
+	p.barangay_id, b.name, p.id
+FROM feedbacks f
+JOIN projects p ON p.id = f.project_id AND p.deleted_at IS NULL
+JOIN barangays b ON b.id = p.barangay_id
+CROSS JOIN search
+WHERE f.deleted_at IS NULL AND f.moderation_state = 'published' AND f.search_vector @@ search.query`
+
+type SearchService struct {
+	db *gorm.DB
+}
+
+func NewSearchService(db *gorm.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// Search ranks projects, budget items, budget categories and feedback
+// against q. type and barangay_ID narrow the results and accept comma
+// separated lists.
+func (s *SearchService) Search(params url.Values) ([]models.SearchResult, models.PageMeta, error) {
+	text := strings.TrimSpace(params.Get("q"))
+	if text == "" {
+		return nil, models.PageMeta{}, ErrEmptySearch
+	}
+	if len([]rune(text)) > MAX_SEARCH_LENGTH {
+		return nil, models.PageMeta{}, ErrSearchTooLong
+	}
+
+	page, limit, err := parsePageParams(params)
+	if err != nil {
+		return nil, models.PageMeta{}, err
+	}
+
+	var types []string
+	if raw := params.Get("type"); raw != "" {
+		types = strings.Split(raw, ",")
+		for _, t := range types {
+			if !containsString(SEARCH_TYPES, t) {
+				return nil, models.PageMeta{}, FieldValidationError("type", "type must be one of: "+strings.Join(SEARCH_TYPES, ", "))
+			}
+		}
+	}
+
+	var barangayIDs []uint
+	if raw := params.Get("barangay_ID"); raw != "" {
+		for _, id := range strings.Split(raw, ",") {
+			idInt, err := strconv.ParseUint(id, 10, 32)
+			if err != nil {
+				return nil, models.PageMeta{}, FieldValidationError("barangay_ID", "barangay_ID must be a list of barangay IDs")
+			}
+			barangayIDs = append(barangayIDs, uint(idInt))
+		}
+	}
+
+	results := s.db.Table("(?) AS results", s.db.Raw(searchUnion, map[string]interface{}{"text": text}))
+	if len(types) > 0 {
+		results = results.Where("type IN ?", types)
+	}
+	if len(barangayIDs) > 0 {
+		results = results.Where("barangay_id IN ?", barangayIDs)
+	}
+
+	results = results.Session(&gorm.Session{})
+
+	var total int64
+	if err := results.Count(&total).Error; err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	var hits []models.SearchResult
+	if err := results.Order("rank DESC").Order("type").Order("id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Scan(&hits).Error; err != nil {
+		return nil, models.PageMeta{}, fmt.Errorf("failed to search: %w", err)
+	}
+
+	if hits == nil {
+		hits = []models.SearchResult{}
+	}
+
+	meta := models.PageMeta{
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+		HasMore:    int64(page*limit) < total,
+		Sort:       "-rank",
+	}
+
+	return hits, meta, nil
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("Failed to create mock: %v", err)
+	}
+	defer db.Close()
+
+	gormDB, err := gorm.Open(postgres.New(postgres.Config{Conn: db, DriverName: "postgres"}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open gorm: %v", err)
+	}
+	svc := NewSearchService(gormDB)
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM \(WITH search AS \(SELECT search_query\(\$1\) (.+) UNION ALL (.+)\) AS results WHERE type IN \(\$\d+,\$\d+\) AND barangay_id IN \(\$\d+\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectQuery(`SELECT \* FROM \(WITH search AS (.+)\) AS results WHERE (.+) ORDER BY rank DESC,type,id LIMIT \$\d+ OFFSET \$\d+`).
+		WillReturnRows(sqlmock.NewRows([]string{"type", "id", "title", "snippet", "rank", "barangay_id", "barangay_name", "project_id"}).
+			AddRow("project", 7, "Drainage Canal", "Clearing the <mark>drainage</mark> along Rizal St.", 0.8, 2, "San Isidro", 7).
+			AddRow("budget_item", 12, "Culvert pipes", "Pipes for the <mark>drainage</mark> canal", 0.4, 2, "San Isidro", 7))
+
+	params := url.Values{"q": {"drainage"}, "type": {"project,budget_item"}, "barangay_ID": {"2"}, "limit": {"2"}, "page": {"2"}}
+	results, meta, err := svc.Search(params)
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 2 || results[0].Type != "project" || results[1].ProjectID == nil || *results[1].ProjectID != 7 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	if meta.Total != 3 || meta.TotalPages != 2 || meta.HasMore {
+		t.Errorf("Unexpected meta: %+v", meta)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestSearchService_Search_Validation(t *testing.T) {
+	svc := NewSearchService(nil)
+
+	tests := []struct {
+		name      string
+		params    url.Values
+		wantField string
+	}{
+		{name: "Empty Query", params: url.Values{"q": {"  "}}, wantField: "q"},
+		{name: "Unknown Type", params: url.Values{"q": {"kanal"}, "type": {"users"}}, wantField: "type"},
+		{name: "Bad Barangay", params: url.Values{"q": {"kanal"}, "barangay_ID": {"1,two"}}, wantField: "barangay_ID"},
+		{name: "Bad Limit", params: url.Values{"q": {"kanal"}, "limit": {"0"}}, wantField: "limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := svc.Search(tt.params)
+
+			var serviceErr *ServiceError
+			if !errors.As(err, &serviceErr) || len(serviceErr.Fields) != 1 || serviceErr.Fields[0].Field != tt.wantField {
+				t.Errorf("Search() error = %v, want field error on %s", err, tt.wantField)
+			}
+		})
+	}
+}
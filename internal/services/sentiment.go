@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"wow-bato-backend/internal/models"
+)
+
+// lexicon/ holds the English and Tagalog sentiment word lists and the topic
+// keywords, built into the binary so scoring works offline.
+//
+//go:embed lexicon/*.txt
+var lexiconFiles embed.FS
+
+var (
+	SENTIMENT_LEXICONS = []string{"lexicon/sentiment_en.txt", "lexicon/sentiment_tl.txt"}
+	TOPIC_KEYWORDS     = "lexicon/topics.txt"
+
+	// words that flip the valence of a term right after them
+	SENTIMENT_NEGATORS = []string{"not", "no", "never", "dont", "don", "isnt", "isn", "wasnt", "wasn", "hindi", "di", "wag", "huwag", "ayaw"}
+	// words that strengthen the term right after them
+	SENTIMENT_BOOSTERS = []string{"very", "so", "really", "too", "extremely", "sobrang", "sobra", "talagang", "talaga", "grabe", "super"}
+
+	// a score this far from zero is no longer neutral
+	SENTIMENT_THRESHOLD = 0.05
+)
+
+const (
+	negatedWeight = -0.5
+	boostedWeight = 1.5
+	// evens out sums of valences into -1..1, as VADER does
+	normalizeAlpha = 15.0
+)
+
+// FeedbackAnalysis is what the lexicon reads from one feedback.
+type FeedbackAnalysis struct {
+	Sentiment string
+	Score     float64 //-1 (very negative) to 1 (very positive)
+	Topics    []string
+}
+
+// Lexicon scores sentiment and picks topics from the words of a text.
+// Multi-word terms are stored joined by single spaces.
+type Lexicon struct {
+	valences map[string]float64
+	topics   map[string]string //keyword -> topic
+	longest  int               //words in the longest term or keyword
+}
+
+// LexiconFromEnv loads the built-in word lists and adds
+// SENTIMENT_LEXICON, a file of extra terms in the format of
+// lexicon/sentiment_en.txt, when it is set.
+func LexiconFromEnv() *Lexicon {
+	lexicon := &Lexicon{valences: map[string]float64{}, topics: map[string]string{}}
+
+	for _, name := range SENTIMENT_LEXICONS {
+		if err := lexicon.loadFile(name, lexicon.readValences); err != nil {
+			log.Printf("sentiment: ignoring %s: %v", name, err)
+		}
+	}
+	if err := lexicon.loadFile(TOPIC_KEYWORDS, lexicon.readTopics); err != nil {
+		log.Printf("sentiment: ignoring %s: %v", TOPIC_KEYWORDS, err)
+	}
+
+	if path := os.Getenv("SENTIMENT_LEXICON"); path != "" {
+		file, err := os.Open(path)
+		if err == nil {
+			err = lexicon.readValences(file)
+			file.Close()
+		}
+		if err != nil {
+			log.Printf("sentiment: ignoring SENTIMENT_LEXICON: %v", err)
+		}
+	}
+
+	return lexicon
+}
+
+func (l *Lexicon) loadFile(name string, read func(io.Reader) error) error {
+	file, err := lexiconFiles.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return read(file)
+}
+
+func (l *Lexicon) addTerm(term string) string {
+	tokens := filterTokens(term)
+	l.longest = max(l.longest, len(tokens))
+	return strings.Join(tokens, " ")
+}
+
+// readValences reads lines of a term and its valence, "good job 3".
+func (l *Lexicon) readValences(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		cut := strings.LastIndexByte(text, ' ')
+		if cut < 0 {
+			return fmt.Errorf("line %d: missing valence", line)
+		}
+		valence, err := strconv.ParseFloat(text[cut+1:], 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid valence %q", line, text[cut+1:])
+		}
+		if term := l.addTerm(text[:cut]); term != "" {
+			l.valences[term] = valence
+		}
+	}
+	return scanner.Err()
+}
+
+// readTopics reads lines of a topic and its keywords, "water: tubig, gripo".
+func (l *Lexicon) readTopics(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		topic, keywords, found := strings.Cut(text, ":")
+		topic = strings.TrimSpace(topic)
+		if !found || !tagPattern.MatchString(topic) {
+			return fmt.Errorf("line %d: expected a topic and its keywords", line)
+		}
+		for _, keyword := range strings.Split(keywords, ",") {
+			if keyword := l.addTerm(keyword); keyword != "" {
+				l.topics[keyword] = topic
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// match finds the longest term of terms starting at tokens[i] and returns
+// it with the number of tokens it covers. Tagalog words are tried without
+// their -ng linker as well, so "sirang" matches "sira".
+func match[V any](terms map[string]V, tokens []string, i int, longest int) (V, int, bool) {
+	for n := min(longest, len(tokens)-i); n > 0; n-- {
+		phrase := strings.Join(tokens[i:i+n], " ")
+		if value, ok := terms[phrase]; ok {
+			return value, n, true
+		}
+		if stem, ok := strings.CutSuffix(phrase, "ng"); ok && len(stem) > 2 {
+			if value, ok := terms[stem]; ok {
+				return value, n, true
+			}
+		}
+	}
+	var zero V
+	return zero, 0, false
+}
+
+// Analyze scores the sentiment of text and lists the topics it mentions.
+// A negator up to three words before a term softens and flips it, a booster
+// right before it strengthens it. Tagalog "napaka-" adjectives count as
+// boosted.
+func (l *Lexicon) Analyze(text string) FeedbackAnalysis {
+	tokens := filterTokens(text)
+
+	var sum float64
+	for i := 0; i < len(tokens); {
+		valence, n, ok := match(l.valences, tokens, i, l.longest)
+		boosted := i > 0 && slices.Contains(SENTIMENT_BOOSTERS, tokens[i-1])
+		if !ok {
+			if stem, cut := strings.CutPrefix(tokens[i], "napaka"); cut && stem != "" {
+				valence, _, ok = match(l.valences, []string{stem}, 0, 1)
+				n, boosted = 1, true
+			}
+		}
+		if !ok {
+			i++
+			continue
+		}
+
+		if boosted {
+			valence *= boostedWeight
+		}
+		for j := max(0, i-3); j < i; j++ {
+			if slices.Contains(SENTIMENT_NEGATORS, tokens[j]) {
+				valence *= negatedWeight
+				break
+			}
+		}
+		sum += valence
+		i += n
+	}
+
+	analysis := FeedbackAnalysis{Score: sum / math.Sqrt(sum*sum+normalizeAlpha), Sentiment: models.SentimentNeutral}
+	switch {
+	case analysis.Score >= SENTIMENT_THRESHOLD:
+		analysis.Sentiment = models.SentimentPositive
+	case analysis.Score <= -SENTIMENT_THRESHOLD:
+		analysis.Sentiment = models.SentimentNegative
+	}
+
+	for i := 0; i < len(tokens); {
+		topic, n, ok := match(l.topics, tokens, i, l.longest)
+		if !ok {
+			i++
+			continue
+		}
+		if !slices.Contains(analysis.Topics, topic) {
+			analysis.Topics = append(analysis.Topics, topic)
+		}
+		i += n
+	}
+	slices.Sort(analysis.Topics)
+
+	return analysis
+}
+
+func (a FeedbackAnalysis) feedbackTopics() []models.FeedbackTopic {
+	topics := make([]models.FeedbackTopic, len(a.Topics))
+	for i, topic := range a.Topics {
+		topics[i] = models.FeedbackTopic{Topic: topic}
+	}
+	return topics
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"wow-bato-backend/internal/models"
+)
+
+func TestLexiconAnalyze(t *testing.T) {
+	lexicon := LexiconFromEnv()
+
+	tests := []struct {
+		text      string
+		sentiment string
+		topics    []string
+	}{
+		{"Thank you, the new road is great!", models.SentimentPositive, []string{"roads"}},
+		{"Maraming salamat po sa magandang kalsada.", models.SentimentPositive, []string{"roads"}},
+		{"Napakabagal ng proyekto, nakatiwangwang pa rin ang tulay.", models.SentimentNegative, []string{"delays", "roads"}},
+		{"Bumabaha pa rin sa kanto, barado ang kanal.", models.SentimentNegative, []string{"drainage"}},
+		{"The streetlights are not working and it is dangerous at night.", models.SentimentNegative, []string{"electricity"}},
+		{"Hindi maganda ang pagkakagawa.", models.SentimentNegative, nil},
+		{"No problems so far with the water supply.", models.SentimentPositive, []string{"water"}},
+		{"When will the covered court open?", models.SentimentNeutral, []string{"facilities"}},
+	}
+
+	for _, tt := range tests {
+		analysis := lexicon.Analyze(tt.text)
+		if analysis.Sentiment != tt.sentiment {
+			t.Errorf("Analyze(%q) sentiment = %s (%.2f), want %s", tt.text, analysis.Sentiment, analysis.Score, tt.sentiment)
+		}
+		if !reflect.DeepEqual(analysis.Topics, tt.topics) {
+			t.Errorf("Analyze(%q) topics = %v, want %v", tt.text, analysis.Topics, tt.topics)
+		}
+		if analysis.Score < -1 || analysis.Score > 1 {
+			t.Errorf("Analyze(%q) score = %f, want between -1 and 1", tt.text, analysis.Score)
+		}
+	}
+}
+
+func TestLexiconBoostersAndNegators(t *testing.T) {
+	lexicon := LexiconFromEnv()
+
+	good := lexicon.Analyze("good").Score
+	if boosted := lexicon.Analyze("very good").Score; boosted <= good {
+		t.Errorf("Analyze(very good) = %.2f, want above good %.2f", boosted, good)
+	}
+	if negated := lexicon.Analyze("not good").Score; negated >= 0 {
+		t.Errorf("Analyze(not good) = %.2f, want negative", negated)
+	}
+}
+
+func TestLexiconReadValences(t *testing.T) {
+	lexicon := &Lexicon{valences: map[string]float64{}, topics: map[string]string{}}
+
+	if err := lexicon.readValences(strings.NewReader("# extra\nPalpak 3x\n")); err == nil {
+		t.Error("readValences() accepted an invalid valence")
+	}
+	if err := lexicon.readValences(strings.NewReader("# extra\nPalpak na proyekto -3\n")); err != nil {
+		t.Fatalf("readValences() error = %v", err)
+	}
+	if lexicon.valences["palpak na proyekto"] != -3 || lexicon.longest != 3 {
+		t.Errorf("readValences() = %v, longest %d", lexicon.valences, lexicon.longest)
+	}
+}
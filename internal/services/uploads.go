@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+var (
+	UPLOAD_URL_PREFIX       = "/uploads"
+	MAX_IMAGE_BYTES   int64 = 5 << 20
+	ErrInvalidImage         = ValidationError("uploaded file is not a supported image (jpeg or png)")
+	ErrImageTooLarge        = newKindError(KindTooLarge, "uploaded image exceeds the 5MB limit")
+	// a few kilobytes of png can declare a canvas that takes gigabytes to
+	// decode, so the dimensions are checked against a pixel budget first
+	MAX_IMAGE_PIXELS   int64 = 40_000_000
+	ErrImageDimensions       = newKindError(KindTooLarge, "uploaded image exceeds the 40 megapixel limit")
+
+	MAX_DOCUMENT_BYTES  int64 = 20 << 20
+	ErrInvalidDocument        = ValidationError("uploaded file is not a PDF document")
+	ErrDocumentTooLarge       = newKindError(KindTooLarge, "uploaded document exceeds the 20MB limit")
+)
+
+// UploadDirectory is where user uploaded files are written and served from.
+func UploadDirectory() string {
+	if dir := os.Getenv("UPLOAD_DIR"); dir != "" {
+		return dir
+	}
+
+	return "uploads"
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// decodeImage reads at most MAX_IMAGE_BYTES and decodes a jpeg or png image
+// of at most MAX_IMAGE_PIXELS.
+func decodeImage(file io.Reader) (image.Image, error) {
+	data, err := io.ReadAll(io.LimitReader(file, MAX_IMAGE_BYTES+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	if int64(len(data)) > MAX_IMAGE_BYTES {
+		return nil, ErrImageTooLarge
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	if int64(config.Width)*int64(config.Height) > MAX_IMAGE_PIXELS {
+		return nil, ErrImageDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	return img, nil
+}
+
+// savePNG encodes img into uploadDir/subdir/name and returns its public URL.
+func savePNG(uploadDir string, subdir string, name string, img image.Image) (string, error) {
+	dir := filepath.Join(uploadDir, subdir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	out, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload file: %w", err)
+	}
+	defer out.Close()
+
+	if err := png.Encode(out, img); err != nil {
+		return "", fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	return path.Join(UPLOAD_URL_PREFIX, subdir, name), nil
+}
+
+// squareThumbnail center-crops src to a square and scales it down to
+// size x size by averaging the source pixels that fall in each target pixel.
+// Images smaller than size are scaled up with nearest neighbour sampling.
+func squareThumbnail(src image.Image, size int) *image.RGBA {
+	bounds := src.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	return resample(src, image.Rect(x0, y0, x0+side, y0+side), size, size)
+}
+
+// fitImage scales src down, keeping its aspect ratio, so neither side is
+// longer than max. Smaller images are returned as they are.
+func fitImage(src image.Image, max int) image.Image {
+	bounds := src.Bounds()
+	if bounds.Dx() <= max && bounds.Dy() <= max {
+		return src
+	}
+
+	width, height := max, bounds.Dy()*max/bounds.Dx()
+	if bounds.Dy() > bounds.Dx() {
+		width, height = bounds.Dx()*max/bounds.Dy(), max
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	return resample(src, bounds, width, height)
+}
+
+// resample scales the area of src inside crop to width x height by averaging
+// the source pixels that fall in each target pixel.
+func resample(src image.Image, crop image.Rectangle, width int, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		sy0 := crop.Min.Y + y*crop.Dy()/height
+		sy1 := crop.Min.Y + (y+1)*crop.Dy()/height
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+
+		for x := 0; x < width; x++ {
+			sx0 := crop.Min.X + x*crop.Dx()/width
+			sx1 := crop.Min.X + (x+1)*crop.Dx()/width
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return dst
+}
+
+// savePDF checks that file is a PDF of at most MAX_DOCUMENT_BYTES, writes it
+// to uploadDir/subdir/name and returns its public URL.
+func savePDF(uploadDir string, subdir string, name string, file io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(file, MAX_DOCUMENT_BYTES+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	if int64(len(data)) > MAX_DOCUMENT_BYTES {
+		return "", ErrDocumentTooLarge
+	}
+
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", ErrInvalidDocument
+	}
+
+	dir := filepath.Join(uploadDir, subdir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write upload file: %w", err)
+	}
+
+	return path.Join(UPLOAD_URL_PREFIX, subdir, name), nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func TestSquareThumbnail(t *testing.T) {
+	// left half red, right half blue, wider than tall so the crop is centered
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			if x < 150 {
+				src.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				src.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "Downscale", size: 10},
+		{name: "Upscale", size: 200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb := squareThumbnail(src, tt.size)
+
+			if thumb.Bounds().Dx() != tt.size || thumb.Bounds().Dy() != tt.size {
+				t.Fatalf("squareThumbnail() size = %v, want %dx%d", thumb.Bounds().Size(), tt.size, tt.size)
+			}
+
+			left := thumb.RGBAAt(0, tt.size/2)
+			if left.R != 255 || left.B != 0 {
+				t.Errorf("squareThumbnail() left edge = %v, want red", left)
+			}
+
+			right := thumb.RGBAAt(tt.size-1, tt.size/2)
+			if right.B != 255 || right.R != 0 {
+				t.Errorf("squareThumbnail() right edge = %v, want blue", right)
+			}
+		})
+	}
+}
+
+func TestFitImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		width, height int
+		max           int
+		want          image.Point
+	}{
+		{name: "Landscape", width: 4000, height: 3000, max: 1280, want: image.Pt(1280, 960)},
+		{name: "Portrait", width: 1080, height: 1920, max: 1280, want: image.Pt(720, 1280)},
+		{name: "Square", width: 2000, height: 2000, max: 1280, want: image.Pt(1280, 1280)},
+		{name: "Already small", width: 800, height: 600, max: 1280, want: image.Pt(800, 600)},
+		{name: "Thin strip keeps a pixel", width: 5000, height: 2, max: 1280, want: image.Pt(1280, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+
+			fitted := fitImage(src, tt.max)
+
+			if got := fitted.Bounds().Size(); got != tt.want {
+				t.Errorf("fitImage(%dx%d, %d) size = %v, want %v", tt.width, tt.height, tt.max, got, tt.want)
+			}
+		})
+	}
+}
+
+// pngHeader returns the signature and header chunk of a png declaring a
+// width x height canvas, which is all image.DecodeConfig reads.
+func pngHeader(width, height uint32) []byte {
+	chunk := make([]byte, 0, 17)
+	chunk = append(chunk, "IHDR"...)
+	chunk = binary.BigEndian.AppendUint32(chunk, width)
+	chunk = binary.BigEndian.AppendUint32(chunk, height)
+	chunk = append(chunk, 8, 6, 0, 0, 0)
+
+	header := []byte("\x89PNG\r\n\x1a\n")
+	header = binary.BigEndian.AppendUint32(header, 13)
+	header = append(header, chunk...)
+	return binary.BigEndian.AppendUint32(header, crc32.ChecksumIEEE(chunk))
+}
+
+func TestDecodeImage(t *testing.T) {
+	var small bytes.Buffer
+	if err := png.Encode(&small, image.NewRGBA(image.Rect(0, 0, 64, 48))); err != nil {
+		t.Fatalf("Failed to encode image: %v", err)
+	}
+
+	img, err := decodeImage(bytes.NewReader(small.Bytes()))
+	if err != nil {
+		t.Fatalf("decodeImage() error = %v", err)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(64, 48) {
+		t.Errorf("decodeImage() size = %v, want 64x48", got)
+	}
+
+	// the declared canvas is rejected before any pixel data is decoded
+	if _, err := decodeImage(bytes.NewReader(pngHeader(100000, 100000))); !errors.Is(err, ErrImageDimensions) {
+		t.Errorf("Expected ErrImageDimensions for an oversized canvas, got %v", err)
+	}
+
+	if _, err := decodeImage(bytes.NewReader([]byte("not an image"))); !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Expected ErrInvalidImage, got %v", err)
+	}
+
+	if _, err := decodeImage(bytes.NewReader(make([]byte, MAX_IMAGE_BYTES+1))); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Expected ErrImageTooLarge, got %v", err)
+	}
+}
@@ -1,52 +1,91 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 	"wow-bato-backend/internal/models"
 
 	"gorm.io/gorm"
 )
 
 var (
-	ErrUserNotFound          = errors.New("user not found")
-	ErrInvalidCredentials    = errors.New("invalid email or password")
-	ErrEmptyEmail            = errors.New("email cannot be empty")
-	ErrEmptyPassword         = errors.New("password cannot be empty")
-	ErrEmptyFirstName        = errors.New("first name cannot be empty")
-	ErrEmptyLastName         = errors.New("last name cannot be empty")
-	ErrInvalidRole           = errors.New("invalid user role")
-	ErrEmptyContact          = errors.New("contact information cannot be empty")
+	ErrUserNotFound          = NotFoundError("user not found")
+	ErrInvalidCredentials    = UnauthorizedError("invalid email or password")
+	ErrEmptyEmail            = FieldValidationError("email", "email cannot be empty")
+	ErrEmptyPassword         = FieldValidationError("password", "password cannot be empty")
+	ErrEmptyFirstName        = FieldValidationError("firstName", "first name cannot be empty")
+	ErrEmptyLastName         = FieldValidationError("lastName", "last name cannot be empty")
+	ErrInvalidRole           = ValidationError("invalid user role")
+	ErrEmptyContact          = FieldValidationError("contact", "contact information cannot be empty")
 	ErrPasswordHashingFailed = errors.New("password hashing failed")
+	ErrNoProfileChanges      = ValidationError("no profile fields to update")
+	ErrEmailTaken            = ConflictError("email is already in use")
+	ErrSameEmail             = FieldValidationError("newEmail", "new email is the same as the current email")
+	ErrInvalidEmailToken     = ValidationError("email confirmation token is invalid or expired")
+	ErrSamePassword          = FieldValidationError("newPassword", "new password must be different from the current password")
+)
+
+var (
+	EMAIL_CHANGE_TTL      = 24 * time.Hour
+	AVATAR_SIZE           = 256
+	AVATAR_THUMBNAIL_SIZE = 64
 )
 
 type UserService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	mailer    Mailer
+	uploadDir string
 }
 
 func NewUserService(db *gorm.DB) *UserService {
-	return &UserService{db: db}
+	return &UserService{db: db, mailer: LogMailer{}, uploadDir: UploadDirectory()}
 }
 
-func validateUserRegistration(user models.RegisterUser) error {
-	if user.Email == "" {
-		return ErrEmptyEmail
+// validateUserRegistration checks every field and returns the registration
+// with email and contact normalized for storage.
+func validateUserRegistration(user models.RegisterUser) (models.RegisterUser, error) {
+	if strings.TrimSpace(user.Email) == "" {
+		return user, ErrEmptyEmail
 	}
 	if user.Password == "" {
-		return ErrEmptyPassword
+		return user, ErrEmptyPassword
 	}
-	if user.FirstName == "" {
-		return ErrEmptyFirstName
+	if strings.TrimSpace(user.FirstName) == "" {
+		return user, ErrEmptyFirstName
 	}
-	if user.LastName == "" {
-		return ErrEmptyLastName
+	if strings.TrimSpace(user.LastName) == "" {
+		return user, ErrEmptyLastName
 	}
-	if user.Contact == "" {
-		return ErrEmptyContact
+	if strings.TrimSpace(user.Contact) == "" {
+		return user, ErrEmptyContact
 	}
 
-	return nil
+	email, err := NormalizeEmail(user.Email)
+	if err != nil {
+		return user, err
+	}
+
+	contact, err := NormalizePHMobile(user.Contact)
+	if err != nil {
+		return user, err
+	}
+
+	if err := ValidatePasswordStrength(user.Password); err != nil {
+		return user, err
+	}
+
+	user.Email = email
+	user.Contact = contact
+	user.FirstName = strings.TrimSpace(user.FirstName)
+	user.LastName = strings.TrimSpace(user.LastName)
+
+	return user, nil
 }
 
 func validateLoginUser(loginUser models.LoginUser) (models.UserStruct, error){
@@ -60,48 +99,78 @@ func validateLoginUser(loginUser models.LoginUser) (models.UserStruct, error){
 	return models.UserStruct{}, nil
 }
 
+// RegisterUser creates a citizen account and files a pending residency
+// claim for the chosen barangay, to be approved by one of its officials.
 func (s *UserService) RegisterUser(registerUser models.RegisterUser) error {
-	if err := validateUserRegistration(registerUser); err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
-
-	hash, err := HashPassword(registerUser.Password)
+	registerUser, err := validateUserRegistration(registerUser)
 	if err != nil {
-		return fmt.Errorf("%w: %v", ErrPasswordHashingFailed, err)
+		return fmt.Errorf("validation failed: %w", err)
 	}
 
 	barangay_ID, err := strconv.Atoi(registerUser.Barangay_ID)
-	if err != nil {
+	if err != nil || barangay_ID <= 0 {
 		return fmt.Errorf("%w: %s", ErrorInvalidBarangayID, registerUser.Barangay_ID)
 	}
 
 	barangay_ID_uint := uint(barangay_ID)
 
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("LOWER(email) = ?", registerUser.Email).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check email availability: %w", err)
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+
+	hash, err := HashPassword(registerUser.Password)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPasswordHashingFailed, err)
+	}
+
 	user := models.User{
 		Email:       registerUser.Email,
 		Password:    hash,
 		FirstName:   registerUser.FirstName,
 		LastName:    registerUser.LastName,
-		Role:        registerUser.Role,
+		Role:        models.RoleCitizen,
 		Barangay_ID: &barangay_ID_uint,
 		Contact:     registerUser.Contact,
 	}
 
-	if err := s.db.Create(&user).Error; err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
-	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
 
-	return nil
+		claim := models.ResidencyClaim{
+			UserID:      user.ID,
+			Barangay_ID: barangay_ID_uint,
+			Status:      models.ResidencyPending,
+		}
+
+		if err := tx.Create(&claim).Error; err != nil {
+			return fmt.Errorf("failed to file residency claim: %w", err)
+		}
+
+		return nil
+	})
 }
 
 func (s *UserService) LoginUser(loginUser models.LoginUser) (models.UserStruct, error) {
 
 	validateLoginUser(loginUser)
 
+	// emails are matched case-insensitively like at registration, accounts
+	// registered before emails were normalized may still be stored mixed case
+	email, err := NormalizeEmail(loginUser.Email)
+	if err != nil {
+		return models.UserStruct{}, ErrInvalidCredentials
+	}
+
 	var user models.UserStruct
 	if err := s.db.Model(&models.User{}).
-		Select("id, password, role, barangay_id").
-		Where("email = ?", loginUser.Email).
+		Select("id, password, role, barangay_id, session_version").
+		Where("LOWER(email) = ?", email).
 		Scan(&user).Error; err != nil {
 		return models.UserStruct{}, fmt.Errorf("%w: email not found", ErrUserNotFound)
 	}
@@ -124,7 +193,7 @@ func (s *UserService) GetUserProfile(userID uint) (models.UserProfile, error) {
 
 	var userProfile models.UserProfile
 	if err := s.db.Model(&models.User{}).
-		Select("id, email, first_name, last_name, role, contact").
+		Select("id, email, first_name, last_name, role, contact, profile_picture, profile_thumbnail, pending_email, residency_verified").
 		Where("id = ?", userID).
 		Scan(&userProfile).Error; err != nil {
 		return models.UserProfile{}, fmt.Errorf("%w: user ID %d not found", ErrUserNotFound, userID)
@@ -132,3 +201,238 @@ func (s *UserService) GetUserProfile(userID uint) (models.UserProfile, error) {
 
 	return userProfile, nil
 }
+
+// checkCurrentPassword re-authenticates the user before a sensitive change.
+func (s *UserService) checkCurrentPassword(userID uint, password string) (models.User, error) {
+	if password == "" {
+		return models.User{}, ErrEmptyPassword
+	}
+
+	var user models.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		return models.User{}, fmt.Errorf("%w: user ID %d not found", ErrUserNotFound, userID)
+	}
+
+	if !CheckPassword(user.Password, password) {
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *UserService) UpdateUserProfile(userID uint, update models.UpdateProfile) error {
+
+	changes := map[string]interface{}{}
+	if name := strings.TrimSpace(update.FirstName); name != "" {
+		changes["first_name"] = name
+	}
+	if name := strings.TrimSpace(update.LastName); name != "" {
+		changes["last_name"] = name
+	}
+	if strings.TrimSpace(update.Contact) != "" {
+		contact, err := NormalizePHMobile(update.Contact)
+		if err != nil {
+			return err
+		}
+		changes["contact"] = contact
+	}
+
+	if len(changes) == 0 {
+		return ErrNoProfileChanges
+	}
+
+	result := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(changes)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update profile: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: user ID %d not found", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// RequestEmailChange stores the new address as pending and mails a
+// confirmation link to it. The email only changes once the link is used.
+func (s *UserService) RequestEmailChange(userID uint, change models.ChangeEmail) error {
+
+	if strings.TrimSpace(change.NewEmail) == "" {
+		return ErrEmptyEmail
+	}
+
+	newEmail, err := NormalizeEmail(change.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.checkCurrentPassword(userID, change.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return ErrSameEmail
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("LOWER(email) = ?", newEmail).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check email availability: %w", err)
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+
+	expiry := time.Now().Add(EMAIL_CHANGE_TTL)
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"pending_email":       newEmail,
+		"email_change_token":  hashToken(token),
+		"email_change_expiry": expiry,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to save pending email: %w", err)
+	}
+
+	link := fmt.Sprintf("%s/confirm-email?token=%s", AppURL(), token)
+	body := fmt.Sprintf("Hi %s,\n\nConfirm your new email address for WOW Bato by opening the link below. It expires in %d hours.\n\n%s\n", user.FirstName, int(EMAIL_CHANGE_TTL.Hours()), link)
+
+	if err := s.mailer.Send(newEmail, "Confirm your new email address", body); err != nil {
+		return fmt.Errorf("failed to send confirmation email: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UserService) ConfirmEmailChange(token string) error {
+	if token == "" {
+		return ErrInvalidEmailToken
+	}
+
+	var user models.User
+	if err := s.db.Where("email_change_token = ?", hashToken(token)).First(&user).Error; err != nil {
+		return ErrInvalidEmailToken
+	}
+
+	if user.EmailChangeExpiry == nil || time.Now().After(*user.EmailChangeExpiry) {
+		return ErrInvalidEmailToken
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("LOWER(email) = ? AND id <> ?", user.PendingEmail, user.ID).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check email availability: %w", err)
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"email":               user.PendingEmail,
+		"pending_email":       gorm.Expr("NULL"),
+		"email_change_token":  gorm.Expr("NULL"),
+		"email_change_expiry": gorm.Expr("NULL"),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to confirm email change: %w", err)
+	}
+
+	return nil
+}
+
+// ChangePassword replaces the password and bumps the session version so
+// every other session of the user is logged out. The new version is
+// returned so the caller can keep its own session valid.
+func (s *UserService) ChangePassword(userID uint, change models.ChangePassword) (uint, error) {
+	if change.NewPassword == "" {
+		return 0, ErrEmptyPassword
+	}
+
+	if err := ValidatePasswordStrength(change.NewPassword); err != nil {
+		return 0, err
+	}
+
+	user, err := s.checkCurrentPassword(userID, change.CurrentPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	if CheckPassword(user.Password, change.NewPassword) {
+		return 0, ErrSamePassword
+	}
+
+	hash, err := HashPassword(change.NewPassword)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrPasswordHashingFailed, err)
+	}
+
+	newVersion := user.SessionVersion + 1
+	if err := s.db.Model(&user).Updates(map[string]interface{}{
+		"password":        hash,
+		"session_version": newVersion,
+	}).Error; err != nil {
+		return 0, fmt.Errorf("failed to change password: %w", err)
+	}
+
+	return newVersion, nil
+}
+
+func (s *UserService) GetSessionVersion(userID uint) (uint, error) {
+
+	var user models.User
+	if err := s.db.Select("id, session_version").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("%w: user ID %d not found", ErrUserNotFound, userID)
+		}
+		return 0, fmt.Errorf("failed to retrieve session version: %w", err)
+	}
+
+	return user.SessionVersion, nil
+}
+
+// UpdateProfilePicture stores the uploaded image as a square avatar plus a
+// small thumbnail and points the user's profile at them.
+func (s *UserService) UpdateProfilePicture(userID uint, file io.Reader) (models.ProfilePictureResponse, error) {
+
+	img, err := decodeImage(file)
+	if err != nil {
+		return models.ProfilePictureResponse{}, err
+	}
+
+	suffix, err := randomHex(8)
+	if err != nil {
+		return models.ProfilePictureResponse{}, fmt.Errorf("failed to name avatar: %w", err)
+	}
+
+	base := fmt.Sprintf("user-%d-%s", userID, suffix)
+
+	picture, err := savePNG(s.uploadDir, "avatars", fmt.Sprintf("%s-%d.png", base, AVATAR_SIZE), squareThumbnail(img, AVATAR_SIZE))
+	if err != nil {
+		return models.ProfilePictureResponse{}, err
+	}
+
+	thumbnail, err := savePNG(s.uploadDir, "avatars", fmt.Sprintf("%s-%d.png", base, AVATAR_THUMBNAIL_SIZE), squareThumbnail(img, AVATAR_THUMBNAIL_SIZE))
+	if err != nil {
+		return models.ProfilePictureResponse{}, err
+	}
+
+	result := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"profile_picture":   picture,
+		"profile_thumbnail": thumbnail,
+	})
+	if result.Error != nil {
+		return models.ProfilePictureResponse{}, fmt.Errorf("failed to save profile picture: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return models.ProfilePictureResponse{}, fmt.Errorf("%w: user ID %d not found", ErrUserNotFound, userID)
+	}
+
+	return models.ProfilePictureResponse{ProfilePicture: picture, ProfileThumbnail: thumbnail}, nil
+}
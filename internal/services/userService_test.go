@@ -1,7 +1,15 @@
 package services
 
 import (
+	"bytes"
 	"database/sql"
+	"errors"
+	"image"
+	"image/png"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"testing"
 	"wow-bato-backend/internal/models"
 
@@ -34,19 +42,25 @@ func TestUserService_RegisterUser(t *testing.T) {
 
 	svc := NewUserService(gormDB)
 	registerUser := models.RegisterUser{
-		Email:       "test@example.com",
+		Email:       "Test@Example.com",
 		Password:    "password123",
 		FirstName:   "Test",
 		LastName:    "User",
-		Role:        "user",
 		Barangay_ID: "1",
-		Contact:     "1234567890",
+		Contact:     "0917 123 4567",
 	}
 
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "users" WHERE LOWER\(email\) = \$1`).
+		WithArgs("test@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
 	mock.ExpectBegin()
 	mock.ExpectQuery(`INSERT INTO "users"`).
 		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
-			"test@example.com", sqlmock.AnyArg(), "Test", "User", "user", uint(1), "1234567890").
+			"test@example.com", sqlmock.AnyArg(), "Test", "User", models.RoleCitizen, 0, "+639171234567", false, uint(1)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`INSERT INTO "residency_claims"`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+			uint(1), uint(1), models.ResidencyPending, "").
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
@@ -94,18 +108,18 @@ func TestUserService_LoginUser(t *testing.T) {
 		Password: "password123",
 	}
 
-	userRows := sqlmock.NewRows([]string{"id", "password", "role", "barangay_id"}).
-		AddRow(1, hashedPassword, "user", 1)
+	userRows := sqlmock.NewRows([]string{"id", "password", "role", "barangay_id", "session_version"}).
+		AddRow(1, hashedPassword, "user", 1, 0)
 
-	mock.ExpectQuery(`SELECT id, password, role, barangay_id FROM "users" WHERE email = \$1`).
+	mock.ExpectQuery(`SELECT id, password, role, barangay_id, session_version FROM "users" WHERE LOWER\(email\) = \$1`).
 		WithArgs("test@example.com").
 		WillReturnRows(userRows)
 
 	barangayRows := sqlmock.NewRows([]string{"name"}).
 		AddRow("Test Barangay")
 
-	mock.ExpectQuery(`SELECT name FROM "barangays" WHERE id = \$1`).
-		WithArgs(1).
+	mock.ExpectQuery(`SELECT "name" FROM "barangays" WHERE id = \$1`).
+		WithArgs(uint(1), 1).
 		WillReturnRows(barangayRows)
 
 	result, err := svc.LoginUser(loginUser)
@@ -164,10 +178,10 @@ func TestUserService_GetUserProfile(t *testing.T) {
 	userID := uint(1)
 
 	// Mock user profile data retrieval
-	profileRows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "role", "contact"}).
-		AddRow(1, "test@example.com", "Test", "User", "user", "1234567890")
+	profileRows := sqlmock.NewRows([]string{"id", "email", "first_name", "last_name", "role", "contact", "profile_picture", "profile_thumbnail", "pending_email", "residency_verified"}).
+		AddRow(1, "test@example.com", "Test", "User", "user", "1234567890", nil, nil, nil, false)
 
-	mock.ExpectQuery(`SELECT id, email, first_name, last_name, role, contact FROM "users" WHERE id = \$1`).
+	mock.ExpectQuery(`SELECT id, email, first_name, last_name, role, contact, profile_picture, profile_thumbnail, pending_email, residency_verified FROM "users" WHERE id = \$1`).
 		WithArgs(userID).
 		WillReturnRows(profileRows)
 
@@ -207,3 +221,230 @@ func TestUserService_GetUserProfile(t *testing.T) {
 		t.Errorf("Unfulfilled expectations: %v", err)
 	}
 }
+
+func newUserServiceMock(t *testing.T) (*UserService, sqlmock.Sqlmock, *sql.DB) {
+	gormDB, mock, db := newMockDB(t)
+	return NewUserService(gormDB), mock, db
+}
+
+func TestUserService_UpdateUserProfile(t *testing.T) {
+	svc, mock, db := newUserServiceMock(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET "contact"=\$1,"first_name"=\$2,"updated_at"=\$3 WHERE id = \$4`).
+		WithArgs("+639171234567", "Juan", sqlmock.AnyArg(), uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := svc.UpdateUserProfile(1, models.UpdateProfile{FirstName: " Juan ", Contact: "09171234567"})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserService_UpdateUserProfile_NoChanges(t *testing.T) {
+	svc, _, db := newUserServiceMock(t)
+	defer db.Close()
+
+	err := svc.UpdateUserProfile(1, models.UpdateProfile{FirstName: "  "})
+	if !errors.Is(err, ErrNoProfileChanges) {
+		t.Errorf("Expected ErrNoProfileChanges, got %v", err)
+	}
+}
+
+func TestUserService_ChangePassword_WrongCurrentPassword(t *testing.T) {
+	svc, mock, db := newUserServiceMock(t)
+	defer db.Close()
+
+	hashedPassword, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE id = \$1`).
+		WithArgs(uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "session_version"}).
+			AddRow(1, hashedPassword, 0))
+
+	_, err = svc.ChangePassword(1, models.ChangePassword{CurrentPassword: "wrong", NewPassword: "newPassword123"})
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected ErrInvalidCredentials, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserService_ChangePassword(t *testing.T) {
+	svc, mock, db := newUserServiceMock(t)
+	defer db.Close()
+
+	hashedPassword, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE id = \$1`).
+		WithArgs(uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "session_version"}).
+			AddRow(1, hashedPassword, 3))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET "password"=\$1,"session_version"=\$2,"updated_at"=\$3 WHERE "users"."deleted_at" IS NULL AND "id" = \$4`).
+		WithArgs(sqlmock.AnyArg(), uint(4), sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	version, err := svc.ChangePassword(1, models.ChangePassword{CurrentPassword: "password123", NewPassword: "newPassword123"})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if version != 4 {
+		t.Errorf("Expected session version 4, got %d", version)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserService_ConfirmEmailChange_InvalidToken(t *testing.T) {
+	svc, mock, db := newUserServiceMock(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT \* FROM "users" WHERE email_change_token = \$1`).
+		WithArgs(hashToken("bad-token"), 1).
+		WillReturnError(gorm.ErrRecordNotFound)
+
+	err := svc.ConfirmEmailChange("bad-token")
+	if !errors.Is(err, ErrInvalidEmailToken) {
+		t.Errorf("Expected ErrInvalidEmailToken, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserService_UpdateProfilePicture(t *testing.T) {
+	svc, mock, db := newUserServiceMock(t)
+	defer db.Close()
+
+	svc.uploadDir = t.TempDir()
+
+	var upload bytes.Buffer
+	if err := png.Encode(&upload, image.NewRGBA(image.Rect(0, 0, 400, 300))); err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users" SET "profile_picture"=\$1,"profile_thumbnail"=\$2,"updated_at"=\$3 WHERE id = \$4`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), uint(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	result, err := svc.UpdateProfilePicture(1, &upload)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !strings.HasPrefix(result.ProfilePicture, "/uploads/avatars/user-1-") || !strings.HasSuffix(result.ProfilePicture, "-256.png") {
+		t.Errorf("Unexpected profile picture URL %s", result.ProfilePicture)
+	}
+
+	if !strings.HasSuffix(result.ProfileThumbnail, "-64.png") {
+		t.Errorf("Unexpected thumbnail URL %s", result.ProfileThumbnail)
+	}
+
+	if _, err := os.Stat(filepath.Join(svc.uploadDir, "avatars", path.Base(result.ProfileThumbnail))); err != nil {
+		t.Errorf("Expected thumbnail file to be written: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
+
+func TestUserService_UpdateProfilePicture_InvalidImage(t *testing.T) {
+	svc, _, db := newUserServiceMock(t)
+	defer db.Close()
+
+	_, err := svc.UpdateProfilePicture(1, strings.NewReader("not an image"))
+	if !errors.Is(err, ErrInvalidImage) {
+		t.Errorf("Expected ErrInvalidImage, got %v", err)
+	}
+}
+
+func TestUserService_RegisterUser_Validation(t *testing.T) {
+	svc, _, db := newUserServiceMock(t)
+	defer db.Close()
+
+	valid := models.RegisterUser{
+		Email:       "juan@example.com",
+		Password:    "password123",
+		FirstName:   "Juan",
+		LastName:    "Dela Cruz",
+		Barangay_ID: "1",
+		Contact:     "09171234567",
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(u *models.RegisterUser)
+		wantErr error
+	}{
+		{name: "Invalid Email", modify: func(u *models.RegisterUser) { u.Email = "juan@" }, wantErr: ErrInvalidEmail},
+		{name: "Landline Contact", modify: func(u *models.RegisterUser) { u.Contact = "032-123-4567" }, wantErr: ErrInvalidContact},
+		{name: "Weak Password", modify: func(u *models.RegisterUser) { u.Password = "password" }, wantErr: ErrWeakPassword},
+		{name: "Missing First Name", modify: func(u *models.RegisterUser) { u.FirstName = " " }, wantErr: ErrEmptyFirstName},
+		{name: "Invalid Barangay", modify: func(u *models.RegisterUser) { u.Barangay_ID = "abc" }, wantErr: ErrorInvalidBarangayID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := valid
+			tt.modify(&user)
+
+			if err := svc.RegisterUser(user); !errors.Is(err, tt.wantErr) {
+				t.Errorf("RegisterUser() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserService_LoginUserMixedCaseEmail(t *testing.T) {
+	svc, mock, db := newUserServiceMock(t)
+	defer db.Close()
+
+	hashedPassword, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+
+	// logging in as Juan@Mail.com matches the account however its email was stored
+	mock.ExpectQuery(`SELECT id, password, role, barangay_id, session_version FROM "users" WHERE LOWER\(email\) = \$1`).
+		WithArgs("juan@mail.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "password", "role", "barangay_id", "session_version"}).
+			AddRow(1, hashedPassword, models.RoleCitizen, 1, 0))
+	mock.ExpectQuery(`SELECT "name" FROM "barangays" WHERE id = \$1`).
+		WithArgs(uint(1), 1).
+		WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("Test Barangay"))
+
+	result, err := svc.LoginUser(models.LoginUser{Email: " Juan@Mail.com ", Password: "password123"})
+	if err != nil {
+		t.Fatalf("LoginUser() error = %v", err)
+	}
+	if result.ID != 1 {
+		t.Errorf("Expected user ID 1, got %d", result.ID)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unfulfilled expectations: %v", err)
+	}
+}
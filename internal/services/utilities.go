@@ -2,7 +2,6 @@ package services
 
 import (
 	"errors"
-	"net/http"
 	"strconv"
 	"wow-bato-backend/internal/models"
 
@@ -11,6 +10,12 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUnauthenticated = UnauthorizedError("Unauthorized")
+	ErrInvalidSession  = UnauthorizedError("Invalid session data")
+	ErrForbidden       = ForbiddenError("Forbidden")
+)
+
 func HashPassword(password string) (string, error) {
 	if password == "" {
 		return "", errors.New("password cannot be empty")
@@ -24,11 +29,17 @@ func CheckPassword(hashedPassword, password string) bool {
 	return err == nil
 }
 
-func BindJSON(c *gin.Context, obj interface{}) {
+// BindJSON decodes and validates the request body into obj, writing every
+// failing field and returning false when it cannot.
+func BindJSON(c *gin.Context, obj interface{}) bool {
+	registerValidatorsOnce.Do(registerValidators)
+
 	if err := c.ShouldBindJSON(obj); err != nil {
-		c.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return 
+		WriteError(c, bindingError(err))
+		return false
 	}
+
+	return true
 }
 
 func SetSession(session sessions.Session, user models.UserStruct){
@@ -36,26 +47,46 @@ func SetSession(session sessions.Session, user models.UserStruct){
 	session.Set("user_role", user.Role)
 	session.Set("barangay_id", user.Barangay_ID)
 	session.Set("barangay_name", user.Barangay_Name)
+	session.Set("session_version", user.SessionVersion)
 	session.Set("authenticated", true)
 }
 
-func CheckServiceError(c *gin.Context, err error){
-	if err != nil {
-		c.IndentedJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+// CheckServiceError writes the error envelope for err and returns true when
+// the handler must stop.
+func CheckServiceError(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
 	}
+
+	WriteError(c, err)
+	return true
 }
 
-func CheckAuthentication(c *gin.Context) sessions.Session{
+// CheckAuthentication returns the session, or writes a 401 and returns nil.
+func CheckAuthentication(c *gin.Context) sessions.Session {
 	session := sessions.Default(c)
 	if session.Get("authenticated") != true {
-		c.IndentedJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		WriteError(c, ErrUnauthenticated)
 		return nil
 	}
 
 	return session
 }
 
+// CheckRole writes a 403 and returns false unless the session user has one
+// of the given roles.
+func CheckRole(c *gin.Context, session sessions.Session, roles ...string) bool {
+	role, _ := session.Get("user_role").(string)
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+
+	WriteError(c, ErrForbidden)
+	return false
+}
+
 func ConvertToInt(stringData string) (int, error) {
 	data, err := strconv.Atoi(stringData)
 	if err != nil {
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"wow-bato-backend/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	ErrInvalidEmail   = FieldValidationError("email", "email address is not valid")
+	ErrInvalidContact = FieldValidationError("contact", "contact must be a Philippine mobile number (09XXXXXXXXX or +639XXXXXXXXX)")
+	ErrWeakPassword   = FieldValidationError("password", "password must be 8 to 72 characters and contain a letter and a number")
+)
+
+// VALIDATION_FAILED_MESSAGE heads the envelope when binding tags fail, the
+// details are in the per-field errors.
+var VALIDATION_FAILED_MESSAGE = "Request validation failed"
+
+var phMobilePattern = regexp.MustCompile(`^(?:\+?63|0)(9\d{9})$`)
+
+// NormalizeEmail trims and lowercases an address after checking its syntax.
+// Display names ("Juan <juan@example.com>") are rejected.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return "", ErrInvalidEmail
+	}
+
+	at := strings.LastIndex(email, "@")
+	if !strings.Contains(email[at+1:], ".") {
+		return "", ErrInvalidEmail
+	}
+
+	return email, nil
+}
+
+// NormalizePHMobile accepts 09XXXXXXXXX, 639XXXXXXXXX and +639XXXXXXXXX with
+// optional spaces, dashes or parentheses and returns the +639XXXXXXXXX form.
+func NormalizePHMobile(contact string) (string, error) {
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '(', ')', '.':
+			return -1
+		}
+		return r
+	}, contact)
+
+	match := phMobilePattern.FindStringSubmatch(cleaned)
+	if match == nil {
+		return "", ErrInvalidContact
+	}
+
+	return "+63" + match[1], nil
+}
+
+// ValidatePasswordStrength requires 8 to 72 bytes (bcrypt ignores anything
+// longer) with at least one letter and one digit.
+func ValidatePasswordStrength(password string) error {
+	if len(password) < 8 || len(password) > 72 {
+		return ErrWeakPassword
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+
+	if !hasLetter || !hasDigit {
+		return ErrWeakPassword
+	}
+
+	return nil
+}
+
+var registerValidatorsOnce sync.Once
+
+// registerValidators teaches gin's validator the rules used in the request
+// models' binding tags and makes it report fields by their JSON names.
+func registerValidators() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	validate.RegisterValidation("ph_mobile", func(fl validator.FieldLevel) bool {
+		_, err := NormalizePHMobile(fl.Field().String())
+		return err == nil
+	})
+
+	validate.RegisterValidation("strong_password", func(fl validator.FieldLevel) bool {
+		return ValidatePasswordStrength(fl.Field().String()) == nil
+	})
+
+	validate.RegisterStructValidation(validateProjectDates, models.NewProject{})
+}
+
+// validateProjectDates rejects projects that end before they start. Malformed
+// dates are already reported by the datetime tag.
+func validateProjectDates(sl validator.StructLevel) {
+	project := sl.Current().Interface().(models.NewProject)
+
+	start, startErr := time.Parse(GO_DATE_FORMAT, project.StartDate)
+	end, endErr := time.Parse(GO_DATE_FORMAT, project.EndDate)
+	if startErr != nil || endErr != nil {
+		return
+	}
+
+	if end.Before(start) {
+		sl.ReportError(project.EndDate, "endDate", "EndDate", "date_order", "startDate")
+	}
+}
+
+// bindingError converts a ShouldBindJSON failure into a validation error
+// listing every failing field.
+func bindingError(err error) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, FieldError{Field: fieldErr.Field(), Message: fieldMessage(fieldErr)})
+		}
+		return &ServiceError{Kind: KindValidation, Message: VALIDATION_FAILED_MESSAGE, Fields: fields}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return FieldValidationError(typeErr.Field, fmt.Sprintf("%s must be a %s", typeErr.Field, typeErr.Type.Kind()))
+	}
+
+	return ValidationError("Request body is not valid JSON")
+}
+
+// fieldMessage renders a readable message for a failed binding tag.
+func fieldMessage(fieldErr validator.FieldError) string {
+	field := fieldErr.Field()
+
+	switch fieldErr.Tag() {
+	case "required", "required_without":
+		return field + " is required"
+	case "len":
+		return fmt.Sprintf("%s must be exactly %s characters", field, fieldErr.Param())
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters", field, fieldErr.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fieldErr.Param())
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters", field, fieldErr.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, fieldErr.Param())
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fieldErr.Param(), " ", ", "))
+	case "numeric":
+		return field + " must be a number"
+	case "datetime":
+		return field + " must be a date in YYYY-MM-DD format"
+	case "date_order":
+		return fmt.Sprintf("%s must not be before %s", field, fieldErr.Param())
+	case "ph_mobile":
+		return ErrInvalidContact.Error()
+	case "strong_password":
+		return ErrWeakPassword.Error()
+	}
+
+	return field + " is not valid"
+}
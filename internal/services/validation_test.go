@@ -0,0 +1,188 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"wow-bato-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		name    string
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Valid", email: "juan@example.com", want: "juan@example.com"},
+		{name: "Trims And Lowercases", email: "  Juan.Dela@Example.COM ", want: "juan.dela@example.com"},
+		{name: "Missing At", email: "juan.example.com", wantErr: true},
+		{name: "Missing Domain Dot", email: "juan@localhost", wantErr: true},
+		{name: "Display Name", email: "Juan <juan@example.com>", wantErr: true},
+		{name: "Empty", email: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeEmail(tt.email)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrInvalidEmail) {
+				t.Errorf("NormalizeEmail() error = %v, want ErrInvalidEmail", err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeEmail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizePHMobile(t *testing.T) {
+	tests := []struct {
+		name    string
+		contact string
+		want    string
+		wantErr bool
+	}{
+		{name: "Local Format", contact: "09171234567", want: "+639171234567"},
+		{name: "International Format", contact: "+639171234567", want: "+639171234567"},
+		{name: "Without Plus", contact: "639171234567", want: "+639171234567"},
+		{name: "With Spaces", contact: "+63 917 123 4567", want: "+639171234567"},
+		{name: "With Dashes", contact: "0917-123-4567", want: "+639171234567"},
+		{name: "Landline", contact: "(032) 123 4567", wantErr: true},
+		{name: "Too Short", contact: "0917123456", wantErr: true},
+		{name: "Letters", contact: "0917abc4567", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePHMobile(tt.contact)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePHMobile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePHMobile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{name: "Letters And Digits", password: "password123"},
+		{name: "Too Short", password: "pass12", wantErr: true},
+		{name: "No Digit", password: "passwordonly", wantErr: true},
+		{name: "No Letter", password: "1234567890", wantErr: true},
+		{name: "Too Long", password: "a1234567890123456789012345678901234567890123456789012345678901234567890123", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidatePasswordStrength(tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePasswordStrength() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		bind       func(c *gin.Context) bool
+		wantFields []string
+	}{
+		{
+			name:       "Valid Project",
+			body:       `{"name":"Road","startDate":"2024-06-01","endDate":"2024-06-30","status":"planned"}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.NewProject{}) },
+			wantFields: nil,
+		},
+		{
+			name:       "Every Failing Field Reported",
+			body:       `{"startDate":"June 1","endDate":"2024-06-30","status":"pending"}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.NewProject{}) },
+			wantFields: []string{"name", "startDate", "status"},
+		},
+		{
+			name:       "End Before Start",
+			body:       `{"name":"Road","startDate":"2024-06-30","endDate":"2024-06-01","status":"planned"}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.NewProject{}) },
+			wantFields: []string{"endDate"},
+		},
+		{
+			name:       "Non Positive Amount",
+			body:       `{"name":"Cement","amount_allocated":-5}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.NewBudgetItem{}) },
+			wantFields: []string{"amount_allocated"},
+		},
+		{
+			name:       "Budget Item Created Approved",
+			body:       `{"name":"Cement","amount_allocated":5000,"status":"approved"}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.NewBudgetItem{}) },
+			wantFields: []string{"status"},
+		},
+		{
+			name:       "Wrong JSON Type",
+			body:       `{"name":"Cement","amount_allocated":"lots"}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.NewBudgetItem{}) },
+			wantFields: []string{"amount_allocated"},
+		},
+		{
+			name:       "Custom Rules",
+			body:       `{"email":"juan@example.com","password":"short","firstName":"Juan","lastName":"Dela Cruz","barangay":"1","contact":"12345"}`,
+			bind:       func(c *gin.Context) bool { return BindJSON(c, &models.RegisterUser{}) },
+			wantFields: []string{"contact", "password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			ok := tt.bind(c)
+			if ok != (tt.wantFields == nil) {
+				t.Fatalf("BindJSON() = %v, body %s", ok, w.Body.String())
+			}
+			if ok {
+				return
+			}
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+
+			var body struct {
+				Error ErrorResponse `json:"error"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Failed to decode envelope: %v", err)
+			}
+
+			var got []string
+			for _, field := range body.Error.Fields {
+				got = append(got, field.Field)
+			}
+			sort.Strings(got)
+			if strings.Join(got, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("Expected fields %v, got %+v", tt.wantFields, body.Error.Fields)
+			}
+		})
+	}
+}